@@ -0,0 +1,347 @@
+package memory
+
+import (
+	"strings"
+
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+)
+
+// SchemaBuilder builds a DatabaseSchema for tests and fixtures
+type SchemaBuilder struct {
+	schema domain.DatabaseSchema
+}
+
+// NewSchema starts building a database schema
+func NewSchema(databaseName string) *SchemaBuilder {
+	return &SchemaBuilder{schema: domain.DatabaseSchema{DatabaseName: databaseName}}
+}
+
+// Collation sets the database default collation
+func (b *SchemaBuilder) Collation(collation string) *SchemaBuilder {
+	b.schema.Collation = collation
+	return b
+}
+
+// ServerCollation sets the default collation of the server
+func (b *SchemaBuilder) ServerCollation(collation string) *SchemaBuilder {
+	b.schema.ServerCollation = collation
+	return b
+}
+
+// ScopedConfiguration sets a database scoped configuration value
+func (b *SchemaBuilder) ScopedConfiguration(name, value string) *SchemaBuilder {
+	b.schema.ScopedConfigurations = append(b.schema.ScopedConfigurations, domain.ScopedConfiguration{Name: name, Value: value})
+	return b
+}
+
+// Schema adds a schema owned by owner. An empty owner stands for a schema
+// whose owner principal was dropped.
+func (b *SchemaBuilder) Schema(name, owner string) *SchemaBuilder {
+	b.schema.Schemas = append(b.schema.Schemas, domain.Schema{Name: name, Owner: owner})
+	return b
+}
+
+// Table adds a table
+func (b *SchemaBuilder) Table(t *TableBuilder) *SchemaBuilder {
+	b.schema.Tables = append(b.schema.Tables, t.Build())
+	return b
+}
+
+// View adds a view. Modules added by the builder use the default
+// ANSI_NULLS and QUOTED_IDENTIFIER ON settings.
+func (b *SchemaBuilder) View(schemaName, name, definition string) *SchemaBuilder {
+	b.schema.Views = append(b.schema.Views, domain.View{
+		SchemaName: schemaName, Name: name, Definition: definition,
+		UsesAnsiNulls: true, UsesQuotedIdentifier: true,
+	})
+	return b
+}
+
+// Procedure adds a stored procedure
+func (b *SchemaBuilder) Procedure(schemaName, name, definition string) *SchemaBuilder {
+	b.schema.StoredProcedures = append(b.schema.StoredProcedures,
+		domain.StoredProcedure{
+			SchemaName: schemaName, Name: name, Definition: definition,
+			UsesAnsiNulls: true, UsesQuotedIdentifier: true,
+		})
+	return b
+}
+
+// Function adds a function of the given type (SCALAR, TABLE or INLINE)
+func (b *SchemaBuilder) Function(schemaName, name, funcType, definition string) *SchemaBuilder {
+	b.schema.Functions = append(b.schema.Functions,
+		domain.Function{
+			SchemaName: schemaName, Name: name, FuncType: funcType, Definition: definition,
+			UsesAnsiNulls: true, UsesQuotedIdentifier: true,
+		})
+	return b
+}
+
+// Trigger adds a trigger on a table
+func (b *SchemaBuilder) Trigger(schemaName, tableName, name, definition string) *SchemaBuilder {
+	b.schema.Triggers = append(b.schema.Triggers,
+		domain.Trigger{
+			SchemaName: schemaName, TableName: tableName, Name: name, Scope: domain.TriggerScopeTable,
+			Definition: definition, UsesAnsiNulls: true, UsesQuotedIdentifier: true,
+		})
+	return b
+}
+
+// DatabaseTrigger adds a DDL trigger ON DATABASE
+func (b *SchemaBuilder) DatabaseTrigger(name, definition string) *SchemaBuilder {
+	b.schema.Triggers = append(b.schema.Triggers,
+		domain.Trigger{
+			Name: name, Scope: domain.TriggerScopeDatabase,
+			Definition: definition, UsesAnsiNulls: true, UsesQuotedIdentifier: true,
+		})
+	return b
+}
+
+// Statistic adds statistics on table columns
+func (b *SchemaBuilder) Statistic(st domain.Statistic) *SchemaBuilder {
+	b.schema.Statistics = append(b.schema.Statistics, st)
+	return b
+}
+
+// Permission adds a permission
+func (b *SchemaBuilder) Permission(p domain.Permission) *SchemaBuilder {
+	b.schema.Permissions = append(b.schema.Permissions, p)
+	return b
+}
+
+// Build returns the database schema
+func (b *SchemaBuilder) Build() *domain.DatabaseSchema {
+	schema := b.schema
+	return &schema
+}
+
+// Store returns a SchemaStore serving the built schema
+func (b *SchemaBuilder) Store() *SchemaStore {
+	return NewSchemaStore(b.Build())
+}
+
+// TableBuilder builds a Table
+type TableBuilder struct {
+	table domain.Table
+}
+
+// NewTable starts building a table
+func NewTable(schemaName, name string) *TableBuilder {
+	return &TableBuilder{table: domain.Table{SchemaName: schemaName, Name: name}}
+}
+
+// Column adds a column; ordinal positions are assigned in order
+func (b *TableBuilder) Column(c *ColumnBuilder) *TableBuilder {
+	col := c.Build()
+	col.OrdinalPosition = len(b.table.Columns) + 1
+	b.table.Columns = append(b.table.Columns, col)
+	return b
+}
+
+// PrimaryKey sets a clustered primary key on the columns
+func (b *TableBuilder) PrimaryKey(name string, columns ...string) *TableBuilder {
+	b.table.PrimaryKey = &domain.Index{
+		Name:           name,
+		SchemaName:     b.table.SchemaName,
+		TableName:      b.table.Name,
+		Type:           domain.IndexTypeClustered,
+		IsPrimaryKey:   true,
+		IsUnique:       true,
+		IsClustered:    true,
+		AllowRowLocks:  true,
+		AllowPageLocks: true,
+		Columns:        indexColumns(columns),
+	}
+	return b
+}
+
+// Index adds a nonclustered index on the columns. Prefix a column with "-"
+// for descending order.
+func (b *TableBuilder) Index(name string, unique bool, columns ...string) *TableBuilder {
+	b.table.Indexes = append(b.table.Indexes, domain.Index{
+		Name:           name,
+		SchemaName:     b.table.SchemaName,
+		TableName:      b.table.Name,
+		Type:           domain.IndexTypeNonclustered,
+		IsUnique:       unique,
+		AllowRowLocks:  true,
+		AllowPageLocks: true,
+		Columns:        indexColumns(columns),
+	})
+	return b
+}
+
+// HashIndex adds a hash index with the given bucket count, as memory-optimized
+// tables have
+func (b *TableBuilder) HashIndex(name string, bucketCount int64, columns ...string) *TableBuilder {
+	b.table.Indexes = append(b.table.Indexes, domain.Index{
+		Name:           name,
+		SchemaName:     b.table.SchemaName,
+		TableName:      b.table.Name,
+		Type:           domain.IndexTypeNonclusteredHash,
+		BucketCount:    bucketCount,
+		AllowRowLocks:  true,
+		AllowPageLocks: true,
+		Columns:        indexColumns(columns),
+	})
+	return b
+}
+
+// MemoryOptimized makes the table memory-optimized with the given durability,
+// SCHEMA_AND_DATA or SCHEMA_ONLY
+func (b *TableBuilder) MemoryOptimized(durability string) *TableBuilder {
+	b.table.IsMemoryOptimized = true
+	b.table.Durability = durability
+	return b
+}
+
+// ForeignKey adds a foreign key where columns[i] references refColumns[i]
+func (b *TableBuilder) ForeignKey(name, refSchema, refTable string, columns, refColumns []string) *TableBuilder {
+	fk := domain.ForeignKey{
+		Name:                 name,
+		SchemaName:           b.table.SchemaName,
+		TableName:            b.table.Name,
+		ReferencedSchemaName: refSchema,
+		ReferencedTableName:  refTable,
+		DeleteAction:         "NO_ACTION",
+		UpdateAction:         "NO_ACTION",
+	}
+	for i := range columns {
+		if i < len(refColumns) {
+			fk.Columns = append(fk.Columns, domain.ForeignKeyColumn{ColumnName: columns[i], ReferencedColumnName: refColumns[i]})
+		}
+	}
+	b.table.ForeignKeys = append(b.table.ForeignKeys, fk)
+	return b
+}
+
+// Check adds a check constraint
+func (b *TableBuilder) Check(name, definition string) *TableBuilder {
+	b.table.CheckConstraints = append(b.table.CheckConstraints, domain.CheckConstraint{
+		Name:       name,
+		SchemaName: b.table.SchemaName,
+		TableName:  b.table.Name,
+		Definition: definition,
+	})
+	return b
+}
+
+// Unique adds a nonclustered UNIQUE constraint on the columns
+func (b *TableBuilder) Unique(name string, columns ...string) *TableBuilder {
+	b.table.UniqueConstraints = append(b.table.UniqueConstraints, domain.UniqueConstraint{
+		Name:       name,
+		SchemaName: b.table.SchemaName,
+		TableName:  b.table.Name,
+		Columns:    indexColumns(columns),
+	})
+	return b
+}
+
+// Build returns the table. The primary key and indexes of a memory-optimized
+// table are marked as such, and its primary key is made nonclustered.
+func (b *TableBuilder) Build() domain.Table {
+	t := b.table
+	if !t.IsMemoryOptimized {
+		return t
+	}
+	if t.PrimaryKey != nil {
+		pk := *t.PrimaryKey
+		pk.IsMemoryOptimized = true
+		if pk.IsClustered {
+			pk.IsClustered = false
+			pk.Type = domain.IndexTypeNonclustered
+		}
+		t.PrimaryKey = &pk
+	}
+	t.Indexes = append([]domain.Index(nil), t.Indexes...)
+	for i := range t.Indexes {
+		t.Indexes[i].IsMemoryOptimized = true
+	}
+	return t
+}
+
+// indexColumns converts column names to index columns; a leading "-" marks descending order
+func indexColumns(names []string) []domain.IndexColumn {
+	var cols []domain.IndexColumn
+	for i, name := range names {
+		col := domain.IndexColumn{Name: name, Position: i + 1}
+		if strings.HasPrefix(name, "-") {
+			col.Name = strings.TrimPrefix(name, "-")
+			col.IsDescending = true
+		}
+		cols = append(cols, col)
+	}
+	return cols
+}
+
+// ColumnBuilder builds a Column. Columns are NOT NULL unless Nullable is called.
+type ColumnBuilder struct {
+	column domain.Column
+}
+
+// NewColumn starts building a column of the given data type (e.g. "int", "nvarchar")
+func NewColumn(name, dataType string) *ColumnBuilder {
+	return &ColumnBuilder{column: domain.Column{Name: name, DataType: dataType}}
+}
+
+// Length sets the length in characters; it is stored in bytes as in sys.columns
+func (b *ColumnBuilder) Length(chars int) *ColumnBuilder {
+	if strings.HasPrefix(strings.ToUpper(b.column.DataType), "N") {
+		chars *= 2
+	}
+	b.column.MaxLength = chars
+	return b
+}
+
+// Max sets the length to MAX
+func (b *ColumnBuilder) Max() *ColumnBuilder {
+	b.column.MaxLength = -1
+	return b
+}
+
+// Precision sets the precision and scale
+func (b *ColumnBuilder) Precision(precision, scale int) *ColumnBuilder {
+	b.column.Precision = precision
+	b.column.Scale = scale
+	return b
+}
+
+// Nullable allows NULL values
+func (b *ColumnBuilder) Nullable() *ColumnBuilder {
+	b.column.IsNullable = true
+	return b
+}
+
+// Identity makes the column an identity column
+func (b *ColumnBuilder) Identity(seed, increment int64) *ColumnBuilder {
+	b.column.IsIdentity = true
+	b.column.IdentitySeed = seed
+	b.column.IdentityIncrement = increment
+	return b
+}
+
+// Default sets a named default constraint; value is a SQL expression such as "((0))"
+func (b *ColumnBuilder) Default(name, value string) *ColumnBuilder {
+	b.column.HasDefault = true
+	b.column.DefaultName = name
+	b.column.DefaultValue = value
+	return b
+}
+
+// Computed makes the column a computed column
+func (b *ColumnBuilder) Computed(definition string) *ColumnBuilder {
+	b.column.IsComputed = true
+	b.column.ComputedDefinition = definition
+	return b
+}
+
+// Collation sets the column collation
+func (b *ColumnBuilder) Collation(collation string) *ColumnBuilder {
+	b.column.Collation = collation
+	return b
+}
+
+// Build returns the column
+func (b *ColumnBuilder) Build() domain.Column {
+	return b.column
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+)
+
+func TestTableBuilder(t *testing.T) {
+	table := NewTable("sales", "Orders").
+		Column(NewColumn("Id", "int").Identity(1, 1)).
+		Column(NewColumn("Name", "nvarchar").Length(50).Nullable()).
+		Column(NewColumn("Notes", "varchar").Max()).
+		PrimaryKey("PK_Orders", "Id").
+		Index("IX_Orders_Name", true, "Name", "-Id").
+		Build()
+
+	var positions []int
+	for _, c := range table.Columns {
+		positions = append(positions, c.OrdinalPosition)
+	}
+	if !reflect.DeepEqual(positions, []int{1, 2, 3}) {
+		t.Errorf("ordinal positions = %v, want 1, 2, 3", positions)
+	}
+	if got := table.Columns[1].MaxLength; got != 100 {
+		t.Errorf("nvarchar(50) MaxLength = %d bytes, want 100", got)
+	}
+	if got := table.Columns[2].MaxLength; got != -1 {
+		t.Errorf("varchar(max) MaxLength = %d, want -1", got)
+	}
+
+	pk := table.PrimaryKey
+	if pk == nil || !pk.IsPrimaryKey || !pk.IsClustered || pk.SchemaName != "sales" || pk.TableName != "Orders" {
+		t.Fatalf("primary key = %+v, want a clustered key on sales.Orders", pk)
+	}
+
+	idx := table.Indexes[0]
+	want := []domain.IndexColumn{{Name: "Name", Position: 1}, {Name: "Id", Position: 2, IsDescending: true}}
+	if !idx.IsUnique || idx.Type != domain.IndexTypeNonclustered || !reflect.DeepEqual(idx.Columns, want) {
+		t.Errorf("index = %+v, want a unique nonclustered index on Name, Id DESC", idx)
+	}
+}
+
+func TestTableBuilderMemoryOptimized(t *testing.T) {
+	builder := NewTable("dbo", "Sessions").
+		Column(NewColumn("Id", "int")).
+		PrimaryKey("PK_Sessions", "Id").
+		HashIndex("IX_Sessions_Id", 1024, "Id").
+		MemoryOptimized("SCHEMA_ONLY")
+	table := builder.Build()
+
+	if !table.IsMemoryOptimized || table.Durability != "SCHEMA_ONLY" {
+		t.Errorf("table memory-optimized %v with durability %q, want SCHEMA_ONLY", table.IsMemoryOptimized, table.Durability)
+	}
+	if pk := table.PrimaryKey; pk.IsClustered || pk.Type != domain.IndexTypeNonclustered || !pk.IsMemoryOptimized {
+		t.Errorf("primary key = %+v, want a nonclustered memory-optimized key", pk)
+	}
+	if idx := table.Indexes[0]; !idx.IsMemoryOptimized || idx.BucketCount != 1024 {
+		t.Errorf("hash index = %+v, want memory-optimized with 1024 buckets", idx)
+	}
+
+	// Building does not change the builder's own key and indexes
+	if builder.table.PrimaryKey.IsMemoryOptimized || builder.table.Indexes[0].IsMemoryOptimized {
+		t.Error("Build modified the builder")
+	}
+}
@@ -0,0 +1,216 @@
+// Package memory provides an in-memory implementation of the schema port.
+// It lets consumers exercise schema comparison and DDL generation without a
+// SQL Server instance.
+package memory
+
+import (
+	"context"
+
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+)
+
+// SchemaStore serves a fixed DatabaseSchema through the SchemaPort interface
+type SchemaStore struct {
+	schema *domain.DatabaseSchema
+}
+
+// NewSchemaStore creates a schema store backed by the given schema
+func NewSchemaStore(schema *domain.DatabaseSchema) *SchemaStore {
+	if schema == nil {
+		schema = &domain.DatabaseSchema{}
+	}
+	return &SchemaStore{schema: schema}
+}
+
+// ExtractSchema returns the stored schema filtered by the dump options,
+// mirroring the SQL Server extractor
+func (s *SchemaStore) ExtractSchema(ctx context.Context, opts *domain.DumpOptions) (*domain.DatabaseSchema, error) {
+	schema := &domain.DatabaseSchema{DatabaseName: s.schema.DatabaseName, ProductVersion: s.schema.ProductVersion}
+
+	// Partial snapshots skip database-level settings and schemas. Stored
+	// objects have no modification dates, so ModifiedSince is not applied.
+	if !opts.IsPartial() {
+		schema.Collation = s.schema.Collation
+		schema.ScopedConfigurations, _ = s.ExtractScopedConfigurations(ctx)
+		schema.Schemas, _ = s.ExtractSchemas(ctx, opts)
+	}
+
+	if opts.IncludeTables {
+		schema.PartitionFunctions, _ = s.ExtractPartitionFunctions(ctx)
+		schema.PartitionSchemes, _ = s.ExtractPartitionSchemes(ctx)
+		schema.Tables, _ = s.ExtractTables(ctx, opts)
+	}
+	if opts.IncludeViews {
+		schema.Views, _ = s.ExtractViews(ctx, opts)
+	}
+	if opts.IncludeProcedures {
+		schema.StoredProcedures, _ = s.ExtractProcedures(ctx, opts)
+	}
+	if opts.IncludeFunctions {
+		schema.Functions, _ = s.ExtractFunctions(ctx, opts)
+	}
+	if opts.IncludeTriggers {
+		schema.Triggers, _ = s.ExtractTriggers(ctx, opts)
+	}
+	if opts.IncludePermissions {
+		schema.Permissions, _ = s.ExtractPermissions(ctx, opts)
+	}
+	if opts.IncludeTables && opts.IncludeStatistics {
+		schema.Statistics, _ = s.ExtractStatistics(ctx, opts)
+	}
+
+	return schema, nil
+}
+
+// CountObjects counts the stored objects matching the dump options
+func (s *SchemaStore) CountObjects(ctx context.Context, opts *domain.DumpOptions) (domain.ObjectCounts, error) {
+	schema, err := s.ExtractSchema(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return schema.CountObjects(opts), nil
+}
+
+// ExtractTables returns the stored tables matching the filters
+func (s *SchemaStore) ExtractTables(ctx context.Context, opts *domain.DumpOptions) ([]domain.Table, error) {
+	var tables []domain.Table
+	for _, t := range s.schema.Tables {
+		if matchesFilter(opts.SchemaFilter, opts.SchemaExclude, t.SchemaName) &&
+			matchesFilter(opts.TableFilter, opts.TableExclude, t.Name) &&
+			domain.MatchObject(opts.ObjectFilter, t.SchemaName, t.Name) {
+			tables = append(tables, t)
+		}
+	}
+	return tables, nil
+}
+
+// ExtractViews returns the stored views matching the schema filter
+func (s *SchemaStore) ExtractViews(ctx context.Context, opts *domain.DumpOptions) ([]domain.View, error) {
+	var views []domain.View
+	for _, v := range s.schema.Views {
+		if matchesFilter(opts.SchemaFilter, opts.SchemaExclude, v.SchemaName) &&
+			domain.MatchObject(opts.ObjectFilter, v.SchemaName, v.Name) {
+			views = append(views, v)
+		}
+	}
+	return views, nil
+}
+
+// ExtractProcedures returns the stored procedures matching the schema filter
+func (s *SchemaStore) ExtractProcedures(ctx context.Context, opts *domain.DumpOptions) ([]domain.StoredProcedure, error) {
+	var procs []domain.StoredProcedure
+	for _, p := range s.schema.StoredProcedures {
+		if matchesFilter(opts.SchemaFilter, opts.SchemaExclude, p.SchemaName) &&
+			domain.MatchObject(opts.ObjectFilter, p.SchemaName, p.Name) {
+			procs = append(procs, p)
+		}
+	}
+	return procs, nil
+}
+
+// ExtractFunctions returns the stored functions matching the schema filter
+func (s *SchemaStore) ExtractFunctions(ctx context.Context, opts *domain.DumpOptions) ([]domain.Function, error) {
+	var funcs []domain.Function
+	for _, f := range s.schema.Functions {
+		if matchesFilter(opts.SchemaFilter, opts.SchemaExclude, f.SchemaName) &&
+			domain.MatchObject(opts.ObjectFilter, f.SchemaName, f.Name) {
+			funcs = append(funcs, f)
+		}
+	}
+	return funcs, nil
+}
+
+// ExtractTriggers returns the stored triggers matching the schema filter
+func (s *SchemaStore) ExtractTriggers(ctx context.Context, opts *domain.DumpOptions) ([]domain.Trigger, error) {
+	var triggers []domain.Trigger
+	for _, tr := range s.schema.Triggers {
+		if matchesFilter(opts.SchemaFilter, opts.SchemaExclude, tr.SchemaName) &&
+			domain.MatchObject(opts.ObjectFilter, tr.SchemaName, tr.Name) {
+			triggers = append(triggers, tr)
+		}
+	}
+	return triggers, nil
+}
+
+// ExtractSchemas returns the stored schemas
+func (s *SchemaStore) ExtractSchemas(ctx context.Context, opts *domain.DumpOptions) ([]domain.Schema, error) {
+	return s.schema.Schemas, nil
+}
+
+// ExtractPartitionFunctions returns the stored partition functions
+func (s *SchemaStore) ExtractPartitionFunctions(ctx context.Context) ([]domain.PartitionFunction, error) {
+	return s.schema.PartitionFunctions, nil
+}
+
+// ExtractPartitionSchemes returns the stored partition schemes
+func (s *SchemaStore) ExtractPartitionSchemes(ctx context.Context) ([]domain.PartitionScheme, error) {
+	return s.schema.PartitionSchemes, nil
+}
+
+// ExtractScopedConfigurations returns the stored scoped configurations
+func (s *SchemaStore) ExtractScopedConfigurations(ctx context.Context) ([]domain.ScopedConfiguration, error) {
+	return s.schema.ScopedConfigurations, nil
+}
+
+// ExtractPermissions returns the stored permissions matching the schema filter.
+// Database-level permissions have no schema and are only returned unfiltered.
+// With named objects, only permissions on those objects are returned.
+func (s *SchemaStore) ExtractPermissions(ctx context.Context, opts *domain.DumpOptions) ([]domain.Permission, error) {
+	var perms []domain.Permission
+	for _, p := range s.schema.Permissions {
+		if matchesFilter(opts.SchemaFilter, opts.SchemaExclude, p.SchemaName) &&
+			domain.MatchObject(opts.ObjectFilter, p.SchemaName, p.ObjectName) {
+			perms = append(perms, p)
+		}
+	}
+	return perms, nil
+}
+
+// ExtractStatistics returns the stored statistics matching the filters.
+// Auto-created statistics are only returned with opts.IncludeAutoStatistics.
+func (s *SchemaStore) ExtractStatistics(ctx context.Context, opts *domain.DumpOptions) ([]domain.Statistic, error) {
+	var statistics []domain.Statistic
+	for _, st := range s.schema.Statistics {
+		if (!st.IsAutoCreated || opts.IncludeAutoStatistics) &&
+			matchesFilter(opts.SchemaFilter, opts.SchemaExclude, st.SchemaName) &&
+			matchesFilter(opts.TableFilter, opts.TableExclude, st.TableName) &&
+			domain.MatchObject(opts.ObjectFilter, st.SchemaName, st.TableName) {
+			statistics = append(statistics, st)
+		}
+	}
+	return statistics, nil
+}
+
+// ExtractTableData returns the table's insertable columns. The store holds
+// no row data, so there are never any rows.
+func (s *SchemaStore) ExtractTableData(ctx context.Context, table domain.Table) (*domain.TableData, error) {
+	data := &domain.TableData{SchemaName: table.SchemaName, TableName: table.Name}
+	for _, col := range table.Columns {
+		if col.IsComputed {
+			continue
+		}
+		data.Columns = append(data.Columns, col.Name)
+		data.HasIdentity = data.HasIdentity || col.IsIdentity
+	}
+	return data, nil
+}
+
+// matchesFilter reports whether name passes the include and exclude lists.
+// An empty include list matches everything; exclusion takes precedence.
+// Names compare case-insensitively, as with the default SQL Server collation.
+func matchesFilter(include, exclude []string, name string) bool {
+	if containsName(exclude, name) {
+		return false
+	}
+	return len(include) == 0 || containsName(include, name)
+}
+
+// containsName reports whether any entry (a name or glob pattern) in the list matches name
+func containsName(list []string, name string) bool {
+	for _, item := range list {
+		if domain.MatchGlob(item, name) {
+			return true
+		}
+	}
+	return false
+}
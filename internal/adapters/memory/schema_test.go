@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+	"github.com/enunezf/SQLPulse/internal/core/ports"
+	"github.com/enunezf/SQLPulse/internal/core/services"
+)
+
+var _ ports.SchemaPort = (*SchemaStore)(nil)
+
+// shop returns a store with tables, views and a procedure in two schemas
+func shop() *SchemaStore {
+	return NewSchema("Shop").
+		Collation("Latin1_General_CI_AS").
+		Schema("sales", "dbo").
+		Schema("audit", "dbo").
+		Table(NewTable("sales", "Orders").Column(NewColumn("Id", "int"))).
+		Table(NewTable("sales", "OrderLines").Column(NewColumn("Id", "int"))).
+		Table(NewTable("audit", "Log").Column(NewColumn("Id", "int"))).
+		View("sales", "BigOrders", "CREATE VIEW sales.BigOrders AS SELECT 1 AS a").
+		Procedure("audit", "Purge", "CREATE PROCEDURE audit.Purge AS SELECT 1").
+		Store()
+}
+
+// objectNames returns the qualified names of the extracted tables, views and procedures
+func objectNames(s *domain.DatabaseSchema) []string {
+	var names []string
+	for _, t := range s.Tables {
+		names = append(names, t.SchemaName+"."+t.Name)
+	}
+	for _, v := range s.Views {
+		names = append(names, v.SchemaName+"."+v.Name)
+	}
+	for _, p := range s.StoredProcedures {
+		names = append(names, p.SchemaName+"."+p.Name)
+	}
+	return names
+}
+
+func TestSchemaStoreExtractSchema(t *testing.T) {
+	tests := []struct {
+		name        string
+		configure   func(*domain.DumpOptions)
+		want        []string
+		wantSchemas int
+	}{
+		{"everything", nil, []string{"sales.Orders", "sales.OrderLines", "audit.Log", "sales.BigOrders", "audit.Purge"}, 2},
+		{"schema filter", func(o *domain.DumpOptions) { o.SchemaFilter = []string{"SALES"} }, []string{"sales.Orders", "sales.OrderLines", "sales.BigOrders"}, 2},
+		{"schema exclude", func(o *domain.DumpOptions) { o.SchemaExclude = []string{"sales"} }, []string{"audit.Log", "audit.Purge"}, 2},
+		{"table glob", func(o *domain.DumpOptions) { o.TableFilter = []string{"Order*"} }, []string{"sales.Orders", "sales.OrderLines", "sales.BigOrders", "audit.Purge"}, 2},
+		{"no tables", func(o *domain.DumpOptions) { o.IncludeTables = false }, []string{"sales.BigOrders", "audit.Purge"}, 2},
+		{"named objects", func(o *domain.DumpOptions) { o.ObjectFilter = []string{"audit.Purge"} }, []string{"audit.Purge"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := domain.DefaultDumpOptions()
+			if tt.configure != nil {
+				tt.configure(opts)
+			}
+			schema, err := shop().ExtractSchema(context.Background(), opts)
+			if err != nil {
+				t.Fatalf("ExtractSchema: %v", err)
+			}
+			if got := objectNames(schema); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extracted %v, want %v", got, tt.want)
+			}
+			if len(schema.Schemas) != tt.wantSchemas {
+				t.Errorf("extracted %d schemas, want %d", len(schema.Schemas), tt.wantSchemas)
+			}
+		})
+	}
+}
+
+func TestSchemaStoresCompare(t *testing.T) {
+	target := NewSchema("Shop").
+		Collation("Latin1_General_CI_AS").
+		Schema("sales", "dbo").
+		Schema("audit", "dbo").
+		Table(NewTable("sales", "Orders").Column(NewColumn("Id", "int"))).
+		Table(NewTable("audit", "Log").Column(NewColumn("Id", "int"))).
+		View("sales", "BigOrders", "CREATE VIEW sales.BigOrders AS SELECT 1 AS a").
+		Procedure("audit", "Purge", "CREATE PROCEDURE audit.Purge AS SELECT 1").
+		Store()
+
+	ctx := context.Background()
+	opts := domain.DefaultDumpOptions()
+	src, err := shop().ExtractSchema(ctx, opts)
+	if err != nil {
+		t.Fatalf("source: %v", err)
+	}
+	tgt, err := target.ExtractSchema(ctx, opts)
+	if err != nil {
+		t.Fatalf("target: %v", err)
+	}
+
+	result := services.NewSchemaComparator(nil).Compare(src, tgt)
+	if len(result.Differences) != 1 {
+		t.Fatalf("got %d differences, want 1: %+v", len(result.Differences), result.Differences)
+	}
+	if d := result.Differences[0]; d.Type != domain.DiffRemoved || d.Category != domain.DiffCategoryTable {
+		t.Errorf("got %s %s %s, want the table missing in the target", d.Type, d.Category, d.ObjectName)
+	}
+}
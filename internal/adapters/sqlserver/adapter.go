@@ -4,9 +4,13 @@ package sqlserver
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
-	_ "github.com/microsoft/go-mssqldb" // SQL Server driver
+	mssql "github.com/microsoft/go-mssqldb" // SQL Server driver
+	"github.com/microsoft/go-mssqldb/msdsn"
 
 	"github.com/enunezf/SQLPulse/internal/core/domain"
 	"github.com/enunezf/SQLPulse/internal/security"
@@ -27,31 +31,86 @@ func NewAdapter(config *domain.ConnectionConfig) *Adapter {
 	}
 }
 
-// Connect establishes a connection to SQL Server
+// ParseConnectionString creates a connection config that connects with a
+// driver connection string as is. The server, database and login are read
+// from it for messages.
+func ParseConnectionString(connStr string) (*domain.ConnectionConfig, error) {
+	params, err := msdsn.Parse(connStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection string: %w", err)
+	}
+
+	config := domain.NewConnectionConfig()
+	config.RawConnectionString = connStr
+	config.Server = params.Host
+	if params.Instance != "" {
+		config.Server += `\` + params.Instance
+	}
+	if params.Port != 0 {
+		config.Port = int(params.Port)
+	}
+	config.Database = params.Database
+	config.User = params.User
+	config.TrustedAuth = params.User == ""
+	if params.ReadOnlyIntent {
+		config.ApplicationIntent = domain.ApplicationIntentReadOnly
+	}
+	return config, nil
+}
+
+// Connect establishes a connection to SQL Server, retrying with exponential
+// backoff on transient errors
 func (a *Adapter) Connect(ctx context.Context) error {
 	if err := a.config.Validate(); err != nil {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
 
+	delay := a.config.ConnectRetryDelay
+	for attempt := 0; ; attempt++ {
+		db, err := a.open(ctx)
+		if err == nil {
+			a.db = db
+			return nil
+		}
+
+		if attempt >= a.config.ConnectRetries || ctx.Err() != nil || !isTransientError(err) {
+			return newConnectionError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return newConnectionError(err)
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// open opens the connection pool and verifies it with a ping
+func (a *Adapter) open(ctx context.Context) (*sql.DB, error) {
 	connStr := a.config.ConnectionString()
 
 	db, err := sql.Open("sqlserver", connStr)
 	if err != nil {
-		return fmt.Errorf("failed to open connection: %w", err)
+		return nil, fmt.Errorf("failed to open connection: %w", err)
 	}
 
-	// Set connection pool settings
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(5)
+	// Set connection pool settings; idle connections never exceed the open limit
+	idle := a.config.MaxIdleConns
+	if a.config.MaxOpenConns > 0 && idle > a.config.MaxOpenConns {
+		idle = a.config.MaxOpenConns
+	}
+	db.SetMaxOpenConns(a.config.MaxOpenConns)
+	db.SetMaxIdleConns(idle)
+	db.SetConnMaxLifetime(a.config.ConnMaxLifetime)
 
 	// Verify the connection
 	if err := db.PingContext(ctx); err != nil {
 		db.Close()
-		return fmt.Errorf("failed to ping database: %w", err)
+		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	a.db = db
-	return nil
+	return db, nil
 }
 
 // Ping verifies the connection is still alive
@@ -87,37 +146,165 @@ func (a *Adapter) GetServerInfo(ctx context.Context) (*domain.ServerInfo, error)
 			@@SERVERNAME as ServerName
 	`
 
+	// @@SERVERNAME is NULL when the server was renamed without sp_addserver
+	var serverName sql.NullString
 	row := a.db.QueryRowContext(ctx, query)
-	err := row.Scan(&info.Version, &info.Edition, &info.ProductName, &info.ServerName)
+	err := row.Scan(&info.Version, &info.Edition, &info.ProductName, &serverName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get server info: %w", err)
 	}
+	info.ServerName = serverName.String
 
 	return info, nil
 }
 
+// GetPermissions retrieves the effective database permissions of the
+// connected principal, including those implied by roles and higher permissions
+func (a *Adapter) GetPermissions(ctx context.Context) ([]string, error) {
+	if a.db == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	query := `
+		SELECT permission_name
+		FROM fn_my_permissions(NULL, 'DATABASE')
+		ORDER BY permission_name
+	`
+
+	rows, err := a.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permissions: %w", err)
+	}
+	defer rows.Close()
+
+	var permissions []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan permission: %w", err)
+		}
+		permissions = append(permissions, name)
+	}
+
+	return permissions, rows.Err()
+}
+
+// GetDatabaseInfo retrieves the size, file layout and settings of the
+// connected database. Sizes are reported by SQL Server in 8 KB pages.
+func (a *Adapter) GetDatabaseInfo(ctx context.Context) (*domain.DatabaseInfo, error) {
+	if a.db == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	info := &domain.DatabaseInfo{}
+
+	query := `
+		SELECT
+			d.name,
+			d.compatibility_level,
+			CAST(DATABASEPROPERTYEX(d.name, 'Recovery') AS nvarchar(60)),
+			ISNULL(CAST(DATABASEPROPERTYEX(d.name, 'Collation') AS nvarchar(128)), ''),
+			CAST(DATABASEPROPERTYEX(d.name, 'Status') AS nvarchar(60))
+		FROM sys.databases d
+		WHERE d.database_id = DB_ID()
+	`
+
+	// DATABASEPROPERTYEX returns NULL when the login cannot see the property
+	var recoveryModel, status sql.NullString
+	row := a.db.QueryRowContext(ctx, query)
+	err := row.Scan(&info.Name, &info.CompatibilityLevel, &recoveryModel, &info.Collation, &status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database info: %w", err)
+	}
+	info.RecoveryModel = recoveryModel.String
+	info.Status = status.String
+
+	filesQuery := `
+		SELECT
+			f.name,
+			f.type_desc,
+			ISNULL(fg.name, ''),
+			f.physical_name,
+			CAST(f.size AS bigint),
+			CAST(ISNULL(FILEPROPERTY(f.name, 'SpaceUsed'), 0) AS bigint),
+			CAST(f.max_size AS bigint),
+			CAST(f.growth AS bigint),
+			f.is_percent_growth
+		FROM sys.database_files f
+		LEFT JOIN sys.filegroups fg ON f.data_space_id = fg.data_space_id
+		ORDER BY f.file_id
+	`
+
+	rows, err := a.db.QueryContext(ctx, filesQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query database files: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var f domain.DatabaseFile
+		var size, used, maxSize, growth int64
+		var percentGrowth bool
+		if err := rows.Scan(&f.Name, &f.Type, &f.FileGroup, &f.PhysicalName, &size, &used, &maxSize, &growth, &percentGrowth); err != nil {
+			return nil, fmt.Errorf("failed to scan database file: %w", err)
+		}
+		f.SizeMB = pagesToMB(size)
+		f.UsedMB = pagesToMB(used)
+		f.MaxSizeMB = -1
+		if maxSize != -1 {
+			f.MaxSizeMB = pagesToMB(maxSize)
+		}
+		switch {
+		case growth == 0:
+			f.Growth = "none"
+		case percentGrowth:
+			f.Growth = fmt.Sprintf("%d%%", growth)
+		default:
+			f.Growth = fmt.Sprintf("%.0f MB", pagesToMB(growth))
+		}
+
+		info.SizeMB += f.SizeMB
+		info.UsedMB += f.UsedMB
+		info.Files = append(info.Files, f)
+	}
+
+	return info, rows.Err()
+}
+
+// pagesToMB converts a count of 8 KB pages to megabytes
+func pagesToMB(pages int64) float64 {
+	return float64(pages) * 8 / 1024
+}
+
 // ExecuteWithApproval executes SQL after getting user approval
 func (a *Adapter) ExecuteWithApproval(ctx context.Context, sqlText string, level security.ApprovalLevel, operation string) error {
 	if a.db == nil {
 		return fmt.Errorf("not connected")
 	}
 
-	// Create approval request
+	// Create approval request; destructive operations require typing the database name
 	req := security.ApprovalRequest{
-		Operation:     operation,
-		SQL:           sqlText,
-		Level:         level,
-		ImpactSummary: "", // Can be populated by caller
+		Operation:          operation,
+		SQL:                sqlText,
+		Level:              level,
+		ImpactSummary:      "", // Can be populated by caller
+		ConfirmationPhrase: a.config.Database,
+		Editable:           true,
 	}
 
 	// Request approval
-	approved, err := a.approver.RequestApproval(req)
+	decision, err := a.approver.RequestApproval(ctx, req)
 	if err != nil {
 		return fmt.Errorf("approval error: %w", err)
 	}
 
-	if !approved {
-		return fmt.Errorf("operation cancelled by user")
+	if !decision.Approved {
+		return security.ErrCancelled
+	}
+
+	// Execute what the user approved, which may be an edit of sqlText
+	if decision.EditedSQL != "" {
+		sqlText = decision.EditedSQL
 	}
 
 	// Execute the SQL
@@ -129,11 +316,341 @@ func (a *Adapter) ExecuteWithApproval(ctx context.Context, sqlText string, level
 	return nil
 }
 
+// ExecuteTransaction executes the batches in a single transaction after one
+// approval at the given level. If any batch fails, everything is rolled back
+// and a *domain.BatchError identifies the failing batch.
+func (a *Adapter) ExecuteTransaction(ctx context.Context, batches []domain.Batch, level security.ApprovalLevel, operation string) error {
+	if a.db == nil {
+		return fmt.Errorf("not connected")
+	}
+
+	sqlTexts := make([]string, len(batches))
+	for i, b := range batches {
+		sqlTexts[i] = b.SQL
+	}
+
+	req := security.ApprovalRequest{
+		Operation:          operation,
+		SQL:                strings.Join(sqlTexts, "\nGO\n"),
+		Level:              level,
+		ImpactSummary:      fmt.Sprintf("%d batch(es) executed in a single transaction; any failure rolls back all of them", len(batches)),
+		ConfirmationPhrase: a.config.Database,
+	}
+
+	decision, err := a.approver.RequestApproval(ctx, req)
+	if err != nil {
+		return fmt.Errorf("approval error: %w", err)
+	}
+
+	if !decision.Approved {
+		return security.ErrCancelled
+	}
+
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for i, b := range batches {
+		if _, err := tx.ExecContext(ctx, b.SQL); err != nil {
+			if isNonTransactionalError(err) {
+				err = fmt.Errorf("statement cannot run inside a transaction: %w", err)
+			}
+			return &domain.BatchError{Index: i + 1, Line: b.Line, Err: err}
+		}
+
+		// A COMMIT or ROLLBACK inside a batch ends the transaction early
+		var tranCount int
+		if err := tx.QueryRowContext(ctx, "SELECT @@TRANCOUNT").Scan(&tranCount); err != nil || tranCount == 0 {
+			return &domain.BatchError{
+				Index: i + 1,
+				Line:  b.Line,
+				Err:   fmt.Errorf("batch ended the transaction (COMMIT or ROLLBACK in batch)"),
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ExecuteReadQuery runs an ad-hoc query and returns at most maxRows rows
+// (0 for no limit)
+func (a *Adapter) ExecuteReadQuery(ctx context.Context, sqlText string, maxRows int) (*domain.QueryResult, error) {
+	result := &domain.QueryResult{}
+	_, truncated, err := a.streamQuery(ctx, sqlText, "Read query", maxRows, &resultCollector{result: result})
+	if err != nil {
+		return nil, err
+	}
+	result.Truncated = truncated
+	return result, nil
+}
+
+// ExportTable streams the rows of a table to w and returns the number of
+// rows written. An empty column list selects all columns; where is an
+// optional filter expression without the WHERE keyword. The expression is
+// parenthesized and the query approved like any other, so an expression
+// that smuggles in a statement needs that statement's approval.
+func (a *Adapter) ExportTable(ctx context.Context, schemaName, tableName string, columns []string, where string, w domain.RowWriter) (int, error) {
+	if err := domain.CheckWhereExpression(where); err != nil {
+		return 0, err
+	}
+
+	selectList := "*"
+	if len(columns) > 0 {
+		quoted := make([]string, len(columns))
+		for i, c := range columns {
+			quoted[i] = domain.QuoteIdent(c)
+		}
+		selectList = strings.Join(quoted, ", ")
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s.%s", selectList, domain.QuoteIdent(schemaName), domain.QuoteIdent(tableName))
+	if where != "" {
+		// The closing parenthesis goes on its own line so a trailing -- comment cannot hide it
+		query += " WHERE (" + where + "\n)"
+	}
+
+	count, _, err := a.streamQuery(ctx, query, "Export table", 0, w)
+	return count, err
+}
+
+// streamQuery runs a query and passes its first result set to w, stopping
+// after maxRows rows (0 for no limit). A single SELECT or WITH statement
+// needs read-only approval; anything else is approved at the level of its
+// risk. The query runs inside a transaction that is always rolled back, and
+// fails if it ends that transaction itself. It returns the number of rows
+// written and whether rows were left unread because of the limit.
+func (a *Adapter) streamQuery(ctx context.Context, sqlText, operation string, maxRows int, w domain.RowWriter) (int, bool, error) {
+	if a.db == nil {
+		return 0, false, fmt.Errorf("not connected")
+	}
+
+	req := security.ApprovalRequest{
+		Operation:     operation,
+		SQL:           sqlText,
+		Level:         queryApprovalLevel(sqlText),
+		ImpactSummary: "Query runs inside a transaction that is always rolled back",
+	}
+	if req.Level != security.ReadOnly {
+		req.ConfirmationPhrase = a.config.Database
+		req.ImpactSummary = "Query is not a single SELECT; changes it makes outside the transaction, such as a COMMIT, are not rolled back"
+	}
+
+	decision, err := a.approver.RequestApproval(ctx, req)
+	if err != nil {
+		return 0, false, fmt.Errorf("approval error: %w", err)
+	}
+
+	if !decision.Approved {
+		return 0, false, security.ErrCancelled
+	}
+
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, sqlText)
+	if err != nil {
+		return 0, false, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read columns: %w", err)
+	}
+
+	columns := make([]string, len(columnTypes))
+	for i, ct := range columnTypes {
+		columns[i] = ct.Name()
+	}
+	if err := w.WriteHeader(columns); err != nil {
+		return 0, false, err
+	}
+
+	values := make([]interface{}, len(columnTypes))
+	dest := make([]interface{}, len(columnTypes))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	count, truncated := 0, false
+	for rows.Next() {
+		if maxRows > 0 && count == maxRows {
+			truncated = true
+			break
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return count, false, fmt.Errorf("failed to scan row: %w", err)
+		}
+		row := make([]domain.QueryValue, len(values))
+		for i, v := range values {
+			row[i] = formatQueryValue(v, columnTypes[i].DatabaseTypeName())
+		}
+		if err := w.WriteRow(row); err != nil {
+			return count, false, err
+		}
+		count++
+	}
+	if err := rows.Close(); err != nil {
+		return count, false, err
+	}
+	if err := rows.Err(); err != nil {
+		return count, false, err
+	}
+
+	// A COMMIT or ROLLBACK in the query ends the transaction, so what it
+	// changed before is no longer rolled back
+	var tranCount int
+	if err := tx.QueryRowContext(ctx, "SELECT @@TRANCOUNT").Scan(&tranCount); err != nil || tranCount == 0 {
+		return count, false, fmt.Errorf("query ended its transaction (COMMIT or ROLLBACK in query); its changes may have been persisted")
+	}
+
+	return count, truncated, nil
+}
+
+// queryApprovalLevel returns the approval an ad-hoc query needs: read-only
+// for a single SELECT or WITH statement, otherwise the level of its risk
+func queryApprovalLevel(sqlText string) security.ApprovalLevel {
+	if domain.IsReadOnlyQuery(sqlText) {
+		return security.ReadOnly
+	}
+	if domain.ClassifySQL(sqlText) == domain.RiskModification {
+		return security.Modification
+	}
+	return security.Destructive
+}
+
+// resultCollector is a RowWriter that buffers rows into a QueryResult
+type resultCollector struct {
+	result *domain.QueryResult
+}
+
+func (c *resultCollector) WriteHeader(columns []string) error {
+	c.result.Columns = columns
+	return nil
+}
+
+func (c *resultCollector) WriteRow(row []domain.QueryValue) error {
+	c.result.Rows = append(c.result.Rows, row)
+	return nil
+}
+
+// formatQueryValue renders a scanned value as text. The driver returns
+// decimals and money as digit strings and uniqueidentifiers in SQL Server's
+// mixed-endian byte order, so []byte is interpreted by the column type.
+func formatQueryValue(v interface{}, typeName string) domain.QueryValue {
+	switch val := v.(type) {
+	case nil:
+		return domain.QueryValue{Null: true}
+	case []byte:
+		switch typeName {
+		case "UNIQUEIDENTIFIER":
+			var id mssql.UniqueIdentifier
+			if err := id.Scan(val); err == nil {
+				return domain.QueryValue{Text: id.String()}
+			}
+		case "DECIMAL", "MONEY", "SMALLMONEY":
+			return domain.QueryValue{Text: string(val)}
+		}
+		return domain.QueryValue{Text: fmt.Sprintf("0x%X", val)}
+	case time.Time:
+		switch typeName {
+		case "DATE":
+			return domain.QueryValue{Text: val.Format("2006-01-02")}
+		case "TIME":
+			return domain.QueryValue{Text: val.Format("15:04:05.9999999")}
+		case "DATETIMEOFFSET":
+			return domain.QueryValue{Text: val.Format("2006-01-02 15:04:05.9999999 -07:00")}
+		}
+		return domain.QueryValue{Text: val.Format("2006-01-02 15:04:05.9999999")}
+	default:
+		return domain.QueryValue{Text: fmt.Sprint(val)}
+	}
+}
+
+// ValidateScript executes the batches inside a transaction that is always
+// rolled back. It returns a *domain.BatchError for the first failing batch.
+// Batches that commit, roll back or begin a transaction are refused before
+// anything runs, since they would make the earlier batches permanent.
+func (a *Adapter) ValidateScript(ctx context.Context, batches []domain.Batch) error {
+	if a.db == nil {
+		return fmt.Errorf("not connected")
+	}
+
+	for i, b := range batches {
+		if stmt := domain.TransactionStatement(b.SQL); stmt != "" {
+			return &domain.BatchError{
+				Index: i + 1,
+				Line:  b.Line,
+				Err:   fmt.Errorf("%s cannot be validated: it would end or nest the validation transaction", stmt),
+			}
+		}
+	}
+
+	// Nothing is persisted, so validation only needs read-only approval
+	req := security.ApprovalRequest{
+		Operation:     "Validate script",
+		Level:         security.ReadOnly,
+		ImpactSummary: fmt.Sprintf("%d batch(es) executed inside a transaction that is always rolled back", len(batches)),
+	}
+
+	decision, err := a.approver.RequestApproval(ctx, req)
+	if err != nil {
+		return fmt.Errorf("approval error: %w", err)
+	}
+
+	if !decision.Approved {
+		return security.ErrCancelled
+	}
+
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for i, b := range batches {
+		if _, err := tx.ExecContext(ctx, b.SQL); err != nil {
+			line := b.Line
+			var sqlErr mssql.Error
+			if errors.As(err, &sqlErr) && sqlErr.LineNo > 0 {
+				line += int(sqlErr.LineNo) - 1
+			}
+			return &domain.BatchError{Index: i + 1, Line: line, Err: err}
+		}
+
+		// A COMMIT or ROLLBACK inside the script ends the validation transaction
+		var tranCount int
+		if err := tx.QueryRowContext(ctx, "SELECT @@TRANCOUNT").Scan(&tranCount); err != nil || tranCount == 0 {
+			return &domain.BatchError{
+				Index: i + 1,
+				Line:  b.Line,
+				Err:   fmt.Errorf("batch ended the validation transaction (COMMIT or ROLLBACK in script)"),
+			}
+		}
+	}
+
+	return nil
+}
+
 // SetApprover sets the approver to use for operations
 func (a *Adapter) SetApprover(approver security.Approver) {
 	a.approver = approver
 }
 
+// Approver returns the approver currently used for operations
+func (a *Adapter) Approver() security.Approver {
+	return a.approver
+}
+
 // DB returns the underlying database connection for advanced usage
 func (a *Adapter) DB() *sql.DB {
 	return a.db
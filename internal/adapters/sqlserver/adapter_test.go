@@ -0,0 +1,128 @@
+package sqlserver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+	"github.com/enunezf/SQLPulse/internal/security"
+)
+
+// recordingApprover approves every request and keeps them
+type recordingApprover struct {
+	requests []security.ApprovalRequest
+}
+
+func (a *recordingApprover) RequestApproval(ctx context.Context, req security.ApprovalRequest) (security.Decision, error) {
+	a.requests = append(a.requests, req)
+	return security.Decision{Approved: true}, nil
+}
+
+// newFakeAdapter returns an adapter on the fake queries approving through a
+// recording approver
+func newFakeAdapter(queries ...fakeQuery) (*Adapter, *recordingApprover) {
+	approver := &recordingApprover{}
+	return &Adapter{
+		config:   &domain.ConnectionConfig{Database: "Shop"},
+		db:       sql.OpenDB(&fakeDB{queries: queries}),
+		approver: approver,
+	}, approver
+}
+
+// tranCount answers SELECT @@TRANCOUNT with n
+func tranCount(n int64) fakeQuery {
+	return fakeQuery{
+		match:   "SELECT @@TRANCOUNT",
+		columns: []string{""},
+		rows:    func([]driver.NamedValue) [][]driver.Value { return [][]driver.Value{{n}} },
+	}
+}
+
+func TestExecuteReadQueryCommittingQuery(t *testing.T) {
+	a, approver := newFakeAdapter(
+		fakeQuery{
+			match:   "DELETE FROM dbo.Users",
+			columns: []string{},
+			rows:    func([]driver.NamedValue) [][]driver.Value { return nil },
+		},
+		tranCount(0),
+	)
+
+	_, err := a.ExecuteReadQuery(context.Background(), "DELETE FROM dbo.Users; COMMIT", 0)
+	if err == nil || !strings.Contains(err.Error(), "ended its transaction") {
+		t.Errorf("got %v, want an error for the ended transaction", err)
+	}
+	if len(approver.requests) != 1 || approver.requests[0].Level != security.Destructive {
+		t.Errorf("approval requests %+v, want one at destructive level", approver.requests)
+	}
+}
+
+func TestExecuteReadQuerySelect(t *testing.T) {
+	a, approver := newFakeAdapter(
+		fakeQuery{
+			match:   "SELECT name FROM sys.tables",
+			columns: []string{"name"},
+			rows:    func([]driver.NamedValue) [][]driver.Value { return [][]driver.Value{{"Users"}} },
+		},
+		tranCount(1),
+	)
+
+	result, err := a.ExecuteReadQuery(context.Background(), "SELECT name FROM sys.tables", 0)
+	if err != nil {
+		t.Fatalf("ExecuteReadQuery: %v", err)
+	}
+	if len(result.Rows) != 1 || result.Rows[0][0].Text != "Users" {
+		t.Errorf("got rows %+v, want Users", result.Rows)
+	}
+	if len(approver.requests) != 1 || approver.requests[0].Level != security.ReadOnly {
+		t.Errorf("approval requests %+v, want one at read-only level", approver.requests)
+	}
+}
+
+func TestExportTableWhereWithStatement(t *testing.T) {
+	t.Run("semicolon", func(t *testing.T) {
+		a, approver := newFakeAdapter(tranCount(1))
+		_, err := a.ExportTable(context.Background(), "dbo", "T", nil, "1=1; DELETE FROM dbo.T; COMMIT", &resultCollector{result: &domain.QueryResult{}})
+		if err == nil || len(approver.requests) != 0 {
+			t.Errorf("got %v after %d approval(s), want the expression rejected before approval", err, len(approver.requests))
+		}
+	})
+
+	t.Run("closing parenthesis", func(t *testing.T) {
+		a, approver := newFakeAdapter(
+			fakeQuery{
+				match:   "SELECT * FROM [dbo].[T]",
+				columns: []string{"Id"},
+				rows:    func([]driver.NamedValue) [][]driver.Value { return nil },
+			},
+			tranCount(1),
+		)
+		_, err := a.ExportTable(context.Background(), "dbo", "T", nil, "1=1) DELETE FROM dbo.T WHERE (1=1", &resultCollector{result: &domain.QueryResult{}})
+		if err != nil {
+			t.Fatalf("ExportTable: %v", err)
+		}
+		if len(approver.requests) != 1 || approver.requests[0].Level != security.Destructive {
+			t.Errorf("approval requests %+v, want one at destructive level", approver.requests)
+		}
+	})
+}
+
+func TestValidateScriptRefusesCommitBeforeRunning(t *testing.T) {
+	db := &fakeDB{queries: []fakeQuery{tranCount(1)}}
+	a := &Adapter{config: &domain.ConnectionConfig{Database: "Shop"}, db: sql.OpenDB(db), approver: &recordingApprover{}}
+
+	batches := domain.SplitBatches("DELETE FROM dbo.Users\nGO\nCOMMIT\nGO\n")
+	err := a.ValidateScript(context.Background(), batches)
+
+	var batchErr *domain.BatchError
+	if !errors.As(err, &batchErr) || batchErr.Index != 2 {
+		t.Fatalf("got %v, want a batch error for batch 2", err)
+	}
+	if len(db.received) != 0 {
+		t.Errorf("ran %q, want nothing run", db.received)
+	}
+}
@@ -0,0 +1,226 @@
+package sqlserver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+)
+
+// bulkTableThreshold is the number of tables above which their details are
+// extracted with one query per kind of detail for all tables, instead of a
+// set of queries for each table
+const bulkTableThreshold = 50
+
+// userTablesCondition selects the details of every user table in bulk queries
+const userTablesCondition = "t.is_ms_shipped = 0"
+
+// allTablesCondition also selects the details of tables shipped with SQL Server
+const allTablesCondition = "1 = 1"
+
+// indexKey identifies an index by its table object_id and name
+type indexKey struct {
+	objectID int
+	name     string
+}
+
+// extractTableDetailsBulk extracts the columns, keys, indexes and constraints
+// of all tables, whose object_ids are given in ids, and distributes the rows
+// to the right table. Rows of tables left out by the filters are skipped.
+func (e *SchemaExtractor) extractTableDetailsBulk(ctx context.Context, tables []domain.Table, ids []int, masking, inMemory, includeSystem bool) error {
+	condition := userTablesCondition
+	if includeSystem {
+		condition = allTablesCondition
+	}
+
+	byID := make(map[int]*domain.Table, len(tables))
+	for i := range tables {
+		byID[ids[i]] = &tables[i]
+	}
+
+	// Index columns are shared by primary keys, indexes and unique constraints
+	var indexColumns map[indexKey][]domain.IndexColumn
+	err := e.bulkQuery(ctx, "index columns", indexColumnsQuery(condition),
+		func() { indexColumns = make(map[indexKey][]domain.IndexColumn) },
+		func(rows *sql.Rows) error {
+			objectID, indexName, c, err := scanIndexColumn(rows)
+			if err != nil {
+				return err
+			}
+			if byID[objectID] != nil {
+				key := indexKey{objectID, indexName}
+				indexColumns[key] = append(indexColumns[key], c)
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	err = e.bulkQuery(ctx, "columns", columnsQuery(condition, masking),
+		func() {
+			for _, t := range byID {
+				t.Columns = nil
+			}
+		},
+		func(rows *sql.Rows) error {
+			objectID, c, err := scanColumn(rows)
+			if err != nil {
+				return err
+			}
+			if t := byID[objectID]; t != nil {
+				t.Columns = append(t.Columns, c)
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	err = e.bulkQuery(ctx, "primary keys", primaryKeysQuery(condition, inMemory),
+		func() {
+			for _, t := range byID {
+				t.PrimaryKey = nil
+			}
+		},
+		func(rows *sql.Rows) error {
+			var objectID int
+			var name, indexType string
+			var bucketCount int64
+			if err := rows.Scan(&objectID, &name, &indexType, &bucketCount); err != nil {
+				return fmt.Errorf("failed to scan primary key: %w", err)
+			}
+			if t := byID[objectID]; t != nil {
+				t.PrimaryKey = newPrimaryKey(t.SchemaName, t.Name, name, indexType, bucketCount)
+				t.PrimaryKey.Columns = indexColumns[indexKey{objectID, name}]
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	err = e.bulkQuery(ctx, "indexes", indexesQuery(condition, inMemory),
+		func() {
+			for _, t := range byID {
+				t.Indexes = nil
+			}
+		},
+		func(rows *sql.Rows) error {
+			objectID, idx, err := scanIndex(rows)
+			if err != nil {
+				return err
+			}
+			if t := byID[objectID]; t != nil {
+				idx.SchemaName = t.SchemaName
+				idx.TableName = t.Name
+				idx.Columns = indexColumns[indexKey{objectID, idx.Name}]
+				t.Indexes = append(t.Indexes, idx)
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	// FK names are only unique within a schema, so key their columns on object_id
+	var fkColumns map[int][]domain.ForeignKeyColumn
+	err = e.bulkQuery(ctx, "foreign key columns", foreignKeyColumnsQuery(condition),
+		func() { fkColumns = make(map[int][]domain.ForeignKeyColumn) },
+		func(rows *sql.Rows) error {
+			objectID, c, err := scanForeignKeyColumn(rows)
+			if err != nil {
+				return err
+			}
+			fkColumns[objectID] = append(fkColumns[objectID], c)
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	err = e.bulkQuery(ctx, "foreign keys", foreignKeysQuery(condition),
+		func() {
+			for _, t := range byID {
+				t.ForeignKeys = nil
+			}
+		},
+		func(rows *sql.Rows) error {
+			tableID, objectID, fk, err := scanForeignKey(rows)
+			if err != nil {
+				return err
+			}
+			if t := byID[tableID]; t != nil {
+				fk.Columns = fkColumns[objectID]
+				t.ForeignKeys = append(t.ForeignKeys, fk)
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	err = e.bulkQuery(ctx, "check constraints", checkConstraintsQuery(condition),
+		func() {
+			for _, t := range byID {
+				t.CheckConstraints = nil
+			}
+		},
+		func(rows *sql.Rows) error {
+			objectID, c, err := scanCheckConstraint(rows)
+			if err != nil {
+				return err
+			}
+			if t := byID[objectID]; t != nil {
+				t.CheckConstraints = append(t.CheckConstraints, c)
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	return e.bulkQuery(ctx, "unique constraints", uniqueConstraintsQuery(condition),
+		func() {
+			for _, t := range byID {
+				t.UniqueConstraints = nil
+			}
+		},
+		func(rows *sql.Rows) error {
+			var objectID int
+			var uc domain.UniqueConstraint
+			if err := rows.Scan(&objectID, &uc.Name, &uc.IsClustered); err != nil {
+				return fmt.Errorf("failed to scan unique constraint: %w", err)
+			}
+			if t := byID[objectID]; t != nil {
+				uc.SchemaName = t.SchemaName
+				uc.TableName = t.Name
+				// The backing index has the same name as the constraint
+				uc.Columns = indexColumns[indexKey{objectID, uc.Name}]
+				t.UniqueConstraints = append(t.UniqueConstraints, uc)
+			}
+			return nil
+		})
+}
+
+// bulkQuery runs a query covering all tables as its own extraction step,
+// passing each row to scan. reset clears what scan collected before each
+// attempt, as a step that times out is retried.
+func (e *SchemaExtractor) bulkQuery(ctx context.Context, step, query string, reset func(), scan func(rows *sql.Rows) error) error {
+	return e.withTimeout(ctx, step, func(ctx context.Context) error {
+		reset()
+		rows, err := e.query(ctx, query)
+		if err != nil {
+			return fmt.Errorf("failed to query %s: %w", step, err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			if err := scan(rows); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	})
+}
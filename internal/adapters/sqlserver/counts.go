@@ -0,0 +1,146 @@
+package sqlserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+)
+
+// countQuery counts the objects of one category. from joins the catalog
+// views with sys.schemas aliased s; table-level objects also join sys.tables
+// aliased t, so the table filters apply to them.
+type countQuery struct {
+	category   domain.DiffCategory
+	from       string
+	shipped    string // Alias of the view whose is_ms_shipped is checked
+	schema     string // Schema name expression the schema filters apply to, s.name by default
+	tableLevel bool
+	conditions []string
+}
+
+// tableCountFrom joins a catalog view of table-level objects, whose table is
+// referenced by column, to its table and schema
+func tableCountFrom(view, column string) string {
+	return fmt.Sprintf(`%s
+		INNER JOIN sys.tables t ON %s = t.object_id
+		INNER JOIN sys.schemas s ON t.schema_id = s.schema_id`, view, column)
+}
+
+// countQueries returns the count queries of the object types opts includes,
+// matching the objects the extraction queries return
+func countQueries(opts *domain.DumpOptions) []countQuery {
+	var queries []countQuery
+
+	if opts.IncludeTables {
+		queries = append(queries,
+			countQuery{category: domain.DiffCategoryTable, from: "sys.tables t INNER JOIN sys.schemas s ON t.schema_id = s.schema_id", shipped: "t", tableLevel: true},
+			countQuery{category: domain.DiffCategoryColumn, from: tableCountFrom("sys.columns c", "c.object_id"), shipped: "t", tableLevel: true})
+		if opts.IncludeIndexes {
+			queries = append(queries, countQuery{
+				category: domain.DiffCategoryIndex, from: tableCountFrom("sys.indexes i", "i.object_id"), shipped: "t", tableLevel: true,
+				conditions: []string{"i.is_primary_key = 0", "i.is_unique_constraint = 0", "i.type > 0", "i.name IS NOT NULL"},
+			})
+		}
+		if opts.IncludeForeignKeys {
+			queries = append(queries, countQuery{
+				category: domain.DiffCategoryForeignKey, from: tableCountFrom("sys.foreign_keys fk", "fk.parent_object_id"), shipped: "t", tableLevel: true,
+			})
+		}
+		if opts.IncludeConstraints {
+			queries = append(queries, countQuery{
+				category: domain.DiffCategoryConstraint, from: tableCountFrom("sys.objects k", "k.parent_object_id"), shipped: "t", tableLevel: true,
+				conditions: []string{"k.type IN ('C', 'UQ')"},
+			})
+		}
+	}
+
+	if opts.IncludeViews {
+		queries = append(queries, countQuery{
+			category: domain.DiffCategoryView, from: "sys.views v INNER JOIN sys.schemas s ON v.schema_id = s.schema_id", shipped: "v",
+		})
+	}
+	if opts.IncludeProcedures {
+		queries = append(queries, countQuery{
+			category: domain.DiffCategoryProcedure, from: "sys.procedures p INNER JOIN sys.schemas s ON p.schema_id = s.schema_id", shipped: "p",
+		})
+	}
+	if opts.IncludeFunctions {
+		queries = append(queries, countQuery{
+			category: domain.DiffCategoryFunction, from: "sys.objects o INNER JOIN sys.schemas s ON o.schema_id = s.schema_id", shipped: "o",
+			conditions: []string{"o.type IN ('FN', 'IF', 'TF')"},
+		})
+	}
+	if opts.IncludeTriggers {
+		queries = append(queries, countQuery{
+			category: domain.DiffCategoryTrigger, shipped: "tr", schema: "ISNULL(s.name, '')",
+			from: `sys.triggers tr
+		LEFT JOIN sys.tables t ON tr.parent_class = 1 AND tr.parent_id = t.object_id
+		LEFT JOIN sys.schemas s ON t.schema_id = s.schema_id`,
+			conditions: []string{"(tr.parent_class = 0 OR t.object_id IS NOT NULL)"},
+		})
+	}
+
+	return queries
+}
+
+// CountObjects counts the objects of each category opts includes, with the
+// same filters as extraction but without reading any definitions. It is a
+// quick check of how close two databases are before a full comparison.
+func (e *SchemaExtractor) CountObjects(ctx context.Context, opts *domain.DumpOptions) (domain.ObjectCounts, error) {
+	counts := make(domain.ObjectCounts)
+
+	schemas := newQueryFilter()
+	if !opts.IncludeSystemObjects {
+		schemas.conditions = append(schemas.conditions, systemSchemasCondition)
+	}
+	var n int
+	err := e.withTimeout(ctx, "schema count", func(ctx context.Context) error {
+		return e.queryRow(ctx, "SELECT COUNT(*) FROM sys.schemas s "+schemas.where()).Scan(&n)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count schemas: %w", err)
+	}
+	counts[domain.DiffCategorySchema] = n
+
+	for _, q := range countQueries(opts) {
+		filter := newQueryFilter(q.conditions...)
+		filter.userObjects(q.shipped, opts.IncludeSystemObjects)
+		schema := q.schema
+		if schema == "" {
+			schema = "s.name"
+		}
+		filter.in(schema, opts.SchemaFilter)
+		filter.notIn(schema, opts.SchemaExclude)
+		if q.tableLevel {
+			filter.in("t.name", opts.TableFilter)
+			filter.notIn("t.name", opts.TableExclude)
+		}
+
+		query := fmt.Sprintf("SELECT COUNT(*) FROM %s %s", q.from, filter.where())
+		step := fmt.Sprintf("%s count", q.category)
+		err := e.withTimeout(ctx, step, func(ctx context.Context) error {
+			return e.queryRow(ctx, query, filter.args...).Scan(&n)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s objects: %w", q.category, err)
+		}
+		counts[q.category] = n
+	}
+
+	// Permissions have no cheaper query than their extraction, which reads
+	// no definitions anyway
+	if opts.IncludePermissions {
+		var permissions []domain.Permission
+		err := e.withTimeout(ctx, "permissions", func(ctx context.Context) (err error) {
+			permissions, err = e.ExtractPermissions(ctx, opts)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		counts[domain.DiffCategoryPermission] = len(permissions)
+	}
+
+	return counts, nil
+}
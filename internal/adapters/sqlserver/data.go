@@ -0,0 +1,140 @@
+package sqlserver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	mssql "github.com/microsoft/go-mssqldb"
+
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+)
+
+// ExtractTableData reads all rows of a table as T-SQL literals, ordered by
+// the primary key when there is one. Computed, rowversion and temporal period
+// columns are skipped because they cannot be inserted.
+func (e *SchemaExtractor) ExtractTableData(ctx context.Context, table domain.Table) (*domain.TableData, error) {
+	data := &domain.TableData{SchemaName: table.SchemaName, TableName: table.Name}
+
+	var columns []domain.Column
+	for _, col := range table.Columns {
+		switch {
+		case col.IsComputed, col.GeneratedAlways != "":
+			continue
+		case strings.EqualFold(col.DataType, "timestamp"), strings.EqualFold(col.DataType, "rowversion"):
+			continue
+		}
+		columns = append(columns, col)
+		data.Columns = append(data.Columns, col.Name)
+		if col.IsIdentity {
+			data.HasIdentity = true
+		}
+	}
+	if len(columns) == 0 {
+		return data, nil
+	}
+
+	selectList := make([]string, len(columns))
+	for i, col := range columns {
+		selectList[i] = domain.QuoteIdent(col.Name)
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s.%s", strings.Join(selectList, ", "),
+		domain.QuoteIdent(table.SchemaName), domain.QuoteIdent(table.Name))
+	if table.PrimaryKey != nil && len(table.PrimaryKey.Columns) > 0 {
+		var orderBy []string
+		for _, col := range table.PrimaryKey.Columns {
+			orderBy = append(orderBy, domain.QuoteIdent(col.Name))
+		}
+		query += " ORDER BY " + strings.Join(orderBy, ", ")
+	}
+
+	rows, err := e.query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query data of %s.%s: %w", table.SchemaName, table.Name, err)
+	}
+	defer rows.Close()
+
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan row of %s.%s: %w", table.SchemaName, table.Name, err)
+		}
+		row := make([]string, len(values))
+		for i, v := range values {
+			row[i] = sqlLiteral(v, columns[i].DataType)
+		}
+		data.Rows = append(data.Rows, row)
+	}
+
+	return data, rows.Err()
+}
+
+// sqlLiteral renders a scanned value as a T-SQL literal for a column of the
+// given type. Dates use unambiguous ISO 8601 formats and binary values 0x hex.
+func sqlLiteral(v interface{}, dataType string) string {
+	dataType = strings.ToLower(dataType)
+
+	switch val := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if val {
+			return "1"
+		}
+		return "0"
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case float64:
+		if math.IsInf(val, 0) || math.IsNaN(val) {
+			return "NULL"
+		}
+		return strconv.FormatFloat(val, 'g', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(val), 'g', -1, 32)
+	case string:
+		return stringLiteral(val, dataType)
+	case []byte:
+		switch dataType {
+		case "decimal", "numeric", "money", "smallmoney":
+			return string(val)
+		case "uniqueidentifier":
+			var id mssql.UniqueIdentifier
+			if err := id.Scan(val); err == nil {
+				return "'" + id.String() + "'"
+			}
+		}
+		return fmt.Sprintf("0x%X", val)
+	case time.Time:
+		switch dataType {
+		case "date":
+			return "'" + val.Format("2006-01-02") + "'"
+		case "time":
+			return "'" + val.Format("15:04:05.9999999") + "'"
+		case "datetimeoffset":
+			return "'" + val.Format("2006-01-02T15:04:05.9999999-07:00") + "'"
+		case "datetime", "smalldatetime":
+			return "'" + val.Format("2006-01-02T15:04:05.999") + "'"
+		}
+		return "'" + val.Format("2006-01-02T15:04:05.9999999") + "'"
+	default:
+		return stringLiteral(fmt.Sprint(val), dataType)
+	}
+}
+
+// stringLiteral quotes a string, with the N prefix for Unicode types
+func stringLiteral(s, dataType string) string {
+	quoted := "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	switch dataType {
+	case "nchar", "nvarchar", "ntext", "xml", "sysname", "sql_variant":
+		return "N" + quoted
+	}
+	return quoted
+}
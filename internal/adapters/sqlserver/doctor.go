@@ -0,0 +1,151 @@
+package sqlserver
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	mssql "github.com/microsoft/go-mssqldb"
+	"github.com/microsoft/go-mssqldb/msdsn"
+
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+)
+
+// featureCheck describes a server feature SQLPulse relies on. A feature is
+// checked either by minimum product version or by a catalog query returning
+// whether it exists.
+type featureCheck struct {
+	name       string
+	impact     string
+	minVersion string
+	query      string
+}
+
+// featureChecks are the features reported by Diagnose
+var featureChecks = []featureCheck{
+	{
+		name:       "CONCAT and TRY_CONVERT (SQL Server 2012)",
+		impact:     "dump, diff and sync cannot extract the schema",
+		minVersion: "11.0",
+	},
+	{
+		name:       "CREATE OR ALTER (SQL Server 2016 SP1)",
+		impact:     "scripts using CREATE OR ALTER fail",
+		minVersion: "13.0.4001",
+	},
+	{
+		name:   "sys.sql_expression_dependencies",
+		impact: "dependencies between views and modules cannot be read",
+		query:  "SELECT CASE WHEN OBJECT_ID('sys.sql_expression_dependencies') IS NULL THEN 0 ELSE 1 END",
+	},
+	{
+		name:   "Temporal tables",
+		impact: "system versioning is not dumped or compared",
+		query:  "SELECT CASE WHEN COL_LENGTH('sys.tables', 'temporal_type') IS NULL THEN 0 ELSE 1 END",
+	},
+	{
+		name:   "Dynamic data masking",
+		impact: "column masks are not dumped or compared",
+		query:  "SELECT CASE WHEN COL_LENGTH('sys.columns', 'is_masked') IS NULL THEN 0 ELSE 1 END",
+	},
+	{
+		name:   "Database scoped configurations",
+		impact: "scoped configurations are not dumped or compared",
+		query:  "SELECT CASE WHEN OBJECT_ID('sys.database_scoped_configurations') IS NULL THEN 0 ELSE 1 END",
+	},
+}
+
+// Diagnose checks step by step that the server can be reached, the TLS
+// handshake and login succeed, and which features SQLPulse relies on the
+// server supports. It never fails: whatever a step could not determine is
+// reported in the returned diagnostics.
+func Diagnose(ctx context.Context, config *domain.ConnectionConfig) *domain.Diagnostics {
+	d := &domain.Diagnostics{
+		Address:     fmt.Sprintf("%s:%d", config.Server, config.Port),
+		EncryptMode: config.EffectiveEncryptMode(),
+		TrustServer: config.TrustServer,
+	}
+
+	// Named instances are resolved through the SQL Server Browser, so their
+	// port is not known up front
+	if !strings.Contains(config.Server, `\`) {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(config.Server, strconv.Itoa(config.Port)))
+		if err != nil {
+			d.ReachError = err.Error()
+			d.ConnectHint = connectionHints[errorNumberNetwork]
+			return d
+		}
+		conn.Close()
+		d.Reachable = true
+	}
+
+	db, err := openDiagnostic(config, d)
+	if err == nil {
+		defer db.Close()
+		err = db.PingContext(ctx)
+	}
+	if err != nil {
+		connErr := newConnectionError(err)
+		d.ConnectError = connErr.Error()
+		d.ConnectHint = connErr.Hint
+		return d
+	}
+	d.Connected = true
+
+	row := db.QueryRowContext(ctx, `
+		SELECT
+			CONVERT(nvarchar(128), SERVERPROPERTY('ProductVersion')),
+			CONVERT(nvarchar(128), SERVERPROPERTY('Edition'))
+	`)
+	if err := row.Scan(&d.ProductVersion, &d.Edition); err != nil {
+		d.ConnectError = fmt.Sprintf("failed to get server version: %v", err)
+		return d
+	}
+
+	// sys.dm_exec_connections needs VIEW SERVER STATE; without it the
+	// session encryption stays unknown
+	row = db.QueryRowContext(ctx, `
+		SELECT CONVERT(nvarchar(40), encrypt_option), CONVERT(nvarchar(40), auth_scheme)
+		FROM sys.dm_exec_connections
+		WHERE session_id = @@SPID
+	`)
+	if err := row.Scan(&d.Encrypted, &d.AuthScheme); err != nil {
+		d.Encrypted, d.AuthScheme = "", ""
+	}
+
+	for _, fc := range featureChecks {
+		check := domain.FeatureCheck{Name: fc.name, Impact: fc.impact}
+		if fc.query == "" {
+			check.Available = domain.VersionAtLeast(d.ProductVersion, fc.minVersion)
+		} else if err := db.QueryRowContext(ctx, fc.query).Scan(&check.Available); err != nil {
+			check.Error = err.Error()
+		}
+		d.Features = append(d.Features, check)
+	}
+
+	return d
+}
+
+// openDiagnostic opens a single-connection pool for config that records the
+// negotiated TLS version in d once the handshake succeeds
+func openDiagnostic(config *domain.ConnectionConfig, d *domain.Diagnostics) (*sql.DB, error) {
+	params, err := msdsn.Parse(config.ConnectionString())
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection settings: %w", err)
+	}
+	if params.TLSConfig != nil {
+		params.TLSConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			d.TLSVersion = tls.VersionName(state.Version)
+			return nil
+		}
+	}
+
+	db := sql.OpenDB(mssql.NewConnectorConfig(params))
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
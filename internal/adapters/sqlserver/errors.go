@@ -0,0 +1,65 @@
+package sqlserver
+
+import (
+	"errors"
+	"net"
+	"strings"
+
+	mssql "github.com/microsoft/go-mssqldb"
+)
+
+// errorNumberNetwork is the SQL Server error number for a network-related or
+// instance-specific error; the driver reports these as dial errors instead
+const errorNumberNetwork = 2
+
+// connectionHints are remediation hints for the error numbers most commonly
+// raised while connecting
+var connectionHints = map[int32]string{
+	errorNumberNetwork: "The server could not be reached: check the server name and port, that SQL Server is running and accepts TCP connections, and that no firewall blocks the port",
+	4060:               "The database could not be opened: check the database name and that the login is mapped to a user in it",
+	18452:              "The login is from an untrusted domain: use SQL authentication or connect from a machine in a trusted domain",
+	18456:              "Login failed: check the user name and password, or use Windows authentication if the server does not allow SQL logins",
+	40615:              "The client IP address is blocked: add it to the server firewall rules",
+}
+
+// certificateHint is the hint for a TLS handshake that failed because the
+// server certificate could not be verified
+const certificateHint = "The server certificate is not trusted: install the certificate of its issuing CA on this machine, or use --trust-cert on networks you trust"
+
+// ConnectionError reports a failed connection along with a remediation hint
+// derived from the underlying SQL Server error number
+type ConnectionError struct {
+	Number int32  // SQL Server error number, 0 when unknown
+	Hint   string // Suggested remediation, empty when the cause is unknown
+	Err    error  // Underlying error
+}
+
+// Error returns the underlying error message
+func (e *ConnectionError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *ConnectionError) Unwrap() error {
+	return e.Err
+}
+
+// newConnectionError classifies a connection failure by its error number
+func newConnectionError(err error) *ConnectionError {
+	connErr := &ConnectionError{Err: err}
+
+	var sqlErr mssql.Error
+	var netErr net.Error
+	switch {
+	case errors.As(err, &sqlErr):
+		connErr.Number = sqlErr.Number
+	case errors.As(err, &netErr), strings.Contains(err.Error(), "unable to open tcp connection"):
+		connErr.Number = errorNumberNetwork
+	}
+
+	connErr.Hint = connectionHints[connErr.Number]
+	if connErr.Hint == "" && strings.Contains(err.Error(), "x509:") {
+		connErr.Hint = certificateHint
+	}
+	return connErr
+}
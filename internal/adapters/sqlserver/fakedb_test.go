@@ -0,0 +1,91 @@
+package sqlserver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// fakeQuery answers the catalog queries that contain match with the rows
+// returned for their arguments
+type fakeQuery struct {
+	match   string
+	columns []string
+	rows    func(args []driver.NamedValue) [][]driver.Value
+}
+
+// fakeDB is a database/sql connector serving canned catalog query results,
+// recording every query it receives
+type fakeDB struct {
+	queries  []fakeQuery
+	received []string
+}
+
+// newFakeExtractor returns a schema extractor reading from the fake queries
+func newFakeExtractor(queries ...fakeQuery) (*SchemaExtractor, *fakeDB) {
+	db := &fakeDB{queries: queries}
+	return NewSchemaExtractor(sql.OpenDB(db)), db
+}
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{db}, nil }
+
+func (db *fakeDB) Driver() driver.Driver { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("fake driver: open through the connector")
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fake driver: prepared statements are not supported")
+}
+
+func (fakeConn) Close() error { return nil }
+
+// Begin starts a transaction whose commit and rollback do nothing; the fake
+// queries answer SELECT @@TRANCOUNT as the test needs
+func (fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error { return nil }
+
+func (fakeTx) Rollback() error { return nil }
+
+func (c fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.received = append(c.db.received, query)
+	for _, q := range c.db.queries {
+		if strings.Contains(query, q.match) {
+			return &fakeRows{columns: q.columns, rows: q.rows(args)}, nil
+		}
+	}
+	return nil, fmt.Errorf("fake driver: unexpected query %s", query)
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
@@ -0,0 +1,136 @@
+package sqlserver
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+)
+
+// likeEscaper escapes LIKE metacharacters using \ as the escape character
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`, `[`, `\[`)
+
+// queryFilter builds a parameterized WHERE clause. Arguments are numbered
+// @p1, @p2, ... in the order they are added, so they must be passed to the
+// query in the same order via args.
+type queryFilter struct {
+	conditions []string
+	args       []interface{}
+}
+
+// newQueryFilter creates a filter with fixed conditions that take no arguments
+func newQueryFilter(conditions ...string) *queryFilter {
+	return &queryFilter{conditions: conditions}
+}
+
+// param adds an argument and returns its placeholder
+func (f *queryFilter) param(value interface{}) string {
+	f.args = append(f.args, value)
+	return fmt.Sprintf("@p%d", len(f.args))
+}
+
+// placeholders adds the values as arguments and returns their placeholders
+func (f *queryFilter) placeholders(values []string) string {
+	params := make([]string, len(values))
+	for i, v := range values {
+		params[i] = f.param(v)
+	}
+	return strings.Join(params, ", ")
+}
+
+// names adds the values as a single XML argument and returns a derived table
+// listing them in its name column. A request takes at most 2100 parameters,
+// so long lists such as the objects modified since a cutoff cannot be passed
+// one per parameter; XML is used rather than OPENJSON, which needs database
+// compatibility level 130.
+func (f *queryFilter) names(values []string) string {
+	var sb strings.Builder
+	for _, v := range values {
+		sb.WriteString("<n>")
+		xml.EscapeText(&sb, []byte(v))
+		sb.WriteString("</n>")
+	}
+	return fmt.Sprintf("(SELECT x.n.value('.', 'nvarchar(max)') AS name FROM (SELECT CAST(%s AS xml) AS doc) d CROSS APPLY d.doc.nodes('/n') x(n))",
+		f.param(sb.String()))
+}
+
+// in restricts column to the values. Values containing * or ? are glob
+// patterns matched with LIKE; the others are matched exactly. An empty list
+// adds no condition.
+func (f *queryFilter) in(column string, values []string) {
+	if preds := f.matchPredicates(column, values); len(preds) > 0 {
+		f.conditions = append(f.conditions, "("+strings.Join(preds, " OR ")+")")
+	}
+}
+
+// notIn excludes the values (literal names or glob patterns) from column.
+// An empty list adds no condition. Exclusions are ANDed with inclusions, so
+// they take precedence.
+func (f *queryFilter) notIn(column string, values []string) {
+	if preds := f.matchPredicates(column, values); len(preds) > 0 {
+		f.conditions = append(f.conditions, "NOT ("+strings.Join(preds, " OR ")+")")
+	}
+}
+
+// objects restricts column, an object_id, to the named objects. Names are
+// resolved with OBJECT_ID, so they may be schema-qualified and bracket-quoted.
+// An empty list adds no condition.
+func (f *queryFilter) objects(column string, names []string) {
+	if len(names) == 0 {
+		return
+	}
+	f.conditions = append(f.conditions, fmt.Sprintf("%s IN (SELECT OBJECT_ID(v.name) FROM %s v)", column, f.names(names)))
+}
+
+// userObjects excludes the objects shipped with SQL Server from the catalog
+// view aliased alias, unless includeSystem is set
+func (f *queryFilter) userObjects(alias string, includeSystem bool) {
+	if !includeSystem {
+		f.conditions = append(f.conditions, alias+".is_ms_shipped = 0")
+	}
+}
+
+// modifiedSince restricts column, an object's modify_date, to changes after
+// since. modify_date is in server local time, so the cutoff is shifted to the
+// server's current UTC offset. A zero time adds no condition.
+func (f *queryFilter) modifiedSince(column string, since time.Time) {
+	if since.IsZero() {
+		return
+	}
+	f.conditions = append(f.conditions, fmt.Sprintf(
+		"%s > CAST(SWITCHOFFSET(%s, DATEPART(TZOFFSET, SYSDATETIMEOFFSET())) AS datetime2)", column, f.param(since)))
+}
+
+// matchPredicates returns an IN predicate for the literal values and a LIKE
+// predicate for each glob pattern
+func (f *queryFilter) matchPredicates(column string, values []string) []string {
+	var literals, preds []string
+	for _, v := range values {
+		if domain.IsGlobPattern(v) {
+			preds = append(preds, fmt.Sprintf(`%s LIKE %s ESCAPE '\'`, column, f.param(globToLike(v))))
+		} else {
+			literals = append(literals, v)
+		}
+	}
+	if len(literals) > 0 {
+		preds = append([]string{fmt.Sprintf("%s IN (%s)", column, f.placeholders(literals))}, preds...)
+	}
+	return preds
+}
+
+// globToLike converts a glob pattern to a LIKE pattern: LIKE metacharacters
+// are escaped, then * becomes % and ? becomes _
+func globToLike(glob string) string {
+	escaped := likeEscaper.Replace(glob)
+	return strings.NewReplacer("*", "%", "?", "_").Replace(escaped)
+}
+
+// where returns the WHERE clause, or an empty string when there are no conditions
+func (f *queryFilter) where() string {
+	if len(f.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(f.conditions, "\n\t\t\tAND ")
+}
@@ -0,0 +1,73 @@
+package sqlserver
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+)
+
+// decodeNames returns the names of an XML list built by queryFilter.names
+func decodeNames(t *testing.T, arg interface{}) []string {
+	t.Helper()
+	var list struct {
+		Names []string `xml:"n"`
+	}
+	if err := xml.Unmarshal([]byte("<l>"+arg.(string)+"</l>"), &list); err != nil {
+		t.Fatalf("decode name list %q: %v", arg, err)
+	}
+	return list.Names
+}
+
+// manyNames returns more object names than a request can take parameters
+func manyNames() []string {
+	names := []string{"[dbo].[A&B]", "[dbo].[<Tag>]"}
+	for i := len(names); i < 3000; i++ {
+		names = append(names, fmt.Sprintf("[dbo].[T%d]", i))
+	}
+	return names
+}
+
+func TestQueryFilterObjectsUsesOneParameter(t *testing.T) {
+	names := manyNames()
+	filter := newQueryFilter()
+	filter.objects("t.object_id", names)
+
+	if len(filter.args) != 1 {
+		t.Fatalf("got %d parameters, want the names in one", len(filter.args))
+	}
+	if got := decodeNames(t, filter.args[0]); !reflect.DeepEqual(got, names) {
+		t.Errorf("decoded %d names, want the %d names passed in", len(got), len(names))
+	}
+}
+
+func TestResolveObjectsManyNames(t *testing.T) {
+	e, db := newFakeExtractor(fakeQuery{
+		match:   "LEFT JOIN sys.objects o",
+		columns: []string{"name", "object_type"},
+		rows: func(args []driver.NamedValue) [][]driver.Value {
+			var rows [][]driver.Value
+			for _, name := range decodeNames(t, args[0].Value) {
+				rows = append(rows, []driver.Value{name, "U"})
+			}
+			return rows
+		},
+	})
+
+	opts := domain.DefaultDumpOptions()
+	opts.ObjectFilter = manyNames()
+	resolved, err := e.resolveObjects(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("resolveObjects: %v", err)
+	}
+	if !resolved.IncludeTables || resolved.IncludeViews {
+		t.Errorf("got tables=%v views=%v, want only tables", resolved.IncludeTables, resolved.IncludeViews)
+	}
+	if len(db.received) != 1 {
+		t.Errorf("got %d queries, want 1", len(db.received))
+	}
+}
@@ -0,0 +1,63 @@
+package sqlserver
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"syscall"
+
+	mssql "github.com/microsoft/go-mssqldb"
+)
+
+// transientErrorNumbers are SQL Server / Azure SQL error numbers that
+// indicate a temporary condition worth retrying
+var transientErrorNumbers = map[int32]bool{
+	40197: true, // Service error processing the request
+	40501: true, // Service is busy
+	40613: true, // Database is not currently available
+	49918: true, // Not enough resources to process the request
+	49919: true, // Too many create/update operations in progress
+	49920: true, // Too many operations in progress
+}
+
+// nonTransactionalErrorNumbers are SQL Server error numbers raised when a
+// statement is not allowed inside a user transaction
+var nonTransactionalErrorNumbers = map[int32]bool{
+	226: true, // Statement not allowed within a multi-statement transaction
+	574: true, // Statement cannot be used inside a user transaction
+}
+
+// isNonTransactionalError reports whether err was raised because the
+// statement cannot run inside a transaction
+func isNonTransactionalError(err error) bool {
+	var sqlErr mssql.Error
+	return errors.As(err, &sqlErr) && nonTransactionalErrorNumbers[sqlErr.Number]
+}
+
+// isTransientError reports whether err is a temporary failure such as a
+// timeout, a refused connection, or an Azure SQL throttling error
+func isTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var sqlErr mssql.Error
+	if errors.As(err, &sqlErr) {
+		return transientErrorNumbers[sqlErr.Number]
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+
+	// The driver does not always wrap dial errors, so fall back to the message
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "connection refused") ||
+		strings.Contains(msg, "i/o timeout") ||
+		strings.Contains(msg, "connection reset")
+}
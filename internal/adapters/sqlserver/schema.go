@@ -3,15 +3,28 @@ package sqlserver
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/enunezf/SQLPulse/internal/core/domain"
 )
 
+// queryWhitespace matches runs of whitespace in logged queries
+var queryWhitespace = regexp.MustCompile(`\s+`)
+
+// maxLoggedQuery is the number of characters of a query shown in the query log
+const maxLoggedQuery = 100
+
 // SchemaExtractor extracts DDL from SQL Server
 type SchemaExtractor struct {
-	db *sql.DB
+	db           *sql.DB
+	queryLog     io.Writer     // Receives per-query timings when set
+	queryTimeout time.Duration // Limit for each extraction step (0 = none)
+	database     string        // Database the queries run in, when not the connection's own
 }
 
 // NewSchemaExtractor creates a new schema extractor
@@ -19,27 +32,158 @@ func NewSchemaExtractor(db *sql.DB) *SchemaExtractor {
 	return &SchemaExtractor{db: db}
 }
 
+// SetQueryLog enables logging of every catalog query and its duration to w
+func (e *SchemaExtractor) SetQueryLog(w io.Writer) {
+	e.queryLog = w
+}
+
+// SetQueryTimeout limits each extraction step (the queries for one table, or
+// for one object type) to d, so a single slow catalog query fails fast instead
+// of using up the command's whole deadline. Zero disables the limit.
+func (e *SchemaExtractor) SetQueryTimeout(d time.Duration) {
+	e.queryTimeout = d
+}
+
+// SetDatabase runs every catalog query in another database of the same
+// server, so one connection can extract several databases. Each query is
+// prefixed with USE; the pool resets the database of a connection before
+// reusing it.
+func (e *SchemaExtractor) SetDatabase(name string) {
+	e.database = name
+}
+
+// withTimeout runs an extraction step under the query timeout, retrying it
+// once if it fails with a transient error
+func (e *SchemaExtractor) withTimeout(ctx context.Context, step string, extract func(ctx context.Context) error) error {
+	timedOut, err := e.attempt(ctx, extract)
+	if err != nil && !timedOut && ctx.Err() == nil && isTransientError(err) {
+		timedOut, err = e.attempt(ctx, extract)
+	}
+	if timedOut {
+		return fmt.Errorf("extracting %s timed out after %s (see --query-timeout): %w", step, e.queryTimeout, err)
+	}
+	return err
+}
+
+// attempt runs extract once and reports whether it hit the query timeout
+func (e *SchemaExtractor) attempt(ctx context.Context, extract func(ctx context.Context) error) (bool, error) {
+	if e.queryTimeout <= 0 {
+		return false, extract(ctx)
+	}
+	stepCtx, cancel := context.WithTimeout(ctx, e.queryTimeout)
+	defer cancel()
+	err := extract(stepCtx)
+	return err != nil && ctx.Err() == nil && errors.Is(stepCtx.Err(), context.DeadlineExceeded), err
+}
+
+// query runs a catalog query, logging its duration when a query log is set
+func (e *SchemaExtractor) query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := e.db.QueryContext(ctx, e.inDatabase(query), args...)
+	e.logQuery(query, time.Since(start))
+	return rows, err
+}
+
+// queryRow runs a single-row catalog query, logging its duration when a query log is set
+func (e *SchemaExtractor) queryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := e.db.QueryRowContext(ctx, e.inDatabase(query), args...)
+	e.logQuery(query, time.Since(start))
+	return row
+}
+
+// inDatabase prefixes query with USE when the extractor targets another database
+func (e *SchemaExtractor) inDatabase(query string) string {
+	if e.database == "" {
+		return query
+	}
+	return "USE " + domain.QuoteIdent(e.database) + ";\n" + query
+}
+
+// logQuery writes a condensed query and its duration to the query log
+func (e *SchemaExtractor) logQuery(query string, elapsed time.Duration) {
+	if e.queryLog == nil {
+		return
+	}
+	text := strings.TrimSpace(queryWhitespace.ReplaceAllString(query, " "))
+	if len(text) > maxLoggedQuery {
+		text = text[:maxLoggedQuery] + "..."
+	}
+	fmt.Fprintf(e.queryLog, "  [%8.1fms] %s\n", float64(elapsed.Microseconds())/1000, text)
+}
+
 // ExtractSchema extracts the complete database schema
 func (e *SchemaExtractor) ExtractSchema(ctx context.Context, opts *domain.DumpOptions) (*domain.DatabaseSchema, error) {
 	schema := &domain.DatabaseSchema{}
 
-	// Get database name
-	row := e.db.QueryRowContext(ctx, "SELECT DB_NAME()")
-	if err := row.Scan(&schema.DatabaseName); err != nil {
+	// Get database name, default collations and server version
+	row := e.queryRow(ctx,
+		"SELECT DB_NAME(), ISNULL(CONVERT(nvarchar(128), DATABASEPROPERTYEX(DB_NAME(), 'Collation')), ''), ISNULL(CONVERT(nvarchar(128), SERVERPROPERTY('Collation')), ''), CONVERT(nvarchar(128), SERVERPROPERTY('ProductVersion'))")
+	if err := row.Scan(&schema.DatabaseName, &schema.Collation, &schema.ServerCollation, &schema.ProductVersion); err != nil {
 		return nil, fmt.Errorf("failed to get database name: %w", err)
 	}
 
+	// HASHBYTES only takes more than 8000 bytes from SQL Server 2016, so older
+	// servers send the definitions, which the comparator hashes itself
+	if opts.DefinitionHashesOnly && domain.MajorVersion(schema.ProductVersion) < domain.SQLServer2016 {
+		withText := *opts
+		withText.DefinitionHashesOnly = false
+		opts = &withText
+	}
+
 	var err error
 
-	// Extract schemas
-	schema.Schemas, err = e.ExtractSchemas(ctx)
-	if err != nil {
-		return nil, err
+	// Named objects only: resolve their types so that only the matching
+	// object queries run. Partial snapshots skip database-level settings and schemas.
+	if len(opts.ObjectFilter) > 0 {
+		opts, err = e.resolveObjects(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+	partial := opts.IsPartial()
+	if partial {
+		schema.Collation = ""
+		schema.ServerCollation = ""
+	}
+
+	if !partial {
+		// Extract database scoped configurations
+		err = e.withTimeout(ctx, "scoped configurations", func(ctx context.Context) (err error) {
+			schema.ScopedConfigurations, err = e.ExtractScopedConfigurations(ctx)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Extract schemas
+		err = e.withTimeout(ctx, "schemas", func(ctx context.Context) (err error) {
+			schema.Schemas, err = e.ExtractSchemas(ctx, opts)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Extract partition functions and schemes used by tables and indexes
+	if opts.IncludeTables {
+		err = e.withTimeout(ctx, "partitions", func(ctx context.Context) (err error) {
+			if schema.PartitionFunctions, err = e.ExtractPartitionFunctions(ctx); err != nil {
+				return err
+			}
+			schema.PartitionSchemes, err = e.ExtractPartitionSchemes(ctx)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Extract tables with indexes and constraints
 	if opts.IncludeTables {
-		schema.Tables, err = e.ExtractTables(ctx, opts.SchemaFilter, opts.TableFilter)
+		schema.Tables, err = e.ExtractTables(ctx, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -47,7 +191,10 @@ func (e *SchemaExtractor) ExtractSchema(ctx context.Context, opts *domain.DumpOp
 
 	// Extract views
 	if opts.IncludeViews {
-		schema.Views, err = e.ExtractViews(ctx, opts.SchemaFilter)
+		err = e.withTimeout(ctx, "views", func(ctx context.Context) (err error) {
+			schema.Views, err = e.ExtractViews(ctx, opts)
+			return err
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -55,7 +202,10 @@ func (e *SchemaExtractor) ExtractSchema(ctx context.Context, opts *domain.DumpOp
 
 	// Extract stored procedures
 	if opts.IncludeProcedures {
-		schema.StoredProcedures, err = e.ExtractProcedures(ctx, opts.SchemaFilter)
+		err = e.withTimeout(ctx, "procedures", func(ctx context.Context) (err error) {
+			schema.StoredProcedures, err = e.ExtractProcedures(ctx, opts)
+			return err
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -63,7 +213,10 @@ func (e *SchemaExtractor) ExtractSchema(ctx context.Context, opts *domain.DumpOp
 
 	// Extract functions
 	if opts.IncludeFunctions {
-		schema.Functions, err = e.ExtractFunctions(ctx, opts.SchemaFilter)
+		err = e.withTimeout(ctx, "functions", func(ctx context.Context) (err error) {
+			schema.Functions, err = e.ExtractFunctions(ctx, opts)
+			return err
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -71,7 +224,32 @@ func (e *SchemaExtractor) ExtractSchema(ctx context.Context, opts *domain.DumpOp
 
 	// Extract triggers
 	if opts.IncludeTriggers {
-		schema.Triggers, err = e.ExtractTriggers(ctx, opts.SchemaFilter)
+		err = e.withTimeout(ctx, "triggers", func(ctx context.Context) (err error) {
+			schema.Triggers, err = e.ExtractTriggers(ctx, opts)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Extract permissions
+	if opts.IncludePermissions {
+		err = e.withTimeout(ctx, "permissions", func(ctx context.Context) (err error) {
+			schema.Permissions, err = e.ExtractPermissions(ctx, opts)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Extract statistics that do not back an index
+	if opts.IncludeTables && opts.IncludeStatistics {
+		err = e.withTimeout(ctx, "statistics", func(ctx context.Context) (err error) {
+			schema.Statistics, err = e.ExtractStatistics(ctx, opts)
+			return err
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -80,22 +258,173 @@ func (e *SchemaExtractor) ExtractSchema(ctx context.Context, opts *domain.DumpOp
 	return schema, nil
 }
 
-// ExtractSchemas extracts schema definitions
-func (e *SchemaExtractor) ExtractSchemas(ctx context.Context) ([]domain.Schema, error) {
-	query := `
+// resolveObjects looks up the type of each object in opts.ObjectFilter and
+// returns a copy of opts that only includes the object types found. It fails
+// if any name does not resolve to an object.
+func (e *SchemaExtractor) resolveObjects(ctx context.Context, opts *domain.DumpOptions) (*domain.DumpOptions, error) {
+	filter := newQueryFilter()
+	query := fmt.Sprintf(`
+		SELECT v.name, ISNULL(RTRIM(o.type), '') AS object_type
+		FROM %s v
+		LEFT JOIN sys.objects o ON o.object_id = OBJECT_ID(v.name)
+	`, filter.names(opts.ObjectFilter))
+
+	rows, err := e.query(ctx, query, filter.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve objects: %w", err)
+	}
+	defer rows.Close()
+
+	resolved := *opts
+	resolved.IncludeTables = false
+	resolved.IncludeViews = false
+	resolved.IncludeProcedures = false
+	resolved.IncludeFunctions = false
+	resolved.IncludeTriggers = false
+
+	var missing []string
+	for rows.Next() {
+		var name, objectType string
+		if err := rows.Scan(&name, &objectType); err != nil {
+			return nil, fmt.Errorf("failed to scan object: %w", err)
+		}
+		switch objectType {
+		case "U":
+			resolved.IncludeTables = opts.IncludeTables
+		case "V":
+			resolved.IncludeViews = opts.IncludeViews
+		case "P":
+			resolved.IncludeProcedures = opts.IncludeProcedures
+		case "FN", "IF", "TF":
+			resolved.IncludeFunctions = opts.IncludeFunctions
+		case "TR":
+			resolved.IncludeTriggers = opts.IncludeTriggers
+		case "":
+			missing = append(missing, name)
+		default:
+			return nil, fmt.Errorf("object %s has unsupported type %s", name, objectType)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 && !opts.IgnoreMissingObjects {
+		return nil, fmt.Errorf("object not found: %s", strings.Join(missing, ", "))
+	}
+
+	return &resolved, nil
+}
+
+// ModifiedObjects returns the schema-qualified names of the tables, views,
+// procedures, functions and triggers modified after since
+func (e *SchemaExtractor) ModifiedObjects(ctx context.Context, since time.Time) ([]string, error) {
+	filter := newQueryFilter("o.is_ms_shipped = 0", "o.type IN ('U', 'V', 'P', 'FN', 'IF', 'TF', 'TR')")
+	filter.modifiedSince("o.modify_date", since)
+
+	query := fmt.Sprintf(`
+		SELECT QUOTENAME(s.name) + '.' + QUOTENAME(o.name)
+		FROM sys.objects o
+		INNER JOIN sys.schemas s ON o.schema_id = s.schema_id
+		%s
+		ORDER BY s.name, o.name
+	`, filter.where())
+
+	rows, err := e.query(ctx, query, filter.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query modified objects: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan object name: %w", err)
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+// ExtractScopedConfigurations extracts database scoped configurations.
+// Servers older than SQL Server 2016 have none and return an empty list.
+func (e *SchemaExtractor) ExtractScopedConfigurations(ctx context.Context) ([]domain.ScopedConfiguration, error) {
+	var available bool
+	row := e.queryRow(ctx, "SELECT CASE WHEN OBJECT_ID('sys.database_scoped_configurations') IS NULL THEN 0 ELSE 1 END")
+	if err := row.Scan(&available); err != nil {
+		return nil, fmt.Errorf("failed to check for scoped configurations: %w", err)
+	}
+	if !available {
+		return nil, nil
+	}
+
+	// is_value_default needs SQL Server 2017; older servers fall back to the
+	// known defaults of the domain
+	isDefault := "CAST(0 AS bit) AS is_value_default"
+	flagged, err := e.hasCatalogColumn(ctx, "sys.database_scoped_configurations", "is_value_default")
+	if err != nil {
+		return nil, err
+	}
+	if flagged {
+		isDefault = "is_value_default"
+	}
+
+	query := fmt.Sprintf(`
 		SELECT
-			s.name AS schema_name,
-			p.name AS owner_name
-		FROM sys.schemas s
-		INNER JOIN sys.database_principals p ON s.principal_id = p.principal_id
-		WHERE s.schema_id < 16384
+			name,
+			ISNULL(CONVERT(nvarchar(4000), value), '') AS value,
+			ISNULL(CONVERT(nvarchar(4000), value_for_secondary), '') AS value_for_secondary,
+			%s
+		FROM sys.database_scoped_configurations
+		ORDER BY name
+	`, isDefault)
+
+	rows, err := e.query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scoped configurations: %w", err)
+	}
+	defer rows.Close()
+
+	var configs []domain.ScopedConfiguration
+	for rows.Next() {
+		var sc domain.ScopedConfiguration
+		if err := rows.Scan(&sc.Name, &sc.Value, &sc.ValueForSecondary, &sc.ValueIsDefault); err != nil {
+			return nil, fmt.Errorf("failed to scan scoped configuration: %w", err)
+		}
+		configs = append(configs, sc)
+	}
+
+	return configs, rows.Err()
+}
+
+// systemSchemasCondition excludes the schemas every database has and those
+// of the fixed database roles
+const systemSchemasCondition = `s.schema_id < 16384
 			AND s.name NOT IN ('dbo', 'guest', 'INFORMATION_SCHEMA', 'sys', 'db_owner',
 				'db_accessadmin', 'db_securityadmin', 'db_ddladmin', 'db_backupoperator',
-				'db_datareader', 'db_datawriter', 'db_denydatareader', 'db_denydatawriter')
+				'db_datareader', 'db_datawriter', 'db_denydatareader', 'db_denydatawriter')`
+
+// ExtractSchemas extracts schema definitions. Built-in schemas are skipped
+// unless opts includes system objects. A schema whose owner principal was
+// dropped is still extracted, with an empty owner.
+func (e *SchemaExtractor) ExtractSchemas(ctx context.Context, opts *domain.DumpOptions) ([]domain.Schema, error) {
+	condition := "WHERE " + systemSchemasCondition
+	if opts.IncludeSystemObjects {
+		condition = ""
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			s.name AS schema_name,
+			ISNULL(p.name, '') AS owner_name
+		FROM sys.schemas s
+		LEFT JOIN sys.database_principals p ON s.principal_id = p.principal_id
+		%s
 		ORDER BY s.name
-	`
+	`, condition)
 
-	rows, err := e.db.QueryContext(ctx, query)
+	rows, err := e.query(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query schemas: %w", err)
 	}
@@ -114,80 +443,203 @@ func (e *SchemaExtractor) ExtractSchemas(ctx context.Context) ([]domain.Schema,
 }
 
 // ExtractTables extracts table definitions with columns, PKs, and indexes
-func (e *SchemaExtractor) ExtractTables(ctx context.Context, schemaFilter, tableFilter []string) ([]domain.Table, error) {
+func (e *SchemaExtractor) ExtractTables(ctx context.Context, opts *domain.DumpOptions) ([]domain.Table, error) {
 	// Build filter conditions
-	whereClause := "WHERE t.is_ms_shipped = 0"
-	if len(schemaFilter) > 0 {
-		whereClause += fmt.Sprintf(" AND s.name IN ('%s')", strings.Join(schemaFilter, "','"))
+	filter := newQueryFilter()
+	filter.userObjects("t", opts.IncludeSystemObjects)
+	filter.in("s.name", opts.SchemaFilter)
+	filter.in("t.name", opts.TableFilter)
+	filter.notIn("s.name", opts.SchemaExclude)
+	filter.notIn("t.name", opts.TableExclude)
+	filter.objects("t.object_id", opts.ObjectFilter)
+	filter.modifiedSince("t.modify_date", opts.ModifiedSince)
+
+	// Temporal tables need SQL Server 2016 or later
+	temporalColumns := "0 AS temporal_type, '' AS history_schema, '' AS history_table"
+	temporalJoins := ""
+	temporal, err := e.hasCatalogColumn(ctx, "sys.tables", "temporal_type")
+	if err != nil {
+		return nil, err
 	}
-	if len(tableFilter) > 0 {
-		whereClause += fmt.Sprintf(" AND t.name IN ('%s')", strings.Join(tableFilter, "','"))
+	if temporal {
+		temporalColumns = "t.temporal_type, ISNULL(hs.name, '') AS history_schema, ISNULL(ht.name, '') AS history_table"
+		temporalJoins = `
+		LEFT JOIN sys.tables ht ON ht.object_id = t.history_table_id
+		LEFT JOIN sys.schemas hs ON ht.schema_id = hs.schema_id`
+	}
+
+	// Dynamic Data Masking needs SQL Server 2016 or later
+	masking, err := e.hasCatalogColumn(ctx, "sys.columns", "is_masked")
+	if err != nil {
+		return nil, err
+	}
+
+	// Memory-optimized tables and their hash indexes need SQL Server 2014 or later
+	memoryColumns := "CAST(0 AS bit) AS is_memory_optimized, '' AS durability"
+	inMemory, err := e.hasCatalogColumn(ctx, "sys.tables", "is_memory_optimized")
+	if err != nil {
+		return nil, err
+	}
+	if inMemory {
+		memoryColumns = "t.is_memory_optimized, CASE WHEN t.is_memory_optimized = 1 THEN t.durability_desc ELSE '' END AS durability"
 	}
 
 	// Query tables
 	query := fmt.Sprintf(`
 		SELECT
+			t.object_id,
 			s.name AS schema_name,
-			t.name AS table_name
+			t.name AS table_name,
+			ISNULL(ps.name, '') AS partition_scheme,
+			ISNULL(pc.name, '') AS partition_column,
+			ISNULL(fg.name, '') AS filegroup_name,
+			%s,
+			%s
 		FROM sys.tables t
-		INNER JOIN sys.schemas s ON t.schema_id = s.schema_id
+		INNER JOIN sys.schemas s ON t.schema_id = s.schema_id%s
+		LEFT JOIN sys.indexes hi ON hi.object_id = t.object_id AND hi.index_id IN (0, 1)
+		LEFT JOIN sys.partition_schemes ps ON hi.data_space_id = ps.data_space_id
+		LEFT JOIN sys.filegroups fg ON hi.data_space_id = fg.data_space_id
+		LEFT JOIN sys.index_columns pic ON pic.object_id = hi.object_id
+			AND pic.index_id = hi.index_id AND pic.partition_ordinal = 1
+		LEFT JOIN sys.columns pc ON pic.object_id = pc.object_id AND pic.column_id = pc.column_id
 		%s
 		ORDER BY s.name, t.name
-	`, whereClause)
-
-	rows, err := e.db.QueryContext(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query tables: %w", err)
-	}
-	defer rows.Close()
+	`, temporalColumns, memoryColumns, temporalJoins, filter.where())
 
 	var tables []domain.Table
-	for rows.Next() {
-		var t domain.Table
-		if err := rows.Scan(&t.SchemaName, &t.Name); err != nil {
-			return nil, fmt.Errorf("failed to scan table: %w", err)
+	var ids []int
+	err = e.withTimeout(ctx, "tables", func(ctx context.Context) error {
+		tables, ids = nil, nil
+		rows, err := e.query(ctx, query, filter.args...)
+		if err != nil {
+			return fmt.Errorf("failed to query tables: %w", err)
 		}
-		tables = append(tables, t)
-	}
-	if err := rows.Err(); err != nil {
+		defer rows.Close()
+
+		for rows.Next() {
+			var t domain.Table
+			var objectID, temporalType int
+			if err := rows.Scan(&objectID, &t.SchemaName, &t.Name, &t.PartitionScheme, &t.PartitionColumn, &t.FileGroup,
+				&temporalType, &t.HistorySchema, &t.HistoryTable, &t.IsMemoryOptimized, &t.Durability); err != nil {
+				return fmt.Errorf("failed to scan table: %w", err)
+			}
+			t.IsHistoryTable = temporalType == 1
+			t.IsSystemVersioned = temporalType == 2
+			tables = append(tables, t)
+			ids = append(ids, objectID)
+		}
+		return rows.Err()
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// Extract columns, PKs, indexes, and FKs for each table
-	for i := range tables {
-		tables[i].Columns, err = e.extractColumns(ctx, tables[i].SchemaName, tables[i].Name)
-		if err != nil {
+	// Many tables are faster to extract with a few queries covering all of
+	// them; otherwise extract columns, PKs, indexes, and FKs for each table,
+	// each table under its own query timeout
+	if len(tables) > bulkTableThreshold {
+		if err := e.extractTableDetailsBulk(ctx, tables, ids, masking, inMemory, opts.IncludeSystemObjects); err != nil {
 			return nil, err
 		}
-
-		tables[i].PrimaryKey, err = e.extractPrimaryKey(ctx, tables[i].SchemaName, tables[i].Name)
-		if err != nil {
-			return nil, err
+	} else {
+		for i := range tables {
+			name := fmt.Sprintf("table %s.%s", tables[i].SchemaName, tables[i].Name)
+			err := e.withTimeout(ctx, name, func(ctx context.Context) error {
+				return e.extractTableDetails(ctx, &tables[i], masking, inMemory)
+			})
+			if err != nil {
+				return nil, err
+			}
 		}
+	}
 
-		tables[i].Indexes, err = e.extractIndexes(ctx, tables[i].SchemaName, tables[i].Name)
-		if err != nil {
-			return nil, err
-		}
+	markMemoryOptimizedIndexes(tables)
+	return tables, nil
+}
 
-		tables[i].ForeignKeys, err = e.extractForeignKeys(ctx, tables[i].SchemaName, tables[i].Name)
-		if err != nil {
-			return nil, err
+// markMemoryOptimizedIndexes flags the primary key and indexes of
+// memory-optimized tables, which are created and dropped with the table
+// statements rather than CREATE and DROP INDEX
+func markMemoryOptimizedIndexes(tables []domain.Table) {
+	for i := range tables {
+		t := &tables[i]
+		if !t.IsMemoryOptimized {
+			continue
 		}
-
-		tables[i].CheckConstraints, err = e.extractCheckConstraints(ctx, tables[i].SchemaName, tables[i].Name)
-		if err != nil {
-			return nil, err
+		if t.PrimaryKey != nil {
+			t.PrimaryKey.IsMemoryOptimized = true
+		}
+		for j := range t.Indexes {
+			t.Indexes[j].IsMemoryOptimized = true
 		}
 	}
+}
 
-	return tables, nil
+// hasCatalogColumn reports whether a catalog view has a column, which
+// depends on the SQL Server version
+func (e *SchemaExtractor) hasCatalogColumn(ctx context.Context, view, column string) (bool, error) {
+	var exists bool
+	row := e.queryRow(ctx, "SELECT CASE WHEN COL_LENGTH(@p1, @p2) IS NULL THEN 0 ELSE 1 END", view, column)
+	if err := row.Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check for %s.%s: %w", view, column, err)
+	}
+	return exists, nil
 }
 
-// extractColumns extracts column definitions for a table
-func (e *SchemaExtractor) extractColumns(ctx context.Context, schemaName, tableName string) ([]domain.Column, error) {
-	query := `
+// tableCondition restricts the table detail queries to one table, given as
+// @p1 (schema) and @p2 (table)
+const tableCondition = "s.name = @p1 AND t.name = @p2"
+
+// extractTableDetails extracts the columns, keys, indexes and constraints of
+// a table. masking reports whether the server supports Dynamic Data Masking,
+// inMemory whether it supports memory-optimized tables.
+func (e *SchemaExtractor) extractTableDetails(ctx context.Context, t *domain.Table, masking, inMemory bool) error {
+	var err error
+
+	t.Columns, err = e.extractColumns(ctx, t.SchemaName, t.Name, masking)
+	if err != nil {
+		return err
+	}
+
+	t.PrimaryKey, err = e.extractPrimaryKey(ctx, t.SchemaName, t.Name, inMemory)
+	if err != nil {
+		return err
+	}
+
+	t.Indexes, err = e.extractIndexes(ctx, t.SchemaName, t.Name, inMemory)
+	if err != nil {
+		return err
+	}
+
+	t.ForeignKeys, err = e.extractForeignKeys(ctx, t.SchemaName, t.Name)
+	if err != nil {
+		return err
+	}
+
+	t.CheckConstraints, err = e.extractCheckConstraints(ctx, t.SchemaName, t.Name)
+	if err != nil {
+		return err
+	}
+
+	t.UniqueConstraints, err = e.extractUniqueConstraints(ctx, t.SchemaName, t.Name)
+	return err
+}
+
+// columnsQuery returns the column query for the tables matching condition,
+// with their masking functions when masking is supported
+func columnsQuery(condition string, masking bool) string {
+	maskingColumn := "'' AS masking_function"
+	maskingJoin := ""
+	if masking {
+		maskingColumn = "ISNULL(mc.masking_function, '') AS masking_function"
+		maskingJoin = `
+		LEFT JOIN sys.masked_columns mc ON c.object_id = mc.object_id AND c.column_id = mc.column_id`
+	}
+
+	return fmt.Sprintf(`
 		SELECT
+			c.object_id,
 			c.name AS column_name,
 			c.column_id AS ordinal_position,
 			TYPE_NAME(c.user_type_id) AS data_type,
@@ -196,24 +648,58 @@ func (e *SchemaExtractor) extractColumns(ctx context.Context, schemaName, tableN
 			c.scale,
 			c.is_nullable,
 			CASE WHEN dc.definition IS NOT NULL THEN 1 ELSE 0 END AS has_default,
+			ISNULL(dc.name, '') AS default_name,
 			ISNULL(dc.definition, '') AS default_value,
 			c.is_identity,
 			ISNULL(CAST(ic.seed_value AS BIGINT), 0) AS identity_seed,
 			ISNULL(CAST(ic.increment_value AS BIGINT), 0) AS identity_increment,
 			c.is_computed,
 			ISNULL(cc.definition, '') AS computed_definition,
-			ISNULL(c.collation_name, '') AS collation_name
+			ISNULL(c.collation_name, '') AS collation_name,
+			CASE COLUMNPROPERTY(c.object_id, c.name, 'GeneratedAlwaysType')
+				WHEN 1 THEN 'ROW START' WHEN 2 THEN 'ROW END' ELSE '' END AS generated_always,
+			ISNULL(COLUMNPROPERTY(c.object_id, c.name, 'IsHidden'), 0) AS is_hidden,
+			c.is_sparse,
+			c.is_column_set,
+			c.is_rowguidcol,
+			c.is_filestream,
+			%s
 		FROM sys.columns c
 		INNER JOIN sys.tables t ON c.object_id = t.object_id
 		INNER JOIN sys.schemas s ON t.schema_id = s.schema_id
 		LEFT JOIN sys.default_constraints dc ON c.default_object_id = dc.object_id
 		LEFT JOIN sys.identity_columns ic ON c.object_id = ic.object_id AND c.column_id = ic.column_id
-		LEFT JOIN sys.computed_columns cc ON c.object_id = cc.object_id AND c.column_id = cc.column_id
-		WHERE s.name = @p1 AND t.name = @p2
-		ORDER BY c.column_id
-	`
+		LEFT JOIN sys.computed_columns cc ON c.object_id = cc.object_id AND c.column_id = cc.column_id%s
+		WHERE %s
+		ORDER BY c.object_id, c.column_id
+	`, maskingColumn, maskingJoin, condition)
+}
+
+// scanColumn scans a row of columnsQuery, returning the table object_id.
+// TYPE_NAME returns NULL for types the login cannot see, leaving the data
+// type empty.
+func scanColumn(rows *sql.Rows) (int, domain.Column, error) {
+	var objectID int
+	var c domain.Column
+	var dataType sql.NullString
+	if err := rows.Scan(
+		&objectID, &c.Name, &c.OrdinalPosition, &dataType, &c.MaxLength,
+		&c.Precision, &c.Scale, &c.IsNullable, &c.HasDefault, &c.DefaultName, &c.DefaultValue,
+		&c.IsIdentity, &c.IdentitySeed, &c.IdentityIncrement,
+		&c.IsComputed, &c.ComputedDefinition, &c.Collation,
+		&c.GeneratedAlways, &c.IsHidden, &c.IsSparse, &c.IsColumnSet,
+		&c.IsRowGuidCol, &c.IsFilestream, &c.MaskingFunction,
+	); err != nil {
+		return 0, c, fmt.Errorf("failed to scan column: %w", err)
+	}
+	c.DataType = dataType.String
+	return objectID, c, nil
+}
 
-	rows, err := e.db.QueryContext(ctx, query, schemaName, tableName)
+// extractColumns extracts column definitions for a table, with their masking
+// functions when masking is supported
+func (e *SchemaExtractor) extractColumns(ctx context.Context, schemaName, tableName string, masking bool) ([]domain.Column, error) {
+	rows, err := e.query(ctx, columnsQuery(tableCondition, masking), schemaName, tableName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query columns for %s.%s: %w", schemaName, tableName, err)
 	}
@@ -221,14 +707,9 @@ func (e *SchemaExtractor) extractColumns(ctx context.Context, schemaName, tableN
 
 	var columns []domain.Column
 	for rows.Next() {
-		var c domain.Column
-		if err := rows.Scan(
-			&c.Name, &c.OrdinalPosition, &c.DataType, &c.MaxLength,
-			&c.Precision, &c.Scale, &c.IsNullable, &c.HasDefault, &c.DefaultValue,
-			&c.IsIdentity, &c.IdentitySeed, &c.IdentityIncrement,
-			&c.IsComputed, &c.ComputedDefinition, &c.Collation,
-		); err != nil {
-			return nil, fmt.Errorf("failed to scan column: %w", err)
+		_, c, err := scanColumn(rows)
+		if err != nil {
+			return nil, err
 		}
 		columns = append(columns, c)
 	}
@@ -236,21 +717,55 @@ func (e *SchemaExtractor) extractColumns(ctx context.Context, schemaName, tableN
 	return columns, rows.Err()
 }
 
-// extractPrimaryKey extracts the primary key for a table
-func (e *SchemaExtractor) extractPrimaryKey(ctx context.Context, schemaName, tableName string) (*domain.Index, error) {
-	query := `
+// bucketCountColumn returns the select expression of the bucket count of
+// hash indexes, which need SQL Server 2014 or later, and the join it needs
+func bucketCountColumn(inMemory bool) (column, join string) {
+	if !inMemory {
+		return "CAST(0 AS bigint) AS bucket_count", ""
+	}
+	return "ISNULL(hi.bucket_count, 0) AS bucket_count", `
+		LEFT JOIN sys.hash_indexes hi ON i.object_id = hi.object_id AND i.index_id = hi.index_id`
+}
+
+// primaryKeysQuery returns the primary key query for the tables matching
+// condition, with the bucket count of hash primary keys when inMemory
+func primaryKeysQuery(condition string, inMemory bool) string {
+	bucketCount, bucketJoin := bucketCountColumn(inMemory)
+	return fmt.Sprintf(`
 		SELECT
+			t.object_id,
 			i.name AS index_name,
-			i.type_desc AS index_type
+			i.type_desc AS index_type,
+			%s
 		FROM sys.indexes i
 		INNER JOIN sys.tables t ON i.object_id = t.object_id
-		INNER JOIN sys.schemas s ON t.schema_id = s.schema_id
-		WHERE s.name = @p1 AND t.name = @p2 AND i.is_primary_key = 1
-	`
+		INNER JOIN sys.schemas s ON t.schema_id = s.schema_id%s
+		WHERE %s AND i.is_primary_key = 1
+	`, bucketCount, bucketJoin, condition)
+}
 
-	var pk domain.Index
-	var indexType string
-	err := e.db.QueryRowContext(ctx, query, schemaName, tableName).Scan(&pk.Name, &indexType)
+// newPrimaryKey returns a primary key index from a row of primaryKeysQuery
+func newPrimaryKey(schemaName, tableName, name, indexType string, bucketCount int64) *domain.Index {
+	return &domain.Index{
+		Name:           name,
+		SchemaName:     schemaName,
+		TableName:      tableName,
+		Type:           domain.IndexType(indexType),
+		IsPrimaryKey:   true,
+		IsUnique:       true,
+		IsClustered:    indexType == "CLUSTERED",
+		BucketCount:    bucketCount,
+		AllowRowLocks:  true,
+		AllowPageLocks: true,
+	}
+}
+
+// extractPrimaryKey extracts the primary key for a table
+func (e *SchemaExtractor) extractPrimaryKey(ctx context.Context, schemaName, tableName string, inMemory bool) (*domain.Index, error) {
+	var objectID int
+	var name, indexType string
+	var bucketCount int64
+	err := e.queryRow(ctx, primaryKeysQuery(tableCondition, inMemory), schemaName, tableName).Scan(&objectID, &name, &indexType, &bucketCount)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -258,11 +773,7 @@ func (e *SchemaExtractor) extractPrimaryKey(ctx context.Context, schemaName, tab
 		return nil, fmt.Errorf("failed to query primary key for %s.%s: %w", schemaName, tableName, err)
 	}
 
-	pk.SchemaName = schemaName
-	pk.TableName = tableName
-	pk.IsPrimaryKey = true
-	pk.IsUnique = true
-	pk.IsClustered = indexType == "CLUSTERED"
+	pk := newPrimaryKey(schemaName, tableName, name, indexType, bucketCount)
 
 	// Get PK columns
 	pk.Columns, err = e.extractIndexColumns(ctx, schemaName, tableName, pk.Name)
@@ -270,29 +781,75 @@ func (e *SchemaExtractor) extractPrimaryKey(ctx context.Context, schemaName, tab
 		return nil, err
 	}
 
-	return &pk, nil
+	return pk, nil
 }
 
-// extractIndexes extracts non-PK indexes for a table
-func (e *SchemaExtractor) extractIndexes(ctx context.Context, schemaName, tableName string) ([]domain.Index, error) {
-	query := `
+// indexesQuery returns the non-PK index query for the tables matching
+// condition, with the bucket count of hash indexes when inMemory
+func indexesQuery(condition string, inMemory bool) string {
+	bucketCount, bucketJoin := bucketCountColumn(inMemory)
+	return fmt.Sprintf(`
 		SELECT
+			t.object_id,
 			i.name AS index_name,
+			i.type_desc,
 			i.is_unique,
 			CASE WHEN i.type = 1 THEN 1 ELSE 0 END AS is_clustered,
 			i.is_disabled,
-			ISNULL(i.filter_definition, '') AS filter_definition
+			ISNULL(i.filter_definition, '') AS filter_definition,
+			ISNULL(ps.name, '') AS partition_scheme,
+			ISNULL(pc.name, '') AS partition_column,
+			ISNULL(fg.name, '') AS filegroup_name,
+			ISNULL(pxi.name, '') AS xml_primary_index,
+			ISNULL(xi.secondary_type_desc, '') AS xml_secondary_type,
+			ISNULL(sit.tessellation_scheme, '') AS tessellation_scheme,
+			CASE WHEN sit.bounding_box_xmin IS NULL THEN ''
+				ELSE CONCAT('(', sit.bounding_box_xmin, ', ', sit.bounding_box_ymin, ', ',
+					sit.bounding_box_xmax, ', ', sit.bounding_box_ymax, ')')
+			END AS bounding_box,
+			i.is_padded,
+			i.ignore_dup_key,
+			i.allow_row_locks,
+			i.allow_page_locks,
+			%s
 		FROM sys.indexes i
 		INNER JOIN sys.tables t ON i.object_id = t.object_id
-		INNER JOIN sys.schemas s ON t.schema_id = s.schema_id
-		WHERE s.name = @p1 AND t.name = @p2
+		INNER JOIN sys.schemas s ON t.schema_id = s.schema_id%s
+		LEFT JOIN sys.xml_indexes xi ON i.object_id = xi.object_id AND i.index_id = xi.index_id
+		LEFT JOIN sys.indexes pxi ON xi.object_id = pxi.object_id AND xi.using_xml_index_id = pxi.index_id
+		LEFT JOIN sys.spatial_index_tessellations sit ON i.object_id = sit.object_id AND i.index_id = sit.index_id
+		LEFT JOIN sys.partition_schemes ps ON i.data_space_id = ps.data_space_id
+		LEFT JOIN sys.filegroups fg ON i.data_space_id = fg.data_space_id
+		LEFT JOIN sys.index_columns pic ON pic.object_id = i.object_id
+			AND pic.index_id = i.index_id AND pic.partition_ordinal = 1
+		LEFT JOIN sys.columns pc ON pic.object_id = pc.object_id AND pic.column_id = pc.column_id
+		WHERE %s
 			AND i.is_primary_key = 0
+			AND i.is_unique_constraint = 0
 			AND i.type > 0
 			AND i.name IS NOT NULL
-		ORDER BY i.name
-	`
+		ORDER BY t.object_id, i.name
+	`, bucketCount, bucketJoin, condition)
+}
+
+// scanIndex scans a row of indexesQuery, returning the table object_id
+func scanIndex(rows *sql.Rows) (int, domain.Index, error) {
+	var objectID int
+	var idx domain.Index
+	var indexType string
+	if err := rows.Scan(&objectID, &idx.Name, &indexType, &idx.IsUnique, &idx.IsClustered, &idx.IsDisabled, &idx.FilterDefinition,
+		&idx.PartitionScheme, &idx.PartitionColumn, &idx.FileGroup,
+		&idx.XMLPrimaryIndex, &idx.XMLSecondaryType, &idx.TessellationScheme, &idx.BoundingBox,
+		&idx.PadIndex, &idx.IgnoreDupKey, &idx.AllowRowLocks, &idx.AllowPageLocks, &idx.BucketCount); err != nil {
+		return 0, idx, fmt.Errorf("failed to scan index: %w", err)
+	}
+	idx.Type = domain.IndexType(indexType)
+	return objectID, idx, nil
+}
 
-	rows, err := e.db.QueryContext(ctx, query, schemaName, tableName)
+// extractIndexes extracts non-PK indexes for a table
+func (e *SchemaExtractor) extractIndexes(ctx context.Context, schemaName, tableName string, inMemory bool) ([]domain.Index, error) {
+	rows, err := e.query(ctx, indexesQuery(tableCondition, inMemory), schemaName, tableName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query indexes for %s.%s: %w", schemaName, tableName, err)
 	}
@@ -300,12 +857,12 @@ func (e *SchemaExtractor) extractIndexes(ctx context.Context, schemaName, tableN
 
 	var indexes []domain.Index
 	for rows.Next() {
-		var idx domain.Index
+		_, idx, err := scanIndex(rows)
+		if err != nil {
+			return nil, err
+		}
 		idx.SchemaName = schemaName
 		idx.TableName = tableName
-		if err := rows.Scan(&idx.Name, &idx.IsUnique, &idx.IsClustered, &idx.IsDisabled, &idx.FilterDefinition); err != nil {
-			return nil, fmt.Errorf("failed to scan index: %w", err)
-		}
 
 		// Get index columns
 		idx.Columns, err = e.extractIndexColumns(ctx, schemaName, tableName, idx.Name)
@@ -319,10 +876,14 @@ func (e *SchemaExtractor) extractIndexes(ctx context.Context, schemaName, tableN
 	return indexes, rows.Err()
 }
 
-// extractIndexColumns extracts columns for an index
-func (e *SchemaExtractor) extractIndexColumns(ctx context.Context, schemaName, tableName, indexName string) ([]domain.IndexColumn, error) {
-	query := `
+// indexColumnsQuery returns the index column query for the indexes matching
+// condition. Included columns all have key_ordinal 0, so they are ordered by
+// index_column_id, the order they were listed in INCLUDE.
+func indexColumnsQuery(condition string) string {
+	return fmt.Sprintf(`
 		SELECT
+			t.object_id,
+			i.name AS index_name,
 			c.name AS column_name,
 			ic.key_ordinal AS position,
 			ic.is_descending_key,
@@ -332,11 +893,26 @@ func (e *SchemaExtractor) extractIndexColumns(ctx context.Context, schemaName, t
 		INNER JOIN sys.columns c ON ic.object_id = c.object_id AND ic.column_id = c.column_id
 		INNER JOIN sys.tables t ON i.object_id = t.object_id
 		INNER JOIN sys.schemas s ON t.schema_id = s.schema_id
-		WHERE s.name = @p1 AND t.name = @p2 AND i.name = @p3
-		ORDER BY ic.is_included_column, ic.key_ordinal
-	`
+		WHERE %s
+		ORDER BY t.object_id, i.name, ic.is_included_column, ic.key_ordinal, ic.index_column_id
+	`, condition)
+}
 
-	rows, err := e.db.QueryContext(ctx, query, schemaName, tableName, indexName)
+// scanIndexColumn scans a row of indexColumnsQuery, returning the table
+// object_id and the index name
+func scanIndexColumn(rows *sql.Rows) (int, string, domain.IndexColumn, error) {
+	var objectID int
+	var indexName string
+	var c domain.IndexColumn
+	if err := rows.Scan(&objectID, &indexName, &c.Name, &c.Position, &c.IsDescending, &c.IsIncluded); err != nil {
+		return 0, "", c, fmt.Errorf("failed to scan index column: %w", err)
+	}
+	return objectID, indexName, c, nil
+}
+
+// extractIndexColumns extracts columns for an index
+func (e *SchemaExtractor) extractIndexColumns(ctx context.Context, schemaName, tableName, indexName string) ([]domain.IndexColumn, error) {
+	rows, err := e.query(ctx, indexColumnsQuery(tableCondition+" AND i.name = @p3"), schemaName, tableName, indexName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query index columns: %w", err)
 	}
@@ -344,9 +920,9 @@ func (e *SchemaExtractor) extractIndexColumns(ctx context.Context, schemaName, t
 
 	var columns []domain.IndexColumn
 	for rows.Next() {
-		var c domain.IndexColumn
-		if err := rows.Scan(&c.Name, &c.Position, &c.IsDescending, &c.IsIncluded); err != nil {
-			return nil, fmt.Errorf("failed to scan index column: %w", err)
+		_, _, c, err := scanIndexColumn(rows)
+		if err != nil {
+			return nil, err
 		}
 		columns = append(columns, c)
 	}
@@ -354,26 +930,51 @@ func (e *SchemaExtractor) extractIndexColumns(ctx context.Context, schemaName, t
 	return columns, rows.Err()
 }
 
-// extractForeignKeys extracts foreign key constraints for a table
-func (e *SchemaExtractor) extractForeignKeys(ctx context.Context, schemaName, tableName string) ([]domain.ForeignKey, error) {
-	query := `
+// foreignKeysQuery returns the foreign key query for the tables matching condition
+func foreignKeysQuery(condition string) string {
+	return fmt.Sprintf(`
 		SELECT
+			t.object_id,
+			fk.object_id,
 			fk.name AS fk_name,
 			SCHEMA_NAME(fk.schema_id) AS schema_name,
 			OBJECT_NAME(fk.parent_object_id) AS table_name,
 			SCHEMA_NAME(rt.schema_id) AS referenced_schema,
 			rt.name AS referenced_table,
 			fk.delete_referential_action_desc,
-			fk.update_referential_action_desc
+			fk.update_referential_action_desc,
+			fk.is_disabled,
+			fk.is_not_trusted
 		FROM sys.foreign_keys fk
 		INNER JOIN sys.tables t ON fk.parent_object_id = t.object_id
 		INNER JOIN sys.schemas s ON t.schema_id = s.schema_id
 		INNER JOIN sys.tables rt ON fk.referenced_object_id = rt.object_id
-		WHERE s.name = @p1 AND t.name = @p2
-		ORDER BY fk.name
-	`
+		WHERE %s
+		ORDER BY t.object_id, fk.name
+	`, condition)
+}
 
-	rows, err := e.db.QueryContext(ctx, query, schemaName, tableName)
+// scanForeignKey scans a row of foreignKeysQuery, returning the table
+// object_id and the foreign key object_id. The metadata functions return
+// NULL for objects dropped while the query runs, leaving those names empty.
+func scanForeignKey(rows *sql.Rows) (int, int, domain.ForeignKey, error) {
+	var tableID, objectID int
+	var fk domain.ForeignKey
+	var schemaName, tableName, referencedSchema sql.NullString
+	if err := rows.Scan(&tableID, &objectID, &fk.Name, &schemaName, &tableName,
+		&referencedSchema, &fk.ReferencedTableName,
+		&fk.DeleteAction, &fk.UpdateAction, &fk.IsDisabled, &fk.IsNotTrusted); err != nil {
+		return 0, 0, fk, fmt.Errorf("failed to scan foreign key: %w", err)
+	}
+	fk.SchemaName = schemaName.String
+	fk.TableName = tableName.String
+	fk.ReferencedSchemaName = referencedSchema.String
+	return tableID, objectID, fk, nil
+}
+
+// extractForeignKeys extracts foreign key constraints for a table
+func (e *SchemaExtractor) extractForeignKeys(ctx context.Context, schemaName, tableName string) ([]domain.ForeignKey, error) {
+	rows, err := e.query(ctx, foreignKeysQuery(tableCondition), schemaName, tableName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query foreign keys for %s.%s: %w", schemaName, tableName, err)
 	}
@@ -381,15 +982,13 @@ func (e *SchemaExtractor) extractForeignKeys(ctx context.Context, schemaName, ta
 
 	var fks []domain.ForeignKey
 	for rows.Next() {
-		var fk domain.ForeignKey
-		if err := rows.Scan(&fk.Name, &fk.SchemaName, &fk.TableName,
-			&fk.ReferencedSchemaName, &fk.ReferencedTableName,
-			&fk.DeleteAction, &fk.UpdateAction); err != nil {
-			return nil, fmt.Errorf("failed to scan foreign key: %w", err)
+		_, objectID, fk, err := scanForeignKey(rows)
+		if err != nil {
+			return nil, err
 		}
 
-		// Get FK columns
-		fk.Columns, err = e.extractForeignKeyColumns(ctx, fk.Name)
+		// Get FK columns; FK names are only unique within a schema, so key on object_id
+		fk.Columns, err = e.extractForeignKeyColumns(ctx, objectID)
 		if err != nil {
 			return nil, err
 		}
@@ -400,19 +999,39 @@ func (e *SchemaExtractor) extractForeignKeys(ctx context.Context, schemaName, ta
 	return fks, rows.Err()
 }
 
-// extractForeignKeyColumns extracts column mappings for a foreign key
-func (e *SchemaExtractor) extractForeignKeyColumns(ctx context.Context, fkName string) ([]domain.ForeignKeyColumn, error) {
-	query := `
+// foreignKeyColumnsQuery returns the column mapping query for the foreign
+// keys matching condition
+func foreignKeyColumnsQuery(condition string) string {
+	return fmt.Sprintf(`
 		SELECT
+			fkc.constraint_object_id,
 			COL_NAME(fkc.parent_object_id, fkc.parent_column_id) AS column_name,
 			COL_NAME(fkc.referenced_object_id, fkc.referenced_column_id) AS referenced_column
 		FROM sys.foreign_key_columns fkc
-		INNER JOIN sys.foreign_keys fk ON fkc.constraint_object_id = fk.object_id
-		WHERE fk.name = @p1
-		ORDER BY fkc.constraint_column_id
-	`
+		INNER JOIN sys.tables t ON fkc.parent_object_id = t.object_id
+		WHERE %s
+		ORDER BY fkc.constraint_object_id, fkc.constraint_column_id
+	`, condition)
+}
+
+// scanForeignKeyColumn scans a row of foreignKeyColumnsQuery, returning the
+// foreign key object_id. COL_NAME returns NULL for columns dropped while the
+// query runs, leaving those names empty.
+func scanForeignKeyColumn(rows *sql.Rows) (int, domain.ForeignKeyColumn, error) {
+	var objectID int
+	var c domain.ForeignKeyColumn
+	var column, referencedColumn sql.NullString
+	if err := rows.Scan(&objectID, &column, &referencedColumn); err != nil {
+		return 0, c, fmt.Errorf("failed to scan FK column: %w", err)
+	}
+	c.ColumnName = column.String
+	c.ReferencedColumnName = referencedColumn.String
+	return objectID, c, nil
+}
 
-	rows, err := e.db.QueryContext(ctx, query, fkName)
+// extractForeignKeyColumns extracts column mappings for a foreign key
+func (e *SchemaExtractor) extractForeignKeyColumns(ctx context.Context, fkObjectID int) ([]domain.ForeignKeyColumn, error) {
+	rows, err := e.query(ctx, foreignKeyColumnsQuery("fkc.constraint_object_id = @p1"), fkObjectID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query FK columns: %w", err)
 	}
@@ -420,9 +1039,9 @@ func (e *SchemaExtractor) extractForeignKeyColumns(ctx context.Context, fkName s
 
 	var columns []domain.ForeignKeyColumn
 	for rows.Next() {
-		var c domain.ForeignKeyColumn
-		if err := rows.Scan(&c.ColumnName, &c.ReferencedColumnName); err != nil {
-			return nil, fmt.Errorf("failed to scan FK column: %w", err)
+		_, c, err := scanForeignKeyColumn(rows)
+		if err != nil {
+			return nil, err
 		}
 		columns = append(columns, c)
 	}
@@ -430,23 +1049,42 @@ func (e *SchemaExtractor) extractForeignKeyColumns(ctx context.Context, fkName s
 	return columns, rows.Err()
 }
 
-// extractCheckConstraints extracts check constraints for a table
-func (e *SchemaExtractor) extractCheckConstraints(ctx context.Context, schemaName, tableName string) ([]domain.CheckConstraint, error) {
-	query := `
+// checkConstraintsQuery returns the check constraint query for the tables matching condition
+func checkConstraintsQuery(condition string) string {
+	return fmt.Sprintf(`
 		SELECT
+			t.object_id,
 			cc.name AS constraint_name,
 			SCHEMA_NAME(t.schema_id) AS schema_name,
 			t.name AS table_name,
 			cc.definition,
-			cc.is_disabled
+			cc.is_disabled,
+			cc.is_not_trusted
 		FROM sys.check_constraints cc
 		INNER JOIN sys.tables t ON cc.parent_object_id = t.object_id
 		INNER JOIN sys.schemas s ON t.schema_id = s.schema_id
-		WHERE s.name = @p1 AND t.name = @p2
-		ORDER BY cc.name
-	`
+		WHERE %s
+		ORDER BY t.object_id, cc.name
+	`, condition)
+}
 
-	rows, err := e.db.QueryContext(ctx, query, schemaName, tableName)
+// scanCheckConstraint scans a row of checkConstraintsQuery, returning the
+// table object_id. The definition is NULL when the login cannot view it.
+func scanCheckConstraint(rows *sql.Rows) (int, domain.CheckConstraint, error) {
+	var objectID int
+	var c domain.CheckConstraint
+	var schemaName, definition sql.NullString
+	if err := rows.Scan(&objectID, &c.Name, &schemaName, &c.TableName, &definition, &c.IsDisabled, &c.IsNotTrusted); err != nil {
+		return 0, c, fmt.Errorf("failed to scan check constraint: %w", err)
+	}
+	c.SchemaName = schemaName.String
+	c.Definition = definition.String
+	return objectID, c, nil
+}
+
+// extractCheckConstraints extracts check constraints for a table
+func (e *SchemaExtractor) extractCheckConstraints(ctx context.Context, schemaName, tableName string) ([]domain.CheckConstraint, error) {
+	rows, err := e.query(ctx, checkConstraintsQuery(tableCondition), schemaName, tableName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query check constraints: %w", err)
 	}
@@ -454,9 +1092,9 @@ func (e *SchemaExtractor) extractCheckConstraints(ctx context.Context, schemaNam
 
 	var constraints []domain.CheckConstraint
 	for rows.Next() {
-		var c domain.CheckConstraint
-		if err := rows.Scan(&c.Name, &c.SchemaName, &c.TableName, &c.Definition, &c.IsDisabled); err != nil {
-			return nil, fmt.Errorf("failed to scan check constraint: %w", err)
+		_, c, err := scanCheckConstraint(rows)
+		if err != nil {
+			return nil, err
 		}
 		constraints = append(constraints, c)
 	}
@@ -464,26 +1102,217 @@ func (e *SchemaExtractor) extractCheckConstraints(ctx context.Context, schemaNam
 	return constraints, rows.Err()
 }
 
-// ExtractViews extracts view definitions
-func (e *SchemaExtractor) ExtractViews(ctx context.Context, schemaFilter []string) ([]domain.View, error) {
-	whereClause := "WHERE v.is_ms_shipped = 0"
-	if len(schemaFilter) > 0 {
-		whereClause += fmt.Sprintf(" AND s.name IN ('%s')", strings.Join(schemaFilter, "','"))
+// uniqueConstraintsQuery returns the UNIQUE constraint query for the tables matching condition
+func uniqueConstraintsQuery(condition string) string {
+	return fmt.Sprintf(`
+		SELECT
+			t.object_id,
+			kc.name AS constraint_name,
+			CASE WHEN i.type = 1 THEN 1 ELSE 0 END AS is_clustered
+		FROM sys.key_constraints kc
+		INNER JOIN sys.indexes i ON kc.parent_object_id = i.object_id AND kc.unique_index_id = i.index_id
+		INNER JOIN sys.tables t ON kc.parent_object_id = t.object_id
+		INNER JOIN sys.schemas s ON t.schema_id = s.schema_id
+		WHERE %s AND kc.type = 'UQ'
+		ORDER BY t.object_id, kc.name
+	`, condition)
+}
+
+// extractUniqueConstraints extracts UNIQUE constraints for a table. Their
+// backing indexes are excluded from extractIndexes.
+func (e *SchemaExtractor) extractUniqueConstraints(ctx context.Context, schemaName, tableName string) ([]domain.UniqueConstraint, error) {
+	rows, err := e.query(ctx, uniqueConstraintsQuery(tableCondition), schemaName, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unique constraints for %s.%s: %w", schemaName, tableName, err)
 	}
+	defer rows.Close()
+
+	var constraints []domain.UniqueConstraint
+	for rows.Next() {
+		var objectID int
+		uc := domain.UniqueConstraint{SchemaName: schemaName, TableName: tableName}
+		if err := rows.Scan(&objectID, &uc.Name, &uc.IsClustered); err != nil {
+			return nil, fmt.Errorf("failed to scan unique constraint: %w", err)
+		}
+		constraints = append(constraints, uc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// The backing index has the same name as the constraint
+	for i := range constraints {
+		constraints[i].Columns, err = e.extractIndexColumns(ctx, schemaName, tableName, constraints[i].Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return constraints, nil
+}
+
+// ExtractPartitionFunctions extracts partition function definitions
+func (e *SchemaExtractor) ExtractPartitionFunctions(ctx context.Context) ([]domain.PartitionFunction, error) {
+	query := `
+		SELECT
+			pf.name AS function_name,
+			TYPE_NAME(pp.user_type_id) AS data_type,
+			pp.max_length,
+			pp.precision,
+			pp.scale,
+			pf.boundary_value_on_right
+		FROM sys.partition_functions pf
+		INNER JOIN sys.partition_parameters pp ON pf.function_id = pp.function_id AND pp.parameter_id = 1
+		ORDER BY pf.name
+	`
+
+	rows, err := e.query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query partition functions: %w", err)
+	}
+	defer rows.Close()
+
+	var funcs []domain.PartitionFunction
+	for rows.Next() {
+		var pf domain.PartitionFunction
+		var dataType sql.NullString
+		if err := rows.Scan(&pf.Name, &dataType, &pf.MaxLength, &pf.Precision, &pf.Scale, &pf.RangeRight); err != nil {
+			return nil, fmt.Errorf("failed to scan partition function: %w", err)
+		}
+		pf.DataType = dataType.String
+		funcs = append(funcs, pf)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Boundary values are rendered as SQL literals by the server
+	boundaryQuery := `
+		SELECT
+			pf.name AS function_name,
+			CASE
+				WHEN prv.value IS NULL THEN 'NULL'
+				WHEN SQL_VARIANT_PROPERTY(prv.value, 'BaseType') IN ('date', 'datetime', 'datetime2', 'smalldatetime')
+					THEN '''' + CONVERT(varchar(40), CAST(prv.value AS datetime2), 126) + ''''
+				WHEN SQL_VARIANT_PROPERTY(prv.value, 'BaseType') = 'datetimeoffset'
+					THEN '''' + CONVERT(varchar(40), CAST(prv.value AS datetimeoffset), 126) + ''''
+				WHEN SQL_VARIANT_PROPERTY(prv.value, 'BaseType') IN ('char', 'varchar', 'nchar', 'nvarchar', 'uniqueidentifier', 'time')
+					THEN 'N''' + REPLACE(CONVERT(nvarchar(4000), prv.value), '''', '''''') + ''''
+				ELSE CONVERT(nvarchar(4000), prv.value)
+			END AS boundary_value
+		FROM sys.partition_range_values prv
+		INNER JOIN sys.partition_functions pf ON prv.function_id = pf.function_id
+		ORDER BY pf.name, prv.boundary_id
+	`
+
+	boundaryRows, err := e.query(ctx, boundaryQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query partition boundaries: %w", err)
+	}
+	defer boundaryRows.Close()
+
+	boundaries := make(map[string][]string)
+	for boundaryRows.Next() {
+		var name, value string
+		if err := boundaryRows.Scan(&name, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan partition boundary: %w", err)
+		}
+		boundaries[name] = append(boundaries[name], value)
+	}
+	if err := boundaryRows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range funcs {
+		funcs[i].Boundaries = boundaries[funcs[i].Name]
+	}
+
+	return funcs, nil
+}
+
+// ExtractPartitionSchemes extracts partition scheme definitions
+func (e *SchemaExtractor) ExtractPartitionSchemes(ctx context.Context) ([]domain.PartitionScheme, error) {
+	query := `
+		SELECT
+			ps.name AS scheme_name,
+			pf.name AS function_name,
+			ds.name AS filegroup_name
+		FROM sys.partition_schemes ps
+		INNER JOIN sys.partition_functions pf ON ps.function_id = pf.function_id
+		INNER JOIN sys.destination_data_spaces dds ON ps.data_space_id = dds.partition_scheme_id
+		INNER JOIN sys.data_spaces ds ON dds.data_space_id = ds.data_space_id
+		ORDER BY ps.name, dds.destination_id
+	`
+
+	rows, err := e.query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query partition schemes: %w", err)
+	}
+	defer rows.Close()
+
+	var schemes []domain.PartitionScheme
+	for rows.Next() {
+		var name, funcName, fileGroup string
+		if err := rows.Scan(&name, &funcName, &fileGroup); err != nil {
+			return nil, fmt.Errorf("failed to scan partition scheme: %w", err)
+		}
+		if len(schemes) == 0 || schemes[len(schemes)-1].Name != name {
+			schemes = append(schemes, domain.PartitionScheme{Name: name, FunctionName: funcName})
+		}
+		last := &schemes[len(schemes)-1]
+		last.FileGroups = append(last.FileGroups, fileGroup)
+	}
+
+	return schemes, rows.Err()
+}
+
+// definitionColumns selects the definition of a module, or only its SHA-256
+// hash when opts.DefinitionHashesOnly is set, to avoid transferring the text.
+// The hash is of the whitespace-normalized definition, as domain.DefinitionHash.
+func definitionColumns(opts *domain.DumpOptions) string {
+	if opts.DefinitionHashesOnly {
+		return fmt.Sprintf("'' AS definition, ISNULL(CONVERT(VARCHAR(64), HASHBYTES('SHA2_256', %s), 2), '') AS definition_hash",
+			normalizedDefinition)
+	}
+	return "ISNULL(m.definition, '') AS definition, '' AS definition_hash"
+}
+
+// normalizedDefinition turns tabs and line breaks of m.definition into spaces,
+// collapses runs of spaces and trims the result. A run is collapsed by
+// replacing each space with NCHAR(1) NCHAR(2) and removing the inner pairs.
+var normalizedDefinition = func() string {
+	expr := "m.definition COLLATE Latin1_General_BIN2"
+	for _, c := range []int{9, 10, 11, 12, 13} {
+		expr = fmt.Sprintf("REPLACE(%s, NCHAR(%d), N' ')", expr, c)
+	}
+	expr = fmt.Sprintf("REPLACE(REPLACE(REPLACE(%s, N' ', NCHAR(1) + NCHAR(2)), NCHAR(2) + NCHAR(1), N''), NCHAR(1) + NCHAR(2), N' ')", expr)
+	return "LTRIM(RTRIM(" + expr + "))"
+}()
+
+// ExtractViews extracts view definitions
+func (e *SchemaExtractor) ExtractViews(ctx context.Context, opts *domain.DumpOptions) ([]domain.View, error) {
+	filter := newQueryFilter()
+	filter.userObjects("v", opts.IncludeSystemObjects)
+	filter.in("s.name", opts.SchemaFilter)
+	filter.notIn("s.name", opts.SchemaExclude)
+	filter.objects("v.object_id", opts.ObjectFilter)
+	filter.modifiedSince("v.modify_date", opts.ModifiedSince)
 
 	query := fmt.Sprintf(`
 		SELECT
 			s.name AS schema_name,
 			v.name AS view_name,
-			ISNULL(m.definition, '') AS definition
+			%s,
+			ISNULL(m.uses_ansi_nulls, 1) AS uses_ansi_nulls,
+			ISNULL(m.uses_quoted_identifier, 1) AS uses_quoted_identifier
 		FROM sys.views v
 		INNER JOIN sys.schemas s ON v.schema_id = s.schema_id
 		LEFT JOIN sys.sql_modules m ON v.object_id = m.object_id
 		%s
 		ORDER BY s.name, v.name
-	`, whereClause)
+	`, definitionColumns(opts), filter.where())
 
-	rows, err := e.db.QueryContext(ctx, query)
+	rows, err := e.query(ctx, query, filter.args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query views: %w", err)
 	}
@@ -492,7 +1321,7 @@ func (e *SchemaExtractor) ExtractViews(ctx context.Context, schemaFilter []strin
 	var views []domain.View
 	for rows.Next() {
 		var v domain.View
-		if err := rows.Scan(&v.SchemaName, &v.Name, &v.Definition); err != nil {
+		if err := rows.Scan(&v.SchemaName, &v.Name, &v.Definition, &v.DefinitionHash, &v.UsesAnsiNulls, &v.UsesQuotedIdentifier); err != nil {
 			return nil, fmt.Errorf("failed to scan view: %w", err)
 		}
 		views = append(views, v)
@@ -501,26 +1330,108 @@ func (e *SchemaExtractor) ExtractViews(ctx context.Context, schemaFilter []strin
 	return views, rows.Err()
 }
 
-// ExtractProcedures extracts stored procedure definitions
-func (e *SchemaExtractor) ExtractProcedures(ctx context.Context, schemaFilter []string) ([]domain.StoredProcedure, error) {
-	whereClause := "WHERE p.is_ms_shipped = 0"
-	if len(schemaFilter) > 0 {
-		whereClause += fmt.Sprintf(" AND s.name IN ('%s')", strings.Join(schemaFilter, "','"))
+// moduleKey identifies a procedure or function by schema and name
+type moduleKey struct {
+	schema string
+	name   string
+}
+
+// extractParameters extracts the parameters of the procedures or functions
+// matching typeCondition, a condition on sys.objects aliased o. The return
+// value of scalar functions (parameter_id 0) is not a parameter.
+func (e *SchemaExtractor) extractParameters(ctx context.Context, opts *domain.DumpOptions, typeCondition string) (map[moduleKey][]domain.Parameter, error) {
+	filter := newQueryFilter(typeCondition, "pm.parameter_id > 0")
+	filter.userObjects("o", opts.IncludeSystemObjects)
+	filter.in("s.name", opts.SchemaFilter)
+	filter.notIn("s.name", opts.SchemaExclude)
+	filter.objects("o.object_id", opts.ObjectFilter)
+	filter.modifiedSince("o.modify_date", opts.ModifiedSince)
+
+	query := fmt.Sprintf(`
+		SELECT
+			s.name AS schema_name,
+			o.name AS object_name,
+			pm.name AS parameter_name,
+			pm.parameter_id,
+			TYPE_NAME(pm.user_type_id) AS data_type,
+			pm.max_length,
+			pm.precision,
+			pm.scale,
+			pm.is_output,
+			pm.is_readonly,
+			pm.has_default_value,
+			ISNULL(CONVERT(nvarchar(4000), pm.default_value), '') AS default_value
+		FROM sys.parameters pm
+		INNER JOIN sys.objects o ON pm.object_id = o.object_id
+		INNER JOIN sys.schemas s ON o.schema_id = s.schema_id
+		%s
+		ORDER BY s.name, o.name, pm.parameter_id
+	`, filter.where())
+
+	rows, err := e.query(ctx, query, filter.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query parameters: %w", err)
+	}
+	defer rows.Close()
+
+	params := make(map[moduleKey][]domain.Parameter)
+	for rows.Next() {
+		var key moduleKey
+		var p domain.Parameter
+		var dataType sql.NullString
+		if err := rows.Scan(&key.schema, &key.name, &p.Name, &p.Position, &dataType, &p.MaxLength, &p.Precision, &p.Scale,
+			&p.IsOutput, &p.IsReadOnly, &p.HasDefault, &p.DefaultValue); err != nil {
+			return nil, fmt.Errorf("failed to scan parameter: %w", err)
+		}
+		p.DataType = dataType.String
+		params[key] = append(params[key], p)
+	}
+
+	return params, rows.Err()
+}
+
+// withDefinitionDefaults fills the defaults sys.parameters leaves empty for
+// T-SQL modules from the parameter list in the module definition
+func withDefinitionDefaults(params []domain.Parameter, definition string) []domain.Parameter {
+	defaults := domain.ParseParameterDefaults(definition)
+	for i := range params {
+		if value, ok := defaults[strings.ToLower(params[i].Name)]; ok && !params[i].HasDefault {
+			params[i].HasDefault = true
+			params[i].DefaultValue = value
+		}
 	}
+	return params
+}
+
+// ExtractProcedures extracts stored procedure definitions and parameters
+func (e *SchemaExtractor) ExtractProcedures(ctx context.Context, opts *domain.DumpOptions) ([]domain.StoredProcedure, error) {
+	params, err := e.extractParameters(ctx, opts, "o.type IN ('P', 'PC')")
+	if err != nil {
+		return nil, err
+	}
+
+	filter := newQueryFilter()
+	filter.userObjects("p", opts.IncludeSystemObjects)
+	filter.in("s.name", opts.SchemaFilter)
+	filter.notIn("s.name", opts.SchemaExclude)
+	filter.objects("p.object_id", opts.ObjectFilter)
+	filter.modifiedSince("p.modify_date", opts.ModifiedSince)
 
 	query := fmt.Sprintf(`
 		SELECT
 			s.name AS schema_name,
 			p.name AS proc_name,
-			ISNULL(m.definition, '') AS definition
+			%s,
+			ISNULL(m.uses_ansi_nulls, 1) AS uses_ansi_nulls,
+			ISNULL(m.uses_quoted_identifier, 1) AS uses_quoted_identifier
 		FROM sys.procedures p
 		INNER JOIN sys.schemas s ON p.schema_id = s.schema_id
 		LEFT JOIN sys.sql_modules m ON p.object_id = m.object_id
 		%s
 		ORDER BY s.name, p.name
-	`, whereClause)
+	`, definitionColumns(opts), filter.where())
 
-	rows, err := e.db.QueryContext(ctx, query)
+	rows, err := e.query(ctx, query, filter.args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query procedures: %w", err)
 	}
@@ -529,41 +1440,51 @@ func (e *SchemaExtractor) ExtractProcedures(ctx context.Context, schemaFilter []
 	var procs []domain.StoredProcedure
 	for rows.Next() {
 		var p domain.StoredProcedure
-		if err := rows.Scan(&p.SchemaName, &p.Name, &p.Definition); err != nil {
+		if err := rows.Scan(&p.SchemaName, &p.Name, &p.Definition, &p.DefinitionHash, &p.UsesAnsiNulls, &p.UsesQuotedIdentifier); err != nil {
 			return nil, fmt.Errorf("failed to scan procedure: %w", err)
 		}
+		p.Parameters = withDefinitionDefaults(params[moduleKey{p.SchemaName, p.Name}], p.Definition)
 		procs = append(procs, p)
 	}
 
 	return procs, rows.Err()
 }
 
-// ExtractFunctions extracts function definitions
-func (e *SchemaExtractor) ExtractFunctions(ctx context.Context, schemaFilter []string) ([]domain.Function, error) {
-	whereClause := "WHERE o.is_ms_shipped = 0"
-	if len(schemaFilter) > 0 {
-		whereClause += fmt.Sprintf(" AND s.name IN ('%s')", strings.Join(schemaFilter, "','"))
+// ExtractFunctions extracts function definitions and parameters
+func (e *SchemaExtractor) ExtractFunctions(ctx context.Context, opts *domain.DumpOptions) ([]domain.Function, error) {
+	params, err := e.extractParameters(ctx, opts, "o.type IN ('FN', 'IF', 'TF')")
+	if err != nil {
+		return nil, err
 	}
 
+	filter := newQueryFilter("o.type IN ('FN', 'IF', 'TF')")
+	filter.userObjects("o", opts.IncludeSystemObjects)
+	filter.in("s.name", opts.SchemaFilter)
+	filter.notIn("s.name", opts.SchemaExclude)
+	filter.objects("o.object_id", opts.ObjectFilter)
+	filter.modifiedSince("o.modify_date", opts.ModifiedSince)
+
 	query := fmt.Sprintf(`
 		SELECT
 			s.name AS schema_name,
 			o.name AS func_name,
-			ISNULL(m.definition, '') AS definition,
+			%s,
 			CASE o.type
 				WHEN 'FN' THEN 'SCALAR'
 				WHEN 'IF' THEN 'INLINE'
 				WHEN 'TF' THEN 'TABLE'
 				ELSE 'UNKNOWN'
-			END AS func_type
+			END AS func_type,
+			ISNULL(m.uses_ansi_nulls, 1) AS uses_ansi_nulls,
+			ISNULL(m.uses_quoted_identifier, 1) AS uses_quoted_identifier
 		FROM sys.objects o
 		INNER JOIN sys.schemas s ON o.schema_id = s.schema_id
 		LEFT JOIN sys.sql_modules m ON o.object_id = m.object_id
-		%s AND o.type IN ('FN', 'IF', 'TF')
+		%s
 		ORDER BY s.name, o.name
-	`, whereClause)
+	`, definitionColumns(opts), filter.where())
 
-	rows, err := e.db.QueryContext(ctx, query)
+	rows, err := e.query(ctx, query, filter.args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query functions: %w", err)
 	}
@@ -572,38 +1493,54 @@ func (e *SchemaExtractor) ExtractFunctions(ctx context.Context, schemaFilter []s
 	var funcs []domain.Function
 	for rows.Next() {
 		var f domain.Function
-		if err := rows.Scan(&f.SchemaName, &f.Name, &f.Definition, &f.FuncType); err != nil {
+		if err := rows.Scan(&f.SchemaName, &f.Name, &f.Definition, &f.DefinitionHash, &f.FuncType, &f.UsesAnsiNulls, &f.UsesQuotedIdentifier); err != nil {
 			return nil, fmt.Errorf("failed to scan function: %w", err)
 		}
+		f.Parameters = withDefinitionDefaults(params[moduleKey{f.SchemaName, f.Name}], f.Definition)
 		funcs = append(funcs, f)
 	}
 
 	return funcs, rows.Err()
 }
 
-// ExtractTriggers extracts trigger definitions
-func (e *SchemaExtractor) ExtractTriggers(ctx context.Context, schemaFilter []string) ([]domain.Trigger, error) {
-	whereClause := "WHERE tr.is_ms_shipped = 0"
-	if len(schemaFilter) > 0 {
-		whereClause += fmt.Sprintf(" AND s.name IN ('%s')", strings.Join(schemaFilter, "','"))
-	}
+// ExtractTriggers extracts the DML triggers of tables and the DDL triggers
+// of the database. Database triggers have no schema; they only match when no
+// schema filter is set.
+func (e *SchemaExtractor) ExtractTriggers(ctx context.Context, opts *domain.DumpOptions) ([]domain.Trigger, error) {
+	filter := newQueryFilter("(tr.parent_class = 0 OR t.object_id IS NOT NULL)")
+	filter.userObjects("tr", opts.IncludeSystemObjects)
+	filter.in("ISNULL(s.name, '')", opts.SchemaFilter)
+	filter.notIn("ISNULL(s.name, '')", opts.SchemaExclude)
+	filter.objects("tr.object_id", opts.ObjectFilter)
+	filter.modifiedSince("tr.modify_date", opts.ModifiedSince)
 
 	query := fmt.Sprintf(`
 		SELECT
-			s.name AS schema_name,
-			t.name AS table_name,
+			ISNULL(s.name, '') AS schema_name,
+			ISNULL(t.name, '') AS table_name,
 			tr.name AS trigger_name,
-			ISNULL(m.definition, '') AS definition,
-			tr.is_disabled
+			tr.parent_class,
+			%s,
+			tr.is_disabled,
+			tr.is_instead_of_trigger,
+			ISNULL(STUFF((
+				SELECT ', ' + te.type_desc
+				FROM sys.trigger_events te
+				WHERE te.object_id = tr.object_id
+				ORDER BY te.type
+				FOR XML PATH('')
+			), 1, 2, ''), '') AS events,
+			ISNULL(m.uses_ansi_nulls, 1) AS uses_ansi_nulls,
+			ISNULL(m.uses_quoted_identifier, 1) AS uses_quoted_identifier
 		FROM sys.triggers tr
-		INNER JOIN sys.tables t ON tr.parent_id = t.object_id
-		INNER JOIN sys.schemas s ON t.schema_id = s.schema_id
+		LEFT JOIN sys.tables t ON tr.parent_class = 1 AND tr.parent_id = t.object_id
+		LEFT JOIN sys.schemas s ON t.schema_id = s.schema_id
 		LEFT JOIN sys.sql_modules m ON tr.object_id = m.object_id
 		%s
-		ORDER BY s.name, t.name, tr.name
-	`, whereClause)
+		ORDER BY tr.parent_class DESC, s.name, t.name, tr.name
+	`, definitionColumns(opts), filter.where())
 
-	rows, err := e.db.QueryContext(ctx, query)
+	rows, err := e.query(ctx, query, filter.args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query triggers: %w", err)
 	}
@@ -612,11 +1549,159 @@ func (e *SchemaExtractor) ExtractTriggers(ctx context.Context, schemaFilter []st
 	var triggers []domain.Trigger
 	for rows.Next() {
 		var tr domain.Trigger
-		if err := rows.Scan(&tr.SchemaName, &tr.TableName, &tr.Name, &tr.Definition, &tr.IsDisabled); err != nil {
+		var parentClass int
+		var events string
+		if err := rows.Scan(&tr.SchemaName, &tr.TableName, &tr.Name, &parentClass, &tr.Definition, &tr.DefinitionHash, &tr.IsDisabled,
+			&tr.IsInsteadOf, &events, &tr.UsesAnsiNulls, &tr.UsesQuotedIdentifier); err != nil {
 			return nil, fmt.Errorf("failed to scan trigger: %w", err)
 		}
+		tr.Scope = domain.TriggerScopeTable
+		if parentClass == 0 {
+			tr.Scope = domain.TriggerScopeDatabase
+		}
+		if events != "" {
+			tr.Events = strings.Split(events, ", ")
+		}
 		triggers = append(triggers, tr)
 	}
 
 	return triggers, rows.Err()
 }
+
+// ExtractPermissions extracts database, schema and object permissions granted
+// or denied to database principals. Permissions on system objects and of fixed
+// roles are skipped.
+func (e *SchemaExtractor) ExtractPermissions(ctx context.Context, opts *domain.DumpOptions) ([]domain.Permission, error) {
+	// Database-level permissions have no schema; they only match when no schema
+	// filter is set. With named objects or a modification cutoff, only
+	// permissions on the matching objects are extracted.
+	filter := newQueryFilter(
+		"p.class IN (0, 1, 3)",
+		"dp.is_fixed_role = 0",
+		"NOT (p.class = 0 AND p.permission_name = 'CONNECT' AND dp.name = 'dbo')",
+	)
+	if !opts.IncludeSystemObjects {
+		filter.conditions = append(filter.conditions, "(p.class <> 1 OR o.is_ms_shipped = 0)")
+	}
+	filter.in("ISNULL(ISNULL(os.name, ss.name), '')", opts.SchemaFilter)
+	filter.notIn("ISNULL(ISNULL(os.name, ss.name), '')", opts.SchemaExclude)
+	filter.objects("CASE WHEN p.class = 1 THEN p.major_id END", opts.ObjectFilter)
+	filter.modifiedSince("o.modify_date", opts.ModifiedSince)
+
+	query := fmt.Sprintf(`
+		SELECT
+			p.state_desc,
+			p.permission_name,
+			p.class_desc,
+			ISNULL(ISNULL(os.name, ss.name), '') AS schema_name,
+			ISNULL(o.name, '') AS object_name,
+			ISNULL(c.name, '') AS column_name,
+			dp.name AS grantee
+		FROM sys.database_permissions p
+		INNER JOIN sys.database_principals dp ON p.grantee_principal_id = dp.principal_id
+		LEFT JOIN sys.objects o ON p.class = 1 AND p.major_id = o.object_id
+		LEFT JOIN sys.schemas os ON o.schema_id = os.schema_id
+		LEFT JOIN sys.schemas ss ON p.class = 3 AND p.major_id = ss.schema_id
+		LEFT JOIN sys.columns c ON p.class = 1 AND p.minor_id <> 0
+			AND p.major_id = c.object_id AND p.minor_id = c.column_id
+		%s
+		ORDER BY dp.name, p.class, schema_name, object_name, column_name, p.permission_name
+	`, filter.where())
+
+	rows, err := e.query(ctx, query, filter.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query permissions: %w", err)
+	}
+	defer rows.Close()
+
+	var permissions []domain.Permission
+	for rows.Next() {
+		var p domain.Permission
+		if err := rows.Scan(&p.State, &p.PermissionName, &p.ClassDesc, &p.SchemaName,
+			&p.ObjectName, &p.ColumnName, &p.Grantee); err != nil {
+			return nil, fmt.Errorf("failed to scan permission: %w", err)
+		}
+		permissions = append(permissions, p)
+	}
+
+	return permissions, rows.Err()
+}
+
+// ExtractStatistics extracts the statistics on table columns that do not
+// back an index. Only user-created statistics are extracted, unless
+// opts.IncludeAutoStatistics adds the ones created by the optimizer.
+func (e *SchemaExtractor) ExtractStatistics(ctx context.Context, opts *domain.DumpOptions) ([]domain.Statistic, error) {
+	// Statistics of indexes are neither user- nor auto-created
+	filter := newQueryFilter("st.user_created = 1")
+	if opts.IncludeAutoStatistics {
+		filter = newQueryFilter("(st.user_created = 1 OR st.auto_created = 1)")
+	}
+	filter.userObjects("t", opts.IncludeSystemObjects)
+	filter.in("s.name", opts.SchemaFilter)
+	filter.in("t.name", opts.TableFilter)
+	filter.notIn("s.name", opts.SchemaExclude)
+	filter.notIn("t.name", opts.TableExclude)
+	filter.objects("t.object_id", opts.ObjectFilter)
+	filter.modifiedSince("t.modify_date", opts.ModifiedSince)
+
+	// Persisted sample rates need SQL Server 2016 SP1 CU4 or later
+	sampleColumn := "CONVERT(float, 0) AS sample_percent"
+	sampleJoin := ""
+	persisted, err := e.hasCatalogColumn(ctx, "sys.stats", "has_persisted_sample")
+	if err != nil {
+		return nil, err
+	}
+	if persisted {
+		sampleColumn = "CASE WHEN st.has_persisted_sample = 1 THEN ISNULL(sp.persisted_sample_percent, 0) ELSE 0 END AS sample_percent"
+		sampleJoin = `
+		OUTER APPLY sys.dm_db_stats_properties(st.object_id, st.stats_id) sp`
+	}
+
+	// One row per statistics column, in key order
+	query := fmt.Sprintf(`
+		SELECT
+			st.object_id,
+			st.stats_id,
+			s.name AS schema_name,
+			t.name AS table_name,
+			st.name AS stats_name,
+			st.no_recompute,
+			st.auto_created,
+			ISNULL(st.filter_definition, '') AS filter_definition,
+			%s,
+			c.name AS column_name
+		FROM sys.stats st
+		INNER JOIN sys.tables t ON st.object_id = t.object_id
+		INNER JOIN sys.schemas s ON t.schema_id = s.schema_id
+		INNER JOIN sys.stats_columns sc ON sc.object_id = st.object_id AND sc.stats_id = st.stats_id
+		INNER JOIN sys.columns c ON c.object_id = sc.object_id AND c.column_id = sc.column_id%s
+		%s
+		ORDER BY s.name, t.name, st.name, sc.stats_column_id
+	`, sampleColumn, sampleJoin, filter.where())
+
+	rows, err := e.query(ctx, query, filter.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statistics: %w", err)
+	}
+	defer rows.Close()
+
+	var statistics []domain.Statistic
+	var lastObjectID, lastStatsID int
+	for rows.Next() {
+		var objectID, statsID int
+		var st domain.Statistic
+		var column string
+		if err := rows.Scan(&objectID, &statsID, &st.SchemaName, &st.TableName, &st.Name, &st.NoRecompute,
+			&st.IsAutoCreated, &st.FilterDefinition, &st.SamplePercent, &column); err != nil {
+			return nil, fmt.Errorf("failed to scan statistics: %w", err)
+		}
+		if len(statistics) == 0 || objectID != lastObjectID || statsID != lastStatsID {
+			statistics = append(statistics, st)
+			lastObjectID, lastStatsID = objectID, statsID
+		}
+		last := &statistics[len(statistics)-1]
+		last.Columns = append(last.Columns, column)
+	}
+
+	return statistics, rows.Err()
+}
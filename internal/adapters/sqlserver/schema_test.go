@@ -0,0 +1,60 @@
+package sqlserver
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+)
+
+func TestExtractForeignKeysSameNameInOtherSchema(t *testing.T) {
+	// sales.Orders and archive.Orders both have a foreign key named FK_Orders_Customers
+	fks := map[string][]driver.Value{
+		"sales":   {int64(1), int64(101), "FK_Orders_Customers", "sales", "Orders", "sales", "Customers", "NO_ACTION", "NO_ACTION", false, false},
+		"archive": {int64(2), int64(202), "FK_Orders_Customers", "archive", "Orders", "archive", "Customers", "CASCADE", "NO_ACTION", false, false},
+	}
+	columns := map[int64][]driver.Value{
+		101: {int64(101), "CustomerId", "Id"},
+		202: {int64(202), "ArchivedCustomerId", "CustomerId"},
+	}
+	e, _ := newFakeExtractor(
+		fakeQuery{
+			match:   "FROM sys.foreign_keys fk",
+			columns: []string{"table_id", "object_id", "fk_name", "schema_name", "table_name", "referenced_schema", "referenced_table", "delete_action", "update_action", "is_disabled", "is_not_trusted"},
+			rows: func(args []driver.NamedValue) [][]driver.Value {
+				return [][]driver.Value{fks[args[0].Value.(string)]}
+			},
+		},
+		fakeQuery{
+			match:   "FROM sys.foreign_key_columns fkc",
+			columns: []string{"constraint_object_id", "column_name", "referenced_column"},
+			rows: func(args []driver.NamedValue) [][]driver.Value {
+				return [][]driver.Value{columns[args[0].Value.(int64)]}
+			},
+		},
+	)
+
+	tests := []struct {
+		schema string
+		want   []domain.ForeignKeyColumn
+	}{
+		{"sales", []domain.ForeignKeyColumn{{ColumnName: "CustomerId", ReferencedColumnName: "Id"}}},
+		{"archive", []domain.ForeignKeyColumn{{ColumnName: "ArchivedCustomerId", ReferencedColumnName: "CustomerId"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			got, err := e.extractForeignKeys(context.Background(), tt.schema, "Orders")
+			if err != nil {
+				t.Fatalf("extractForeignKeys: %v", err)
+			}
+			if len(got) != 1 {
+				t.Fatalf("got %d foreign keys, want 1", len(got))
+			}
+			if got[0].SchemaName != tt.schema || !reflect.DeepEqual(got[0].Columns, tt.want) {
+				t.Errorf("got %s columns %+v, want %s columns %+v", got[0].SchemaName, got[0].Columns, tt.schema, tt.want)
+			}
+		})
+	}
+}
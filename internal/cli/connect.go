@@ -8,7 +8,7 @@ import (
 
 	"github.com/spf13/cobra"
 
-	"github.com/enunezf/SQLPulse/internal/adapters/sqlserver"
+	"github.com/enunezf/SQLPulse/internal/color"
 )
 
 // connectCmd represents the connect command
@@ -44,21 +44,15 @@ func runConnect(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("configuration error: %w", err)
 	}
 
-	fmt.Printf("Connecting to %s...\n", config.SafeString())
-
-	// Create adapter and connect
-	adapter := sqlserver.NewAdapter(config)
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
 	defer cancel()
 
-	if err := adapter.Connect(ctx); err != nil {
-		return fmt.Errorf("connection failed: %w", err)
+	adapter, err := connectDatabase(ctx, config, "")
+	if err != nil {
+		return err
 	}
 	defer adapter.Close()
 
-	fmt.Println("\033[32m✓ Connection successful!\033[0m")
-
 	// Get and display server information
 	info, err := adapter.GetServerInfo(ctx)
 	if err != nil {
@@ -67,12 +61,21 @@ func runConnect(cmd *cobra.Command, args []string) error {
 
 	fmt.Println()
 	fmt.Println(strings.Repeat("─", 60))
-	fmt.Printf("\033[1mServer Name:\033[0m    %s\n", info.ServerName)
-	fmt.Printf("\033[1mEdition:\033[0m        %s\n", info.Edition)
-	fmt.Printf("\033[1mProduct Version:\033[0m %s\n", info.ProductName)
+	fmt.Printf("%s    %s\n", color.Bold("Server Name:"), info.ServerName)
+	fmt.Printf("%s        %s\n", color.Bold("Edition:"), info.Edition)
+	fmt.Printf("%s %s\n", color.Bold("Product Version:"), info.ProductName)
 	fmt.Println(strings.Repeat("─", 60))
 	fmt.Println()
-	fmt.Printf("\033[1mVersion Details:\033[0m\n%s\n", formatVersion(info.Version))
+	fmt.Printf("%s\n%s\n", color.Bold("Version Details:"), formatVersion(info.Version))
+
+	// Effective permissions of the login in the database, e.g. whether it
+	// can read module definitions
+	permissions, err := adapter.GetPermissions(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	fmt.Printf("%s\n  %s\n", color.Bold("Database Permissions:"), strings.Join(permissions, ", "))
 
 	return nil
 }
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/enunezf/SQLPulse/internal/adapters/sqlserver"
+	"github.com/enunezf/SQLPulse/internal/color"
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+)
+
+// connectDatabase connects to the database described by config, reporting
+// progress on stderr. role names the database in messages ("source" or
+// "target") and is empty for commands that work on a single database.
+// The caller closes the returned adapter.
+func connectDatabase(ctx context.Context, config *domain.ConnectionConfig, role string) (*sqlserver.Adapter, error) {
+	if role == "" {
+		infof("Connecting to %s...\n", config.SafeString())
+	} else {
+		infof("Connecting to %s: %s...\n", role, config.SafeString())
+	}
+
+	adapter := newAdapter(config)
+	if err := adapter.Connect(ctx); err != nil {
+		return nil, connectionFailed(withRole(role, "connection failed"), err)
+	}
+
+	if role == "" {
+		infoln(color.Green("✓ Connected"))
+	} else {
+		infoln(color.Green("✓ " + strings.ToUpper(role[:1]) + role[1:] + " connected"))
+	}
+	return adapter, nil
+}
+
+// extractSchema extracts the schema of a connected database with opts
+func extractSchema(ctx context.Context, extractor *sqlserver.SchemaExtractor, opts *domain.DumpOptions, role string) (*domain.DatabaseSchema, error) {
+	infof("Extracting %s...\n", withRole(role, "schema"))
+	schema, err := extractor.ExtractSchema(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract %s: %w", withRole(role, "schema"), err)
+	}
+	return schema, nil
+}
+
+// countObjects counts the objects of a connected database with opts
+func countObjects(ctx context.Context, extractor *sqlserver.SchemaExtractor, opts *domain.DumpOptions, role string) (domain.ObjectCounts, error) {
+	infof("Counting %s...\n", withRole(role, "objects"))
+	counts, err := extractor.CountObjects(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count %s: %w", withRole(role, "objects"), err)
+	}
+	return counts, nil
+}
+
+// connectAndExtract connects to a single database and extracts its schema
+// with opts. The caller closes the returned adapter.
+func connectAndExtract(ctx context.Context, config *domain.ConnectionConfig, opts *domain.DumpOptions) (*sqlserver.Adapter, *domain.DatabaseSchema, error) {
+	adapter, err := connectDatabase(ctx, config, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	schema, err := extractSchema(ctx, newExtractor(adapter.DB()), opts, "")
+	if err != nil {
+		adapter.Close()
+		return nil, nil, err
+	}
+	return adapter, schema, nil
+}
+
+// connectAndExtractRole connects to the database of one side of a comparison,
+// extracts its schema with opts and closes the connection
+func connectAndExtractRole(ctx context.Context, config *domain.ConnectionConfig, opts *domain.DumpOptions, role string) (*domain.DatabaseSchema, error) {
+	adapter, err := connectDatabase(ctx, config, role)
+	if err != nil {
+		return nil, err
+	}
+	defer adapter.Close()
+
+	return extractSchema(ctx, newExtractor(adapter.DB()), opts, role)
+}
+
+// loadSnapshot loads the schema of one side of a comparison from a JSON
+// snapshot written by dump --format json, possibly gzip-compressed
+func loadSnapshot(path, role string) (*domain.DatabaseSchema, error) {
+	infof("Loading %s from %s...\n", withRole(role, "schema"), path)
+	data, err := readInputFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", withRole(role, "snapshot"), err)
+	}
+	schema, err := domain.LoadSchemaFromJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s %s: %w", withRole(role, "snapshot"), path, err)
+	}
+	return schema, nil
+}
+
+// buildTargetConfig builds the target connection of diff and sync from the
+// --target-* flags, inheriting from source whatever they leave unset
+func buildTargetConfig(source *domain.ConnectionConfig) *domain.ConnectionConfig {
+	target := domain.NewConnectionConfig()
+	target.Server = targetServer
+	if target.Server == "" {
+		target.Server = source.Server
+	}
+	target.Database = targetDatabase
+	target.User = targetUser
+	if target.User == "" {
+		target.User = source.User
+	}
+	target.Password = targetPassword
+	if target.Password == "" {
+		target.Password = source.Password
+	}
+	// Without any target auth flag the target authenticates like the source;
+	// a target user switches it to SQL authentication
+	target.TrustedAuth = targetTrusted
+	if !targetTrusted && targetUser == "" && targetPassword == "" {
+		target.TrustedAuth = source.TrustedAuth
+	}
+	target.Port = targetPort
+	if target.Port == 0 {
+		target.Port = source.Port
+	}
+	target.TrustServer = source.TrustServer
+	target.EncryptMode = source.EncryptMode
+	target.ApplicationIntent = source.ApplicationIntent
+	inheritPoolSettings(target, source)
+	return target
+}
+
+// inheritPoolSettings copies the connection retry and pool settings, which
+// are not part of the connection string, from source to target
+func inheritPoolSettings(target, source *domain.ConnectionConfig) {
+	target.ConnectRetries = source.ConnectRetries
+	target.ConnectRetryDelay = source.ConnectRetryDelay
+	target.MaxOpenConns = source.MaxOpenConns
+	target.MaxIdleConns = source.MaxIdleConns
+	target.ConnMaxLifetime = source.ConnMaxLifetime
+}
+
+// connectionFailed wraps a connection error, adding the remediation hint of a
+// ConnectionError when the cause was recognized
+func connectionFailed(prefix string, err error) error {
+	var connErr *sqlserver.ConnectionError
+	if errors.As(err, &connErr) && connErr.Hint != "" {
+		return fmt.Errorf("%s: %w\nHint: %s", prefix, err, connErr.Hint)
+	}
+	return fmt.Errorf("%s: %w", prefix, err)
+}
+
+// withRole prefixes s with the database role, if any
+func withRole(role, s string) string {
+	if role == "" {
+		return s
+	}
+	return role + " " + s
+}
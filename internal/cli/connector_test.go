@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+)
+
+// setTargetFlags sets the target connection flags for one test
+func setTargetFlags(t *testing.T, server, user, password string, trusted bool) {
+	t.Helper()
+	s, d, u, p, tr, port := targetServer, targetDatabase, targetUser, targetPassword, targetTrusted, targetPort
+	t.Cleanup(func() {
+		targetServer, targetDatabase, targetUser, targetPassword, targetTrusted, targetPort = s, d, u, p, tr, port
+	})
+	targetServer, targetDatabase, targetUser, targetPassword, targetTrusted, targetPort = server, "Target", user, password, trusted, 0
+}
+
+func TestBuildTargetConfigAuthentication(t *testing.T) {
+	windowsSource := domain.NewConnectionConfig()
+	windowsSource.Server = "db1"
+	windowsSource.TrustedAuth = true
+
+	sqlSource := domain.NewConnectionConfig()
+	sqlSource.Server = "db1"
+	sqlSource.User = "sa"
+	sqlSource.Password = "secret"
+
+	tests := []struct {
+		name        string
+		source      *domain.ConnectionConfig
+		user        string
+		password    string
+		trusted     bool
+		wantTrusted bool
+		wantUser    string
+	}{
+		{"inherits Windows authentication", windowsSource, "", "", false, true, ""},
+		{"target user switches to SQL authentication", windowsSource, "app", "pw", false, false, "app"},
+		{"target password switches to SQL authentication", windowsSource, "", "pw", false, false, ""},
+		{"inherits SQL login", sqlSource, "", "", false, false, "sa"},
+		{"target-trusted overrides SQL login", sqlSource, "", "", true, true, "sa"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTargetFlags(t, "", tt.user, tt.password, tt.trusted)
+			target := buildTargetConfig(tt.source)
+			if target.TrustedAuth != tt.wantTrusted {
+				t.Errorf("TrustedAuth = %v, want %v", target.TrustedAuth, tt.wantTrusted)
+			}
+			if target.User != tt.wantUser {
+				t.Errorf("User = %q, want %q", target.User, tt.wantUser)
+			}
+			if target.Server != tt.source.Server || target.Database != "Target" {
+				t.Errorf("target is %s/%s, want %s/Target", target.Server, target.Database, tt.source.Server)
+			}
+		})
+	}
+}
+
+func TestBuildTargetConfigInheritsPoolSettings(t *testing.T) {
+	setTargetFlags(t, "", "", "", false)
+	source := domain.NewConnectionConfig()
+	source.Server = "db1"
+	source.ConnectRetries = 7
+	source.ConnectRetryDelay = 3 * time.Second
+	source.MaxOpenConns = 20
+	source.MaxIdleConns = 2
+	source.ConnMaxLifetime = time.Hour
+
+	target := buildTargetConfig(source)
+	if target.ConnectRetries != 7 || target.ConnectRetryDelay != 3*time.Second {
+		t.Errorf("retries = %d every %s, want 7 every 3s", target.ConnectRetries, target.ConnectRetryDelay)
+	}
+	if target.MaxOpenConns != 20 || target.MaxIdleConns != 2 || target.ConnMaxLifetime != time.Hour {
+		t.Errorf("pool = %d open, %d idle, %s lifetime, want 20 open, 2 idle, 1h0m0s lifetime",
+			target.MaxOpenConns, target.MaxIdleConns, target.ConnMaxLifetime)
+	}
+}
@@ -10,26 +10,51 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/enunezf/SQLPulse/internal/adapters/sqlserver"
+	"github.com/enunezf/SQLPulse/internal/color"
 	"github.com/enunezf/SQLPulse/internal/core/domain"
 	"github.com/enunezf/SQLPulse/internal/core/services"
 )
 
 var (
 	// Target connection flags
-	targetServer   string
-	targetDatabase string
-	targetUser     string
-	targetPassword string
-	targetTrusted  bool
-	targetPort     int
+	targetServer           string
+	targetDatabase         string
+	targetUser             string
+	targetPassword         string
+	targetPasswordFile     string
+	targetConnectionString string
+	targetTrusted          bool
+	targetPort             int
 
 	// Diff options
-	outputFormat     string
+	outputFormat      string
 	generateMigration bool
-	migrationFile    string
-	ignoreCollation  bool
+	migrationFile     string
+	ignoreCollation   bool
+	ignoreOwners      bool
+	ignoreIdentity    bool
+	ignoreNullability bool
+	ignoreComputed    bool
+	ignoreDefaults    bool
+	noDrop            bool
+	caseInsensitive   bool
+	exitCode          bool
+	onlyTypes         []string
+	onlyCategories    []string
+	summaryOnly       bool
+	sameConnection    bool
+	countOnly         bool
+	maxDifferences    int
+	definitionOnly    bool
+
+	// JSON snapshots compared in place of live databases
+	baselineSource string
+	baselineTarget string
 )
 
+// exitCodeDifferences is the exit status of diff --exit-code when schemas differ
+const exitCodeDifferences = 2
+
 // diffCmd represents the diff command
 var diffCmd = &cobra.Command{
 	Use:   "diff",
@@ -43,6 +68,20 @@ or as a migration script.
 The source database is specified using the global flags (--server, --database, etc.)
 The target database is specified using --target-* flags.
 
+Either side can instead be a JSON snapshot written by dump --format json,
+given with --baseline-source or --baseline-target. With both, the snapshots
+are compared offline without connecting to any server. Schema and table
+exclusions only apply to live databases.
+
+With --count-only, only the number of objects of each category is compared,
+using one COUNT query per category instead of extracting definitions. It is a
+quick check of how close two databases are before a full comparison.
+
+With --definition-only, only views, procedures, functions and triggers are
+compared, by a SHA-256 hash of their definitions computed on the server, and
+each one is reported as changed or unchanged. It is a lightweight check of
+whether any code changed.
+
 Examples:
   # Compare two databases on the same server
   sqlpulse diff --server localhost --database source_db --user sa --password secret \
@@ -59,7 +98,40 @@ Examples:
 
   # Compare only tables, ignore procedures
   sqlpulse diff --server localhost --database db1 --user sa --password secret \
-      --target-database db2 --no-procedures --no-functions --no-views`,
+      --target-database db2 --no-procedures --no-functions --no-views
+
+  # Review only destructive removals of tables and columns
+  sqlpulse diff --server localhost --database db1 --user sa --password secret \
+      --target-database db2 --only-type removed --only-category table,column
+
+  # Fail a CI pipeline when schemas drift
+  sqlpulse diff --server localhost --database db1 --user sa --password secret \
+      --target-database db2 --exit-code
+
+  # Report drift as JUnit test results in CI
+  sqlpulse diff --server localhost --database db1 --user sa --password secret \
+      --target-database db2 --format junit > schema-diff.xml
+
+  # Export the differences per category to a spreadsheet
+  sqlpulse diff --server localhost --database db1 --user sa --password secret \
+      --target-database db2 --format csv > schema-diff.csv
+
+  # Compare two snapshots offline
+  sqlpulse diff --baseline-source before.json --baseline-target after.json
+
+  # Check that object counts match before a full comparison
+  sqlpulse diff --server localhost --database db1 --user sa --password secret \
+      --target-database db2 --count-only
+
+  # Fail a CI pipeline when any module definition changed
+  sqlpulse diff --server localhost --database db1 --user sa --password secret \
+      --target-database db2 --definition-only --exit-code
+
+Exit status with --exit-code:
+  0  schemas are identical (object counts match with --count-only, no
+     module changed with --definition-only)
+  1  an error occurred
+  2  differences were found`,
 	RunE: runDiff,
 }
 
@@ -68,17 +140,32 @@ func init() {
 
 	// Target database flags
 	diffCmd.Flags().StringVar(&targetServer, "target-server", "", "Target SQL Server (defaults to source server)")
-	diffCmd.Flags().StringVar(&targetDatabase, "target-database", "", "Target database name (required)")
+	diffCmd.Flags().StringVar(&targetDatabase, "target-database", "", "Target database name (required unless --baseline-target or --target-connection-string is set)")
 	diffCmd.Flags().StringVar(&targetUser, "target-user", "", "Target username (defaults to source user)")
 	diffCmd.Flags().StringVar(&targetPassword, "target-password", "", "Target password (defaults to source password)")
+	diffCmd.Flags().StringVar(&targetPasswordFile, "target-password-file", "", "Read the target password from a file")
 	diffCmd.Flags().BoolVar(&targetTrusted, "target-trusted", false, "Use Windows auth for target")
 	diffCmd.Flags().IntVar(&targetPort, "target-port", 0, "Target port (defaults to source port)")
+	diffCmd.Flags().StringVar(&targetConnectionString, "target-connection-string", "", "Full driver connection string for the target, used instead of the --target-* connection flags")
 
 	// Output options
-	diffCmd.Flags().StringVar(&outputFormat, "format", "git", "Output format: git, summary, or full")
+	diffCmd.Flags().StringVar(&outputFormat, "format", "git", "Output format: git, summary, full, markdown, html, junit, csv, or tsv")
 	diffCmd.Flags().BoolVar(&generateMigration, "generate-migration", false, "Generate migration SQL script")
-	diffCmd.Flags().StringVar(&migrationFile, "migration-file", "", "Output file for migration script")
+	diffCmd.Flags().StringVar(&migrationFile, "migration-file", "", "Output file for migration script, gzip-compressed when it ends in .gz, or - for stdout")
+	diffCmd.Flags().BoolVar(&compressOutput, "compress", false, "Gzip-compress the migration file regardless of its extension")
 	diffCmd.Flags().BoolVar(&ignoreCollation, "ignore-collation", false, "Ignore collation differences")
+	diffCmd.Flags().BoolVar(&ignoreOwners, "ignore-owners", false, "Ignore schema owner (AUTHORIZATION) differences")
+	diffCmd.Flags().BoolVar(&ignoreIdentity, "ignore-identity", false, "Ignore column IDENTITY differences")
+	diffCmd.Flags().BoolVar(&ignoreNullability, "ignore-nullability", false, "Ignore column nullability differences")
+	diffCmd.Flags().BoolVar(&ignoreComputed, "ignore-computed", false, "Ignore computed column expression differences")
+	diffCmd.Flags().BoolVar(&ignoreDefaults, "ignore-defaults", false, "Ignore column default value differences")
+	diffCmd.Flags().BoolVar(&guarded, "guarded", false, "Wrap generated DROP statements in IF EXISTS checks")
+	diffCmd.Flags().BoolVar(&noDrop, "no-drop", false, "Report objects that exist only in the target without generating SQL to drop them")
+	diffCmd.Flags().BoolVar(&caseInsensitive, "case-insensitive", false, "Match object names regardless of case (defaults to the source database collation)")
+	diffCmd.Flags().BoolVar(&exitCode, "exit-code", false, "Exit with status 2 when differences are found")
+	diffCmd.Flags().StringSliceVar(&onlyTypes, "only-type", nil, "Show only these difference types: added, removed, modified (comma-separated)")
+	diffCmd.Flags().StringSliceVar(&onlyCategories, "only-category", nil, "Show only these categories, e.g. table,index,foreign-key (comma-separated)")
+	diffCmd.Flags().IntVar(&maxDifferences, "max-differences", 0, "Report at most this many differences and count the rest (0 means no limit)")
 
 	// Reuse filter flags from dump (already defined in dump.go)
 	diffCmd.Flags().BoolVar(&noTables, "no-tables", false, "Exclude tables from comparison")
@@ -89,126 +176,318 @@ func init() {
 	diffCmd.Flags().BoolVar(&noIndexes, "no-indexes", false, "Exclude indexes")
 	diffCmd.Flags().BoolVar(&noForeignKeys, "no-foreign-keys", false, "Exclude foreign keys")
 	diffCmd.Flags().BoolVar(&noConstraints, "no-constraints", false, "Exclude check constraints")
-
-	diffCmd.MarkFlagRequired("target-database")
+	diffCmd.Flags().StringSliceVar(&schemaExclude, "schema-exclude", nil, "Exclude schema names from comparison (comma-separated)")
+	diffCmd.Flags().StringSliceVar(&tableExclude, "table-exclude", nil, "Exclude table names from comparison (comma-separated)")
+	diffCmd.Flags().BoolVar(&includePermissions, "include-permissions", false, "Compare GRANT/DENY permissions")
+	diffCmd.Flags().BoolVar(&includeSystemObjects, "include-system-objects", false, "Compare objects shipped with SQL Server (is_ms_shipped) and built-in schemas")
+	diffCmd.Flags().StringVar(&since, "since", "", "Compare only objects modified in either database within a duration (e.g. 24h, 7d) or after a timestamp")
+	diffCmd.Flags().BoolVar(&sameConnection, "same-connection", false, "Read the target database over the source connection (same server and credentials)")
+	diffCmd.Flags().BoolVar(&summaryOnly, "summary-only", false, "Print only the summary, comparing module definitions by server-computed hash instead of fetching their text")
+	diffCmd.Flags().StringVar(&baselineSource, "baseline-source", "", "Load the source schema from a JSON snapshot instead of connecting")
+	diffCmd.Flags().StringVar(&baselineTarget, "baseline-target", "", "Load the target schema from a JSON snapshot instead of connecting")
+	diffCmd.Flags().BoolVar(&countOnly, "count-only", false, "Compare only the number of objects of each category, without extracting definitions")
+	diffCmd.Flags().BoolVar(&definitionOnly, "definition-only", false, "Report only whether each view, procedure, function and trigger changed, comparing server-computed definition hashes")
 }
 
 func runDiff(cmd *cobra.Command, args []string) error {
-	// Build source config
-	sourceConfig := GetConnectionConfig()
-	if err := sourceConfig.Validate(); err != nil {
-		return fmt.Errorf("source configuration error: %w", err)
+	// Parse difference filters before connecting
+	types, categories, err := parseDiffFilters(onlyTypes, onlyCategories)
+	if err != nil {
+		return err
+	}
+
+	sinceTime, err := parseSince(since, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if compressOutput && isStdout(migrationFile) {
+		return fmt.Errorf("--compress requires --migration-file with a file name")
 	}
 
-	// Build target config (inherit from source where not specified)
-	targetConfig := domain.NewConnectionConfig()
-	targetConfig.Server = targetServer
-	if targetConfig.Server == "" {
-		targetConfig.Server = sourceConfig.Server
+	baselines := baselineSource != "" || baselineTarget != ""
+	if baselines && sameConnection {
+		return fmt.Errorf("--same-connection cannot be combined with --baseline-source or --baseline-target")
 	}
-	targetConfig.Database = targetDatabase
-	targetConfig.User = targetUser
-	if targetConfig.User == "" {
-		targetConfig.User = sourceConfig.User
+	if baselines && !sinceTime.IsZero() {
+		return fmt.Errorf("--since requires live source and target databases")
 	}
-	targetConfig.Password = targetPassword
-	if targetConfig.Password == "" {
-		targetConfig.Password = sourceConfig.Password
+	if targetConnectionString != "" {
+		for _, name := range []string{"target-server", "target-database", "target-user", "target-password",
+			"target-password-file", "target-trusted", "target-port", "baseline-target", "same-connection"} {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--target-connection-string cannot be combined with --%s", name)
+			}
+		}
+	} else if baselineTarget == "" && targetDatabase == "" {
+		return fmt.Errorf("--target-database is required unless --baseline-target or --target-connection-string is set")
 	}
-	targetConfig.TrustedAuth = targetTrusted
-	if !targetTrusted && !sourceConfig.TrustedAuth && targetUser == "" {
-		targetConfig.TrustedAuth = sourceConfig.TrustedAuth
+	if maxDifferences < 0 {
+		return fmt.Errorf("--max-differences must not be negative")
 	}
-	targetConfig.Port = targetPort
-	if targetConfig.Port == 0 {
-		targetConfig.Port = sourceConfig.Port
+	// A migration script built from part of the differences would be incomplete
+	if maxDifferences > 0 && generateMigration {
+		return fmt.Errorf("--max-differences cannot be combined with --generate-migration")
+	}
+	if countOnly && (generateMigration || summaryOnly || !sinceTime.IsZero() || len(types) > 0 || len(categories) > 0) {
+		return fmt.Errorf("--count-only cannot be combined with --generate-migration, --summary-only, --since, --only-type or --only-category")
+	}
+	if definitionOnly && (countOnly || generateMigration || summaryOnly || maxDifferences > 0 || len(types) > 0 || len(categories) > 0 || cmd.Flags().Changed("format")) {
+		return fmt.Errorf("--definition-only cannot be combined with --count-only, --generate-migration, --summary-only, --max-differences, --only-type, --only-category or --format")
 	}
-	targetConfig.TrustServer = sourceConfig.TrustServer
 
-	if err := targetConfig.Validate(); err != nil {
-		return fmt.Errorf("target configuration error: %w", err)
+	// --definition-only and --summary-only skip fetching module text, unless
+	// --format full asks for the definitions to be shown. Snapshots hold the
+	// text, so a live side compared with one fetches it too.
+	hashesOnly := (definitionOnly || (summaryOnly && outputFormat != "full")) && !baselines
+	if summaryOnly && !cmd.Flags().Changed("format") {
+		outputFormat = "summary"
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
-	defer cancel()
+	// Build source config
+	sourceConfig := GetConnectionConfig()
+	if baselineSource == "" {
+		if err := sourceConfig.Validate(); err != nil {
+			return fmt.Errorf("source configuration error: %w", err)
+		}
+	}
+
+	// Build target config (inherit from source where not specified). A target
+	// connection string is used as is; only retry and pool settings are inherited.
+	targetConfig := buildTargetConfig(sourceConfig)
+	if targetConnectionString != "" {
+		if targetConfig, err = sqlserver.ParseConnectionString(targetConnectionString); err != nil {
+			return fmt.Errorf("target configuration error: %w", err)
+		}
+		inheritPoolSettings(targetConfig, sourceConfig)
+	}
 
-	// Connect to source
-	fmt.Fprintf(os.Stderr, "Connecting to source: %s...\n", sourceConfig.SafeString())
-	sourceAdapter := sqlserver.NewAdapter(sourceConfig)
-	if err := sourceAdapter.Connect(ctx); err != nil {
-		return fmt.Errorf("source connection failed: %w", err)
+	if baselineTarget == "" {
+		if err := targetConfig.Validate(); err != nil {
+			return fmt.Errorf("target configuration error: %w", err)
+		}
 	}
-	defer sourceAdapter.Close()
-	fmt.Fprintln(os.Stderr, "\033[32m✓ Source connected\033[0m")
 
-	// Connect to target
-	fmt.Fprintf(os.Stderr, "Connecting to target: %s...\n", targetConfig.SafeString())
-	targetAdapter := sqlserver.NewAdapter(targetConfig)
-	if err := targetAdapter.Connect(ctx); err != nil {
-		return fmt.Errorf("target connection failed: %w", err)
+	// A shared connection can only reach another database of the same server
+	// with the same login
+	if sameConnection && (targetConfig.Server != sourceConfig.Server || targetConfig.Port != sourceConfig.Port ||
+		targetConfig.User != sourceConfig.User || targetConfig.TrustedAuth != sourceConfig.TrustedAuth) {
+		return fmt.Errorf("--same-connection requires the target database on the same server with the same login")
 	}
-	defer targetAdapter.Close()
-	fmt.Fprintln(os.Stderr, "\033[32m✓ Target connected\033[0m")
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Minute)
+	defer cancel()
 
 	// Build extraction options
 	opts := &domain.DumpOptions{
-		IncludeTables:      !noTables,
-		IncludeViews:       !noViews,
-		IncludeProcedures:  !noProcedures,
-		IncludeFunctions:   !noFunctions,
-		IncludeTriggers:    !noTriggers,
-		IncludeIndexes:     !noIndexes,
-		IncludeForeignKeys: !noForeignKeys,
-		IncludeConstraints: !noConstraints,
-		SchemaFilter:       schemaFilter,
-		TableFilter:        tableFilter,
-	}
-
-	// Extract source schema
-	fmt.Fprintln(os.Stderr, "Extracting source schema...")
-	sourceExtractor := sqlserver.NewSchemaExtractor(sourceAdapter.DB())
-	sourceSchema, err := sourceExtractor.ExtractSchema(ctx, opts)
-	if err != nil {
-		return fmt.Errorf("failed to extract source schema: %w", err)
+		IncludeTables:        !noTables,
+		IncludeViews:         !noViews,
+		IncludeProcedures:    !noProcedures,
+		IncludeFunctions:     !noFunctions,
+		IncludeTriggers:      !noTriggers,
+		IncludeIndexes:       !noIndexes,
+		IncludeForeignKeys:   !noForeignKeys,
+		IncludeConstraints:   !noConstraints,
+		IncludePermissions:   includePermissions,
+		IncludeSystemObjects: includeSystemObjects,
+		SchemaFilter:         schemaFilter,
+		TableFilter:          tableFilter,
+		SchemaExclude:        schemaExclude,
+		TableExclude:         tableExclude,
+		DefinitionHashesOnly: hashesOnly,
 	}
 
-	// Extract target schema
-	fmt.Fprintln(os.Stderr, "Extracting target schema...")
-	targetExtractor := sqlserver.NewSchemaExtractor(targetAdapter.DB())
-	targetSchema, err := targetExtractor.ExtractSchema(ctx, opts)
-	if err != nil {
-		return fmt.Errorf("failed to extract target schema: %w", err)
+	if countOnly {
+		return runCountOnly(ctx, cmd, sourceConfig, targetConfig, opts)
+	}
+
+	// Only modules are compared with --definition-only
+	if definitionOnly {
+		opts.IncludeTables = false
+		opts.IncludePermissions = false
+	}
+
+	var sourceSchema, targetSchema *domain.DatabaseSchema
+	if baselineSource != "" {
+		if sourceSchema, err = loadSnapshot(baselineSource, "source"); err != nil {
+			return err
+		}
+	}
+	if baselineTarget != "" {
+		if targetSchema, err = loadSnapshot(baselineTarget, "target"); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case sourceSchema != nil && targetSchema != nil:
+		// Both sides are snapshots: compare offline
+
+	case sourceSchema != nil:
+		if targetSchema, err = connectAndExtractRole(ctx, targetConfig, opts, "target"); err != nil {
+			return err
+		}
+
+	case targetSchema != nil:
+		if sourceSchema, err = connectAndExtractRole(ctx, sourceConfig, opts, "source"); err != nil {
+			return err
+		}
+
+	default:
+		// Connect to both databases before extracting either
+		sourceAdapter, err := connectDatabase(ctx, sourceConfig, "source")
+		if err != nil {
+			return err
+		}
+		defer sourceAdapter.Close()
+
+		targetDB := sourceAdapter.DB()
+		if sameConnection {
+			infof("Using the source connection for target database %s\n", targetDatabase)
+		} else {
+			targetAdapter, err := connectDatabase(ctx, targetConfig, "target")
+			if err != nil {
+				return err
+			}
+			defer targetAdapter.Close()
+			targetDB = targetAdapter.DB()
+		}
+
+		sourceExtractor := newExtractor(sourceAdapter.DB())
+		targetExtractor := newExtractor(targetDB)
+		if sameConnection {
+			targetExtractor.SetDatabase(targetDatabase)
+		}
+
+		// With --since, both databases extract the objects modified in either of
+		// them, so an object changed on one side only is compared rather than
+		// reported as missing from the other
+		if !sinceTime.IsZero() {
+			names, err := modifiedInEither(ctx, sourceExtractor, targetExtractor, sinceTime)
+			if err != nil {
+				return err
+			}
+			if len(names) == 0 {
+				fmt.Println(color.Green("✓ No objects modified since " + sinceTime.Format(time.RFC3339)))
+				return nil
+			}
+			infoln(color.Yellow(fmt.Sprintf("⚠ Partial comparison of %d object(s) modified since %s",
+				len(names), sinceTime.Format(time.RFC3339))))
+			opts.ObjectFilter = names
+			opts.IgnoreMissingObjects = true
+		}
+
+		if sourceSchema, err = extractSchema(ctx, sourceExtractor, opts, "source"); err != nil {
+			return err
+		}
+		if targetSchema, err = extractSchema(ctx, targetExtractor, opts, "target"); err != nil {
+			return err
+		}
 	}
 
 	// Build diff options
 	diffOpts := &domain.DiffOptions{
-		IncludeTables:      !noTables,
-		IncludeViews:       !noViews,
-		IncludeProcedures:  !noProcedures,
-		IncludeFunctions:   !noFunctions,
-		IncludeTriggers:    !noTriggers,
-		IncludeIndexes:     !noIndexes,
-		IncludeForeignKeys: !noForeignKeys,
-		IncludeConstraints: !noConstraints,
-		IgnoreCollation:    ignoreCollation,
-		IgnoreWhitespace:   true,
+		IncludeSchemas:       true,
+		IncludeTables:        !noTables,
+		IncludeViews:         !noViews,
+		IncludeProcedures:    !noProcedures,
+		IncludeFunctions:     !noFunctions,
+		IncludeTriggers:      !noTriggers,
+		IncludeIndexes:       !noIndexes,
+		IncludeForeignKeys:   !noForeignKeys,
+		IncludeConstraints:   !noConstraints,
+		IgnoreCollation:      ignoreCollation,
+		IgnoreWhitespace:     true,
+		CaseInsensitiveNames: caseInsensitive,
+		IgnoreOwners:         ignoreOwners,
+		IgnoreIdentity:       ignoreIdentity,
+		IgnoreNullability:    ignoreNullability,
+		IgnoreComputed:       ignoreComputed,
+		IgnoreDefaults:       ignoreDefaults,
+		NoDrop:               noDrop,
+		GuardedDrops:         guarded,
+		IncludePermissions:   includePermissions,
+		MaxDifferences:       maxDifferences,
+		TargetVersion:        targetSchema.ProductVersion,
+	}
+
+	// Without an explicit --case-insensitive, follow the source collation
+	if !cmd.Flags().Changed("case-insensitive") {
+		diffOpts.CaseInsensitiveNames = domain.IsCaseInsensitiveCollation(sourceSchema.Collation)
 	}
 
 	// Compare schemas
-	fmt.Fprintln(os.Stderr, "Comparing schemas...")
+	infoln("Comparing schemas...")
 	comparator := services.NewSchemaComparator(diffOpts)
+
+	if definitionOnly {
+		modules := comparator.CompareDefinitions(sourceSchema, targetSchema)
+		infoln()
+		changed := printModuleChanges(modules)
+		return diffExitError(cmd, changed > 0)
+	}
+	filtered := len(types) > 0 || len(categories) > 0
+
+	// The git format can be printed as differences are found, without
+	// collecting them, unless a migration script is needed
+	if outputFormat == "git" && !generateMigration {
+		infoln()
+		gw := domain.NewGitStyleWriter(os.Stdout, sourceSchema.DatabaseName, targetSchema.DatabaseName)
+		count := 0
+		omitted := comparator.CompareStream(sourceSchema, targetSchema, func(d domain.Difference) {
+			if d.Matches(types, categories) {
+				count++
+				gw.Write(d)
+			}
+		})
+		if count == 0 && omitted == 0 {
+			printNoDifferences(filtered)
+			return nil
+		}
+		if omitted > 0 {
+			fmt.Println("\n" + domain.TruncationNote(omitted))
+		}
+		fmt.Println()
+		return diffExitError(cmd, true)
+	}
+
 	result := comparator.Compare(sourceSchema, targetSchema)
 
+	// Apply --only-type / --only-category filters
+	if filtered {
+		result.Differences = result.Filter(types, categories)
+		result.CalculateSummary()
+	}
+
 	// Output results
-	fmt.Fprintln(os.Stderr)
+	infoln()
 
-	if !result.HasDifferences() {
-		fmt.Println("\033[32m✓ Schemas are identical\033[0m")
+	// Reports meant for other tools are written even without differences
+	reportFormat := outputFormat == "markdown" || outputFormat == "html" || outputFormat == "junit" ||
+		outputFormat == "csv" || outputFormat == "tsv"
+	if !result.HasDifferences() && !result.Truncated && !reportFormat {
+		printNoDifferences(filtered)
 		return nil
 	}
 
 	// Print based on format
 	switch outputFormat {
+	case "markdown":
+		fmt.Print(result.ToMarkdown())
+	case "html":
+		fmt.Print(result.ToHTML())
+	case "junit":
+		fmt.Print(result.ToJUnit())
+	case "csv", "tsv":
+		comma := ','
+		if outputFormat == "tsv" {
+			comma = '\t'
+		}
+		fmt.Print(result.Summary.ToCSV(comma))
+		// The counts cannot carry the note, so it goes to stderr
+		if result.Truncated {
+			infoln(color.Yellow(domain.TruncationNote(result.Omitted)))
+		}
 	case "git":
 		fmt.Println(result.PrintGitStyle())
 	case "summary":
@@ -222,37 +501,232 @@ func runDiff(cmd *cobra.Command, args []string) error {
 	}
 
 	// Generate migration script if requested
-	if generateMigration {
+	if generateMigration && result.HasDifferences() {
 		migration := result.GenerateMigrationScript()
-		if migrationFile != "" {
-			if err := os.WriteFile(migrationFile, []byte(migration), 0644); err != nil {
+		if n := countDestructive(result); n > 0 {
+			infoln(color.Yellow(fmt.Sprintf("⚠ %d migration statement(s) may cause data loss; review the WARNING comments before applying", n)))
+		}
+		if !isStdout(migrationFile) {
+			if err := writeOutputFile(migrationFile, []byte(migration), compressOutput); err != nil {
 				return fmt.Errorf("failed to write migration file: %w", err)
 			}
-			fmt.Fprintf(os.Stderr, "\n\033[32m✓ Migration script written to %s\033[0m\n", migrationFile)
+			infof("\n%s\n", color.Green("✓ Migration script written to "+migrationFile))
 		} else {
 			fmt.Println("\n" + migration)
 		}
 	}
 
+	return diffExitError(cmd, result.HasDifferences() || result.Truncated)
+}
+
+// runCountOnly compares the number of objects of each category in the
+// source and target databases or snapshots
+func runCountOnly(ctx context.Context, cmd *cobra.Command, sourceConfig, targetConfig *domain.ConnectionConfig, opts *domain.DumpOptions) error {
+	var sourceCounts, targetCounts domain.ObjectCounts
+	var sourceAdapter *sqlserver.Adapter
+	sourceName, targetName := sourceConfig.Database, targetConfig.Database
+
+	if baselineSource != "" {
+		schema, err := loadSnapshot(baselineSource, "source")
+		if err != nil {
+			return err
+		}
+		sourceName, sourceCounts = schema.DatabaseName, schema.CountObjects(opts)
+	} else {
+		var err error
+		if sourceAdapter, err = connectDatabase(ctx, sourceConfig, "source"); err != nil {
+			return err
+		}
+		defer sourceAdapter.Close()
+		if sourceCounts, err = countObjects(ctx, newExtractor(sourceAdapter.DB()), opts, "source"); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case baselineTarget != "":
+		schema, err := loadSnapshot(baselineTarget, "target")
+		if err != nil {
+			return err
+		}
+		targetName, targetCounts = schema.DatabaseName, schema.CountObjects(opts)
+
+	case sameConnection:
+		infof("Using the source connection for target database %s\n", targetDatabase)
+		extractor := newExtractor(sourceAdapter.DB())
+		extractor.SetDatabase(targetDatabase)
+		var err error
+		if targetCounts, err = countObjects(ctx, extractor, opts, "target"); err != nil {
+			return err
+		}
+
+	default:
+		targetAdapter, err := connectDatabase(ctx, targetConfig, "target")
+		if err != nil {
+			return err
+		}
+		defer targetAdapter.Close()
+		if targetCounts, err = countObjects(ctx, newExtractor(targetAdapter.DB()), opts, "target"); err != nil {
+			return err
+		}
+	}
+
+	infoln()
+	printObjectCounts(sourceName, targetName, sourceCounts, targetCounts)
+	return diffExitError(cmd, !sourceCounts.Equal(targetCounts))
+}
+
+// printObjectCounts prints the source and target object counts side by
+// side, highlighting the categories whose counts differ
+func printObjectCounts(sourceName, targetName string, source, target domain.ObjectCounts) {
+	fmt.Println(strings.Repeat("─", 50))
+	fmt.Println(color.Bold(fmt.Sprintf("Object Counts: %s → %s", sourceName, targetName)))
+	fmt.Println(strings.Repeat("─", 50))
+
+	fmt.Printf("    %-15s %7s %7s %7s\n", "", "Source", "Target", "Diff")
+	mismatched := 0
+	for _, cat := range source.Categories() {
+		line := fmt.Sprintf("    %-15s %7d %7d", cat+":", source[cat], target[cat])
+		if diff := target[cat] - source[cat]; diff != 0 {
+			mismatched++
+			line += " " + color.Yellow(fmt.Sprintf("%+7d", diff))
+		}
+		fmt.Println(line)
+	}
+	fmt.Println(strings.Repeat("─", 50))
+
+	if mismatched == 0 {
+		fmt.Println(color.Green("✓ Object counts match"))
+	} else {
+		fmt.Println(color.Yellow(fmt.Sprintf("⚠ Object counts differ in %d of %d categories", mismatched, len(source.Categories()))))
+	}
+}
+
+// printModuleChanges prints whether each module changed, followed by how
+// many did, and returns that number
+func printModuleChanges(modules []domain.ModuleChange) int {
+	changed := 0
+	for _, m := range modules {
+		category := strings.ToLower(string(m.Category))
+		if m.Changed {
+			changed++
+			fmt.Printf("%s %-10s %s\n", color.Yellow("changed  "), category, m.Name)
+		} else {
+			fmt.Printf("%s %-10s %s\n", color.Green("unchanged"), category, m.Name)
+		}
+	}
+
+	if len(modules) > 0 {
+		fmt.Println()
+	}
+	if changed == 0 {
+		fmt.Println(color.Green(fmt.Sprintf("✓ No module changed (%d compared)", len(modules))))
+	} else {
+		fmt.Println(color.Yellow(fmt.Sprintf("⚠ %d of %d modules changed", changed, len(modules))))
+	}
+	return changed
+}
+
+// countDestructive counts the migration statements classified as destructive
+func countDestructive(result *domain.DiffResult) int {
+	n := 0
+	for _, d := range result.Differences {
+		if d.MigrationSQL != "" && d.Risk.IsDestructive() {
+			n++
+		}
+	}
+	return n
+}
+
+// modifiedInEither returns the names of the objects modified after since in
+// the source or the target database
+func modifiedInEither(ctx context.Context, source, target *sqlserver.SchemaExtractor, since time.Time) ([]string, error) {
+	sourceNames, err := source.ModifiedObjects(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list modified source objects: %w", err)
+	}
+	targetNames, err := target.ModifiedObjects(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list modified target objects: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, name := range append(sourceNames, targetNames...) {
+		if key := strings.ToLower(name); !seen[key] {
+			seen[key] = true
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// printNoDifferences reports that the comparison found nothing to show
+func printNoDifferences(filtered bool) {
+	if filtered {
+		fmt.Println(color.Green("✓ No differences match the filters"))
+	} else {
+		fmt.Println(color.Green("✓ Schemas are identical"))
+	}
+}
+
+// diffExitError returns the --exit-code error when differences were found
+func diffExitError(cmd *cobra.Command, hasDifferences bool) error {
+	if exitCode && hasDifferences {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return &ExitError{Code: exitCodeDifferences}
+	}
 	return nil
 }
 
+// parseDiffFilters parses the --only-type and --only-category flag values
+func parseDiffFilters(typeNames, categoryNames []string) ([]domain.DiffType, []domain.DiffCategory, error) {
+	var types []domain.DiffType
+	for _, name := range typeNames {
+		t, err := domain.ParseDiffType(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		types = append(types, t)
+	}
+
+	var categories []domain.DiffCategory
+	for _, name := range categoryNames {
+		c, err := domain.ParseDiffCategory(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		categories = append(categories, c)
+	}
+
+	return types, categories, nil
+}
+
 func printDiffSummary(result *domain.DiffResult) {
 	fmt.Println(strings.Repeat("─", 50))
-	fmt.Printf("\033[1mDiff Summary: %s → %s\033[0m\n", result.SourceDatabase, result.TargetDatabase)
+	fmt.Println(color.Bold(fmt.Sprintf("Diff Summary: %s → %s", result.SourceDatabase, result.TargetDatabase)))
 	fmt.Println(strings.Repeat("─", 50))
 
 	fmt.Printf("  Total differences: %d\n", result.Summary.TotalDifferences)
-	fmt.Printf("  \033[32m+ Added:   %d\033[0m (in target only)\n", result.Summary.Added)
-	fmt.Printf("  \033[31m- Removed: %d\033[0m (in source only)\n", result.Summary.Removed)
-	fmt.Printf("  \033[33m~ Modified: %d\033[0m\n", result.Summary.Modified)
+	fmt.Printf("  %s (in target only)\n", color.Green(fmt.Sprintf("+ Added:   %d", result.Summary.Added)))
+	fmt.Printf("  %s (in source only)\n", color.Red(fmt.Sprintf("- Removed: %d", result.Summary.Removed)))
+	fmt.Printf("  %s\n", color.Yellow(fmt.Sprintf("~ Modified: %d", result.Summary.Modified)))
 
 	if len(result.Summary.ByCategory) > 0 {
 		fmt.Println()
 		fmt.Println("  By category:")
-		for cat, count := range result.Summary.ByCategory {
-			fmt.Printf("    %-15s %d\n", cat+":", count)
+		fmt.Printf("    %-15s %7s %7s %8s %6s\n", "", "Added", "Removed", "Modified", "Total")
+		for _, cat := range result.Summary.Categories() {
+			types := result.Summary.ByCategoryType[cat]
+			fmt.Printf("    %-15s %7d %7d %8d %6d\n", cat+":",
+				types[domain.DiffAdded], types[domain.DiffRemoved], types[domain.DiffModified],
+				result.Summary.ByCategory[cat])
 		}
 	}
+	if result.Summary.Truncated {
+		fmt.Println()
+		fmt.Println("  " + color.Yellow(domain.TruncationNote(result.Summary.Omitted)))
+	}
 	fmt.Println(strings.Repeat("─", 50))
 }
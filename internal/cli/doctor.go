@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/enunezf/SQLPulse/internal/adapters/sqlserver"
+	"github.com/enunezf/SQLPulse/internal/color"
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+)
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose connectivity and server compatibility",
+	Long: `Diagnose the connection to a SQL Server instance and its compatibility
+with SQLPulse.
+
+The checks run in order: the server port is reached over TCP, the driver
+negotiates TLS and logs in, and the server is queried for its version, the
+encryption of the session and the features SQLPulse relies on. When a step
+fails, everything determined up to that point is still reported, along with
+a hint for common causes.
+
+Session encryption is read from sys.dm_exec_connections and is only shown
+when the login has VIEW SERVER STATE.
+
+Examples:
+  # Diagnose a connection
+  sqlpulse doctor --server myserver --database mydb --user sa --password secret
+
+  # Check a connection with strict (TDS 8.0) encryption
+  sqlpulse doctor --server myserver --database mydb --trusted --encrypt strict`,
+	RunE: runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	config := GetConnectionConfig()
+
+	// Validate configuration
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+	defer cancel()
+
+	infof("Diagnosing %s...\n", config.SafeString())
+	d := sqlserver.Diagnose(ctx, config)
+	printDiagnostics(d)
+
+	// The report already explains the failure, so only set the exit status
+	if !d.Connected || d.ProductVersion == "" {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return &ExitError{Code: 1}
+	}
+	return nil
+}
+
+// printDiagnostics prints the doctor report
+func printDiagnostics(d *domain.Diagnostics) {
+	encryption := string(d.EncryptMode)
+	if d.TrustServer {
+		encryption += ", server certificate trusted"
+	}
+
+	fmt.Println()
+	fmt.Println(color.Bold("Connection:"))
+	printInfoField("  Server:", d.Address)
+	printInfoField("  Encrypt:", encryption)
+
+	switch {
+	case d.Reachable:
+		printInfoField("  TCP:", color.Green("✓ reachable"))
+	case d.ReachError != "":
+		printInfoField("  TCP:", color.Red("✗ "+d.ReachError))
+	default:
+		printInfoField("  TCP:", "not checked for named instances")
+	}
+
+	if d.TLSVersion != "" {
+		tlsStatus := d.TLSVersion
+		if d.EncryptMode == domain.EncryptDisabled {
+			tlsStatus += " (login packet only)"
+		}
+		printInfoField("  TLS:", color.Green("✓ "+tlsStatus))
+	} else if d.Reachable {
+		printInfoField("  TLS:", color.Yellow("not negotiated"))
+	}
+
+	if d.Connected {
+		printInfoField("  Login:", color.Green("✓ connected"))
+	} else if d.ConnectError != "" {
+		printInfoField("  Login:", color.Red("✗ "+d.ConnectError))
+	}
+
+	if d.ConnectHint != "" {
+		fmt.Println()
+		fmt.Printf("%s %s\n", color.Bold("Hint:"), d.ConnectHint)
+	}
+
+	if d.ProductVersion == "" {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println(color.Bold("Server:"))
+	printInfoField("  Version:", fmt.Sprintf("%s (%s)", d.ProductVersion, d.Edition))
+	switch strings.ToUpper(d.Encrypted) {
+	case "TRUE":
+		printInfoField("  Session:", color.Green("✓ encrypted"))
+	case "FALSE":
+		printInfoField("  Session:", color.Yellow("not encrypted"))
+	default:
+		printInfoField("  Session:", "encryption unknown (requires VIEW SERVER STATE)")
+	}
+	if d.AuthScheme != "" {
+		printInfoField("  Auth scheme:", d.AuthScheme)
+	}
+
+	fmt.Println()
+	fmt.Println(color.Bold("Features:"))
+	missing := 0
+	for _, f := range d.Features {
+		switch {
+		case f.Error != "":
+			fmt.Printf("  %s %s: could not check: %s\n", color.Yellow("?"), f.Name, f.Error)
+		case f.Available:
+			fmt.Printf("  %s %s\n", color.Green("✓"), f.Name)
+		default:
+			missing++
+			fmt.Printf("  %s %s: %s\n", color.Yellow("!"), f.Name, f.Impact)
+		}
+	}
+
+	if missing > 0 {
+		fmt.Println()
+		fmt.Println(color.Yellow(fmt.Sprintf("⚠ %d feature(s) unavailable: this server is older than SQLPulse supports. Upgrade to SQL Server 2016 SP1 or later for full support.", missing)))
+	}
+}
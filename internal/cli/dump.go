@@ -3,29 +3,48 @@ package cli
 import (
 	"context"
 	"fmt"
-	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
-	"github.com/enunezf/SQLPulse/internal/adapters/sqlserver"
+	"github.com/enunezf/SQLPulse/internal/color"
 	"github.com/enunezf/SQLPulse/internal/core/domain"
 )
 
 var (
 	// Dump command flags
-	outputFile   string
-	schemaFilter []string
-	tableFilter      []string
-	noTables         bool
-	noViews          bool
-	noProcedures     bool
-	noFunctions      bool
-	noTriggers       bool
-	noIndexes        bool
-	noForeignKeys    bool
-	noConstraints    bool
+	outputFile            string
+	schemaFilter          []string
+	tableFilter           []string
+	schemaExclude         []string
+	tableExclude          []string
+	noTables              bool
+	noViews               bool
+	noProcedures          bool
+	noFunctions           bool
+	noTriggers            bool
+	noIndexes             bool
+	noForeignKeys         bool
+	noConstraints         bool
+	noSchemaDDL           bool
+	noFileGroups          bool
+	includePermissions    bool
+	includeStatistics     bool
+	includeAutoStatistics bool
+	includeSystemObjects  bool
+	inlineConstraints     bool
+	orderByDependency     bool
+	dumpDialect           string
+	objectFilter          []string
+	dataFor               []string
+	since                 string
+	compressOutput        bool
+	dumpFormat            string
+	strictDump            bool
+	formatSQL             bool
+	guarded               bool
 )
 
 // dumpCmd represents the dump command
@@ -38,6 +57,9 @@ This command generates SQL scripts that can recreate the database schema,
 including tables, views, stored procedures, functions, triggers, indexes,
 and constraints.
 
+Schema and table filters accept exact names and glob patterns (* and ?).
+Matching follows the server collation, so it is case-insensitive by default.
+
 Examples:
   # Dump entire database schema
   sqlpulse dump --server localhost --database mydb --user sa --password secret
@@ -51,17 +73,46 @@ Examples:
   # Dump to file
   sqlpulse dump --server localhost --database mydb --user sa --password secret --output schema.sql
 
+  # Dump tables matching glob patterns (* any characters, ? one character)
+  sqlpulse dump --server localhost --database mydb --user sa --password secret --table "tmp_*,*_archive"
+
+  # Dump everything except the audit and staging schemas
+  sqlpulse dump --server localhost --database mydb --user sa --password secret --schema-exclude audit,staging
+
   # Dump specific tables
-  sqlpulse dump --server localhost --database mydb --user sa --password secret --table Users,Orders`,
+  sqlpulse dump --server localhost --database mydb --user sa --password secret --table Users,Orders
+
+  # Dump a single procedure and view (object types are resolved from the catalog)
+  sqlpulse dump --server localhost --database mydb --user sa --password secret --object dbo.MyProc --object sales.vOrders
+
+  # Include the rows of lookup tables as INSERT statements
+  sqlpulse dump --server localhost --database mydb --user sa --password secret --data-for dbo.Countries,dbo.Currencies
+
+  # Generate PostgreSQL DDL for the tables (module bodies are not translated)
+  sqlpulse dump --server localhost --database mydb --user sa --password secret --dialect postgres
+
+  # Generate a script that can be re-run against a database that already has some of the objects
+  sqlpulse dump --server localhost --database mydb --user sa --password secret --guarded
+
+  # Uppercase keywords and normalize indentation of module definitions
+  sqlpulse dump --server localhost --database mydb --user sa --password secret --format-sql
+
+  # Save a JSON snapshot of the schema for later comparison
+  sqlpulse dump --server localhost --database mydb --user sa --password secret --format json --output schema.json`,
 	RunE: runDump,
 }
 
 func init() {
 	rootCmd.AddCommand(dumpCmd)
 
-	dumpCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file (default: stdout)")
-	dumpCmd.Flags().StringSliceVar(&schemaFilter, "schema", nil, "Filter by schema names (comma-separated)")
-	dumpCmd.Flags().StringSliceVar(&tableFilter, "table", nil, "Filter by table names (comma-separated)")
+	dumpCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file, gzip-compressed when it ends in .gz, or - for stdout (default: stdout)")
+	dumpCmd.Flags().BoolVar(&compressOutput, "compress", false, "Gzip-compress the output file regardless of its extension")
+	dumpCmd.Flags().StringSliceVar(&schemaFilter, "schema", nil, "Filter by schema names or glob patterns such as tmp_* (comma-separated)")
+	dumpCmd.Flags().StringSliceVar(&tableFilter, "table", nil, "Filter by table names or glob patterns such as *_archive (comma-separated)")
+	dumpCmd.Flags().StringSliceVar(&schemaExclude, "schema-exclude", nil, "Exclude schema names (comma-separated, overrides --schema)")
+	dumpCmd.Flags().StringSliceVar(&tableExclude, "table-exclude", nil, "Exclude table names (comma-separated, overrides --table)")
+	dumpCmd.Flags().StringSliceVar(&objectFilter, "object", nil, "Dump only the named objects of any type, e.g. dbo.MyProc (repeatable)")
+	dumpCmd.Flags().StringVar(&since, "since", "", "Dump only objects modified within a duration (e.g. 24h, 7d) or after a timestamp (e.g. 2024-05-01)")
 	dumpCmd.Flags().BoolVar(&noTables, "no-tables", false, "Exclude tables")
 	dumpCmd.Flags().BoolVar(&noViews, "no-views", false, "Exclude views")
 	dumpCmd.Flags().BoolVar(&noProcedures, "no-procedures", false, "Exclude stored procedures")
@@ -70,6 +121,20 @@ func init() {
 	dumpCmd.Flags().BoolVar(&noIndexes, "no-indexes", false, "Exclude indexes (non-PK)")
 	dumpCmd.Flags().BoolVar(&noForeignKeys, "no-foreign-keys", false, "Exclude foreign keys")
 	dumpCmd.Flags().BoolVar(&noConstraints, "no-constraints", false, "Exclude check constraints")
+	dumpCmd.Flags().BoolVar(&noSchemaDDL, "no-schema-ddl", false, "Omit CREATE SCHEMA statements when deploying into existing schemas")
+	dumpCmd.Flags().BoolVar(&noFileGroups, "no-filegroups", false, "Omit ON [filegroup] placement for cross-server portability")
+	dumpCmd.Flags().BoolVar(&includePermissions, "include-permissions", false, "Include GRANT/DENY permissions")
+	dumpCmd.Flags().BoolVar(&includeStatistics, "include-statistics", false, "Include CREATE STATISTICS for user-created statistics")
+	dumpCmd.Flags().BoolVar(&includeAutoStatistics, "include-auto-statistics", false, "With --include-statistics, also include statistics created automatically by the optimizer")
+	dumpCmd.Flags().BoolVar(&includeSystemObjects, "include-system-objects", false, "Include objects shipped with SQL Server (is_ms_shipped) and built-in schemas")
+	dumpCmd.Flags().BoolVar(&inlineConstraints, "inline-constraints", false, "Declare named default and unique constraints inside CREATE TABLE")
+	dumpCmd.Flags().BoolVar(&orderByDependency, "order-by-dependency", false, "Create referenced tables before the tables whose foreign keys reference them")
+	dumpCmd.Flags().StringSliceVar(&dataFor, "data-for", nil, "Append INSERT statements with the rows of these tables, e.g. dbo.Countries (comma-separated)")
+	dumpCmd.Flags().StringVar(&dumpDialect, "dialect", "tsql", "Target SQL dialect for generated DDL (tsql, postgres)")
+	dumpCmd.Flags().StringVar(&dumpFormat, "format", "sql", "Output format: sql (DDL script) or json (schema snapshot)")
+	dumpCmd.Flags().BoolVar(&strictDump, "strict", false, "Fail when module definitions cannot be read instead of emitting a comment")
+	dumpCmd.Flags().BoolVar(&guarded, "guarded", false, "Wrap object creation in IF NOT EXISTS checks so the script can be re-run")
+	dumpCmd.Flags().BoolVar(&formatSQL, "format-sql", false, "Uppercase keywords and normalize indentation of view, procedure, function and trigger definitions")
 }
 
 func runDump(cmd *cobra.Command, args []string) error {
@@ -80,55 +145,150 @@ func runDump(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("configuration error: %w", err)
 	}
 
-	fmt.Fprintf(os.Stderr, "Connecting to %s...\n", config.SafeString())
+	dialect, err := domain.ParseDialect(dumpDialect)
+	if err != nil {
+		return err
+	}
+
+	sinceTime, err := parseSince(since, time.Now())
+	if err != nil {
+		return err
+	}
 
-	// Create adapter and connect
-	adapter := sqlserver.NewAdapter(config)
+	if compressOutput && isStdout(outputFile) {
+		return fmt.Errorf("--compress requires --output with a file name")
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
+	if includeAutoStatistics && !includeStatistics {
+		return fmt.Errorf("--include-auto-statistics requires --include-statistics")
+	}
 
-	if err := adapter.Connect(ctx); err != nil {
-		return fmt.Errorf("connection failed: %w", err)
+	if guarded && dialect != domain.TSQL {
+		return fmt.Errorf("--guarded applies only to the tsql dialect")
+	}
+
+	switch dumpFormat {
+	case "sql":
+	case "json":
+		if dialect != domain.TSQL {
+			return fmt.Errorf("--dialect applies only to --format sql")
+		}
+		if formatSQL {
+			return fmt.Errorf("--format-sql applies only to --format sql")
+		}
+		if guarded {
+			return fmt.Errorf("--guarded applies only to --format sql")
+		}
+		if noSchemaDDL {
+			return fmt.Errorf("--no-schema-ddl applies only to --format sql")
+		}
+		if orderByDependency {
+			return fmt.Errorf("--order-by-dependency applies only to --format sql")
+		}
+	default:
+		return fmt.Errorf("invalid --format %q (expected sql or json)", dumpFormat)
 	}
-	defer adapter.Close()
 
-	fmt.Fprintln(os.Stderr, "\033[32m✓ Connected\033[0m")
+	ctx, cancel := context.WithTimeout(cmd.Context(), 5*time.Minute)
+	defer cancel()
 
 	// Build dump options
 	opts := &domain.DumpOptions{
-		IncludeTables:      !noTables,
-		IncludeViews:       !noViews,
-		IncludeProcedures:  !noProcedures,
-		IncludeFunctions:   !noFunctions,
-		IncludeTriggers:    !noTriggers,
-		IncludeIndexes:     !noIndexes,
-		IncludeForeignKeys: !noForeignKeys,
-		IncludeConstraints: !noConstraints,
-		SchemaFilter:       schemaFilter,
-		TableFilter:        tableFilter,
-		OutputFormat:       "sql",
+		IncludeTables:         !noTables,
+		IncludeViews:          !noViews,
+		IncludeProcedures:     !noProcedures,
+		IncludeFunctions:      !noFunctions,
+		IncludeTriggers:       !noTriggers,
+		IncludeIndexes:        !noIndexes,
+		IncludeForeignKeys:    !noForeignKeys,
+		IncludeConstraints:    !noConstraints,
+		IncludeSchemaDDL:      !noSchemaDDL,
+		IncludeFileGroups:     !noFileGroups,
+		IncludePermissions:    includePermissions,
+		IncludeStatistics:     includeStatistics,
+		IncludeAutoStatistics: includeAutoStatistics,
+		IncludeSystemObjects:  includeSystemObjects,
+		InlineConstraints:     inlineConstraints,
+		OrderByDependency:     orderByDependency,
+		Dialect:               dialect.Name(),
+		SchemaFilter:          schemaFilter,
+		TableFilter:           tableFilter,
+		SchemaExclude:         schemaExclude,
+		TableExclude:          tableExclude,
+		ObjectFilter:          objectFilter,
+		ModifiedSince:         sinceTime,
+		DataFor:               dataFor,
+		OutputFormat:          dumpFormat,
+		FormatSQL:             formatSQL,
+		Guarded:               guarded,
 	}
 
-	// Create schema extractor
-	extractor := sqlserver.NewSchemaExtractor(adapter.DB())
+	adapter, err := connectDatabase(ctx, config, "")
+	if err != nil {
+		return err
+	}
+	defer adapter.Close()
 
-	fmt.Fprintln(os.Stderr, "Extracting schema...")
+	// Without VIEW DEFINITION, module definitions come back empty
+	permissions, err := adapter.GetPermissions(ctx)
+	if err != nil {
+		return err
+	}
+	if !hasPermission(permissions, "VIEW DEFINITION") {
+		infoln(color.Yellow("⚠ The login lacks VIEW DEFINITION on the database; module definitions may not be readable"))
+	}
 
-	schema, err := extractor.ExtractSchema(ctx, opts)
+	schema, err := extractSchema(ctx, newExtractor(adapter.DB()), opts, "")
 	if err != nil {
-		return fmt.Errorf("extraction failed: %w", err)
+		return err
+	}
+
+	if unreadable := schema.UnreadableModules(); len(unreadable) > 0 {
+		if strictDump {
+			return fmt.Errorf("%d module definition(s) could not be read (encrypted or missing VIEW DEFINITION):\n  %s",
+				len(unreadable), strings.Join(unreadable, "\n  "))
+		}
+		infoln(color.Yellow(fmt.Sprintf("⚠ %d module definition(s) could not be read and are emitted as comments (use --strict to fail instead)",
+			len(unreadable))))
+	}
+
+	// Table data, in the order the tables were given
+	extractor := newExtractor(adapter.DB())
+	for _, name := range opts.DataFor {
+		table := findTable(schema.Tables, name)
+		if table == nil {
+			return fmt.Errorf("--data-for table %s is not part of the dump", name)
+		}
+		infof("Extracting data of %s...\n", name)
+		data, err := extractor.ExtractTableData(ctx, *table)
+		if err != nil {
+			return fmt.Errorf("data extraction failed: %w", err)
+		}
+		schema.TableData = append(schema.TableData, *data)
 	}
 
 	// Generate output
-	output := generateDDL(schema, opts)
+	var output, what string
+	if opts.OutputFormat == "json" {
+		var sb strings.Builder
+		if err := schema.WriteJSON(&sb); err != nil {
+			return fmt.Errorf("failed to encode schema: %w", err)
+		}
+		output, what = strings.TrimSuffix(sb.String(), "\n"), "Schema snapshot"
+	} else {
+		// Omit syntax the server version does not support
+		if dialect == domain.TSQL {
+			dialect = domain.TSQLVersion(schema.ProductVersion)
+		}
+		output, what = generateDDL(schema, opts, dialect), "DDL"
+	}
 
 	// Write output
-	if outputFile != "" {
-		if err := os.WriteFile(outputFile, []byte(output), 0644); err != nil {
+	if !isStdout(outputFile) {
+		if err := writeOutputFile(outputFile, []byte(output), compressOutput); err != nil {
 			return fmt.Errorf("failed to write output file: %w", err)
 		}
-		fmt.Fprintf(os.Stderr, "\033[32m✓ DDL written to %s\033[0m\n", outputFile)
+		infoln(color.Green("✓ " + what + " written to " + outputFile))
 	} else {
 		fmt.Println(output)
 	}
@@ -139,24 +299,123 @@ func runDump(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func generateDDL(schema *domain.DatabaseSchema, opts *domain.DumpOptions) string {
+func generateDDL(schema *domain.DatabaseSchema, opts *domain.DumpOptions, d domain.Dialect) string {
 	var sb strings.Builder
 
+	// Statement terminator, followed by the batch separator when the dialect has one
+	end := ";\n"
+	if bt := d.BatchTerminator(); bt != "" {
+		end += bt + "\n"
+	}
+	// Partitioning, database settings, module bodies, statistics and permissions are T-SQL only
+	tsql := domain.IsTSQL(d)
+
+	// Tables skipped for having no columns are not created, so neither are
+	// their indexes, keys, constraints, statistics and triggers
+	created, skipped := tablesWithColumns(schema.Tables)
+
+	// Object creation, guarded by an existence check with --guarded
+	guard := func(exists, sql string) string {
+		if opts.Guarded {
+			return domain.GuardCreate(exists, sql)
+		}
+		return sql
+	}
+
+	// Module definitions, tidied with --format-sql
+	definition := func(sql string) string {
+		if opts.FormatSQL {
+			return domain.FormatModuleSQL(sql)
+		}
+		return sql
+	}
+
 	// Header
 	sb.WriteString("-- ============================================\n")
 	sb.WriteString(fmt.Sprintf("-- SQLPulse DDL Export\n"))
 	sb.WriteString(fmt.Sprintf("-- Database: %s\n", schema.DatabaseName))
 	sb.WriteString(fmt.Sprintf("-- Generated: %s\n", time.Now().Format(time.RFC3339)))
+	if !tsql {
+		sb.WriteString(fmt.Sprintf("-- Dialect: %s\n", d.Name()))
+	}
+	if !opts.ModifiedSince.IsZero() {
+		sb.WriteString(fmt.Sprintf("-- Partial snapshot: only objects modified since %s\n", opts.ModifiedSince.Format(time.RFC3339)))
+	}
 	sb.WriteString("-- ============================================\n\n")
 
+	// Database settings that differ from the defaults, so a replay does not
+	// change what it need not: a new collation fails once objects depend on
+	// the old one. Scoped configurations need SQL Server 2016.
+	var configurations []domain.ScopedConfiguration
+	if d.Supports(domain.FeatureScopedConfigurations) {
+		for _, sc := range schema.ScopedConfigurations {
+			if !sc.IsDefault() {
+				configurations = append(configurations, sc)
+			}
+		}
+	}
+	collation := schema.Collation
+	if strings.EqualFold(collation, schema.ServerCollation) {
+		collation = ""
+	}
+	if tsql && (collation != "" || len(configurations) > 0) {
+		sb.WriteString("-- ============================================\n")
+		sb.WriteString("-- DATABASE SETTINGS\n")
+		sb.WriteString("-- ============================================\n\n")
+		switch {
+		case collation != "" && schema.ServerCollation == "":
+			// Without the server default, the collation may be the default
+			sb.WriteString(fmt.Sprintf("-- Database collation: %s\n\n", collation))
+		case collation != "":
+			sb.WriteString(fmt.Sprintf("ALTER DATABASE CURRENT COLLATE %s;\nGO\n\n", collation))
+		}
+		for _, sc := range configurations {
+			sb.WriteString(sc.GenerateSQL())
+			sb.WriteString(";\nGO\n")
+		}
+		if len(configurations) > 0 {
+			sb.WriteString("\n")
+		}
+	}
+
 	// Schemas
-	if len(schema.Schemas) > 0 {
+	if opts.IncludeSchemaDDL && len(schema.Schemas) > 0 {
 		sb.WriteString("-- ============================================\n")
 		sb.WriteString("-- SCHEMAS\n")
 		sb.WriteString("-- ============================================\n\n")
 		for _, s := range schema.Schemas {
-			sb.WriteString(s.GenerateSQL())
-			sb.WriteString(";\nGO\n\n")
+			if s.Owner == "" {
+				sb.WriteString(fmt.Sprintf("-- Schema [%s]: owner principal no longer exists, created without AUTHORIZATION\n", s.Name))
+			}
+			if opts.Guarded {
+				sb.WriteString(s.GenerateGuardedSQL())
+			} else {
+				sb.WriteString(s.GenerateSQLFor(d))
+			}
+			sb.WriteString(end + "\n")
+		}
+	}
+
+	// Partition functions and schemes
+	if tsql && opts.IncludeTables && len(schema.PartitionFunctions) > 0 {
+		sb.WriteString("-- ============================================\n")
+		sb.WriteString("-- PARTITION FUNCTIONS\n")
+		sb.WriteString("-- ============================================\n\n")
+		for _, pf := range schema.PartitionFunctions {
+			sb.WriteString(fmt.Sprintf("-- Partition Function: [%s]\n", pf.Name))
+			sb.WriteString(guard(pf.ExistsSQL(), pf.GenerateSQL()))
+			sb.WriteString(end + "\n")
+		}
+	}
+
+	if tsql && opts.IncludeTables && len(schema.PartitionSchemes) > 0 {
+		sb.WriteString("-- ============================================\n")
+		sb.WriteString("-- PARTITION SCHEMES\n")
+		sb.WriteString("-- ============================================\n\n")
+		for _, ps := range schema.PartitionSchemes {
+			sb.WriteString(fmt.Sprintf("-- Partition Scheme: [%s]\n", ps.Name))
+			sb.WriteString(guard(ps.ExistsSQL(), ps.GenerateSQL()))
+			sb.WriteString(end + "\n")
 		}
 	}
 
@@ -165,18 +424,41 @@ func generateDDL(schema *domain.DatabaseSchema, opts *domain.DumpOptions) string
 		sb.WriteString("-- ============================================\n")
 		sb.WriteString("-- TABLES\n")
 		sb.WriteString("-- ============================================\n\n")
-		for _, t := range schema.Tables {
+		tables := schema.Tables
+		if opts.OrderByDependency {
+			var cyclic []string
+			tables, cyclic = domain.SortTablesByDependency(tables, domain.CollationNameKey(schema.Collation))
+			if len(cyclic) > 0 {
+				sb.WriteString(fmt.Sprintf("-- In or depending on a foreign key cycle, in alphabetical order at the end: %s\n\n", strings.Join(cyclic, ", ")))
+			}
+		}
+		for _, t := range historyTablesFirst(tables) {
+			if !opts.IncludeFileGroups {
+				t.FileGroup = ""
+			}
 			sb.WriteString(fmt.Sprintf("-- Table: [%s].[%s]\n", t.SchemaName, t.Name))
-			sb.WriteString(t.GenerateSQL())
-			sb.WriteString(";\nGO\n\n")
+			if len(t.Columns) == 0 {
+				sb.WriteString(domain.SkippedNoColumns + "\n\n")
+				continue
+			}
+			if opts.InlineConstraints {
+				if !opts.IncludeConstraints {
+					t.UniqueConstraints = nil
+				}
+			}
+			if !opts.IncludeIndexes {
+				t.Indexes = nil
+			}
+			sb.WriteString(guard(t.ExistsSQL(), t.GenerateSQLFor(d, opts.InlineConstraints)))
+			sb.WriteString(end + "\n")
 		}
 	}
 
 	// Indexes (non-PK)
 	if opts.IncludeIndexes {
 		var hasIndexes bool
-		for _, t := range schema.Tables {
-			if len(t.Indexes) > 0 {
+		for _, t := range created {
+			if len(t.Indexes) > 0 && !(opts.IncludeTables && t.IndexesInline(d)) {
 				hasIndexes = true
 				break
 			}
@@ -185,23 +467,48 @@ func generateDDL(schema *domain.DatabaseSchema, opts *domain.DumpOptions) string
 			sb.WriteString("-- ============================================\n")
 			sb.WriteString("-- INDEXES\n")
 			sb.WriteString("-- ============================================\n\n")
-			for _, t := range schema.Tables {
+			for _, t := range created {
+				// Declared in the CREATE TABLE of a memory-optimized table
+				if opts.IncludeTables && t.IndexesInline(d) {
+					continue
+				}
 				for _, idx := range t.Indexes {
-					sql := idx.GenerateSQL()
+					if !opts.IncludeFileGroups {
+						idx.FileGroup = ""
+					}
+					sql := idx.GenerateSQLFor(d, t.BitColumns()...)
 					if sql != "" {
 						sb.WriteString(fmt.Sprintf("-- Index: [%s] on [%s].[%s]\n", idx.Name, t.SchemaName, t.Name))
-						sb.WriteString(sql)
-						sb.WriteString(";\nGO\n\n")
+						sb.WriteString(guard(idx.ExistsSQL(), sql))
+						sb.WriteString(end + "\n")
 					}
 				}
 			}
 		}
 	}
 
+	// Statistics not backing an index
+	var statistics []domain.Statistic
+	for _, st := range schema.Statistics {
+		if !skipped[st.SchemaName+"."+st.TableName] {
+			statistics = append(statistics, st)
+		}
+	}
+	if tsql && opts.IncludeStatistics && len(statistics) > 0 {
+		sb.WriteString("-- ============================================\n")
+		sb.WriteString("-- STATISTICS\n")
+		sb.WriteString("-- ============================================\n\n")
+		for _, st := range statistics {
+			sb.WriteString(fmt.Sprintf("-- Statistics: [%s] on [%s].[%s]\n", st.Name, st.SchemaName, st.TableName))
+			sb.WriteString(guard(st.ExistsSQL(), st.GenerateSQLFor(d)))
+			sb.WriteString(end + "\n")
+		}
+	}
+
 	// Foreign Keys
 	if opts.IncludeForeignKeys {
 		var hasFKs bool
-		for _, t := range schema.Tables {
+		for _, t := range created {
 			if len(t.ForeignKeys) > 0 {
 				hasFKs = true
 				break
@@ -211,11 +518,38 @@ func generateDDL(schema *domain.DatabaseSchema, opts *domain.DumpOptions) string
 			sb.WriteString("-- ============================================\n")
 			sb.WriteString("-- FOREIGN KEYS\n")
 			sb.WriteString("-- ============================================\n\n")
-			for _, t := range schema.Tables {
+			for _, t := range created {
 				for _, fk := range t.ForeignKeys {
+					if skipped[fk.ReferencedSchemaName+"."+fk.ReferencedTableName] {
+						sb.WriteString(fmt.Sprintf("-- FK: [%s] skipped, [%s].[%s] has no columns\n\n", fk.Name, fk.ReferencedSchemaName, fk.ReferencedTableName))
+						continue
+					}
 					sb.WriteString(fmt.Sprintf("-- FK: [%s]\n", fk.Name))
-					sb.WriteString(fk.GenerateSQL())
-					sb.WriteString(";\nGO\n\n")
+					sb.WriteString(guard(fk.ExistsSQL(), fk.GenerateSQLFor(d)))
+					sb.WriteString(end + "\n")
+				}
+			}
+		}
+	}
+
+	// Unique Constraints (declared in CREATE TABLE with --inline-constraints)
+	if opts.IncludeConstraints && !opts.InlineConstraints {
+		var hasUnique bool
+		for _, t := range created {
+			if len(t.UniqueConstraints) > 0 {
+				hasUnique = true
+				break
+			}
+		}
+		if hasUnique {
+			sb.WriteString("-- ============================================\n")
+			sb.WriteString("-- UNIQUE CONSTRAINTS\n")
+			sb.WriteString("-- ============================================\n\n")
+			for _, t := range created {
+				for _, uc := range t.UniqueConstraints {
+					sb.WriteString(fmt.Sprintf("-- Unique: [%s]\n", uc.Name))
+					sb.WriteString(guard(uc.ExistsSQL(), uc.GenerateSQLFor(d)))
+					sb.WriteString(end + "\n")
 				}
 			}
 		}
@@ -224,7 +558,7 @@ func generateDDL(schema *domain.DatabaseSchema, opts *domain.DumpOptions) string
 	// Check Constraints
 	if opts.IncludeConstraints {
 		var hasConstraints bool
-		for _, t := range schema.Tables {
+		for _, t := range created {
 			if len(t.CheckConstraints) > 0 {
 				hasConstraints = true
 				break
@@ -234,11 +568,11 @@ func generateDDL(schema *domain.DatabaseSchema, opts *domain.DumpOptions) string
 			sb.WriteString("-- ============================================\n")
 			sb.WriteString("-- CHECK CONSTRAINTS\n")
 			sb.WriteString("-- ============================================\n\n")
-			for _, t := range schema.Tables {
+			for _, t := range created {
 				for _, cc := range t.CheckConstraints {
 					sb.WriteString(fmt.Sprintf("-- Check: [%s]\n", cc.Name))
-					sb.WriteString(cc.GenerateSQL())
-					sb.WriteString(";\nGO\n\n")
+					sb.WriteString(guard(cc.ExistsSQL(), cc.GenerateSQLFor(d, t.BitColumns()...)))
+					sb.WriteString(end + "\n")
 				}
 			}
 		}
@@ -251,10 +585,15 @@ func generateDDL(schema *domain.DatabaseSchema, opts *domain.DumpOptions) string
 		sb.WriteString("-- ============================================\n\n")
 		for _, v := range schema.Views {
 			sb.WriteString(fmt.Sprintf("-- View: [%s].[%s]\n", v.SchemaName, v.Name))
-			if v.Definition != "" {
-				sb.WriteString(v.Definition)
-				sb.WriteString(";\nGO\n\n")
-			} else {
+			switch {
+			case !tsql:
+				sb.WriteString(omittedModule(d))
+			case v.Definition != "":
+				sb.WriteString(domain.ModuleSetOptions(v.UsesQuotedIdentifier, v.UsesAnsiNulls))
+				sb.WriteString("\nGO\n")
+				sb.WriteString(definition(v.Definition))
+				sb.WriteString(end + "\n")
+			default:
 				sb.WriteString("-- (definition not available - possibly encrypted)\n\n")
 			}
 		}
@@ -267,10 +606,15 @@ func generateDDL(schema *domain.DatabaseSchema, opts *domain.DumpOptions) string
 		sb.WriteString("-- ============================================\n\n")
 		for _, p := range schema.StoredProcedures {
 			sb.WriteString(fmt.Sprintf("-- Procedure: [%s].[%s]\n", p.SchemaName, p.Name))
-			if p.Definition != "" {
-				sb.WriteString(p.Definition)
-				sb.WriteString(";\nGO\n\n")
-			} else {
+			switch {
+			case !tsql:
+				sb.WriteString(omittedModule(d))
+			case p.Definition != "":
+				sb.WriteString(domain.ModuleSetOptions(p.UsesQuotedIdentifier, p.UsesAnsiNulls))
+				sb.WriteString("\nGO\n")
+				sb.WriteString(definition(p.Definition))
+				sb.WriteString(end + "\n")
+			default:
 				sb.WriteString("-- (definition not available - possibly encrypted)\n\n")
 			}
 		}
@@ -283,10 +627,15 @@ func generateDDL(schema *domain.DatabaseSchema, opts *domain.DumpOptions) string
 		sb.WriteString("-- ============================================\n\n")
 		for _, f := range schema.Functions {
 			sb.WriteString(fmt.Sprintf("-- Function: [%s].[%s] (%s)\n", f.SchemaName, f.Name, f.FuncType))
-			if f.Definition != "" {
-				sb.WriteString(f.Definition)
-				sb.WriteString(";\nGO\n\n")
-			} else {
+			switch {
+			case !tsql:
+				sb.WriteString(omittedModule(d))
+			case f.Definition != "":
+				sb.WriteString(domain.ModuleSetOptions(f.UsesQuotedIdentifier, f.UsesAnsiNulls))
+				sb.WriteString("\nGO\n")
+				sb.WriteString(definition(f.Definition))
+				sb.WriteString(end + "\n")
+			default:
 				sb.WriteString("-- (definition not available - possibly encrypted)\n\n")
 			}
 		}
@@ -298,16 +647,64 @@ func generateDDL(schema *domain.DatabaseSchema, opts *domain.DumpOptions) string
 		sb.WriteString("-- TRIGGERS\n")
 		sb.WriteString("-- ============================================\n\n")
 		for _, tr := range schema.Triggers {
-			sb.WriteString(fmt.Sprintf("-- Trigger: [%s] on [%s].[%s]\n", tr.Name, tr.SchemaName, tr.TableName))
-			if tr.Definition != "" {
-				sb.WriteString(tr.Definition)
-				sb.WriteString(";\nGO\n\n")
+			if !tr.IsDatabaseScoped() && skipped[tr.SchemaName+"."+tr.TableName] {
+				continue
+			}
+			if tr.IsDatabaseScoped() {
+				sb.WriteString(fmt.Sprintf("-- Trigger: [%s] on database\n", tr.Name))
 			} else {
+				sb.WriteString(fmt.Sprintf("-- Trigger: [%s] on [%s].[%s]\n", tr.Name, tr.SchemaName, tr.TableName))
+			}
+			switch {
+			case !tsql:
+				sb.WriteString(omittedModule(d))
+			case tr.Definition != "":
+				sb.WriteString(domain.ModuleSetOptions(tr.UsesQuotedIdentifier, tr.UsesAnsiNulls))
+				sb.WriteString("\nGO\n")
+				sb.WriteString(definition(tr.Definition))
+				sb.WriteString(end)
+				if tr.IsDisabled {
+					sb.WriteString(tr.StateSQL())
+					sb.WriteString(end)
+				}
+				sb.WriteString("\n")
+			default:
 				sb.WriteString("-- (definition not available - possibly encrypted)\n\n")
 			}
 		}
 	}
 
+	// Permissions
+	if tsql && opts.IncludePermissions && len(schema.Permissions) > 0 {
+		sb.WriteString("-- ============================================\n")
+		sb.WriteString("-- PERMISSIONS\n")
+		sb.WriteString("-- ============================================\n\n")
+		for _, p := range schema.Permissions {
+			sb.WriteString(p.GenerateSQL())
+			sb.WriteString(";\n")
+		}
+		sb.WriteString("GO\n\n")
+	}
+
+	// Table data
+	if len(schema.TableData) > 0 {
+		sb.WriteString("-- ============================================\n")
+		sb.WriteString("-- DATA\n")
+		sb.WriteString("-- ============================================\n\n")
+		for _, td := range schema.TableData {
+			sb.WriteString(fmt.Sprintf("-- Data: [%s].[%s] (%d rows)\n", td.SchemaName, td.TableName, len(td.Rows)))
+			if !tsql {
+				sb.WriteString(fmt.Sprintf("-- (INSERT statements are only generated for tsql, not %s)\n\n", d.Name()))
+				continue
+			}
+			for _, stmt := range td.GenerateSQL() {
+				sb.WriteString(stmt)
+				sb.WriteString(end)
+			}
+			sb.WriteString("\n")
+		}
+	}
+
 	sb.WriteString("-- ============================================\n")
 	sb.WriteString("-- END OF DDL EXPORT\n")
 	sb.WriteString("-- ============================================\n")
@@ -315,25 +712,119 @@ func generateDDL(schema *domain.DatabaseSchema, opts *domain.DumpOptions) string
 	return sb.String()
 }
 
+// omittedModule returns the placeholder written instead of a T-SQL module body
+// when generating DDL for another dialect
+func omittedModule(d domain.Dialect) string {
+	return fmt.Sprintf("-- (T-SQL definition omitted: translate manually for %s)\n\n", d.Name())
+}
+
+// findTable returns the table named by a possibly schema-qualified name
+func findTable(tables []domain.Table, name string) *domain.Table {
+	for i := range tables {
+		if domain.MatchObject([]string{name}, tables[i].SchemaName, tables[i].Name) {
+			return &tables[i]
+		}
+	}
+	return nil
+}
+
 func printSummary(schema *domain.DatabaseSchema) {
-	var indexCount, fkCount, checkCount int
+	var indexCount, fkCount, checkCount, uniqueCount int
 	for _, t := range schema.Tables {
 		indexCount += len(t.Indexes)
 		fkCount += len(t.ForeignKeys)
 		checkCount += len(t.CheckConstraints)
+		uniqueCount += len(t.UniqueConstraints)
+	}
+
+	infoln()
+	infoln(strings.Repeat("─", 40))
+	infoln(color.Bold("Extraction Summary:"))
+	infof("  Schemas:            %d\n", len(schema.Schemas))
+	infof("  Tables:             %d\n", len(schema.Tables))
+	infof("  Indexes:            %d\n", indexCount)
+	infof("  Foreign Keys:       %d\n", fkCount)
+	infof("  Check Constraints:  %d\n", checkCount)
+	infof("  Unique Constraints: %d\n", uniqueCount)
+	infof("  Views:              %d\n", len(schema.Views))
+	infof("  Procedures:         %d\n", len(schema.StoredProcedures))
+	infof("  Functions:          %d\n", len(schema.Functions))
+	infof("  Triggers:           %d\n", len(schema.Triggers))
+	if len(schema.Permissions) > 0 {
+		infof("  Permissions:        %d\n", len(schema.Permissions))
+	}
+	if len(schema.Statistics) > 0 {
+		infof("  Statistics:         %d\n", len(schema.Statistics))
+	}
+	infoln(strings.Repeat("─", 40))
+}
+
+// hasPermission reports whether name is among the effective permissions
+func hasPermission(permissions []string, name string) bool {
+	for _, p := range permissions {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// tablesWithColumns returns the tables that have columns, and the
+// "schema.table" names of those that have none
+func tablesWithColumns(tables []domain.Table) ([]domain.Table, map[string]bool) {
+	var created []domain.Table
+	skipped := make(map[string]bool)
+	for _, t := range tables {
+		if len(t.Columns) == 0 {
+			skipped[t.SchemaName+"."+t.Name] = true
+			continue
+		}
+		created = append(created, t)
+	}
+	return created, skipped
+}
+
+// historyTablesFirst orders temporal history tables before the other tables,
+// so that each system-versioned table can name an existing history table
+func historyTablesFirst(tables []domain.Table) []domain.Table {
+	ordered := make([]domain.Table, 0, len(tables))
+	for _, t := range tables {
+		if t.IsHistoryTable {
+			ordered = append(ordered, t)
+		}
+	}
+	for _, t := range tables {
+		if !t.IsHistoryTable {
+			ordered = append(ordered, t)
+		}
+	}
+	return ordered
+}
+
+// parseSince parses a --since value: a duration before now such as 90m, 24h
+// or 7d, or a local date or timestamp. An empty value returns the zero time.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
 	}
 
-	fmt.Fprintln(os.Stderr)
-	fmt.Fprintln(os.Stderr, strings.Repeat("─", 40))
-	fmt.Fprintf(os.Stderr, "\033[1mExtraction Summary:\033[0m\n")
-	fmt.Fprintf(os.Stderr, "  Schemas:           %d\n", len(schema.Schemas))
-	fmt.Fprintf(os.Stderr, "  Tables:            %d\n", len(schema.Tables))
-	fmt.Fprintf(os.Stderr, "  Indexes:           %d\n", indexCount)
-	fmt.Fprintf(os.Stderr, "  Foreign Keys:      %d\n", fkCount)
-	fmt.Fprintf(os.Stderr, "  Check Constraints: %d\n", checkCount)
-	fmt.Fprintf(os.Stderr, "  Views:             %d\n", len(schema.Views))
-	fmt.Fprintf(os.Stderr, "  Procedures:        %d\n", len(schema.StoredProcedures))
-	fmt.Fprintf(os.Stderr, "  Functions:         %d\n", len(schema.Functions))
-	fmt.Fprintf(os.Stderr, "  Triggers:          %d\n", len(schema.Triggers))
-	fmt.Fprintln(os.Stderr, strings.Repeat("─", 40))
+	return time.Time{}, fmt.Errorf("invalid --since %q (expected a duration such as 24h or 7d, or a timestamp such as 2024-05-01)", value)
 }
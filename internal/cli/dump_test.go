@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/enunezf/SQLPulse/internal/adapters/memory"
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+)
+
+// dumpOptions returns the default dump options with schema DDL
+func dumpOptions() *domain.DumpOptions {
+	opts := domain.DefaultDumpOptions()
+	opts.IncludeSchemaDDL = true
+	return opts
+}
+
+func TestGenerateDDLSchemaWithDroppedOwner(t *testing.T) {
+	schema := memory.NewSchema("Shop").Schema("legacy", "").Schema("sales", "dbo").Build()
+	ddl := generateDDL(schema, dumpOptions(), domain.TSQL)
+
+	for _, want := range []string{
+		"-- Schema [legacy]: owner principal no longer exists, created without AUTHORIZATION\nCREATE SCHEMA [legacy];",
+		"CREATE SCHEMA [sales] AUTHORIZATION [dbo];",
+	} {
+		if !strings.Contains(ddl, want) {
+			t.Errorf("DDL does not contain %q:\n%s", want, ddl)
+		}
+	}
+}
+
+func TestGenerateDDLSkipsDependentsOfTablesWithoutColumns(t *testing.T) {
+	schema := memory.NewSchema("Shop").
+		Table(memory.NewTable("dbo", "Hidden").
+			Index("IX_Hidden", false, "Id").
+			Unique("UQ_Hidden", "Code").
+			Check("CK_Hidden", "([Id]>(0))").
+			ForeignKey("FK_Hidden_Orders", "dbo", "Orders", []string{"OrderId"}, []string{"Id"})).
+		Table(memory.NewTable("dbo", "Orders").
+			Column(memory.NewColumn("Id", "int")).
+			Column(memory.NewColumn("HiddenId", "int")).
+			PrimaryKey("PK_Orders", "Id").
+			Index("IX_Orders_HiddenId", false, "HiddenId").
+			ForeignKey("FK_Orders_Hidden", "dbo", "Hidden", []string{"HiddenId"}, []string{"Id"})).
+		Statistic(domain.Statistic{Name: "ST_Hidden", SchemaName: "dbo", TableName: "Hidden", Columns: []string{"Id"}}).
+		Trigger("dbo", "Hidden", "TR_Hidden", "CREATE TRIGGER dbo.TR_Hidden ON dbo.Hidden AFTER INSERT AS SELECT 1").
+		Build()
+	opts := dumpOptions()
+	opts.IncludeStatistics = true
+	ddl := generateDDL(schema, opts, domain.TSQL)
+
+	if !strings.Contains(ddl, "-- Table: [dbo].[Hidden]\n"+domain.SkippedNoColumns) {
+		t.Errorf("DDL does not mark [dbo].[Hidden] as skipped:\n%s", ddl)
+	}
+	for _, name := range []string{"IX_Hidden", "UQ_Hidden", "CK_Hidden", "ST_Hidden", "TR_Hidden", "ADD CONSTRAINT [FK_Hidden_Orders]", "ADD CONSTRAINT [FK_Orders_Hidden]"} {
+		if strings.Contains(ddl, name) {
+			t.Errorf("DDL contains %s of the skipped table:\n%s", name, ddl)
+		}
+	}
+	if !strings.Contains(ddl, "IX_Orders_HiddenId") {
+		t.Errorf("DDL lost the index of [dbo].[Orders]:\n%s", ddl)
+	}
+	for _, section := range []string{"-- STATISTICS", "-- UNIQUE CONSTRAINTS", "-- CHECK CONSTRAINTS"} {
+		if strings.Contains(ddl, section) {
+			t.Errorf("DDL has an empty %s section:\n%s", section, ddl)
+		}
+	}
+}
+
+func TestGenerateDDLOnlyTablesWithoutColumns(t *testing.T) {
+	schema := memory.NewSchema("Shop").
+		Table(memory.NewTable("dbo", "Hidden").Index("IX_Hidden", false, "Id")).
+		Build()
+	ddl := generateDDL(schema, dumpOptions(), domain.TSQL)
+
+	if strings.Contains(ddl, "-- INDEXES") || strings.Contains(ddl, "CREATE") {
+		t.Errorf("DDL creates objects for a schema whose only table has no columns:\n%s", ddl)
+	}
+}
+
+func TestGenerateDDLDatabaseSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  *domain.DatabaseSchema
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "defaults only",
+			schema: memory.NewSchema("Shop").
+				Collation("Latin1_General_CI_AS").ServerCollation("Latin1_General_CI_AS").
+				ScopedConfiguration("MAXDOP", "0").ScopedConfiguration("PARAMETER_SNIFFING", "1").
+				Build(),
+			notWant: []string{"-- DATABASE SETTINGS", "COLLATE", "SCOPED CONFIGURATION"},
+		},
+		{
+			name: "changed settings",
+			schema: memory.NewSchema("Shop").
+				Collation("Latin1_General_CS_AS").ServerCollation("Latin1_General_CI_AS").
+				ScopedConfiguration("MAXDOP", "4").ScopedConfiguration("PARAMETER_SNIFFING", "1").
+				ScopedConfiguration("LEDGER_DIGEST_STORAGE_ENDPOINT", "https://ledger.example/o'brien").
+				Build(),
+			want: []string{
+				"ALTER DATABASE CURRENT COLLATE Latin1_General_CS_AS;",
+				"ALTER DATABASE SCOPED CONFIGURATION SET MAXDOP = 4;",
+				"ALTER DATABASE SCOPED CONFIGURATION SET LEDGER_DIGEST_STORAGE_ENDPOINT = N'https://ledger.example/o''brien';",
+			},
+			notWant: []string{"PARAMETER_SNIFFING"},
+		},
+		{
+			name:    "unknown server collation",
+			schema:  memory.NewSchema("Shop").Collation("Latin1_General_CS_AS").Build(),
+			want:    []string{"-- Database collation: Latin1_General_CS_AS"},
+			notWant: []string{"ALTER DATABASE CURRENT COLLATE"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ddl := generateDDL(tt.schema, dumpOptions(), domain.TSQL)
+			for _, want := range tt.want {
+				if !strings.Contains(ddl, want) {
+					t.Errorf("DDL does not contain %q:\n%s", want, ddl)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(ddl, notWant) {
+					t.Errorf("DDL contains %q:\n%s", notWant, ddl)
+				}
+			}
+		})
+	}
+}
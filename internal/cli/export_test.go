@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+)
+
+func TestCSVRowWriterNull(t *testing.T) {
+	row := []domain.QueryValue{{Null: true}, {Text: ""}, {Text: "a"}}
+	tests := []struct {
+		name string
+		null string
+		want string
+	}{
+		{"default", "", "c1,c2,c3\n,,a\n"},
+		{"marker", `\N`, "c1,c2,c3\n\\N,,a\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := newCSVRowWriter(&buf, tt.null)
+			if err := w.WriteHeader([]string{"c1", "c2", "c3"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.WriteRow(row); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
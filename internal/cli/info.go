@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/enunezf/SQLPulse/internal/color"
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+)
+
+var infoFormat string
+
+// infoCmd represents the info command
+var infoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show database size, file layout and settings",
+	Long: `Show information about the connected database: compatibility level,
+recovery model, collation, total and used size, and each data and log file
+with its filegroup, size and growth settings.
+
+Examples:
+  # Show a report for a database
+  sqlpulse info --server localhost --database mydb --user sa --password secret
+
+  # Output the report as JSON
+  sqlpulse info --server localhost --database mydb --trusted --format json`,
+	RunE: runInfo,
+}
+
+func init() {
+	rootCmd.AddCommand(infoCmd)
+
+	infoCmd.Flags().StringVar(&infoFormat, "format", "text", "Output format: text or json")
+}
+
+func runInfo(cmd *cobra.Command, args []string) error {
+	config := GetConnectionConfig()
+
+	// Validate configuration
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	switch infoFormat {
+	case "text", "json":
+	default:
+		return fmt.Errorf("invalid --format %q (expected text or json)", infoFormat)
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+	defer cancel()
+
+	adapter, err := connectDatabase(ctx, config, "")
+	if err != nil {
+		return err
+	}
+	defer adapter.Close()
+
+	info, err := adapter.GetDatabaseInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	if infoFormat == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(info); err != nil {
+			return fmt.Errorf("failed to write JSON: %w", err)
+		}
+		return nil
+	}
+
+	printDatabaseInfo(info)
+	return nil
+}
+
+// printDatabaseInfo prints the database report
+func printDatabaseInfo(info *domain.DatabaseInfo) {
+	fmt.Println()
+	fmt.Println(strings.Repeat("─", 60))
+	printInfoField("Database:", info.Name)
+	printInfoField("Compatibility Level:", fmt.Sprintf("%d", info.CompatibilityLevel))
+	printInfoField("Recovery Model:", info.RecoveryModel)
+	printInfoField("Collation:", info.Collation)
+	printInfoField("Status:", info.Status)
+	printInfoField("Size:", fmt.Sprintf("%s (%s used)", formatMB(info.SizeMB), formatMB(info.UsedMB)))
+	fmt.Println(strings.Repeat("─", 60))
+
+	if len(info.Files) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println(color.Bold("Files:"))
+	for _, f := range info.Files {
+		location := f.Type
+		if f.FileGroup != "" {
+			location = fmt.Sprintf("%s, %s", f.Type, f.FileGroup)
+		}
+		maxSize := "unlimited"
+		if f.MaxSizeMB >= 0 {
+			maxSize = formatMB(f.MaxSizeMB)
+		}
+		fmt.Printf("  %s (%s)\n", color.Bold(f.Name), location)
+		fmt.Printf("    Path:   %s\n", f.PhysicalName)
+		fmt.Printf("    Size:   %s (%s used), max %s\n", formatMB(f.SizeMB), formatMB(f.UsedMB), maxSize)
+		fmt.Printf("    Growth: %s\n", f.Growth)
+	}
+}
+
+// printInfoField prints a report line with the values aligned
+func printInfoField(label, value string) {
+	fmt.Printf("%s %s\n", color.Bold(fmt.Sprintf("%-20s", label)), value)
+}
+
+// formatMB formats a size in megabytes, switching to gigabytes for large files
+func formatMB(mb float64) string {
+	if mb >= 1024 {
+		return fmt.Sprintf("%.2f GB", mb/1024)
+	}
+	return fmt.Sprintf("%.2f MB", mb)
+}
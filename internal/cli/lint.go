@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/enunezf/SQLPulse/internal/color"
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+	"github.com/enunezf/SQLPulse/internal/core/services"
+)
+
+var (
+	// Lint command flags
+	lintSchemaFilter []string
+	lintTableFilter  []string
+)
+
+// lintCmd represents the lint command
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Report schema smells in a SQL Server database",
+	Long: `Extract the schema of a database and report common design problems.
+
+Checks:
+  heap-table             Tables without a clustered index or primary key (WARNING)
+  unindexed-foreign-key  Foreign keys whose columns have no supporting index (WARNING)
+  nullable-pk-column     Nullable columns that are part of a primary key (ERROR)
+
+The command exits with status 1 when any ERROR finding is reported.
+
+Examples:
+  # Lint the whole database
+  sqlpulse lint --server localhost --database mydb --user sa --password secret
+
+  # Lint only the sales schema
+  sqlpulse lint --server localhost --database mydb --user sa --password secret --schema sales`,
+	RunE: runLint,
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+
+	lintCmd.Flags().StringSliceVar(&lintSchemaFilter, "schema", nil, "Filter by schema names or glob patterns (comma-separated)")
+	lintCmd.Flags().StringSliceVar(&lintTableFilter, "table", nil, "Filter by table names or glob patterns (comma-separated)")
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	config := GetConnectionConfig()
+
+	// Validate configuration
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 5*time.Minute)
+	defer cancel()
+
+	// Only tables, keys and indexes are needed for the checks
+	opts := &domain.DumpOptions{
+		IncludeTables:      true,
+		IncludeIndexes:     true,
+		IncludeForeignKeys: true,
+		IncludeConstraints: true,
+		SchemaFilter:       lintSchemaFilter,
+		TableFilter:        lintTableFilter,
+	}
+
+	adapter, schema, err := connectAndExtract(ctx, config, opts)
+	if err != nil {
+		return err
+	}
+	defer adapter.Close()
+
+	findings := services.LintSchema(schema)
+	if len(findings) == 0 {
+		fmt.Println(color.Green(fmt.Sprintf("✓ No findings in %d table(s)", len(schema.Tables))))
+		return nil
+	}
+
+	var errorCount int
+	for _, f := range findings {
+		severity := color.Yellow(fmt.Sprintf("%-7s", f.Severity))
+		if f.Severity == domain.LintError {
+			severity = color.Red(fmt.Sprintf("%-7s", f.Severity))
+			errorCount++
+		}
+		fmt.Printf("%s %s: %s (%s)\n", severity, f.ObjectName, f.Message, f.Rule)
+	}
+	infof("\n%d finding(s), %d error(s) in %d table(s)\n", len(findings), errorCount, len(schema.Tables))
+
+	if errorCount > 0 {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return &ExitError{Code: 1}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// readInputFile reads the file at path, decompressing it when it is gzip data,
+// whatever its extension
+func readInputFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		return data, nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s: %w", path, err)
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// stdoutPath is the output file name that writes to stdout, so wrappers
+// that always pass an output file can still pipe the output
+const stdoutPath = "-"
+
+// isStdout reports whether an output file flag writes to stdout, when it is
+// empty or "-"
+func isStdout(path string) bool {
+	return path == "" || path == stdoutPath
+}
+
+// writeOutputFile writes data to path, gzip-compressed when compress is set
+// or the path ends in .gz
+func writeOutputFile(path string, data []byte, compress bool) error {
+	if !compress && !strings.HasSuffix(strings.ToLower(path), ".gz") {
+		return os.WriteFile(path, data, 0644)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(f)
+	if _, err := zw.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to compress output: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to compress output: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// profilesCmd represents the profiles command
+var profilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "List connection profiles from the config file",
+	Long: `List the connection profiles defined in the config file (~/.sqlpulse/config.yaml
+or --config). Passwords are never printed.
+
+Select a profile for any command with --profile. Explicit flags override
+SQLPULSE_* environment variables, which override profile values.
+
+Example config file:
+  profiles:
+    prod:
+      server: prod-sql01
+      database: sales
+      user: deploy
+      password: "s3cret"
+    dev:
+      server: localhost
+      database: sales_dev
+      trusted: true`,
+	Args: cobra.NoArgs,
+	RunE: runProfiles,
+}
+
+func init() {
+	rootCmd.AddCommand(profilesCmd)
+}
+
+func runProfiles(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfigFile()
+	if err != nil {
+		return err
+	}
+
+	names := cfg.Names()
+	if len(names) == 0 {
+		infoln("No profiles configured")
+		return nil
+	}
+
+	fmt.Printf("%-15s %-25s %-20s %s\n", "PROFILE", "SERVER", "DATABASE", "AUTH")
+	for _, name := range names {
+		p := cfg.Profiles[name]
+		auth := "sql"
+		if p.User != "" {
+			auth = "sql (" + p.User + ")"
+		}
+		if p.TrustedAuth != nil && *p.TrustedAuth {
+			auth = "windows"
+		}
+		server := p.Server
+		if p.Port > 0 {
+			server = fmt.Sprintf("%s:%d", p.Server, p.Port)
+		}
+		fmt.Printf("%-15s %-25s %-20s %s\n", name, server, p.Database, auth)
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	"github.com/enunezf/SQLPulse/internal/color"
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+)
+
+var (
+	// Query command flags
+	queryFile   string
+	queryFormat string
+	maxRows     int
+)
+
+// queryCmd represents the query command
+var queryCmd = &cobra.Command{
+	Use:   "query [sql]",
+	Short: "Run an ad-hoc read query and print the results",
+	Long: `Run an ad-hoc query such as a SELECT and print the result set.
+
+The query runs inside a transaction that is always rolled back. A single SELECT
+or WITH statement runs at read-only approval level; anything else asks for
+approval at the level of its risk, and a query that ends the transaction with
+COMMIT or ROLLBACK fails. Only the first result set is printed, limited to
+--max-rows rows.
+
+Examples:
+  # Print the ten most recently created tables
+  sqlpulse query --server localhost --database mydb --user sa --password secret \
+    "SELECT TOP 10 name, create_date FROM sys.tables ORDER BY create_date DESC"
+
+  # Export a result set as CSV
+  sqlpulse query --server localhost --database mydb --user sa --password secret \
+    --file report.sql --format csv --max-rows 0 > report.csv`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runQuery,
+}
+
+func init() {
+	rootCmd.AddCommand(queryCmd)
+
+	queryCmd.Flags().StringVarP(&queryFile, "file", "f", "", "Read the query from a file (- for stdin)")
+	queryCmd.Flags().StringVar(&queryFormat, "format", "table", "Output format: table, json, or csv")
+	queryCmd.Flags().IntVar(&maxRows, "max-rows", 1000, "Maximum number of rows to print (0 for no limit)")
+}
+
+func runQuery(cmd *cobra.Command, args []string) error {
+	config := GetConnectionConfig()
+
+	// Validate configuration
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	switch queryFormat {
+	case "table", "json", "csv":
+	default:
+		return fmt.Errorf("invalid --format %q (expected table, json, or csv)", queryFormat)
+	}
+
+	var sqlText string
+	switch {
+	case len(args) == 1 && queryFile != "":
+		return fmt.Errorf("pass the query as an argument or with --file, not both")
+	case len(args) == 1:
+		sqlText = args[0]
+	case queryFile != "":
+		script, err := readScript(queryFile)
+		if err != nil {
+			return err
+		}
+		sqlText = script
+	default:
+		return fmt.Errorf("no query given: pass it as an argument or with --file")
+	}
+	if strings.TrimSpace(sqlText) == "" {
+		return fmt.Errorf("query is empty")
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 5*time.Minute)
+	defer cancel()
+
+	adapter, err := connectDatabase(ctx, config, "")
+	if err != nil {
+		return err
+	}
+	defer adapter.Close()
+
+	result, err := adapter.ExecuteReadQuery(ctx, sqlText, maxRows)
+	if err != nil {
+		return err
+	}
+
+	switch queryFormat {
+	case "json":
+		if err := printQueryJSON(result); err != nil {
+			return err
+		}
+	case "csv":
+		if err := printQueryCSV(result); err != nil {
+			return err
+		}
+	default:
+		printQueryTable(result)
+	}
+
+	infof("(%d row(s))\n", len(result.Rows))
+	if result.Truncated {
+		infoln(color.Yellow(fmt.Sprintf("⚠ Output limited to %d rows; use --max-rows to change the limit", maxRows)))
+	}
+
+	return nil
+}
+
+// printQueryTable prints the result as a table with aligned columns
+func printQueryTable(result *domain.QueryResult) {
+	if len(result.Columns) == 0 {
+		return
+	}
+
+	widths := make([]int, len(result.Columns))
+	for i, c := range result.Columns {
+		widths[i] = utf8.RuneCountInString(c)
+	}
+	for _, row := range result.Rows {
+		for i, v := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cellText(v)))
+		}
+	}
+
+	line := func(cells []string) string {
+		padded := make([]string, len(cells))
+		for i, c := range cells {
+			padded[i] = c + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(c))
+		}
+		return strings.TrimRight(strings.Join(padded, "  "), " ")
+	}
+
+	fmt.Println(color.Bold(line(result.Columns)))
+	separators := make([]string, len(widths))
+	for i, w := range widths {
+		separators[i] = strings.Repeat("─", w)
+	}
+	fmt.Println(line(separators))
+
+	for _, row := range result.Rows {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			cells[i] = cellText(v)
+		}
+		fmt.Println(line(cells))
+	}
+}
+
+// cellText renders a value on a single line for table output
+func cellText(v domain.QueryValue) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\t", " ").Replace(v.String())
+}
+
+// printQueryJSON prints the rows as a JSON array of objects whose keys follow
+// the column order. Values are strings, and NULL is null.
+func printQueryJSON(result *domain.QueryResult) error {
+	var sb strings.Builder
+	sb.WriteString("[")
+	for r, row := range result.Rows {
+		if r > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n  {")
+		for i, v := range row {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			key, err := json.Marshal(result.Columns[i])
+			if err != nil {
+				return err
+			}
+			value := []byte("null")
+			if !v.Null {
+				if value, err = json.Marshal(v.Text); err != nil {
+					return err
+				}
+			}
+			sb.Write(key)
+			sb.WriteString(": ")
+			sb.Write(value)
+		}
+		sb.WriteString("}")
+	}
+	if len(result.Rows) > 0 {
+		sb.WriteString("\n")
+	}
+	sb.WriteString("]")
+	fmt.Println(sb.String())
+	return nil
+}
+
+// printQueryCSV prints the result as CSV with a header row
+func printQueryCSV(result *domain.QueryResult) error {
+	w := newCSVRowWriter(os.Stdout, "")
+	if err := w.WriteHeader(result.Columns); err != nil {
+		return err
+	}
+	for _, row := range result.Rows {
+		if err := w.WriteRow(row); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
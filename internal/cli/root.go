@@ -2,24 +2,60 @@
 package cli
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
+	"github.com/enunezf/SQLPulse/internal/adapters/sqlserver"
+	"github.com/enunezf/SQLPulse/internal/color"
+	"github.com/enunezf/SQLPulse/internal/config"
 	"github.com/enunezf/SQLPulse/internal/core/domain"
+	"github.com/enunezf/SQLPulse/internal/security"
 )
 
 var (
 	// Global flags
-	server      string
-	database    string
-	user        string
-	password    string
-	trustedAuth bool
-	port        int
-	trustCert   bool
-	dryRun      bool
+	server       string
+	database     string
+	user         string
+	password     string
+	passwordFile string
+	trustedAuth  bool
+	port         int
+	trustCert    bool
+	encrypt      string
+	readOnly     bool
+	dryRun       bool
+	auditLog     string
+	quiet        bool
+	verbose      bool
+	noColor      bool
+	configFile   string
+	profileName  string
+
+	// Connection retry flags
+	connectRetries    int
+	connectRetryDelay time.Duration
+
+	// Catalog query timeout
+	queryTimeout time.Duration
+
+	// Time an approval prompt waits for an answer
+	approvalTimeout time.Duration
+
+	// Connection pool flags
+	maxConns        int
+	maxIdleConns    int
+	connMaxLifetime time.Duration
 
 	// Version information
 	version = "0.1.0"
@@ -43,26 +79,238 @@ Features:
 Example:
   sqlpulse connect --server localhost --database master --user sa --password secret`,
 	Version: version,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if noColor {
+			color.SetEnabled(false)
+		}
+		return applyConnectionSettings(cmd.Flags())
+	},
+}
+
+// ExitError makes the process exit with a specific status code
+type ExitError struct {
+	Code int
+	Err  error // Optional error to report before exiting
+}
+
+// Error returns the underlying error message or the exit status
+func (e *ExitError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// Unwrap returns the underlying error
+func (e *ExitError) Unwrap() error {
+	return e.Err
 }
 
+// exitCodeCancelled is the exit status after SIGINT or SIGTERM, as set by shells for SIGINT
+const exitCodeCancelled = 130
+
 // Execute adds all child commands to the root command and sets flags appropriately.
+// SIGINT and SIGTERM cancel the command context so in-flight queries abort cleanly;
+// a second signal terminates immediately.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		fmt.Fprintln(os.Stderr, "\nCancelling... (press Ctrl-C again to force quit)")
+		signal.Stop(signals)
+		cancel()
+	}()
+
+	wrapCancellable(rootCmd)
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		var exitErr *ExitError
+		if errors.As(err, &exitErr) {
+			if exitErr.Err != nil {
+				fmt.Fprintln(os.Stderr, exitErr.Err)
+			}
+			os.Exit(exitErr.Code)
+		}
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
 
+// wrapCancellable installs cancellable on every command of the tree rooted
+// at cmd, including nested subcommands such as those of profiles
+func wrapCancellable(cmd *cobra.Command) {
+	if cmd.RunE != nil {
+		cmd.RunE = cancellable(cmd.RunE)
+	}
+	for _, child := range cmd.Commands() {
+		wrapCancellable(child)
+	}
+}
+
+// cancellable reports a command that failed because its context was cancelled
+// by a signal as "cancelled" with exitCodeCancelled
+func cancellable(run func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		err := run(cmd, args)
+		if err != nil && cmd.Context().Err() != nil {
+			cmd.SilenceErrors = true
+			cmd.SilenceUsage = true
+			return &ExitError{Code: exitCodeCancelled, Err: errors.New("cancelled")}
+		}
+		return err
+	}
+}
+
 func init() {
 	// Global flags available to all commands
 	rootCmd.PersistentFlags().StringVarP(&server, "server", "s", "", "SQL Server hostname or IP address")
 	rootCmd.PersistentFlags().StringVarP(&database, "database", "d", "", "Database name")
 	rootCmd.PersistentFlags().StringVarP(&user, "user", "u", "", "Username for SQL authentication")
 	rootCmd.PersistentFlags().StringVarP(&password, "password", "p", "", "Password for SQL authentication")
+	rootCmd.PersistentFlags().StringVar(&passwordFile, "password-file", "", "Read the password for SQL authentication from a file")
 	rootCmd.PersistentFlags().BoolVarP(&trustedAuth, "trusted", "t", false, "Use Windows/Integrated authentication")
 	rootCmd.PersistentFlags().IntVar(&port, "port", 1433, "SQL Server port")
 	rootCmd.PersistentFlags().BoolVar(&trustCert, "trust-cert", false, "Trust server certificate (insecure)")
+	rootCmd.PersistentFlags().BoolVar(&readOnly, "read-only", false, "Declare a read-only workload (ApplicationIntent=ReadOnly) to reach availability group read replicas")
+	rootCmd.PersistentFlags().StringVar(&encrypt, "encrypt", "true", "Connection encryption: false, true, or strict (TDS 8.0)")
 	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Show what would be executed without making changes")
+	rootCmd.PersistentFlags().IntVar(&connectRetries, "connect-retries", 3, "Retries on transient connection errors")
+	rootCmd.PersistentFlags().DurationVar(&connectRetryDelay, "connect-retry-delay", time.Second, "Initial delay between connection retries (doubles each attempt)")
+	rootCmd.PersistentFlags().DurationVar(&queryTimeout, "query-timeout", 2*time.Minute, "Time limit for the catalog queries of each table or object type during extraction (0 = no limit)")
+	rootCmd.PersistentFlags().IntVar(&maxConns, "max-conns", 10, "Maximum open connections in the pool (0 = unlimited)")
+	rootCmd.PersistentFlags().IntVar(&maxIdleConns, "max-idle-conns", 5, "Maximum idle connections kept in the pool (capped at --max-conns)")
+	rootCmd.PersistentFlags().DurationVar(&connMaxLifetime, "conn-max-lifetime", 30*time.Minute, "Maximum time a pooled connection may be reused (0 = forever)")
+	rootCmd.PersistentFlags().StringVar(&auditLog, "audit-log", "", "Append every approval decision to this JSONL file")
+	rootCmd.PersistentFlags().DurationVar(&approvalTimeout, "approval-timeout", security.DefaultApprovalTimeout, "Time an approval prompt waits for an answer before the operation is not approved (0 = no limit)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress progress messages and summaries on stderr")
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Log every catalog query with its duration to stderr")
+	rootCmd.MarkFlagsMutuallyExclusive("quiet", "verbose")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Config file with connection profiles (default: ~/.sqlpulse/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Load connection settings from a named profile in the config file")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also set by the NO_COLOR environment variable)")
+}
+
+// connectionSetting maps a connection flag to its environment variable
+type connectionSetting struct {
+	flag   string
+	envVar string
+}
+
+// connectionSettings lists the flags that can also come from the environment or a profile
+var connectionSettings = []connectionSetting{
+	{"server", "SQLPULSE_SERVER"},
+	{"database", "SQLPULSE_DATABASE"},
+	{"user", "SQLPULSE_USER"},
+	{"password", "SQLPULSE_PASSWORD"},
+	{"trusted", "SQLPULSE_TRUSTED"},
+	{"port", "SQLPULSE_PORT"},
+	{"trust-cert", "SQLPULSE_TRUST_CERT"},
+	{"encrypt", "SQLPULSE_ENCRYPT"},
+}
+
+// passwordFileFlag maps a password flag to the flag naming a file to read it from
+type passwordFileFlag struct {
+	password string
+	file     string
+}
+
+// passwordFileFlags lists the password flags that can be read from a file
+var passwordFileFlags = []passwordFileFlag{
+	{"password", "password-file"},
+	{"target-password", "target-password-file"},
+}
+
+// applyConnectionSettings fills connection flags that were not given on the
+// command line, first from password files, then from SQLPULSE_* environment
+// variables and then from the --profile. Precedence: explicit flags >
+// password files > environment variables > profile.
+func applyConnectionSettings(flags *pflag.FlagSet) error {
+	for _, pf := range passwordFileFlags {
+		if err := applyPasswordFile(flags, pf); err != nil {
+			return err
+		}
+	}
+
+	var profileValues map[string]string
+	if profileName != "" {
+		cfg, err := loadConfigFile()
+		if err != nil {
+			return err
+		}
+		profile, err := cfg.Profile(profileName)
+		if err != nil {
+			return err
+		}
+		profileValues = profile.Settings()
+	}
+
+	for _, s := range connectionSettings {
+		flag := flags.Lookup(s.flag)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		if value, ok := os.LookupEnv(s.envVar); ok {
+			if err := flag.Value.Set(value); err != nil {
+				return fmt.Errorf("invalid %s: %w", s.envVar, err)
+			}
+			continue
+		}
+		if value, ok := profileValues[s.flag]; ok {
+			if err := flag.Value.Set(value); err != nil {
+				return fmt.Errorf("invalid %s in profile %q: %w", s.flag, profileName, err)
+			}
+		}
+	}
+	return nil
+}
+
+// applyPasswordFile sets a password flag not given on the command line from
+// its password file, if one was given. The flag is then considered set, so
+// the environment and profile do not override it.
+func applyPasswordFile(flags *pflag.FlagSet, pf passwordFileFlag) error {
+	file := flags.Lookup(pf.file)
+	if file == nil || !file.Changed || flags.Changed(pf.password) {
+		return nil
+	}
+	value, err := readPasswordFile(file.Value.String())
+	if err != nil {
+		return fmt.Errorf("invalid --%s: %w", pf.file, err)
+	}
+	return flags.Set(pf.password, value)
+}
+
+// readPasswordFile reads a password from a file, trimming the trailing
+// newline that editors and secret mounts usually add
+func readPasswordFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password file: %w", err)
+	}
+	value := strings.TrimRight(string(data), "\r\n")
+	if value == "" {
+		return "", fmt.Errorf("password file %s is empty", path)
+	}
+	return value, nil
+}
+
+// loadConfigFile loads --config, or the default config file
+func loadConfigFile() (*config.File, error) {
+	path := configFile
+	if path == "" {
+		var err error
+		if path, err = config.DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config file: %w", err)
+	}
+	return cfg, nil
 }
 
 // GetConnectionConfig builds a ConnectionConfig from the global flags
@@ -75,9 +323,61 @@ func GetConnectionConfig() *domain.ConnectionConfig {
 	config.TrustedAuth = trustedAuth
 	config.Port = port
 	config.TrustServer = trustCert
+	config.EncryptMode = domain.EncryptMode(strings.ToLower(encrypt))
+	if readOnly {
+		config.ApplicationIntent = domain.ApplicationIntentReadOnly
+	}
+	config.ConnectRetries = connectRetries
+	config.ConnectRetryDelay = connectRetryDelay
+	config.MaxOpenConns = maxConns
+	config.MaxIdleConns = maxIdleConns
+	config.ConnMaxLifetime = connMaxLifetime
 	return config
 }
 
+// newAdapter creates a SQL Server adapter, using the dry-run approver when
+// --dry-run is set and wrapping it with the audit log when --audit-log is set
+func newAdapter(config *domain.ConnectionConfig) *sqlserver.Adapter {
+	adapter := sqlserver.NewAdapter(config)
+	if dryRun {
+		adapter.SetApprover(security.NewDryRunApprover())
+	} else {
+		approver := security.NewInteractiveApprover()
+		approver.SetTimeout(approvalTimeout)
+		adapter.SetApprover(approver)
+	}
+	if auditLog != "" {
+		adapter.SetApprover(security.NewAuditingApprover(adapter.Approver(), auditLog, config.Server, config.Database))
+	}
+	return adapter
+}
+
+// newExtractor creates a schema extractor with the --query-timeout limit that
+// logs query timings when --verbose is set
+func newExtractor(db *sql.DB) *sqlserver.SchemaExtractor {
+	extractor := sqlserver.NewSchemaExtractor(db)
+	extractor.SetQueryTimeout(queryTimeout)
+	if verbose {
+		extractor.SetQueryLog(os.Stderr)
+	}
+	return extractor
+}
+
+// infof prints an informational message to stderr unless --quiet is set.
+// Errors are returned to cobra and always printed.
+func infof(format string, args ...interface{}) {
+	if !quiet {
+		fmt.Fprintf(os.Stderr, format, args...)
+	}
+}
+
+// infoln prints an informational line to stderr unless --quiet is set
+func infoln(args ...interface{}) {
+	if !quiet {
+		fmt.Fprintln(os.Stderr, args...)
+	}
+}
+
 // IsDryRun returns true if dry-run mode is enabled
 func IsDryRun() bool {
 	return dryRun
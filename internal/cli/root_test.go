@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func TestWrapCancellableNestedCommands(t *testing.T) {
+	root := &cobra.Command{Use: "root"}
+	parent := &cobra.Command{Use: "parent"}
+	child := &cobra.Command{Use: "child", RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Context().Err()
+	}}
+	parent.AddCommand(child)
+	root.AddCommand(parent)
+	wrapCancellable(root)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	root.SetArgs([]string{"parent", "child"})
+	err := root.ExecuteContext(ctx)
+
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != exitCodeCancelled {
+		t.Fatalf("nested command error = %v, want exit code %d", err, exitCodeCancelled)
+	}
+}
+
+func TestApplyConnectionSettingsPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("profiles:\n  dev:\n    server: profile-server\n    password: from-profile\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	passwordPath := filepath.Join(dir, "password")
+	if err := os.WriteFile(passwordPath, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	savedConfig, savedProfile := configFile, profileName
+	t.Cleanup(func() { configFile, profileName = savedConfig, savedProfile })
+	configFile, profileName = configPath, "dev"
+
+	tests := []struct {
+		name  string
+		args  []string
+		env   string
+		want  string
+		check string
+	}{
+		{"flag wins", []string{"--password", "from-flag", "--password-file", passwordPath}, "from-env", "from-flag", "password"},
+		{"password file beats environment", []string{"--password-file", passwordPath}, "from-env", "from-file", "password"},
+		{"environment beats profile", nil, "from-env", "from-env", "password"},
+		{"profile fills the rest", nil, "", "from-profile", "password"},
+		{"profile server", nil, "", "profile-server", "server"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				t.Setenv("SQLPULSE_PASSWORD", tt.env)
+			} else {
+				t.Setenv("SQLPULSE_PASSWORD", "")
+				os.Unsetenv("SQLPULSE_PASSWORD")
+			}
+			t.Setenv("SQLPULSE_SERVER", "")
+			os.Unsetenv("SQLPULSE_SERVER")
+
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.String("server", "", "")
+			flags.String("password", "", "")
+			flags.String("password-file", "", "")
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := applyConnectionSettings(flags); err != nil {
+				t.Fatalf("applyConnectionSettings: %v", err)
+			}
+			if got, _ := flags.GetString(tt.check); got != tt.want {
+				t.Errorf("%s = %q, want %q", tt.check, got, tt.want)
+			}
+		})
+	}
+}
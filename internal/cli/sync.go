@@ -0,0 +1,324 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/enunezf/SQLPulse/internal/color"
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+	"github.com/enunezf/SQLPulse/internal/core/ports"
+	"github.com/enunezf/SQLPulse/internal/core/services"
+	"github.com/enunezf/SQLPulse/internal/security"
+)
+
+var (
+	// Sync command flags
+	transactional bool
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Apply schema differences from a source database to a target",
+	Long: `Compare two SQL Server databases and apply the migration to the target.
+
+The source database is specified using the global flags (--server, --database, etc.)
+and the target database using the --target-* flags, exactly as for diff.
+
+The full plan is printed first. Each change is then executed separately and
+requires approval according to its risk:
+  Modification  creating objects, altering definitions (y/N confirmation)
+  Destructive   dropping objects, altering column types (type the database name)
+
+Before the first change you can approve all changes at once (typing the
+database name when any is destructive), reject all of them, or step through
+and confirm each one.
+
+Execution stops at the first failed or declined change. Differences without
+migration SQL (e.g. changed view definitions) are listed but must be applied
+manually. Use --dry-run to show the plan without making changes.
+
+With --transactional, all changes run in a single transaction after one
+approval at the highest risk level of the plan, and a failure rolls every
+change back. Statements that SQL Server does not allow inside a transaction,
+such as ALTER DATABASE, are reported before anything is executed.
+
+Examples:
+  # Preview the changes needed to bring prod_db in line with dev_db
+  sqlpulse sync --server localhost --database dev_db --user sa --password secret \
+      --target-database prod_db --dry-run
+
+  # Apply them
+  sqlpulse sync --server localhost --database dev_db --user sa --password secret \
+      --target-database prod_db
+
+  # Apply them all or nothing
+  sqlpulse sync --server localhost --database dev_db --user sa --password secret \
+      --target-database prod_db --transactional`,
+	RunE: runSync,
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+
+	// Target database flags (shared with diff)
+	syncCmd.Flags().StringVar(&targetServer, "target-server", "", "Target SQL Server (defaults to source server)")
+	syncCmd.Flags().StringVar(&targetDatabase, "target-database", "", "Target database name (required)")
+	syncCmd.Flags().StringVar(&targetUser, "target-user", "", "Target username (defaults to source user)")
+	syncCmd.Flags().StringVar(&targetPassword, "target-password", "", "Target password (defaults to source password)")
+	syncCmd.Flags().StringVar(&targetPasswordFile, "target-password-file", "", "Read the target password from a file")
+	syncCmd.Flags().BoolVar(&targetTrusted, "target-trusted", false, "Use Windows auth for target")
+	syncCmd.Flags().IntVar(&targetPort, "target-port", 0, "Target port (defaults to source port)")
+
+	syncCmd.Flags().BoolVar(&ignoreCollation, "ignore-collation", false, "Ignore collation differences")
+	syncCmd.Flags().BoolVar(&ignoreOwners, "ignore-owners", false, "Ignore schema owner (AUTHORIZATION) differences")
+	syncCmd.Flags().BoolVar(&ignoreIdentity, "ignore-identity", false, "Ignore column IDENTITY differences")
+	syncCmd.Flags().BoolVar(&ignoreNullability, "ignore-nullability", false, "Ignore column nullability differences")
+	syncCmd.Flags().BoolVar(&ignoreComputed, "ignore-computed", false, "Ignore computed column expression differences")
+	syncCmd.Flags().BoolVar(&ignoreDefaults, "ignore-defaults", false, "Ignore column default value differences")
+	syncCmd.Flags().BoolVar(&guarded, "guarded", false, "Wrap DROP statements in IF EXISTS checks")
+	syncCmd.Flags().BoolVar(&noDrop, "no-drop", false, "Never drop objects that exist only in the target")
+	syncCmd.Flags().BoolVar(&transactional, "transactional", false, "Apply all changes in a single transaction that is rolled back on failure")
+
+	// Reuse filter flags from dump (already defined in dump.go)
+	syncCmd.Flags().BoolVar(&noTables, "no-tables", false, "Exclude tables from synchronization")
+	syncCmd.Flags().BoolVar(&noViews, "no-views", false, "Exclude views from synchronization")
+	syncCmd.Flags().BoolVar(&noProcedures, "no-procedures", false, "Exclude stored procedures")
+	syncCmd.Flags().BoolVar(&noFunctions, "no-functions", false, "Exclude functions")
+	syncCmd.Flags().BoolVar(&noTriggers, "no-triggers", false, "Exclude triggers")
+	syncCmd.Flags().BoolVar(&noIndexes, "no-indexes", false, "Exclude indexes")
+	syncCmd.Flags().BoolVar(&noForeignKeys, "no-foreign-keys", false, "Exclude foreign keys")
+	syncCmd.Flags().BoolVar(&noConstraints, "no-constraints", false, "Exclude check constraints")
+
+	syncCmd.MarkFlagRequired("target-database")
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	// Build source config
+	sourceConfig := GetConnectionConfig()
+	if err := sourceConfig.Validate(); err != nil {
+		return fmt.Errorf("source configuration error: %w", err)
+	}
+
+	// Build target config (inherit from source where not specified). Sync
+	// writes to the target, so a read-only intent is never passed on.
+	targetConfig := buildTargetConfig(sourceConfig)
+	targetConfig.ApplicationIntent = ""
+
+	if err := targetConfig.Validate(); err != nil {
+		return fmt.Errorf("target configuration error: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Minute)
+	defer cancel()
+
+	// Connect to both databases before extracting either
+	sourceAdapter, err := connectDatabase(ctx, sourceConfig, "source")
+	if err != nil {
+		return err
+	}
+	defer sourceAdapter.Close()
+
+	targetAdapter, err := connectDatabase(ctx, targetConfig, "target")
+	if err != nil {
+		return err
+	}
+	defer targetAdapter.Close()
+
+	// Build extraction options
+	opts := &domain.DumpOptions{
+		IncludeTables:      !noTables,
+		IncludeViews:       !noViews,
+		IncludeProcedures:  !noProcedures,
+		IncludeFunctions:   !noFunctions,
+		IncludeTriggers:    !noTriggers,
+		IncludeIndexes:     !noIndexes,
+		IncludeForeignKeys: !noForeignKeys,
+		IncludeConstraints: !noConstraints,
+	}
+
+	sourceSchema, err := extractSchema(ctx, newExtractor(sourceAdapter.DB()), opts, "source")
+	if err != nil {
+		return err
+	}
+
+	targetSchema, err := extractSchema(ctx, newExtractor(targetAdapter.DB()), opts, "target")
+	if err != nil {
+		return err
+	}
+
+	// Compare schemas
+	diffOpts := &domain.DiffOptions{
+		IncludeSchemas:       true,
+		IncludeTables:        !noTables,
+		IncludeViews:         !noViews,
+		IncludeProcedures:    !noProcedures,
+		IncludeFunctions:     !noFunctions,
+		IncludeTriggers:      !noTriggers,
+		IncludeIndexes:       !noIndexes,
+		IncludeForeignKeys:   !noForeignKeys,
+		IncludeConstraints:   !noConstraints,
+		IgnoreCollation:      ignoreCollation,
+		IgnoreWhitespace:     true,
+		CaseInsensitiveNames: domain.IsCaseInsensitiveCollation(sourceSchema.Collation),
+		IgnoreOwners:         ignoreOwners,
+		IgnoreIdentity:       ignoreIdentity,
+		IgnoreNullability:    ignoreNullability,
+		IgnoreComputed:       ignoreComputed,
+		IgnoreDefaults:       ignoreDefaults,
+		NoDrop:               noDrop,
+		GuardedDrops:         guarded,
+		TargetVersion:        targetSchema.ProductVersion,
+	}
+
+	infoln("Comparing schemas...")
+	result := services.NewSchemaComparator(diffOpts).Compare(sourceSchema, targetSchema)
+	infoln()
+
+	if !result.HasDifferences() {
+		fmt.Println(color.Green("✓ Schemas are identical, nothing to sync"))
+		return nil
+	}
+
+	steps := result.MigrationSteps()
+	printSyncPlan(result, steps)
+
+	if len(steps) == 0 {
+		return nil
+	}
+
+	if transactional {
+		if err := applyTransactional(ctx, targetAdapter, steps, targetConfig.Database); err != nil {
+			return err
+		}
+		if IsDryRun() {
+			printDryRunSummary(targetAdapter.Approver())
+		}
+		return nil
+	}
+
+	// Offer to approve or reject the whole plan at once
+	if batch, ok := targetAdapter.Approver().(security.BatchApprover); ok {
+		reqs := make([]security.ApprovalRequest, len(steps))
+		for i, step := range steps {
+			reqs[i] = security.ApprovalRequest{
+				Operation:          stepOperation(i, len(steps), step),
+				SQL:                step.MigrationSQL,
+				Level:              services.ApprovalLevel(step.Risk),
+				ConfirmationPhrase: targetConfig.Database,
+			}
+		}
+		approved, err := batch.RequestBatchApproval(ctx, reqs)
+		if err != nil {
+			return fmt.Errorf("approval error: %w", err)
+		}
+		if !approved {
+			return security.ErrCancelled
+		}
+	}
+
+	// Apply each change, stopping at the first failure
+	applied := 0
+	for i, step := range steps {
+		err := targetAdapter.ExecuteWithApproval(ctx, step.MigrationSQL, services.ApprovalLevel(step.Risk), stepOperation(i, len(steps), step))
+		if err != nil {
+			if IsDryRun() && errors.Is(err, security.ErrCancelled) {
+				continue
+			}
+			infof("\n%s\n", color.Red(fmt.Sprintf("✗ Applied %d of %d changes", applied, len(steps))))
+			return fmt.Errorf("step %d (%s) failed: %w", i+1, step.ObjectName, err)
+		}
+		applied++
+	}
+
+	if IsDryRun() {
+		infof("\n%s\n", color.Blue(fmt.Sprintf("Dry run: %d change(s) would be applied to %s", len(steps), targetConfig.Database)))
+		printDryRunSummary(targetAdapter.Approver())
+		return nil
+	}
+
+	infof("\n%s\n", color.Green(fmt.Sprintf("✓ Applied %d change(s) to %s", applied, targetConfig.Database)))
+	return nil
+}
+
+// printDryRunSummary prints the tally of the operations the dry-run approver
+// was shown
+func printDryRunSummary(approver security.Approver) {
+	if session, ok := approver.(security.SessionApprover); ok && session.Summary() != "" {
+		infoln(session.Summary())
+	}
+}
+
+// stepOperation describes a sync step in approval prompts
+func stepOperation(i, total int, step domain.Difference) string {
+	return fmt.Sprintf("Step %d/%d: %s", i+1, total, step.Description)
+}
+
+// applyTransactional applies all steps in a single transaction after one
+// approval at the highest risk level of the plan
+func applyTransactional(ctx context.Context, adapter ports.DatabasePort, steps []domain.Difference, database string) error {
+	level := security.ReadOnly
+	batches := make([]domain.Batch, len(steps))
+	for i, step := range steps {
+		if stmt := domain.NonTransactionalStatement(step.MigrationSQL); stmt != "" {
+			return fmt.Errorf("step %d (%s) uses %s, which cannot run inside a transaction; rerun without --transactional",
+				i+1, step.ObjectName, stmt)
+		}
+		level = max(level, services.ApprovalLevel(step.Risk))
+		batches[i] = domain.Batch{SQL: step.MigrationSQL, Line: i + 1}
+	}
+
+	operation := fmt.Sprintf("Apply %d change(s) in a single transaction", len(steps))
+	err := adapter.ExecuteTransaction(ctx, batches, level, operation)
+	if IsDryRun() && errors.Is(err, security.ErrCancelled) {
+		infof("\n%s\n", color.Blue(fmt.Sprintf("Dry run: %d change(s) would be applied to %s in a single transaction", len(steps), database)))
+		return nil
+	}
+	if err != nil {
+		var batchErr *domain.BatchError
+		if errors.As(err, &batchErr) {
+			step := steps[batchErr.Index-1]
+			infof("\n%s\n", color.Red("✗ All changes rolled back"))
+			return fmt.Errorf("step %d (%s) failed: %w", batchErr.Index, step.ObjectName, batchErr.Err)
+		}
+		return err
+	}
+
+	infof("\n%s\n", color.Green(fmt.Sprintf("✓ Applied %d change(s) to %s in a single transaction", len(steps), database)))
+	return nil
+}
+
+// printSyncPlan prints the changes that will be applied and those that need manual action
+func printSyncPlan(result *domain.DiffResult, steps []domain.Difference) {
+	fmt.Println(strings.Repeat("─", 60))
+	fmt.Println(color.Bold(fmt.Sprintf("Sync Plan: %s → %s", result.SourceDatabase, result.TargetDatabase)))
+	fmt.Println(strings.Repeat("─", 60))
+
+	for i, step := range steps {
+		fmt.Printf("  %3d. %-12s [%s] %s: %s\n", i+1, step.Risk, step.Category, step.ObjectName, step.Description)
+	}
+	if len(steps) == 0 {
+		fmt.Println("  No changes can be applied automatically.")
+	}
+
+	var manual []domain.Difference
+	for _, d := range result.Differences {
+		if d.MigrationSQL == "" {
+			manual = append(manual, d)
+		}
+	}
+	if len(manual) > 0 {
+		fmt.Println()
+		fmt.Println("  Requires manual action (no migration SQL):")
+		for _, d := range manual {
+			fmt.Printf("    %s\n", d.String())
+		}
+	}
+
+	fmt.Println(strings.Repeat("─", 60))
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/enunezf/SQLPulse/internal/color"
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+)
+
+var (
+	// Validate command flags
+	scriptFile string
+)
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate a SQL script against a server without committing",
+	Long: `Validate a SQL script by executing it inside a transaction that is always rolled back.
+
+The script is split into batches on GO separator lines and each batch is executed
+in order. The first batch that raises an error is reported together with the
+line of the script where the error occurred. No changes are ever committed.
+
+Note that some statements (e.g. CREATE DATABASE, ALTER DATABASE) cannot run
+inside a transaction and will be reported as errors. Scripts with COMMIT,
+ROLLBACK or BEGIN TRANSACTION are refused before any batch runs. A batch
+ended by GO n runs n times.
+
+Examples:
+  # Validate a migration script
+  sqlpulse validate --server localhost --database mydb --user sa --password secret --file migration.sql
+
+  # Validate a script read from stdin
+  cat migration.sql | sqlpulse validate --server localhost --database mydb --user sa --password secret`,
+	RunE: runValidate,
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().StringVarP(&scriptFile, "file", "f", "", "SQL script to validate (default: stdin)")
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	config := GetConnectionConfig()
+
+	// Validate configuration
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	script, err := readScript(scriptFile)
+	if err != nil {
+		return err
+	}
+
+	batches := domain.SplitBatches(script)
+	if len(batches) == 0 {
+		return fmt.Errorf("script is empty")
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Minute)
+	defer cancel()
+
+	adapter, err := connectDatabase(ctx, config, "")
+	if err != nil {
+		return err
+	}
+	defer adapter.Close()
+	infof("Validating %d batch(es) (changes will be rolled back)...\n", len(batches))
+
+	if err := adapter.ValidateScript(ctx, batches); err != nil {
+		var batchErr *domain.BatchError
+		if errors.As(err, &batchErr) {
+			fmt.Printf("%s %v\n", color.Red(fmt.Sprintf("✗ Batch %d of %d failed at line %d:",
+				batchErr.Index, len(batches), batchErr.Line)), batchErr.Err)
+		}
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	fmt.Println(color.Green(fmt.Sprintf("✓ Script is valid: %d batch(es) executed and rolled back", len(batches))))
+	return nil
+}
+
+// readScript reads a SQL script from a file, or from stdin when path is empty or "-"
+func readScript(path string) (string, error) {
+	if path == "" || path == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read script from stdin: %w", err)
+		}
+		return string(data), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read script file: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,73 @@
+// Package color provides ANSI coloring for terminal output.
+// Coloring is disabled when stdout or stderr is not a terminal, when the
+// NO_COLOR environment variable is set, or when disabled explicitly.
+package color
+
+import "os"
+
+// ANSI escape codes
+const (
+	reset  = "\033[0m"
+	bold   = "\033[1m"
+	red    = "\033[31m"
+	green  = "\033[32m"
+	yellow = "\033[33m"
+	blue   = "\033[34m"
+	cyan   = "\033[36m"
+)
+
+// enabled reports whether escape codes are emitted
+var enabled = detect()
+
+// detect enables coloring only when NO_COLOR is unset and both output streams are terminals
+func detect() bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	return isTerminal(os.Stdout) && isTerminal(os.Stderr)
+}
+
+// isTerminal reports whether f is a character device
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Enabled reports whether coloring is active
+func Enabled() bool {
+	return enabled
+}
+
+// SetEnabled turns coloring on or off, overriding auto-detection
+func SetEnabled(on bool) {
+	enabled = on
+}
+
+// wrap surrounds s with the given escape code when coloring is enabled
+func wrap(code, s string) string {
+	if !enabled {
+		return s
+	}
+	return code + s + reset
+}
+
+// Bold renders s in bold
+func Bold(s string) string { return wrap(bold, s) }
+
+// Red renders s in red
+func Red(s string) string { return wrap(red, s) }
+
+// Green renders s in green
+func Green(s string) string { return wrap(green, s) }
+
+// Yellow renders s in yellow
+func Yellow(s string) string { return wrap(yellow, s) }
+
+// Blue renders s in blue
+func Blue(s string) string { return wrap(blue, s) }
+
+// Cyan renders s in cyan
+func Cyan(s string) string { return wrap(cyan, s) }
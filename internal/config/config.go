@@ -0,0 +1,232 @@
+// Package config loads named connection profiles from the SQLPulse config file.
+//
+// The file is a small subset of YAML:
+//
+//	profiles:
+//	  dev:
+//	    server: localhost
+//	    database: mydb
+//	    trusted: true
+//	  prod:
+//	    server: prod-sql01
+//	    database: sales
+//	    user: deploy
+//	    password: "s3cret"
+//	    port: 1433
+//	    trust_cert: false
+//	    encrypt: strict
+//
+// Values may be quoted with single or double quotes. # starts a comment at
+// the start of a line or after a space, so a value such as pa#ss is kept.
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Profile holds the connection settings of a named profile.
+// Unset fields are left to flags, environment variables or defaults.
+type Profile struct {
+	Name        string
+	Server      string
+	Database    string
+	User        string
+	Password    string
+	TrustedAuth *bool
+	Port        int
+	TrustServer *bool
+	Encrypt     string
+}
+
+// File is a parsed config file
+type File struct {
+	Profiles map[string]*Profile
+}
+
+// DefaultPath returns ~/.sqlpulse/config.yaml
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, ".sqlpulse", "config.yaml"), nil
+}
+
+// Load reads and parses a config file
+func Load(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	cfg := &File{Profiles: make(map[string]*Profile)}
+	var section string
+	var current *Profile
+	profileIndent := 0 // Indentation of profile names, set by the first one of a section
+
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := stripComment(scanner.Text())
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected \"key: value\"", path, lineNo)
+		}
+		key = strings.TrimSpace(key)
+		value = unquote(strings.TrimSpace(value))
+
+		switch {
+		case indent == 0:
+			section, current, profileIndent = key, nil, 0
+			if key != "profiles" {
+				return nil, fmt.Errorf("%s:%d: unknown key %q", path, lineNo, key)
+			}
+		case section != "profiles":
+			return nil, fmt.Errorf("%s:%d: unexpected indentation", path, lineNo)
+		case profileIndent == 0 || indent == profileIndent:
+			if value != "" {
+				return nil, fmt.Errorf("%s:%d: expected a profile name", path, lineNo)
+			}
+			profileIndent = indent
+			current = &Profile{Name: key}
+			cfg.Profiles[key] = current
+		case indent > profileIndent:
+			if current == nil {
+				return nil, fmt.Errorf("%s:%d: %q is not inside a profile", path, lineNo, key)
+			}
+			if err := current.set(key, value); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+			}
+		default:
+			return nil, fmt.Errorf("%s:%d: unexpected indentation", path, lineNo)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// Profile returns the named profile
+func (f *File) Profile(name string) (*Profile, error) {
+	p, ok := f.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found", name)
+	}
+	return p, nil
+}
+
+// Names returns the profile names in alphabetical order
+func (f *File) Names() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Settings returns the profile's values keyed by the matching command-line
+// flag name. Unset fields are omitted.
+func (p *Profile) Settings() map[string]string {
+	values := make(map[string]string)
+	add := func(flag, value string) {
+		if value != "" {
+			values[flag] = value
+		}
+	}
+	add("server", p.Server)
+	add("database", p.Database)
+	add("user", p.User)
+	add("password", p.Password)
+	if p.TrustedAuth != nil {
+		add("trusted", strconv.FormatBool(*p.TrustedAuth))
+	}
+	if p.Port > 0 {
+		add("port", strconv.Itoa(p.Port))
+	}
+	if p.TrustServer != nil {
+		add("trust-cert", strconv.FormatBool(*p.TrustServer))
+	}
+	add("encrypt", p.Encrypt)
+	return values
+}
+
+// set assigns a profile field from its config file key
+func (p *Profile) set(key, value string) error {
+	switch key {
+	case "server":
+		p.Server = value
+	case "database":
+		p.Database = value
+	case "user":
+		p.User = value
+	case "password":
+		p.Password = value
+	case "encrypt":
+		p.Encrypt = value
+	case "port":
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid port %q", value)
+		}
+		p.Port = port
+	case "trusted", "trust_cert":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q for %s", value, key)
+		}
+		if key == "trusted" {
+			p.TrustedAuth = &b
+		} else {
+			p.TrustServer = &b
+		}
+	default:
+		return fmt.Errorf("unknown profile key %q", key)
+	}
+	return nil
+}
+
+// stripComment removes a # comment that is not inside quotes. As in YAML,
+// # only starts a comment at the start of the line or after whitespace.
+func stripComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// unquote removes matching surrounding quotes
+func unquote(value string) string {
+	if len(value) >= 2 {
+		first, last := value[0], value[len(value)-1]
+		if (first == '"' || first == '\'') && first == last {
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadString writes content to a config file and loads it
+func loadString(t *testing.T, content string) (*File, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return Load(path)
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		wantErr      string
+		wantServer   string
+		wantPassword string
+	}{
+		{
+			name:         "profile",
+			content:      "profiles:\n  dev:\n    server: localhost # local instance\n    password: \"s3cret\"\n",
+			wantServer:   "localhost",
+			wantPassword: "s3cret",
+		},
+		{
+			name:         "hash inside a value",
+			content:      "profiles:\n  dev:\n    server: localhost\n    password: pa#ss\n",
+			wantServer:   "localhost",
+			wantPassword: "pa#ss",
+		},
+		{
+			name:         "quoted hash after a space",
+			content:      "profiles:\n  dev:\n    server: localhost\n    password: 'pa #ss' # comment\n",
+			wantServer:   "localhost",
+			wantPassword: "pa #ss",
+		},
+		{
+			name:       "second profiles section",
+			content:    "profiles:\n  dev:\n    server: localhost\nprofiles:\n    dev:\n        server: other\n",
+			wantServer: "other",
+		},
+		{
+			name:    "second profiles section with a key but no profile",
+			content: "profiles:\n  dev:\n    server: localhost\nprofiles:\n      server: other\n",
+			wantErr: "expected a profile name",
+		},
+		{
+			name:    "unknown top-level key",
+			content: "servers:\n  dev:\n",
+			wantErr: "unknown key",
+		},
+		{
+			name:    "unknown profile key",
+			content: "profiles:\n  dev:\n    host: localhost\n",
+			wantErr: "unknown profile key",
+		},
+		{
+			name:    "invalid port",
+			content: "profiles:\n  dev:\n    port: abc\n",
+			wantErr: "invalid port",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadString(t, tt.content)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			p, err := cfg.Profile("dev")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Server != tt.wantServer || p.Password != tt.wantPassword {
+				t.Errorf("got server %q password %q, want %q and %q", p.Server, p.Password, tt.wantServer, tt.wantPassword)
+			}
+		})
+	}
+}
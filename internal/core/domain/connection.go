@@ -4,41 +4,107 @@ package domain
 import (
 	"fmt"
 	"net/url"
+	"strings"
+	"time"
 )
 
 // ConnectionConfig holds the configuration for a database connection
 type ConnectionConfig struct {
-	Server       string // Server hostname or IP
-	Port         int    // Port number (default 1433)
-	Database     string // Database name
-	User         string // Username for SQL authentication
-	Password     string // Password for SQL authentication
-	TrustedAuth  bool   // Use Windows/Integrated authentication
-	Encrypt      bool   // Encrypt connection (default true)
-	TrustServer  bool   // Trust server certificate
-	AppName      string // Application name for connection
+	Server      string      // Server hostname or IP
+	Port        int         // Port number (default 1433)
+	Database    string      // Database name
+	User        string      // Username for SQL authentication
+	Password    string      // Password for SQL authentication
+	TrustedAuth bool        // Use Windows/Integrated authentication
+	EncryptMode EncryptMode // false, true or strict; when empty Encrypt is used
+	TrustServer bool        // Trust server certificate
+	AppName     string      // Application name for connection
+
+	ApplicationIntent string // ReadOnly routes availability group listeners to a readable secondary
+
+	// Deprecated: use EncryptMode, which also supports strict encryption
+	Encrypt bool
+
+	ConnectRetries    int           // Retries on transient connection errors
+	ConnectRetryDelay time.Duration // Initial delay between retries (doubles each attempt)
+
+	MaxOpenConns    int           // Maximum open connections in the pool (0 = unlimited)
+	MaxIdleConns    int           // Maximum idle connections kept in the pool
+	ConnMaxLifetime time.Duration // Maximum time a connection may be reused (0 = forever)
+
+	// RawConnectionString, when set, is passed to the driver as is instead of
+	// the connection string built from the fields above, which then only
+	// describe the connection in messages
+	RawConnectionString string
+}
+
+// ApplicationIntentReadOnly declares a read-only workload, so an availability
+// group listener routes the connection to a readable secondary replica
+const ApplicationIntentReadOnly = "ReadOnly"
+
+// EncryptMode controls TLS encryption of the connection
+type EncryptMode string
+
+// Encryption modes understood by the driver's encrypt setting
+const (
+	EncryptDisabled EncryptMode = "false"  // Encrypt only the login packet
+	EncryptEnabled  EncryptMode = "true"   // Encrypt the whole connection
+	EncryptStrict   EncryptMode = "strict" // TDS 8.0: TLS before the login, always validating the certificate
+)
+
+// ParseEncryptMode parses an --encrypt value
+func ParseEncryptMode(s string) (EncryptMode, error) {
+	switch mode := EncryptMode(strings.ToLower(s)); mode {
+	case EncryptDisabled, EncryptEnabled, EncryptStrict:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid encrypt mode %q (expected false, true, or strict)", s)
+	}
+}
+
+// EffectiveEncryptMode returns EncryptMode, falling back to the deprecated
+// Encrypt flag when it is not set
+func (c *ConnectionConfig) EffectiveEncryptMode() EncryptMode {
+	if c.EncryptMode != "" {
+		return c.EncryptMode
+	}
+	if c.Encrypt {
+		return EncryptEnabled
+	}
+	return EncryptDisabled
 }
 
 // NewConnectionConfig creates a new connection config with defaults
 func NewConnectionConfig() *ConnectionConfig {
 	return &ConnectionConfig{
-		Port:       1433,
-		Encrypt:    true,
-		AppName:    "SQLPulse",
+		Port:    1433,
+		Encrypt: true,
+		AppName: "SQLPulse",
+
+		ConnectRetries:    3,
+		ConnectRetryDelay: time.Second,
+
+		MaxOpenConns:    10,
+		MaxIdleConns:    5,
+		ConnMaxLifetime: 30 * time.Minute,
 	}
 }
 
 // ConnectionString generates the MSSQL connection string
 func (c *ConnectionConfig) ConnectionString() string {
+	if c.RawConnectionString != "" {
+		return c.RawConnectionString
+	}
+
 	query := url.Values{}
 
 	query.Add("database", c.Database)
 	query.Add("app name", c.AppName)
 
-	if c.Encrypt {
-		query.Add("encrypt", "true")
-	} else {
-		query.Add("encrypt", "false")
+	query.Add("encrypt", string(c.EffectiveEncryptMode()))
+
+	if c.ApplicationIntent != "" {
+		query.Add("ApplicationIntent", c.ApplicationIntent)
 	}
 
 	if c.TrustServer {
@@ -73,6 +139,11 @@ func (c *ConnectionConfig) Validate() error {
 		return fmt.Errorf("database is required")
 	}
 
+	// The driver checks the settings of a raw connection string when parsing it
+	if c.RawConnectionString != "" {
+		return nil
+	}
+
 	if !c.TrustedAuth {
 		if c.User == "" {
 			return fmt.Errorf("user is required for SQL authentication")
@@ -86,6 +157,19 @@ func (c *ConnectionConfig) Validate() error {
 		return fmt.Errorf("port must be between 1 and 65535")
 	}
 
+	if c.EncryptMode != "" {
+		if _, err := ParseEncryptMode(string(c.EncryptMode)); err != nil {
+			return err
+		}
+	}
+	if c.EffectiveEncryptMode() == EncryptStrict && c.TrustServer {
+		return fmt.Errorf("trust-cert cannot be used with strict encryption, which always validates the server certificate")
+	}
+
+	if c.MaxOpenConns < 0 || c.MaxIdleConns < 0 {
+		return fmt.Errorf("connection pool sizes must not be negative")
+	}
+
 	return nil
 }
 
@@ -106,3 +190,56 @@ type ServerInfo struct {
 	ProductName string // Product name
 	ServerName  string // Server name
 }
+
+// Diagnostics is the report of the doctor command. The checks run in order
+// and stop at the first failure, so later fields are left empty when an
+// earlier step failed.
+type Diagnostics struct {
+	Address      string // Server address the checks connected to
+	EncryptMode  EncryptMode
+	TrustServer  bool
+	Reachable    bool   // A TCP connection to the server port succeeded
+	ReachError   string // Why the server port could not be reached, empty when not checked
+	TLSVersion   string // TLS version negotiated by the driver, e.g. "TLS 1.2"
+	Connected    bool
+	ConnectError string
+	ConnectHint  string // Remediation hint for ConnectError, if the cause was recognized
+
+	ProductVersion string // e.g. 16.0.4135.4
+	Edition        string
+	Encrypted      string // Session encryption reported by the server: TRUE, FALSE, or empty when unknown
+	AuthScheme     string // SQL, NTLM or KERBEROS, empty when unknown
+	Features       []FeatureCheck
+}
+
+// FeatureCheck reports whether the server supports a feature SQLPulse relies on
+type FeatureCheck struct {
+	Name      string
+	Available bool
+	Impact    string // What does not work when the feature is unavailable
+	Error     string // Why the check could not run, if it failed
+}
+
+// DatabaseInfo holds information about the connected database
+type DatabaseInfo struct {
+	Name               string         `json:"name"`
+	CompatibilityLevel int            `json:"compatibility_level"`
+	RecoveryModel      string         `json:"recovery_model"`
+	Collation          string         `json:"collation"`
+	Status             string         `json:"status"`
+	SizeMB             float64        `json:"size_mb"` // Allocated size of all files
+	UsedMB             float64        `json:"used_mb"` // Space used inside the files
+	Files              []DatabaseFile `json:"files"`
+}
+
+// DatabaseFile describes one of the database's data or log files
+type DatabaseFile struct {
+	Name         string  `json:"name"`
+	Type         string  `json:"type"` // ROWS, LOG, FILESTREAM or FULLTEXT
+	FileGroup    string  `json:"file_group,omitempty"`
+	PhysicalName string  `json:"physical_name"`
+	SizeMB       float64 `json:"size_mb"`
+	UsedMB       float64 `json:"used_mb"`
+	MaxSizeMB    float64 `json:"max_size_mb"` // -1 when unlimited
+	Growth       string  `json:"growth"`      // e.g. "64 MB", "10%" or "none"
+}
@@ -0,0 +1,74 @@
+package domain
+
+// ObjectCounts holds the number of objects of each category in a database
+type ObjectCounts map[DiffCategory]int
+
+// Categories returns the counted categories in report order
+func (c ObjectCounts) Categories() []DiffCategory {
+	var cats []DiffCategory
+	for _, cat := range categoryOrder {
+		if _, ok := c[cat]; ok {
+			cats = append(cats, cat)
+		}
+	}
+	return cats
+}
+
+// Equal reports whether both databases have the same number of objects in
+// every category counted in either of them
+func (c ObjectCounts) Equal(other ObjectCounts) bool {
+	if len(c) != len(other) {
+		return false
+	}
+	for cat, n := range c {
+		if m, ok := other[cat]; !ok || m != n {
+			return false
+		}
+	}
+	return true
+}
+
+// CountObjects counts the objects of the schema by category, covering the
+// object types opts includes. Indexes leave out primary keys, and
+// constraints are the check and unique constraints of tables.
+func (s *DatabaseSchema) CountObjects(opts *DumpOptions) ObjectCounts {
+	counts := ObjectCounts{DiffCategorySchema: len(s.Schemas)}
+
+	if opts.IncludeTables {
+		counts[DiffCategoryTable] = len(s.Tables)
+		columns, indexes, foreignKeys, constraints := 0, 0, 0, 0
+		for _, t := range s.Tables {
+			columns += len(t.Columns)
+			indexes += len(t.Indexes)
+			foreignKeys += len(t.ForeignKeys)
+			constraints += len(t.CheckConstraints) + len(t.UniqueConstraints)
+		}
+		counts[DiffCategoryColumn] = columns
+		if opts.IncludeIndexes {
+			counts[DiffCategoryIndex] = indexes
+		}
+		if opts.IncludeForeignKeys {
+			counts[DiffCategoryForeignKey] = foreignKeys
+		}
+		if opts.IncludeConstraints {
+			counts[DiffCategoryConstraint] = constraints
+		}
+	}
+	if opts.IncludeViews {
+		counts[DiffCategoryView] = len(s.Views)
+	}
+	if opts.IncludeProcedures {
+		counts[DiffCategoryProcedure] = len(s.StoredProcedures)
+	}
+	if opts.IncludeFunctions {
+		counts[DiffCategoryFunction] = len(s.Functions)
+	}
+	if opts.IncludeTriggers {
+		counts[DiffCategoryTrigger] = len(s.Triggers)
+	}
+	if opts.IncludePermissions {
+		counts[DiffCategoryPermission] = len(s.Permissions)
+	}
+
+	return counts
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"encoding/csv"
+	"strconv"
+	"strings"
+)
+
+// ToCSV returns the summary by category as delimited text for spreadsheets:
+// a category,added,removed,modified header followed by one row for each
+// category with differences, in report order. comma separates the fields,
+// ',' for CSV or '\t' for TSV.
+func (s *DiffSummary) ToCSV(comma rune) string {
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	w.Comma = comma
+
+	// Writing to a strings.Builder cannot fail
+	_ = w.Write([]string{"category", "added", "removed", "modified"})
+	for _, cat := range s.Categories() {
+		types := s.ByCategoryType[cat]
+		_ = w.Write([]string{
+			string(cat),
+			strconv.Itoa(types[DiffAdded]),
+			strconv.Itoa(types[DiffRemoved]),
+			strconv.Itoa(types[DiffModified]),
+		})
+	}
+	w.Flush()
+	return sb.String()
+}
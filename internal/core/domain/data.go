@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InsertBatchSize is the number of rows per generated INSERT statement, the
+// most a T-SQL table value constructor accepts
+const InsertBatchSize = 1000
+
+// TableData holds the rows of a table for INSERT generation
+type TableData struct {
+	SchemaName  string
+	TableName   string
+	Columns     []string
+	Rows        [][]string // T-SQL literals in column order
+	HasIdentity bool
+}
+
+// GenerateSQL returns INSERT statements of at most InsertBatchSize rows each.
+// Tables with an identity column are wrapped in SET IDENTITY_INSERT ON/OFF so
+// the original values are kept.
+func (td *TableData) GenerateSQL() []string {
+	if len(td.Rows) == 0 {
+		return nil
+	}
+
+	table := fmt.Sprintf("%s.%s", QuoteIdent(td.SchemaName), QuoteIdent(td.TableName))
+
+	cols := make([]string, len(td.Columns))
+	for i, c := range td.Columns {
+		cols[i] = QuoteIdent(c)
+	}
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES\n", table, strings.Join(cols, ", "))
+
+	var stmts []string
+	if td.HasIdentity {
+		stmts = append(stmts, fmt.Sprintf("SET IDENTITY_INSERT %s ON", table))
+	}
+
+	for start := 0; start < len(td.Rows); start += InsertBatchSize {
+		end := min(start+InsertBatchSize, len(td.Rows))
+		values := make([]string, 0, end-start)
+		for _, row := range td.Rows[start:end] {
+			values = append(values, "    ("+strings.Join(row, ", ")+")")
+		}
+		stmts = append(stmts, insert+strings.Join(values, ",\n"))
+	}
+
+	if td.HasIdentity {
+		stmts = append(stmts, fmt.Sprintf("SET IDENTITY_INSERT %s OFF", table))
+	}
+
+	return stmts
+}
@@ -0,0 +1,349 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Dialect describes the SQL flavor that DDL generators emit
+type Dialect interface {
+	// Name returns the dialect name used by --dialect
+	Name() string
+
+	// QuoteIdent quotes an identifier
+	QuoteIdent(name string) string
+
+	// MapType renders a SQL Server data type in this dialect
+	MapType(dataType string, maxLength, precision, scale int) string
+
+	// Identity returns the column clause for an identity column
+	Identity(seed, increment int64) string
+
+	// ComputedColumn returns the column clause for a computed column of the
+	// given (already mapped) type
+	ComputedColumn(dataType, expression string) string
+
+	// TranslateExpression rewrites a SQL Server check, filter or computed
+	// column expression, including its bracket-quoted identifiers. bitColumns
+	// names the bit columns of the table the expression belongs to.
+	TranslateExpression(expression string, bitColumns []string) string
+
+	// TranslateDefault rewrites the default of a column of the given type
+	TranslateDefault(expression, dataType string) string
+
+	// BatchTerminator returns the line that ends a batch, or "" if none
+	BatchTerminator() string
+
+	// StorageOptions reports whether CLUSTERED/NONCLUSTERED, ON [filegroup],
+	// WITH NOCHECK and SQL Server specific index types are supported
+	StorageOptions() bool
+
+	// Supports reports whether version-specific syntax can be generated
+	Supports(f Feature) bool
+}
+
+// Supported dialects
+var (
+	TSQL     Dialect = tsqlDialect{}
+	Postgres Dialect = postgresDialect{}
+)
+
+// ParseDialect returns the dialect with the given name ("tsql" or "postgres")
+func ParseDialect(name string) (Dialect, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "tsql", "sqlserver", "mssql":
+		return TSQL, nil
+	case "postgres", "postgresql", "pg":
+		return Postgres, nil
+	}
+	return nil, fmt.Errorf("unknown dialect %q (expected tsql or postgres)", name)
+}
+
+// tsqlDialect is SQL Server's Transact-SQL, the default. version is the
+// product version generated for, empty for the latest.
+type tsqlDialect struct {
+	version string
+}
+
+func (tsqlDialect) Name() string { return "tsql" }
+
+func (tsqlDialect) QuoteIdent(name string) string { return QuoteIdent(name) }
+
+func (tsqlDialect) MapType(dataType string, maxLength, precision, scale int) string {
+	return formatDataType(dataType, maxLength, precision, scale)
+}
+
+func (tsqlDialect) Identity(seed, increment int64) string {
+	return fmt.Sprintf(" IDENTITY(%d,%d)", seed, increment)
+}
+
+func (tsqlDialect) ComputedColumn(dataType, expression string) string {
+	return fmt.Sprintf("AS %s", expression)
+}
+
+func (tsqlDialect) TranslateExpression(expression string, bitColumns []string) string {
+	return expression
+}
+
+func (tsqlDialect) TranslateDefault(expression, dataType string) string { return expression }
+
+func (tsqlDialect) BatchTerminator() string { return "GO" }
+
+func (tsqlDialect) StorageOptions() bool { return true }
+
+func (d tsqlDialect) Supports(f Feature) bool {
+	return d.version == "" || supportsFeature(d.version, f)
+}
+
+// postgresDialect is PostgreSQL
+type postgresDialect struct{}
+
+// postgresFunctions maps SQL Server functions to their PostgreSQL equivalents
+var postgresFunctions = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`(?i)\b(getdate|sysdatetime|current_timestamp)\s*\(\s*\)`), "CURRENT_TIMESTAMP"},
+	{regexp.MustCompile(`(?i)\b(getutcdate|sysutcdatetime)\s*\(\s*\)`), "(CURRENT_TIMESTAMP AT TIME ZONE 'UTC')"},
+	{regexp.MustCompile(`(?i)\bsysdatetimeoffset\s*\(\s*\)`), "CURRENT_TIMESTAMP"},
+	{regexp.MustCompile(`(?i)\bnewid\s*\(\s*\)`), "gen_random_uuid()"},
+	{regexp.MustCompile(`(?i)\bnewsequentialid\s*\(\s*\)`), "gen_random_uuid()"},
+	{regexp.MustCompile(`(?i)\bisnull\s*\(`), "COALESCE("},
+	{regexp.MustCompile(`(?i)\blen\s*\(`), "LENGTH("},
+}
+
+func (postgresDialect) Name() string { return "postgres" }
+
+func (postgresDialect) QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (postgresDialect) MapType(dataType string, maxLength, precision, scale int) string {
+	length := func(unicode bool) string {
+		if maxLength == -1 {
+			return ""
+		}
+		if unicode {
+			return fmt.Sprintf("(%d)", maxLength/2)
+		}
+		return fmt.Sprintf("(%d)", maxLength)
+	}
+
+	switch strings.ToLower(dataType) {
+	case "bit":
+		return "boolean"
+	case "tinyint", "smallint":
+		return "smallint"
+	case "int":
+		return "integer"
+	case "bigint":
+		return "bigint"
+	case "decimal", "numeric":
+		return fmt.Sprintf("numeric(%d,%d)", precision, scale)
+	case "money":
+		return "numeric(19,4)"
+	case "smallmoney":
+		return "numeric(10,4)"
+	case "float":
+		return "double precision"
+	case "real":
+		return "real"
+	case "varchar", "nvarchar":
+		if maxLength == -1 {
+			return "text"
+		}
+		return "varchar" + length(strings.HasPrefix(strings.ToLower(dataType), "n"))
+	case "char", "nchar":
+		return "char" + length(strings.HasPrefix(strings.ToLower(dataType), "n"))
+	case "text", "ntext", "sysname", "sql_variant":
+		return "text"
+	case "date":
+		return "date"
+	case "time":
+		return fmt.Sprintf("time(%d)", scale)
+	case "datetime", "smalldatetime":
+		return "timestamp"
+	case "datetime2":
+		return fmt.Sprintf("timestamp(%d)", min(scale, 6))
+	case "datetimeoffset":
+		return fmt.Sprintf("timestamptz(%d)", min(scale, 6))
+	case "uniqueidentifier":
+		return "uuid"
+	case "binary", "varbinary", "image", "timestamp", "rowversion":
+		return "bytea"
+	case "xml":
+		return "xml"
+	}
+	return strings.ToLower(dataType)
+}
+
+func (postgresDialect) Identity(seed, increment int64) string {
+	return fmt.Sprintf(" GENERATED BY DEFAULT AS IDENTITY (START WITH %d INCREMENT BY %d)", seed, increment)
+}
+
+func (d postgresDialect) ComputedColumn(dataType, expression string) string {
+	return fmt.Sprintf("%s GENERATED ALWAYS AS (%s) STORED", dataType, d.TranslateExpression(expression, nil))
+}
+
+// TranslateExpression requotes [identifiers] and maps common SQL Server
+// functions outside string literals. N'...' literals lose their N prefix, and
+// comparisons of bitColumns with 0 or 1 compare with false or true.
+func (d postgresDialect) TranslateExpression(expression string, bitColumns []string) string {
+	bits := make(map[string]bool, len(bitColumns))
+	for _, name := range bitColumns {
+		bits[strings.ToLower(name)] = true
+	}
+
+	var sb, code strings.Builder
+	// Functions are only mapped in code, never in literals or identifiers
+	flush := func() {
+		result := code.String()
+		for _, f := range postgresFunctions {
+			result = f.pattern.ReplaceAllString(result, f.replacement)
+		}
+		sb.WriteString(result)
+		code.Reset()
+	}
+
+	runes := []rune(expression)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\'':
+			j := i + 1
+			for j < len(runes) {
+				if runes[j] == '\'' {
+					if j+1 < len(runes) && runes[j+1] == '\'' {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			if j >= len(runes) {
+				j = len(runes) - 1
+			}
+			flush()
+			sb.WriteString(string(runes[i : j+1]))
+			i = j
+		case (r == 'N' || r == 'n') && i+1 < len(runes) && runes[i+1] == '\'' &&
+			(i == 0 || !isIdentRune(runes[i-1])):
+			// Drop the N prefix of a Unicode literal
+		case r == '[':
+			j := i + 1
+			var name strings.Builder
+			for j < len(runes) {
+				if runes[j] == ']' {
+					if j+1 < len(runes) && runes[j+1] == ']' {
+						name.WriteRune(']')
+						j += 2
+						continue
+					}
+					break
+				}
+				name.WriteRune(runes[j])
+				j++
+			}
+			flush()
+			sb.WriteString(d.QuoteIdent(name.String()))
+			i = j
+			if bits[strings.ToLower(name.String())] {
+				if operator, value, next, ok := bitComparison(runes, j+1); ok {
+					sb.WriteString(operator + value)
+					i = next - 1
+				}
+			}
+		default:
+			code.WriteRune(r)
+		}
+	}
+	flush()
+	return sb.String()
+}
+
+// TranslateDefault translates a column default; the 0 and 1 defaults of a
+// bit column become false and true
+func (d postgresDialect) TranslateDefault(expression, dataType string) string {
+	if strings.EqualFold(dataType, "bit") {
+		if value, ok := bitLiteral(strings.Trim(expression, "() ")); ok {
+			return value
+		}
+	}
+	return d.TranslateExpression(expression, nil)
+}
+
+// bitComparison matches a comparison operator followed by 0 or 1, optionally
+// parenthesized, at runes[from:]. It returns the operator, the boolean
+// literal and the index after the match.
+func bitComparison(runes []rune, from int) (operator, value string, next int, ok bool) {
+	i := from
+	skipSpace := func() {
+		for i < len(runes) && unicode.IsSpace(runes[i]) {
+			i++
+		}
+	}
+
+	skipSpace()
+	for _, op := range []string{"<>", "!=", "="} {
+		if strings.HasPrefix(string(runes[i:]), op) {
+			operator = string(runes[from:i]) + op
+			i += len(op)
+			break
+		}
+	}
+	if operator == "" {
+		return "", "", 0, false
+	}
+
+	start := i
+	skipSpace()
+	operator += string(runes[start:i])
+	parens := 0
+	for i < len(runes) && runes[i] == '(' {
+		parens++
+		i++
+		skipSpace()
+	}
+	if i >= len(runes) {
+		return "", "", 0, false
+	}
+	value, ok = bitLiteral(string(runes[i]))
+	if !ok || (i+1 < len(runes) && isIdentRune(runes[i+1])) {
+		return "", "", 0, false
+	}
+	i++
+	for ; parens > 0; parens-- {
+		skipSpace()
+		if i >= len(runes) || runes[i] != ')' {
+			return "", "", 0, false
+		}
+		i++
+	}
+	return operator, value, i, true
+}
+
+// bitLiteral maps the bit values 0 and 1 to false and true
+func bitLiteral(s string) (string, bool) {
+	switch s {
+	case "0":
+		return "false", true
+	case "1":
+		return "true", true
+	}
+	return "", false
+}
+
+func (postgresDialect) BatchTerminator() string { return "" }
+
+func (postgresDialect) StorageOptions() bool { return false }
+
+func (postgresDialect) Supports(f Feature) bool { return false }
+
+// isIdentRune reports whether r can be part of an unquoted identifier
+func isIdentRune(r rune) bool {
+	return r == '_' || r == '@' || r == '#' || r == '$' ||
+		(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
@@ -0,0 +1,57 @@
+package domain
+
+import "testing"
+
+func TestPostgresTranslateExpression(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		bitColumns []string
+		want       string
+	}{
+		{"identifiers", "([Price]>(0))", nil, `("Price">(0))`},
+		{"functions", "(isnull([Name],'')+len([Code]))", nil, `(COALESCE("Name",'')+LENGTH("Code"))`},
+		{"function names in literals", "([Note]<>'getdate() and isnull(x)')", nil, `("Note"<>'getdate() and isnull(x)')`},
+		{"function names in identifiers", "([len(x)]=(1))", nil, `("len(x)"=(1))`},
+		{"unicode literal", "([Status]=N'A')", nil, `("Status"='A')`},
+		{"bit comparison", "([Active]=(1))", []string{"Active"}, `("Active"=true)`},
+		{"bit comparison with spaces", "([IsDeleted] <> 0 AND [Qty]=(1))", []string{"isdeleted"}, `("IsDeleted" <> false AND "Qty"=(1))`},
+		{"bit compared with a number", "([Active]=(10))", []string{"Active"}, `("Active"=(10))`},
+		{"integer column", "([Active]=(1))", nil, `("Active"=(1))`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Postgres.TranslateExpression(tt.expression, tt.bitColumns); got != tt.want {
+				t.Errorf("TranslateExpression(%q) = %q, want %q", tt.expression, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostgresTranslateDefault(t *testing.T) {
+	tests := []struct {
+		expression string
+		dataType   string
+		want       string
+	}{
+		{"((0))", "bit", "false"},
+		{"((1))", "BIT", "true"},
+		{"((1))", "int", "((1))"},
+		{"(getdate())", "datetime", "(CURRENT_TIMESTAMP)"},
+		{"('getdate()')", "varchar", "('getdate()')"},
+	}
+	for _, tt := range tests {
+		if got := Postgres.TranslateDefault(tt.expression, tt.dataType); got != tt.want {
+			t.Errorf("TranslateDefault(%q, %s) = %q, want %q", tt.expression, tt.dataType, got, tt.want)
+		}
+	}
+}
+
+func TestTSQLTranslateUnchanged(t *testing.T) {
+	if got := TSQL.TranslateExpression("([Active]=(1))", []string{"Active"}); got != "([Active]=(1))" {
+		t.Errorf("TranslateExpression = %q", got)
+	}
+	if got := TSQL.TranslateDefault("((1))", "bit"); got != "((1))" {
+		t.Errorf("TranslateDefault = %q", got)
+	}
+}
@@ -2,7 +2,11 @@ package domain
 
 import (
 	"fmt"
+	"io"
+	"sort"
 	"strings"
+
+	"github.com/enunezf/SQLPulse/internal/color"
 )
 
 // DiffType represents the type of difference found
@@ -18,6 +22,7 @@ const (
 type DiffCategory string
 
 const (
+	DiffCategoryDatabase   DiffCategory = "DATABASE"
 	DiffCategorySchema     DiffCategory = "SCHEMA"
 	DiffCategoryTable      DiffCategory = "TABLE"
 	DiffCategoryColumn     DiffCategory = "COLUMN"
@@ -28,18 +33,119 @@ const (
 	DiffCategoryProcedure  DiffCategory = "PROCEDURE"
 	DiffCategoryFunction   DiffCategory = "FUNCTION"
 	DiffCategoryTrigger    DiffCategory = "TRIGGER"
+	DiffCategoryPermission DiffCategory = "PERMISSION"
 )
 
+// categoryOrder is the order in which categories are reported and migrated
+var categoryOrder = []DiffCategory{
+	DiffCategoryDatabase,
+	DiffCategorySchema,
+	DiffCategoryTable,
+	DiffCategoryColumn,
+	DiffCategoryIndex,
+	DiffCategoryForeignKey,
+	DiffCategoryConstraint,
+	DiffCategoryView,
+	DiffCategoryProcedure,
+	DiffCategoryFunction,
+	DiffCategoryTrigger,
+	DiffCategoryPermission,
+}
+
 // Difference represents a single difference between source and target
 type Difference struct {
-	Type        DiffType
-	Category    DiffCategory
-	ObjectName  string // Full object name (e.g., "dbo.Users")
-	PropertyName string // Property that differs (e.g., "DataType", "MaxLength")
-	SourceValue string // Value in source database
-	TargetValue string // Value in target database
-	Description string // Human-readable description
-	MigrationSQL string // SQL to apply the change (from source to target)
+	Type         DiffType
+	Category     DiffCategory
+	ObjectName   string         // Full object name (e.g., "dbo.Users")
+	PropertyName string         // Property that differs (e.g., "DataType", "MaxLength")
+	SourceValue  string         // Value in source database
+	TargetValue  string         // Value in target database
+	Description  string         // Human-readable description
+	Detail       string         // Optional multi-line detail (e.g. unified diff of definitions)
+	MigrationSQL string         // SQL to apply the change (from source to target)
+	Risk         Risk           // Approval needed to apply MigrationSQL
+	Phase        MigrationPhase // Whether MigrationSQL drops, alters or creates an object
+	Order        int            // Position of MigrationSQL in the migration, lowest first
+}
+
+// Risk is how much approval applying a difference's migration SQL needs.
+// The zero value is unclassified and treated as destructive, so differences
+// built without a classification are never applied on a simple confirmation.
+type Risk int
+
+const (
+	RiskUnknown      Risk = iota // Not classified
+	RiskModification             // Creates or alters objects without losing data
+	RiskDestructive              // Drops objects or can lose data
+)
+
+// String returns the name of the risk
+func (r Risk) String() string {
+	switch r {
+	case RiskModification:
+		return "Modification"
+	case RiskDestructive:
+		return "Destructive"
+	default:
+		return "Unknown"
+	}
+}
+
+// IsDestructive reports whether applying the change needs destructive
+// approval, which unclassified changes do
+func (r Risk) IsDestructive() bool {
+	return r != RiskModification
+}
+
+// MigrationPhase is the kind of statement a difference's migration runs
+type MigrationPhase int
+
+const (
+	PhaseDrop   MigrationPhase = iota + 1 // Drops an object that exists only in the target
+	PhaseAlter                            // Changes an object that exists in both
+	PhaseCreate                           // Creates an object that exists only in the source
+)
+
+// String returns the phase name
+func (p MigrationPhase) String() string {
+	switch p {
+	case PhaseDrop:
+		return "DROP"
+	case PhaseAlter:
+		return "ALTER"
+	case PhaseCreate:
+		return "CREATE"
+	}
+	return ""
+}
+
+// SetMigrationOrder sets the phase and order of the difference from its type
+// and category. Drops run first, dependents before what they depend on
+// (foreign keys before tables, tables before schemas). Changes and creations
+// follow in category order, so that a view altered to use a new column runs
+// after the column is added; within a category, changes precede creations.
+func (d *Difference) SetMigrationOrder() {
+	switch d.Type {
+	case DiffAdded:
+		d.Phase = PhaseDrop
+	case DiffRemoved:
+		d.Phase = PhaseCreate
+	default:
+		d.Phase = PhaseAlter
+	}
+
+	position := len(categoryOrder)
+	for i, cat := range categoryOrder {
+		if cat == d.Category {
+			position = i
+			break
+		}
+	}
+	if d.Phase == PhaseDrop {
+		d.Order = len(categoryOrder) - position
+	} else {
+		d.Order = len(categoryOrder) + 1 + 2*position + int(d.Phase-PhaseAlter)
+	}
 }
 
 // String returns a git-diff style representation
@@ -47,22 +153,33 @@ func (d *Difference) String() string {
 	var prefix string
 	switch d.Type {
 	case DiffAdded:
-		prefix = "\033[32m+\033[0m" // Green +
+		prefix = color.Green("+")
 	case DiffRemoved:
-		prefix = "\033[31m-\033[0m" // Red -
+		prefix = color.Red("-")
 	case DiffModified:
-		prefix = "\033[33m~\033[0m" // Yellow ~
+		prefix = color.Yellow("~")
 	}
 
 	return fmt.Sprintf("%s [%s] %s: %s", prefix, d.Category, d.ObjectName, d.Description)
 }
 
+// ModuleChange reports whether a view, procedure, function or trigger
+// changed between two databases, for checks that only need to know whether
+// any code changed
+type ModuleChange struct {
+	Category DiffCategory
+	Name     string
+	Changed  bool // Definition differs or the module exists on one side only
+}
+
 // DiffResult contains all differences between two databases
 type DiffResult struct {
 	SourceDatabase string
 	TargetDatabase string
 	Differences    []Difference
 	Summary        DiffSummary
+	Truncated      bool // Differences past DiffOptions.MaxDifferences were left out
+	Omitted        int  // Differences found past the cap and left out
 }
 
 // DiffSummary provides a summary count of differences
@@ -72,6 +189,20 @@ type DiffSummary struct {
 	Removed          int
 	Modified         int
 	ByCategory       map[DiffCategory]int
+	ByCategoryType   map[DiffCategory]map[DiffType]int // Added/Removed/Modified counts per category
+	Truncated        bool                              // Only the first differences were kept, see DiffResult.Truncated
+	Omitted          int                               // Differences left out of the counts above
+}
+
+// Categories returns the categories that have differences, in report order
+func (s *DiffSummary) Categories() []DiffCategory {
+	var cats []DiffCategory
+	for _, cat := range categoryOrder {
+		if s.ByCategory[cat] > 0 {
+			cats = append(cats, cat)
+		}
+	}
+	return cats
 }
 
 // HasDifferences returns true if there are any differences
@@ -101,6 +232,69 @@ func (r *DiffResult) FilterByCategory(category DiffCategory) []Difference {
 	return filtered
 }
 
+// Filter returns the differences matching any of the given types and any of
+// the given categories, preserving their order. An empty list matches all.
+func (r *DiffResult) Filter(types []DiffType, categories []DiffCategory) []Difference {
+	filtered := []Difference{}
+	for _, d := range r.Differences {
+		if d.Matches(types, categories) {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
+
+// Matches reports whether the difference has one of the given types and
+// categories; an empty list matches everything
+func (d *Difference) Matches(types []DiffType, categories []DiffCategory) bool {
+	if len(types) > 0 && !containsDiffType(types, d.Type) {
+		return false
+	}
+	if len(categories) > 0 && !containsDiffCategory(categories, d.Category) {
+		return false
+	}
+	return true
+}
+
+func containsDiffType(types []DiffType, t DiffType) bool {
+	for _, candidate := range types {
+		if candidate == t {
+			return true
+		}
+	}
+	return false
+}
+
+func containsDiffCategory(categories []DiffCategory, c DiffCategory) bool {
+	for _, candidate := range categories {
+		if candidate == c {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseDiffType parses a difference type name such as "added" (case-insensitive)
+func ParseDiffType(s string) (DiffType, error) {
+	t := DiffType(strings.ToUpper(strings.TrimSpace(s)))
+	switch t {
+	case DiffAdded, DiffRemoved, DiffModified:
+		return t, nil
+	}
+	return "", fmt.Errorf("unknown difference type %q (expected added, removed, or modified)", s)
+}
+
+// ParseDiffCategory parses a category name such as "table" or "foreign-key" (case-insensitive)
+func ParseDiffCategory(s string) (DiffCategory, error) {
+	c := DiffCategory(strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(s), "-", "_")))
+	for _, known := range categoryOrder {
+		if c == known {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("unknown difference category %q", s)
+}
+
 // GenerateMigrationScript generates SQL to migrate from source to target
 func (r *DiffResult) GenerateMigrationScript() string {
 	var sb strings.Builder
@@ -111,72 +305,221 @@ func (r *DiffResult) GenerateMigrationScript() string {
 	sb.WriteString(fmt.Sprintf("-- To:   %s\n", r.TargetDatabase))
 	sb.WriteString("-- ============================================\n\n")
 
-	// Group by category for organized output
-	categories := []DiffCategory{
-		DiffCategorySchema,
-		DiffCategoryTable,
-		DiffCategoryColumn,
-		DiffCategoryIndex,
-		DiffCategoryForeignKey,
-		DiffCategoryConstraint,
-		DiffCategoryView,
-		DiffCategoryProcedure,
-		DiffCategoryFunction,
-		DiffCategoryTrigger,
-	}
-
-	for _, cat := range categories {
+	// A section starts whenever the category or drop phase changes
+	var section string
+	for _, d := range r.MigrationSteps() {
+		title := fmt.Sprintf("%s Changes", d.Category)
+		if d.Phase == PhaseDrop {
+			title = fmt.Sprintf("%s Drops", d.Category)
+		}
+		if title != section {
+			section = title
+			sb.WriteString(fmt.Sprintf("-- %s\n", title))
+			sb.WriteString("-- " + strings.Repeat("-", 40) + "\n\n")
+		}
+
+		sb.WriteString(fmt.Sprintf("-- %s\n", d.Description))
+		if d.Risk.IsDestructive() {
+			sb.WriteString("-- WARNING: potential data loss\n")
+		}
+		sb.WriteString(d.MigrationSQL)
+		sb.WriteString("\nGO\n\n")
+	}
+
+	return sb.String()
+}
+
+// MigrationSteps returns the differences that carry migration SQL, in the
+// order they are applied by GenerateMigrationScript: a stable sort on their
+// Order, so differences with the same order keep the comparator's order
+func (r *DiffResult) MigrationSteps() []Difference {
+	var steps []Difference
+	for _, d := range r.Differences {
+		if d.MigrationSQL == "" {
+			continue
+		}
+		if d.Phase == 0 {
+			d.SetMigrationOrder()
+		}
+		steps = append(steps, d)
+	}
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].Order < steps[j].Order
+	})
+	return steps
+}
+
+// PrintGitStyle prints differences in git-diff style
+func (r *DiffResult) PrintGitStyle() string {
+	var sb strings.Builder
+
+	gw := NewGitStyleWriter(&sb, r.SourceDatabase, r.TargetDatabase)
+	gw.WriteHeader()
+	for _, d := range r.Differences {
+		gw.Write(d)
+	}
+	if r.Truncated {
+		sb.WriteString("\n" + TruncationNote(r.Omitted) + "\n")
+	}
+
+	return sb.String()
+}
+
+// GitStyleWriter writes differences in git-diff style one at a time, so they
+// can be printed as the comparator produces them
+type GitStyleWriter struct {
+	w               io.Writer
+	sourceDatabase  string
+	targetDatabase  string
+	headerWritten   bool
+	currentCategory DiffCategory
+}
+
+// NewGitStyleWriter creates a writer for differences between the two databases
+func NewGitStyleWriter(w io.Writer, sourceDatabase, targetDatabase string) *GitStyleWriter {
+	return &GitStyleWriter{w: w, sourceDatabase: sourceDatabase, targetDatabase: targetDatabase}
+}
+
+// WriteHeader writes the diff header if it has not been written yet
+func (g *GitStyleWriter) WriteHeader() {
+	if g.headerWritten {
+		return
+	}
+	g.headerWritten = true
+	fmt.Fprintf(g.w, "diff --sqlpulse a/%s b/%s\n", g.sourceDatabase, g.targetDatabase)
+	fmt.Fprintf(g.w, "--- a/%s\n", g.sourceDatabase)
+	fmt.Fprintf(g.w, "+++ b/%s\n", g.targetDatabase)
+	fmt.Fprintln(g.w)
+}
+
+// Write writes one difference, starting a new category section when the category changes
+func (g *GitStyleWriter) Write(d Difference) {
+	g.WriteHeader()
+	if d.Category != g.currentCategory {
+		g.currentCategory = d.Category
+		fmt.Fprintf(g.w, "\n@@ %s @@\n", g.currentCategory)
+	}
+	fmt.Fprintln(g.w, d.String())
+	if d.Detail != "" {
+		fmt.Fprint(g.w, formatDetail(d.Detail))
+	}
+}
+
+// ToMarkdown renders the differences as a Markdown report for human review
+func (r *DiffResult) ToMarkdown() string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# Schema Diff: %s → %s\n\n", r.SourceDatabase, r.TargetDatabase))
+
+	if !r.HasDifferences() && !r.Truncated {
+		sb.WriteString("No differences found. Schemas are identical.\n")
+		return sb.String()
+	}
+
+	// Summary table
+	sb.WriteString("## Summary\n\n")
+	sb.WriteString("| Category | Added | Removed | Modified | Total |\n")
+	sb.WriteString("|----------|------:|--------:|---------:|------:|\n")
+
+	var totalAdded, totalRemoved, totalModified int
+	for _, cat := range categoryOrder {
 		diffs := r.FilterByCategory(cat)
 		if len(diffs) == 0 {
 			continue
 		}
+		var added, removed, modified int
+		for _, d := range diffs {
+			switch d.Type {
+			case DiffAdded:
+				added++
+			case DiffRemoved:
+				removed++
+			case DiffModified:
+				modified++
+			}
+		}
+		totalAdded += added
+		totalRemoved += removed
+		totalModified += modified
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d |\n", cat, added, removed, modified, len(diffs)))
+	}
+	sb.WriteString(fmt.Sprintf("| **Total** | **%d** | **%d** | **%d** | **%d** |\n\n",
+		totalAdded, totalRemoved, totalModified, len(r.Differences)))
+	if r.Truncated {
+		sb.WriteString(TruncationNote(r.Omitted) + "\n\n")
+	}
 
-		sb.WriteString(fmt.Sprintf("-- %s Changes\n", cat))
-		sb.WriteString("-- " + strings.Repeat("-", 40) + "\n\n")
+	// Per-category details
+	for _, cat := range categoryOrder {
+		diffs := r.FilterByCategory(cat)
+		if len(diffs) == 0 {
+			continue
+		}
 
+		sb.WriteString(fmt.Sprintf("## %s\n\n", cat))
 		for _, d := range diffs {
-			if d.MigrationSQL != "" {
-				sb.WriteString(fmt.Sprintf("-- %s\n", d.Description))
-				sb.WriteString(d.MigrationSQL)
-				sb.WriteString("\nGO\n\n")
-			}
+			sb.WriteString(d.markdownLine())
+			sb.WriteString("\n")
 		}
+		sb.WriteString("\n")
 	}
 
 	return sb.String()
 }
 
-// PrintGitStyle prints differences in git-diff style
-func (r *DiffResult) PrintGitStyle() string {
-	var sb strings.Builder
+// markdownLine renders a difference as a Markdown bullet
+func (d *Difference) markdownLine() string {
+	var label string
+	switch d.Type {
+	case DiffAdded:
+		label = "Added (target only)"
+	case DiffRemoved:
+		label = "Removed (source only)"
+	case DiffModified:
+		label = "Modified"
+	}
 
-	sb.WriteString(fmt.Sprintf("diff --sqlpulse a/%s b/%s\n", r.SourceDatabase, r.TargetDatabase))
-	sb.WriteString("--- a/" + r.SourceDatabase + "\n")
-	sb.WriteString("+++ b/" + r.TargetDatabase + "\n")
-	sb.WriteString("\n")
+	if d.Type == DiffModified && d.PropertyName != "" && !d.isDefinitionDiff() && (d.SourceValue != "" || d.TargetValue != "") {
+		return fmt.Sprintf("- **%s** `%s` %s: `%s` → `%s`",
+			label, d.ObjectName, d.PropertyName, d.SourceValue, d.TargetValue)
+	}
+	return fmt.Sprintf("- **%s** `%s`: %s", label, d.ObjectName, d.Description)
+}
 
-	// Group by category
-	currentCategory := DiffCategory("")
-	for _, d := range r.Differences {
-		if d.Category != currentCategory {
-			currentCategory = d.Category
-			sb.WriteString(fmt.Sprintf("\n@@ %s @@\n", currentCategory))
+// formatDetail indents a unified diff detail and colors its lines
+func formatDetail(detail string) string {
+	var sb strings.Builder
+	for _, line := range strings.Split(detail, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			sb.WriteString("    " + color.Cyan(line) + "\n")
+		case strings.HasPrefix(line, "+"):
+			sb.WriteString("    " + color.Green(line) + "\n")
+		case strings.HasPrefix(line, "-"):
+			sb.WriteString("    " + color.Red(line) + "\n")
+		default:
+			sb.WriteString("    " + line + "\n")
 		}
-		sb.WriteString(d.String() + "\n")
 	}
-
 	return sb.String()
 }
 
 // CalculateSummary calculates the summary statistics
 func (r *DiffResult) CalculateSummary() {
 	r.Summary = DiffSummary{
-		ByCategory: make(map[DiffCategory]int),
+		ByCategory:     make(map[DiffCategory]int),
+		ByCategoryType: make(map[DiffCategory]map[DiffType]int),
+		Truncated:      r.Truncated,
+		Omitted:        r.Omitted,
 	}
 
 	for _, d := range r.Differences {
 		r.Summary.TotalDifferences++
 		r.Summary.ByCategory[d.Category]++
+		if r.Summary.ByCategoryType[d.Category] == nil {
+			r.Summary.ByCategoryType[d.Category] = make(map[DiffType]int)
+		}
+		r.Summary.ByCategoryType[d.Category][d.Type]++
 
 		switch d.Type {
 		case DiffAdded:
@@ -189,25 +532,49 @@ func (r *DiffResult) CalculateSummary() {
 	}
 }
 
+// TruncationNote returns the line noting the differences left out when a
+// comparison reached DiffOptions.MaxDifferences, or "" when none were
+func TruncationNote(omitted int) string {
+	if omitted == 0 {
+		return ""
+	}
+	return fmt.Sprintf("... and %d more differences (output limited by --max-differences)", omitted)
+}
+
 // DiffOptions configures the comparison behavior
 type DiffOptions struct {
-	IncludeTables      bool
-	IncludeViews       bool
-	IncludeProcedures  bool
-	IncludeFunctions   bool
-	IncludeTriggers    bool
-	IncludeIndexes     bool
-	IncludeForeignKeys bool
-	IncludeConstraints bool
-	SchemaFilter       []string
-	TableFilter        []string
-	IgnoreCollation    bool
-	IgnoreWhitespace   bool // For procedure/view definitions
+	IncludeSchemas       bool
+	IncludeTables        bool
+	IncludeViews         bool
+	IncludeProcedures    bool
+	IncludeFunctions     bool
+	IncludeTriggers      bool
+	IncludeIndexes       bool
+	IncludeForeignKeys   bool
+	IncludeConstraints   bool
+	SchemaFilter         []string
+	TableFilter          []string
+	SchemaExclude        []string
+	TableExclude         []string
+	IgnoreCollation      bool
+	IgnoreWhitespace     bool   // For procedure/view definitions
+	CaseInsensitiveNames bool   // Match object names regardless of case
+	IncludePermissions   bool   // Compare GRANT/DENY permissions
+	IgnoreOwners         bool   // Skip schema AUTHORIZATION differences
+	IgnoreIdentity       bool   // Skip column IDENTITY differences
+	IgnoreNullability    bool   // Skip column NULL/NOT NULL differences
+	IgnoreComputed       bool   // Skip computed column expression differences
+	IgnoreDefaults       bool   // Skip column default value differences
+	NoDrop               bool   // Report target-only objects without migration SQL that drops them
+	GuardedDrops         bool   // Wrap generated DROP statements in IF EXISTS checks
+	MaxDifferences       int    // Keep only this many differences and count the rest; 0 means no limit
+	TargetVersion        string // Product version of the target server; migration SQL omits newer syntax. Empty means the latest
 }
 
 // DefaultDiffOptions returns default comparison options
 func DefaultDiffOptions() *DiffOptions {
 	return &DiffOptions{
+		IncludeSchemas:     true,
 		IncludeTables:      true,
 		IncludeViews:       true,
 		IncludeProcedures:  true,
@@ -220,3 +587,41 @@ func DefaultDiffOptions() *DiffOptions {
 		IgnoreWhitespace:   true,
 	}
 }
+
+// DumpOptions returns the extraction options needed to compare with these options
+func (o *DiffOptions) DumpOptions() *DumpOptions {
+	return &DumpOptions{
+		IncludeTables:      o.IncludeTables,
+		IncludeViews:       o.IncludeViews,
+		IncludeProcedures:  o.IncludeProcedures,
+		IncludeFunctions:   o.IncludeFunctions,
+		IncludeTriggers:    o.IncludeTriggers,
+		IncludeIndexes:     o.IncludeIndexes,
+		IncludeForeignKeys: o.IncludeForeignKeys,
+		IncludeConstraints: o.IncludeConstraints,
+		IncludeFileGroups:  true,
+		IncludePermissions: o.IncludePermissions,
+		SchemaFilter:       o.SchemaFilter,
+		TableFilter:        o.TableFilter,
+		SchemaExclude:      o.SchemaExclude,
+		TableExclude:       o.TableExclude,
+		OutputFormat:       "sql",
+	}
+}
+
+// IsCaseInsensitiveCollation reports whether a SQL Server collation name
+// (e.g. SQL_Latin1_General_CP1_CI_AS) compares identifiers case-insensitively
+func IsCaseInsensitiveCollation(collation string) bool {
+	upper := strings.ToUpper(collation)
+	return strings.Contains(upper, "_CI_") || strings.HasSuffix(upper, "_CI")
+}
+
+// CollationNameKey returns the function mapping an object name to the key
+// identifying it under the collation: lowercased when the collation is
+// case-insensitive, unchanged otherwise
+func CollationNameKey(collation string) func(string) string {
+	if IsCaseInsensitiveCollation(collation) {
+		return strings.ToLower
+	}
+	return func(name string) string { return name }
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+)
+
+// IsGlobPattern reports whether a filter entry contains * or ? wildcards
+func IsGlobPattern(s string) bool {
+	return strings.ContainsAny(s, "*?")
+}
+
+// MatchGlob reports whether name matches a filter entry, where * matches any
+// run of characters and ? a single character. Matching is case-insensitive,
+// as with the default SQL Server collation. Entries without wildcards match
+// the whole name.
+func MatchGlob(pattern, name string) bool {
+	if !IsGlobPattern(pattern) {
+		return strings.EqualFold(pattern, name)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("(?is)^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+
+	return regexp.MustCompile(sb.String()).MatchString(name)
+}
+
+// MatchObject reports whether schemaName.name is one of the named objects.
+// Entries may be bracket-quoted; unqualified entries resolve to the dbo
+// schema, as OBJECT_ID does. An empty list matches everything.
+func MatchObject(objects []string, schemaName, name string) bool {
+	if len(objects) == 0 {
+		return true
+	}
+	for _, object := range objects {
+		objSchema, objName := SplitObjectName(object)
+		if strings.EqualFold(objSchema, schemaName) && strings.EqualFold(objName, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitObjectName splits a possibly bracket-quoted schema.name into its
+// parts. The schema defaults to dbo.
+func SplitObjectName(object string) (schemaName, name string) {
+	var parts []string
+	var sb strings.Builder
+	quoted := false
+	runes := []rune(strings.TrimSpace(object))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '[' && !quoted:
+			quoted = true
+		case r == ']' && quoted:
+			if i+1 < len(runes) && runes[i+1] == ']' {
+				sb.WriteRune(']')
+				i++
+			} else {
+				quoted = false
+			}
+		case r == '.' && !quoted:
+			parts = append(parts, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	parts = append(parts, sb.String())
+
+	if len(parts) == 1 {
+		return "dbo", parts[0]
+	}
+	return parts[len(parts)-2], parts[len(parts)-1]
+}
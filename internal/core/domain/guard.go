@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GuardCreate wraps the statements that create an object so they only run
+// when exists, a condition returned by an ExistsSQL method, is false. SQL
+// Server has no CREATE ... IF NOT EXISTS, so this makes scripts re-runnable.
+func GuardCreate(exists, sql string) string {
+	return fmt.Sprintf("IF NOT %s\nBEGIN\n%s;\nEND", exists, sql)
+}
+
+// GuardDrop wraps a drop statement so it only runs when exists is true
+func GuardDrop(exists, sql string) string {
+	return fmt.Sprintf("IF %s\n    %s", exists, sql)
+}
+
+// ObjectExistsSQL returns the condition that the schema-qualified object
+// exists, optionally restricted to the given sys.objects types
+func ObjectExistsSQL(qualifiedName string, types ...string) string {
+	condition := fmt.Sprintf("object_id = OBJECT_ID(%s)", quoteString(qualifiedName))
+	if len(types) > 0 {
+		quoted := make([]string, len(types))
+		for i, t := range types {
+			quoted[i] = quoteString(t)
+		}
+		condition += fmt.Sprintf(" AND type IN (%s)", strings.Join(quoted, ", "))
+	}
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM sys.objects WHERE %s)", condition)
+}
+
+// ConstraintExistsSQL returns the condition that the schema-qualified table
+// has a constraint with the given name
+func ConstraintExistsSQL(table, name string) string {
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM sys.objects WHERE parent_object_id = OBJECT_ID(%s) AND name = %s)",
+		quoteString(table), quoteString(name))
+}
+
+// ColumnExistsSQL returns the condition that the schema-qualified table has
+// a column with the given name
+func ColumnExistsSQL(table, column string) string {
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM sys.columns WHERE object_id = OBJECT_ID(%s) AND name = %s)",
+		quoteString(table), quoteString(column))
+}
+
+// FileGroupExistsSQL returns the condition that the database has the filegroup
+func FileGroupExistsSQL(name string) string {
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM sys.filegroups WHERE name = %s)", quoteString(name))
+}
+
+// AddFileGroupSQL generates the ALTER DATABASE statement adding the filegroup
+// when the database does not have it yet. The filegroup gets no files: their
+// path and size depend on the server, so adding them is left to the DBA.
+func AddFileGroupSQL(name string) string {
+	return GuardCreate(FileGroupExistsSQL(name), fmt.Sprintf("ALTER DATABASE CURRENT ADD FILEGROUP %s", QuoteIdent(name)))
+}
+
+// quoteString returns s as a Unicode string literal
+func quoteString(s string) string {
+	return "N'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// qualifiedName returns the quoted schema-qualified name of an object
+func qualifiedName(schemaName, name string) string {
+	return QuoteIdent(schemaName) + "." + QuoteIdent(name)
+}
+
+// ExistsSQL returns the condition that the schema exists
+func (s *Schema) ExistsSQL() string {
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM sys.schemas WHERE name = %s)", quoteString(s.Name))
+}
+
+// GenerateGuardedSQL generates the CREATE SCHEMA statement guarded by an
+// existence check. CREATE SCHEMA must be alone in its batch, so it runs
+// through EXEC.
+func (s *Schema) GenerateGuardedSQL() string {
+	return GuardCreate(s.ExistsSQL(), fmt.Sprintf("EXEC(%s)", quoteString(s.GenerateSQL())))
+}
+
+// ExistsSQL returns the condition that the partition function exists
+func (pf *PartitionFunction) ExistsSQL() string {
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM sys.partition_functions WHERE name = %s)", quoteString(pf.Name))
+}
+
+// ExistsSQL returns the condition that the partition scheme exists
+func (ps *PartitionScheme) ExistsSQL() string {
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM sys.partition_schemes WHERE name = %s)", quoteString(ps.Name))
+}
+
+// ExistsSQL returns the condition that the table exists
+func (t *Table) ExistsSQL() string {
+	return ObjectExistsSQL(qualifiedName(t.SchemaName, t.Name), "U")
+}
+
+// ExistsSQL returns the condition that the index exists on its table
+func (i *Index) ExistsSQL() string {
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM sys.indexes WHERE object_id = OBJECT_ID(%s) AND name = %s)",
+		quoteString(qualifiedName(i.SchemaName, i.TableName)), quoteString(i.Name))
+}
+
+// ExistsSQL returns the condition that the statistics exist on their table
+func (st *Statistic) ExistsSQL() string {
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM sys.stats WHERE object_id = OBJECT_ID(%s) AND name = %s)",
+		quoteString(qualifiedName(st.SchemaName, st.TableName)), quoteString(st.Name))
+}
+
+// ExistsSQL returns the condition that the foreign key exists on its table
+func (fk *ForeignKey) ExistsSQL() string {
+	return ConstraintExistsSQL(qualifiedName(fk.SchemaName, fk.TableName), fk.Name)
+}
+
+// ExistsSQL returns the condition that the check constraint exists on its table
+func (cc *CheckConstraint) ExistsSQL() string {
+	return ConstraintExistsSQL(qualifiedName(cc.SchemaName, cc.TableName), cc.Name)
+}
+
+// ExistsSQL returns the condition that the unique constraint exists on its table
+func (uc *UniqueConstraint) ExistsSQL() string {
+	return ConstraintExistsSQL(qualifiedName(uc.SchemaName, uc.TableName), uc.Name)
+}
@@ -0,0 +1,168 @@
+package domain
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// htmlStyle is the embedded stylesheet of the HTML report
+const htmlStyle = `
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292f; }
+h1 { font-size: 1.6em; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #d0d7de; padding: 4px 10px; text-align: left; }
+td.num { text-align: right; }
+details { margin: 0.6em 0; }
+summary { cursor: pointer; font-weight: 600; }
+ul { margin: 0.4em 0; }
+.added { color: #1a7f37; }
+.removed { color: #cf222e; }
+.modified { color: #9a6700; }
+table.sbs { width: 100%; table-layout: fixed; font-family: SFMono-Regular, Consolas, monospace; font-size: 12px; }
+table.sbs td { border: none; padding: 0 6px; white-space: pre-wrap; word-break: break-all; vertical-align: top; }
+table.sbs td.ln { width: 3em; color: #6e7781; text-align: right; }
+table.sbs td.del { background: #ffebe9; }
+table.sbs td.ins { background: #e6ffec; }
+`
+
+// ToHTML renders the differences as a self-contained HTML report. Modified
+// module definitions are shown as a side-by-side line comparison.
+func (r *DiffResult) ToHTML() string {
+	var sb strings.Builder
+
+	title := fmt.Sprintf("Schema Diff: %s → %s", r.SourceDatabase, r.TargetDatabase)
+
+	sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	sb.WriteString(fmt.Sprintf("<title>%s</title>\n", html.EscapeString(title)))
+	sb.WriteString("<style>" + htmlStyle + "</style>\n</head>\n<body>\n")
+	sb.WriteString(fmt.Sprintf("<h1>%s</h1>\n", html.EscapeString(title)))
+
+	if !r.HasDifferences() && !r.Truncated {
+		sb.WriteString("<p>No differences found. Schemas are identical.</p>\n</body>\n</html>\n")
+		return sb.String()
+	}
+
+	// Summary table
+	sb.WriteString("<table>\n<tr><th>Category</th><th>Added</th><th>Removed</th><th>Modified</th><th>Total</th></tr>\n")
+	for _, cat := range categoryOrder {
+		diffs := r.FilterByCategory(cat)
+		if len(diffs) == 0 {
+			continue
+		}
+		var added, removed, modified int
+		for _, d := range diffs {
+			switch d.Type {
+			case DiffAdded:
+				added++
+			case DiffRemoved:
+				removed++
+			case DiffModified:
+				modified++
+			}
+		}
+		sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td class=\"num\">%d</td><td class=\"num\">%d</td><td class=\"num\">%d</td><td class=\"num\">%d</td></tr>\n",
+			cat, added, removed, modified, len(diffs)))
+	}
+	sb.WriteString("</table>\n")
+	if r.Truncated {
+		sb.WriteString(fmt.Sprintf("<p>%s</p>\n", html.EscapeString(TruncationNote(r.Omitted))))
+	}
+
+	// Collapsible per-category sections
+	for _, cat := range categoryOrder {
+		diffs := r.FilterByCategory(cat)
+		if len(diffs) == 0 {
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf("<details open>\n<summary>%s (%d)</summary>\n<ul>\n", cat, len(diffs)))
+		for _, d := range diffs {
+			sb.WriteString(d.htmlItem())
+		}
+		sb.WriteString("</ul>\n</details>\n")
+	}
+
+	sb.WriteString("</body>\n</html>\n")
+	return sb.String()
+}
+
+// htmlItem renders a difference as an HTML list item
+func (d *Difference) htmlItem() string {
+	var sb strings.Builder
+
+	class := strings.ToLower(string(d.Type))
+	sb.WriteString(fmt.Sprintf("<li><span class=\"%s\">%s</span> <code>%s</code>: %s",
+		class, d.Type, html.EscapeString(d.ObjectName), html.EscapeString(d.Description)))
+
+	switch {
+	case d.isDefinitionDiff():
+		sb.WriteString("\n<details>\n<summary>Show definitions</summary>\n")
+		sb.WriteString(sideBySideHTML(d.SourceValue, d.TargetValue))
+		sb.WriteString("</details>\n")
+	case d.Type == DiffModified && (d.SourceValue != "" || d.TargetValue != ""):
+		sb.WriteString(fmt.Sprintf(" (<code>%s</code> → <code>%s</code>)",
+			html.EscapeString(d.SourceValue), html.EscapeString(d.TargetValue)))
+	}
+
+	sb.WriteString("</li>\n")
+	return sb.String()
+}
+
+// isDefinitionDiff reports whether the difference carries two module definitions
+func (d *Difference) isDefinitionDiff() bool {
+	return d.Type == DiffModified && d.PropertyName == "Definition"
+}
+
+// sideBySideHTML renders a side-by-side line comparison of two definitions
+func sideBySideHTML(source, target string) string {
+	var sb strings.Builder
+
+	sb.WriteString("<table class=\"sbs\">\n")
+
+	changes := DiffLines(SplitLines(source), SplitLines(target))
+	srcLine, tgtLine := 0, 0
+
+	for i := 0; i < len(changes); {
+		if changes[i].Op == LineEqual {
+			srcLine++
+			tgtLine++
+			text := html.EscapeString(changes[i].Text)
+			sb.WriteString(fmt.Sprintf("<tr><td class=\"ln\">%d</td><td>%s</td><td class=\"ln\">%d</td><td>%s</td></tr>\n",
+				srcLine, text, tgtLine, text))
+			i++
+			continue
+		}
+
+		// Pair a run of deletions with the following run of insertions
+		var deleted, inserted []string
+		for i < len(changes) && changes[i].Op == LineDelete {
+			deleted = append(deleted, changes[i].Text)
+			i++
+		}
+		for i < len(changes) && changes[i].Op == LineInsert {
+			inserted = append(inserted, changes[i].Text)
+			i++
+		}
+
+		for k := 0; k < len(deleted) || k < len(inserted); k++ {
+			sb.WriteString("<tr>")
+			if k < len(deleted) {
+				srcLine++
+				sb.WriteString(fmt.Sprintf("<td class=\"ln\">%d</td><td class=\"del\">%s</td>", srcLine, html.EscapeString(deleted[k])))
+			} else {
+				sb.WriteString("<td class=\"ln\"></td><td></td>")
+			}
+			if k < len(inserted) {
+				tgtLine++
+				sb.WriteString(fmt.Sprintf("<td class=\"ln\">%d</td><td class=\"ins\">%s</td>", tgtLine, html.EscapeString(inserted[k])))
+			} else {
+				sb.WriteString("<td class=\"ln\"></td><td></td>")
+			}
+			sb.WriteString("</tr>\n")
+		}
+	}
+
+	sb.WriteString("</table>\n")
+	return sb.String()
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite groups the test cases of one category
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a passing category or a difference
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+// junitSkipped marks a category that may not have been fully compared
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// junitFailure describes a difference reported as a failed test case
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",cdata"`
+}
+
+// ToJUnit renders the differences as a JUnit XML report, with a test suite
+// per category. A category without differences is a single passing test
+// case; otherwise each difference is a failed test case with its description
+// as the message. When differences were left out at the limit, categories
+// without differences are skipped instead, as theirs may have been left out.
+func (r *DiffResult) ToJUnit() string {
+	report := junitTestSuites{
+		Name: fmt.Sprintf("Schema Diff: %s → %s", r.SourceDatabase, r.TargetDatabase),
+	}
+
+	for _, cat := range categoryOrder {
+		suite := junitTestSuite{Name: string(cat)}
+		className := "sqlpulse." + strings.ToLower(string(cat))
+
+		diffs := r.FilterByCategory(cat)
+		if len(diffs) == 0 {
+			tc := junitTestCase{Name: fmt.Sprintf("%s matches", cat), ClassName: className}
+			if r.Truncated {
+				tc.Skipped = &junitSkipped{Message: TruncationNote(r.Omitted)}
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		for _, d := range diffs {
+			name := d.ObjectName
+			if d.PropertyName != "" {
+				name += " " + d.PropertyName
+			}
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      name,
+				ClassName: className,
+				Failure:   &junitFailure{Message: d.Description, Type: string(d.Type), Text: d.junitText()},
+			})
+			suite.Failures++
+		}
+
+		suite.Tests = len(suite.Cases)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Suites = append(report.Suites, suite)
+	}
+
+	// The report only holds strings and numbers, which always marshal
+	out, _ := xml.MarshalIndent(report, "", "  ")
+	return xml.Header + string(out) + "\n"
+}
+
+// junitText returns the body of the failure of a difference: the values that
+// differ, the detail and the migration statement
+func (d *Difference) junitText() string {
+	var parts []string
+	if d.SourceValue != "" || d.TargetValue != "" {
+		parts = append(parts, fmt.Sprintf("Source: %s\nTarget: %s", d.SourceValue, d.TargetValue))
+	}
+	if d.Detail != "" {
+		parts = append(parts, d.Detail)
+	}
+	if d.MigrationSQL != "" {
+		parts = append(parts, d.MigrationSQL)
+	}
+	return strings.Join(parts, "\n\n")
+}
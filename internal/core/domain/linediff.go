@@ -0,0 +1,189 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LineOp is the kind of change of a line in a line diff
+type LineOp int
+
+const (
+	LineEqual  LineOp = iota // Line present in both texts
+	LineDelete               // Line present only in the source text
+	LineInsert               // Line present only in the target text
+)
+
+// maxLineDiffCells bounds the LCS table size; larger inputs are reported
+// as a full replacement instead of a minimal diff
+const maxLineDiffCells = 4_000_000
+
+// LineChange is a single line of a line diff
+type LineChange struct {
+	Op   LineOp
+	Text string
+}
+
+// SplitLines splits a definition into lines, normalizing line endings
+func SplitLines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// DiffLines computes a line diff turning a into b using the longest common
+// subsequence of lines
+func DiffLines(a, b []string) []LineChange {
+	// Common prefix and suffix don't need the LCS table
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var changes []LineChange
+	for _, line := range a[:prefix] {
+		changes = append(changes, LineChange{Op: LineEqual, Text: line})
+	}
+	changes = append(changes, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		changes = append(changes, LineChange{Op: LineEqual, Text: line})
+	}
+
+	return changes
+}
+
+// diffMiddle diffs the differing middle section of two texts
+func diffMiddle(a, b []string) []LineChange {
+	var changes []LineChange
+
+	if len(a)*len(b) > maxLineDiffCells {
+		for _, line := range a {
+			changes = append(changes, LineChange{Op: LineDelete, Text: line})
+		}
+		for _, line := range b {
+			changes = append(changes, LineChange{Op: LineInsert, Text: line})
+		}
+		return changes
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			changes = append(changes, LineChange{Op: LineEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			changes = append(changes, LineChange{Op: LineDelete, Text: a[i]})
+			i++
+		default:
+			changes = append(changes, LineChange{Op: LineInsert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		changes = append(changes, LineChange{Op: LineDelete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		changes = append(changes, LineChange{Op: LineInsert, Text: b[j]})
+	}
+
+	return changes
+}
+
+// UnifiedDiff renders a hunk-based unified diff of two texts given as lines.
+// Each hunk keeps contextLines of unchanged lines around the changes, and the
+// output is truncated after maxLines lines (0 = unlimited).
+func UnifiedDiff(a, b []string, contextLines, maxLines int) string {
+	changes := DiffLines(a, b)
+
+	// Number of source/target lines preceding each change
+	srcPos := make([]int, len(changes)+1)
+	tgtPos := make([]int, len(changes)+1)
+	for i, ch := range changes {
+		srcPos[i+1] = srcPos[i]
+		tgtPos[i+1] = tgtPos[i]
+		if ch.Op != LineInsert {
+			srcPos[i+1]++
+		}
+		if ch.Op != LineDelete {
+			tgtPos[i+1]++
+		}
+	}
+
+	// Group changes into hunks of [start, end) indices, merging overlaps
+	var hunks [][2]int
+	for i, ch := range changes {
+		if ch.Op == LineEqual {
+			continue
+		}
+		start := max(0, i-contextLines)
+		end := min(len(changes), i+contextLines+1)
+		if n := len(hunks); n > 0 && start <= hunks[n-1][1] {
+			hunks[n-1][1] = max(hunks[n-1][1], end)
+		} else {
+			hunks = append(hunks, [2]int{start, end})
+		}
+	}
+
+	var lines []string
+	for _, h := range hunks {
+		srcCount := srcPos[h[1]] - srcPos[h[0]]
+		tgtCount := tgtPos[h[1]] - tgtPos[h[0]]
+		lines = append(lines, fmt.Sprintf("@@ -%s +%s @@",
+			hunkRange(srcPos[h[0]], srcCount), hunkRange(tgtPos[h[0]], tgtCount)))
+
+		for _, ch := range changes[h[0]:h[1]] {
+			switch ch.Op {
+			case LineEqual:
+				lines = append(lines, " "+ch.Text)
+			case LineDelete:
+				lines = append(lines, "-"+ch.Text)
+			case LineInsert:
+				lines = append(lines, "+"+ch.Text)
+			}
+		}
+	}
+
+	if maxLines > 0 && len(lines) > maxLines {
+		more := len(lines) - maxLines
+		lines = append(lines[:maxLines], fmt.Sprintf("... (%d more lines)", more))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// hunkRange formats the start,count range of a unified diff hunk header
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "fmt"
+
+// LintSeverity indicates how serious a lint finding is
+type LintSeverity string
+
+const (
+	LintError   LintSeverity = "ERROR"
+	LintWarning LintSeverity = "WARNING"
+)
+
+// Lint rule identifiers
+const (
+	LintRuleHeapTable        = "heap-table"
+	LintRuleUnindexedFK      = "unindexed-foreign-key"
+	LintRuleNullablePKColumn = "nullable-pk-column"
+)
+
+// LintFinding is a schema smell detected in an extracted schema
+type LintFinding struct {
+	Severity   LintSeverity
+	Rule       string
+	ObjectName string
+	Message    string
+}
+
+// String returns a one-line representation of the finding
+func (f *LintFinding) String() string {
+	return fmt.Sprintf("%-7s %s: %s (%s)", f.Severity, f.ObjectName, f.Message, f.Rule)
+}
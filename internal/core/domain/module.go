@@ -0,0 +1,356 @@
+package domain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+)
+
+var (
+	// wordPattern matches identifiers, keywords and variables
+	wordPattern = regexp.MustCompile(`[@#\w]+`)
+
+	// schemaBindingPattern matches the SCHEMABINDING option
+	schemaBindingPattern = regexp.MustCompile(`(?i)\bSCHEMABINDING\b`)
+)
+
+// stripCommentsAndLiterals blanks out comments, string literals and quoted
+// identifiers so keyword searches only see T-SQL code. Offsets are preserved.
+func stripCommentsAndLiterals(sql string) string {
+	return maskCommentsAndLiterals(sql, ' ')
+}
+
+// maskCommentsAndLiterals replaces every character of comments, string
+// literals and quoted identifiers except newlines with mask
+func maskCommentsAndLiterals(sql string, mask byte) string {
+	return maskSQL(sql, mask, true)
+}
+
+// stripComments blanks comments but keeps string literals and quoted
+// identifiers
+func stripComments(sql string) string {
+	return maskSQL(sql, ' ', false)
+}
+
+// maskSQL replaces comments, and literals when requested, with mask
+func maskSQL(sql string, mask byte, literals bool) string {
+	out := []byte(sql)
+	blank := func(from, to int) {
+		for k := from; k < to && k < len(out); k++ {
+			if out[k] != '\n' {
+				out[k] = mask
+			}
+		}
+	}
+
+	for i := 0; i < len(sql); {
+		switch {
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+			blank(i, i+end)
+			i += end
+		case strings.HasPrefix(sql[i:], "/*"):
+			// Block comments nest in T-SQL
+			depth, j := 1, i+2
+			for j < len(sql) && depth > 0 {
+				switch {
+				case strings.HasPrefix(sql[j:], "/*"):
+					depth++
+					j += 2
+				case strings.HasPrefix(sql[j:], "*/"):
+					depth--
+					j += 2
+				default:
+					j++
+				}
+			}
+			blank(i, j)
+			i = j
+		case sql[i] == '\'' || sql[i] == '[' || sql[i] == '"':
+			closing := sql[i]
+			if closing == '[' {
+				closing = ']'
+			}
+			j := i + 1
+			for j < len(sql) {
+				if sql[j] == closing {
+					// A doubled closing character is an escape
+					if j+1 < len(sql) && sql[j+1] == closing {
+						j += 2
+						continue
+					}
+					j++
+					break
+				}
+				j++
+			}
+			if literals {
+				blank(i, j)
+			}
+			i = j
+		default:
+			i++
+		}
+	}
+
+	return string(out)
+}
+
+// ModuleHeader returns the part of a module definition (view, procedure,
+// function or trigger) before the AS keyword that starts its body, with
+// comments and literals removed. AS in "EXECUTE AS" and "@param AS type"
+// does not end the header.
+func ModuleHeader(definition string) string {
+	code := stripCommentsAndLiterals(definition)
+
+	prev := ""
+	for _, loc := range wordPattern.FindAllStringIndex(code, -1) {
+		word := strings.ToUpper(code[loc[0]:loc[1]])
+		if word == "AS" && prev != "EXECUTE" && prev != "EXEC" && !strings.HasPrefix(prev, "@") {
+			return code[:loc[0]]
+		}
+		prev = word
+	}
+	return code
+}
+
+// FormatModuleSQL tidies a module definition conservatively: reserved
+// keywords are uppercased, leading tabs are expanded to four-column stops and
+// trailing whitespace is removed. Comments, string literals and quoted
+// identifiers are left exactly as they are, so the module is unchanged.
+func FormatModuleSQL(definition string) string {
+	const literal = 0
+	masked := maskCommentsAndLiterals(definition, literal)
+
+	out := []byte(definition)
+	for _, loc := range wordPattern.FindAllStringIndex(masked, -1) {
+		word := strings.ToUpper(masked[loc[0]:loc[1]])
+		if reservedKeywords[word] {
+			copy(out[loc[0]:loc[1]], word)
+		}
+	}
+
+	var sb strings.Builder
+	for start := 0; start <= len(out); {
+		end := bytes.IndexByte(out[start:], '\n')
+		if end < 0 {
+			end = len(out)
+		} else {
+			end += start
+		}
+
+		// Whitespace belonging to a literal or comment is kept
+		line := start
+		if line < end && masked[line] != literal {
+			col := 0
+			for ; line < end && (out[line] == ' ' || out[line] == '\t'); line++ {
+				if out[line] == '\t' {
+					col += 4 - col%4
+				} else {
+					col++
+				}
+			}
+			sb.WriteString(strings.Repeat(" ", col))
+		}
+		// Line endings are kept as stored
+		trimmed := end
+		if trimmed > line && out[trimmed-1] == '\r' {
+			trimmed--
+		}
+		eol := trimmed
+		for trimmed > line && masked[trimmed-1] != literal && (out[trimmed-1] == ' ' || out[trimmed-1] == '\t') {
+			trimmed--
+		}
+		sb.Write(out[line:trimmed])
+		sb.Write(out[eol:end])
+
+		if end == len(out) {
+			break
+		}
+		sb.WriteByte('\n')
+		start = end + 1
+	}
+	return sb.String()
+}
+
+// reservedKeywords are the T-SQL reserved keywords, which cannot be used as
+// unquoted identifiers and so are safe to uppercase
+var reservedKeywords = func() map[string]bool {
+	words := strings.Fields(`
+		ADD ALL ALTER AND ANY AS ASC AUTHORIZATION BACKUP BEGIN BETWEEN BREAK
+		BROWSE BULK BY CASCADE CASE CHECK CHECKPOINT CLOSE CLUSTERED COALESCE
+		COLLATE COLUMN COMMIT COMPUTE CONSTRAINT CONTAINS CONTAINSTABLE CONTINUE
+		CONVERT CREATE CROSS CURRENT CURRENT_DATE CURRENT_TIME CURRENT_TIMESTAMP
+		CURRENT_USER CURSOR DATABASE DBCC DEALLOCATE DECLARE DEFAULT DELETE DENY
+		DESC DISK DISTINCT DISTRIBUTED DOUBLE DROP DUMP ELSE END ERRLVL ESCAPE
+		EXCEPT EXEC EXECUTE EXISTS EXIT EXTERNAL FETCH FILE FILLFACTOR FOR
+		FOREIGN FREETEXT FREETEXTTABLE FROM FULL FUNCTION GOTO GRANT GROUP
+		HAVING HOLDLOCK IDENTITY IDENTITY_INSERT IDENTITYCOL IF IN INDEX INNER
+		INSERT INTERSECT INTO IS JOIN KEY KILL LEFT LIKE LINENO LOAD MERGE
+		NATIONAL NOCHECK NONCLUSTERED NOT NULL NULLIF OF OFF OFFSETS ON OPEN
+		OPENDATASOURCE OPENQUERY OPENROWSET OPENXML OPTION OR ORDER OUTER OVER
+		PERCENT PIVOT PLAN PRECISION PRIMARY PRINT PROC PROCEDURE PUBLIC
+		RAISERROR READ READTEXT RECONFIGURE REFERENCES REPLICATION RESTORE
+		RESTRICT RETURN REVERT REVOKE RIGHT ROLLBACK ROWCOUNT ROWGUIDCOL RULE
+		SAVE SCHEMA SECURITYAUDIT SELECT SEMANTICKEYPHRASETABLE
+		SEMANTICSIMILARITYDETAILSTABLE SEMANTICSIMILARITYTABLE SESSION_USER SET
+		SETUSER SHUTDOWN SOME STATISTICS SYSTEM_USER TABLE TABLESAMPLE TEXTSIZE
+		THEN TO TOP TRAN TRANSACTION TRIGGER TRUNCATE TRY_CONVERT TSEQUAL UNION
+		UNIQUE UNPIVOT UPDATE UPDATETEXT USE USER VALUES VARYING VIEW WAITFOR
+		WHEN WHERE WHILE WITH WITHIN WRITETEXT`)
+	m := make(map[string]bool, len(words))
+	for _, w := range words {
+		m[w] = true
+	}
+	return m
+}()
+
+// HasSchemaBinding reports whether a module definition is created WITH SCHEMABINDING
+func HasSchemaBinding(definition string) bool {
+	return schemaBindingPattern.MatchString(ModuleHeader(definition))
+}
+
+// parameterStopWords end the parameter list of a module header: RETURNS
+// starts the return type of a function, WITH and FOR the procedure options
+var parameterStopWords = map[string]bool{"RETURNS": true, "WITH": true, "FOR": true}
+
+// parameterSuffixWords may follow the default value of a parameter
+var parameterSuffixWords = map[string]bool{"OUT": true, "OUTPUT": true, "READONLY": true}
+
+// ParseParameterDefaults returns the default values declared for the
+// parameters of a procedure or function definition, keyed by lowercase
+// parameter name. sys.parameters only records defaults of CLR modules, so
+// those of T-SQL modules are read from the header. Defaults are returned as
+// written, e.g. N'abc' or -1.
+func ParseParameterDefaults(definition string) map[string]string {
+	header := ModuleHeader(definition)
+
+	start := strings.IndexByte(header, '@')
+	if start < 0 {
+		return nil
+	}
+	end := len(header)
+	for _, loc := range wordPattern.FindAllStringIndex(header[start:], -1) {
+		if parameterStopWords[strings.ToUpper(header[start+loc[0]:start+loc[1]])] {
+			end = start + loc[0]
+			break
+		}
+	}
+
+	// Split the list at top-level commas; a closing parenthesis without its
+	// opening one ends the list of a function
+	defaults := make(map[string]string)
+	depth, from := 0, start
+	for i := start; i <= end; i++ {
+		last := i == end
+		if !last {
+			switch header[i] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+				last = depth < 0
+			case ',':
+				last = depth == 0
+			}
+		}
+		if !last {
+			continue
+		}
+		if name, value, ok := parameterDefault(definition, header, from, i); ok {
+			defaults[strings.ToLower(name)] = value
+		}
+		if depth < 0 {
+			break
+		}
+		from = i + 1
+	}
+	return defaults
+}
+
+// parameterDefault returns the name and default value of the parameter
+// declared in definition[from:to], using the masked header to find them
+func parameterDefault(definition, header string, from, to int) (name, value string, ok bool) {
+	decl := header[from:to]
+	nameLoc := wordPattern.FindStringIndex(decl)
+	if nameLoc == nil || decl[nameLoc[0]] != '@' {
+		return "", "", false
+	}
+	name = decl[nameLoc[0]:nameLoc[1]]
+
+	depth, eq := 0, -1
+	for i := nameLoc[1]; i < len(decl) && eq < 0; i++ {
+		switch decl[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '=':
+			if depth == 0 {
+				eq = i
+			}
+		}
+	}
+	if eq < 0 {
+		return name, "", false
+	}
+
+	// Drop OUTPUT and READONLY after the value
+	valueEnd := len(decl)
+	for {
+		trimmed := strings.TrimRight(decl[eq+1:valueEnd], " \t\r\n")
+		words := wordPattern.FindAllStringIndex(trimmed, -1)
+		if len(words) == 0 {
+			break
+		}
+		lastWord := words[len(words)-1]
+		if lastWord[1] != len(trimmed) || !parameterSuffixWords[strings.ToUpper(trimmed[lastWord[0]:])] {
+			break
+		}
+		valueEnd = eq + 1 + lastWord[0]
+	}
+
+	value = strings.TrimSpace(stripComments(definition[from+eq+1 : from+valueEnd]))
+	return name, value, value != ""
+}
+
+// DefinitionHash returns the hash SQL Server computes for a module definition
+// with DumpOptions.DefinitionHashesOnly: the uppercase hex SHA-256 of the
+// UTF-16 text with tabs, line breaks and runs of spaces collapsed to one
+// space and leading and trailing spaces trimmed. An empty definition has no
+// hash.
+func DefinitionHash(definition string) string {
+	if definition == "" {
+		return ""
+	}
+
+	var sb strings.Builder
+	space := false
+	for _, r := range definition {
+		switch r {
+		case ' ', '\t', '\n', '\v', '\f', '\r':
+			space = true
+			continue
+		}
+		if space && sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		space = false
+		sb.WriteRune(r)
+	}
+
+	units := utf16.Encode([]rune(sb.String()))
+	text := make([]byte, 2*len(units))
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(text[2*i:], u)
+	}
+	sum := sha256.Sum256(text)
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseParameterDefaults(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition string
+		want       map[string]string
+	}{
+		{
+			name:       "procedure without parameters",
+			definition: "CREATE PROCEDURE dbo.p AS SET @x = 1",
+			want:       nil,
+		},
+		{
+			name: "procedure defaults",
+			definition: `CREATE PROCEDURE [dbo].[p]
+    @id int,
+    @name nvarchar(50) = N'a, b',
+    @amount decimal(10, 2) = -1.5,
+    @flag bit = NULL OUTPUT
+AS
+BEGIN
+    DECLARE @local int = 5
+END`,
+			want: map[string]string{"@name": "N'a, b'", "@amount": "-1.5", "@flag": "NULL"},
+		},
+		{
+			name:       "parenthesized procedure parameters with options",
+			definition: "CREATE PROC p (@a AS int = 1, @B varchar(10) = 'x') WITH RECOMPILE AS SELECT 1",
+			want:       map[string]string{"@a": "1", "@b": "'x'"},
+		},
+		{
+			name:       "comments and literals are skipped",
+			definition: "CREATE PROCEDURE p\n    -- @old int = 9,\n    @a int = 2 /* = 3 */\nAS SELECT '@b = 4'",
+			want:       map[string]string{"@a": "2"},
+		},
+		{
+			name:       "scalar function",
+			definition: "CREATE FUNCTION dbo.f (@a int = 10, @b int) RETURNS int AS BEGIN RETURN @a + @b END",
+			want:       map[string]string{"@a": "10"},
+		},
+		{
+			name:       "multi-statement function returning a table variable",
+			definition: "CREATE FUNCTION dbo.f (@a int = 3) RETURNS @t TABLE (id int DEFAULT 0) AS BEGIN RETURN END",
+			want:       map[string]string{"@a": "3"},
+		},
+		{
+			name:       "table-valued parameter",
+			definition: "CREATE PROCEDURE p @rows dbo.RowList READONLY, @mode char(1) = 'A' AS SELECT 1",
+			want:       map[string]string{"@mode": "'A'"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseParameterDefaults(tt.definition)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseParameterDefaults() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefinitionHash(t *testing.T) {
+	const want = "32AC437F0B584B2712A6D2F5B0BCB6B0F38B0199A9D1221AF34C036E50674060" // SHA-256 of the UTF-16LE text
+	for _, definition := range []string{
+		"SELECT N'é' AS a",
+		"  SELECT\r\n\tN'é'   AS a\n",
+	} {
+		if got := DefinitionHash(definition); got != want {
+			t.Errorf("DefinitionHash(%q) = %s, want %s", definition, got, want)
+		}
+	}
+	if DefinitionHash("SELECT 1") == DefinitionHash("SELECT 2") {
+		t.Error("different definitions have the same hash")
+	}
+	if got := DefinitionHash(""); got != "" {
+		t.Errorf("DefinitionHash of an empty definition = %q, want empty", got)
+	}
+}
@@ -0,0 +1,74 @@
+package domain
+
+import "sort"
+
+// SortTablesByDependency orders tables by foreign key dependency: tables
+// referencing no other table come first, then the tables referencing only
+// those, and so on, each level in alphabetical order. Self-references and
+// references to tables not in the list are ignored. Tables in a reference
+// cycle, or depending on one, cannot be ordered; they follow the others in
+// alphabetical order and their names are returned as cyclic. nameKey maps a
+// schema-qualified name to the key identifying it under the database
+// collation, so that a foreign key finds the table it references.
+func SortTablesByDependency(tables []Table, nameKey func(string) string) (ordered []Table, cyclic []string) {
+	key := func(schemaName, name string) string {
+		return nameKey(schemaName + "." + name)
+	}
+
+	sorted := make([]Table, len(tables))
+	copy(sorted, tables)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return key(sorted[i].SchemaName, sorted[i].Name) < key(sorted[j].SchemaName, sorted[j].Name)
+	})
+
+	index := make(map[string]int, len(sorted))
+	for i, t := range sorted {
+		index[key(t.SchemaName, t.Name)] = i
+	}
+
+	// pending counts the referenced tables not yet ordered; dependents lists
+	// the tables referencing each table
+	pending := make([]int, len(sorted))
+	dependents := make([][]int, len(sorted))
+	for i, t := range sorted {
+		referenced := make(map[int]bool)
+		for _, fk := range t.ForeignKeys {
+			j, ok := index[key(fk.ReferencedSchemaName, fk.ReferencedTableName)]
+			if !ok || j == i || referenced[j] {
+				continue
+			}
+			referenced[j] = true
+			pending[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	done := make([]bool, len(sorted))
+	ordered = make([]Table, 0, len(sorted))
+	for {
+		var level []int
+		for i := range sorted {
+			if !done[i] && pending[i] == 0 {
+				level = append(level, i)
+			}
+		}
+		if len(level) == 0 {
+			break
+		}
+		for _, i := range level {
+			done[i] = true
+			ordered = append(ordered, sorted[i])
+			for _, d := range dependents[i] {
+				pending[d]--
+			}
+		}
+	}
+
+	for i, t := range sorted {
+		if !done[i] {
+			ordered = append(ordered, t)
+			cyclic = append(cyclic, t.SchemaName+"."+t.Name)
+		}
+	}
+	return ordered, cyclic
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+// tableNames returns the schema-qualified names of tables in order
+func tableNames(tables []Table) []string {
+	names := make([]string, len(tables))
+	for i, t := range tables {
+		names[i] = t.SchemaName + "." + t.Name
+	}
+	return names
+}
+
+// references returns a foreign key to the table in dbo
+func references(table string) []ForeignKey {
+	return []ForeignKey{{Name: "FK_" + table, ReferencedSchemaName: "dbo", ReferencedTableName: table}}
+}
+
+func TestSortTablesByDependencyNameKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		collation string
+		tables    []Table
+		want      []string
+	}{
+		{
+			// dbo.Users and dbo.users are two tables
+			name:      "case-sensitive",
+			collation: "Latin1_General_CS_AS",
+			tables: []Table{
+				{SchemaName: "dbo", Name: "Accounts", ForeignKeys: references("users")},
+				{SchemaName: "dbo", Name: "Users", ForeignKeys: references("Accounts")},
+				{SchemaName: "dbo", Name: "users"},
+			},
+			want: []string{"dbo.users", "dbo.Accounts", "dbo.Users"},
+		},
+		{
+			name:      "case-insensitive",
+			collation: "Latin1_General_CI_AS",
+			tables: []Table{
+				{SchemaName: "dbo", Name: "Accounts", ForeignKeys: references("USERS")},
+				{SchemaName: "dbo", Name: "Users"},
+			},
+			want: []string{"dbo.Users", "dbo.Accounts"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ordered, cyclic := SortTablesByDependency(tt.tables, CollationNameKey(tt.collation))
+			if len(cyclic) > 0 {
+				t.Errorf("cyclic = %v, want none", cyclic)
+			}
+			if got := tableNames(ordered); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
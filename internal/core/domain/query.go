@@ -0,0 +1,31 @@
+package domain
+
+// QueryValue is a column value rendered as text
+type QueryValue struct {
+	Text string
+	Null bool // SQL NULL
+}
+
+// String returns the value text, or NULL
+func (v QueryValue) String() string {
+	if v.Null {
+		return "NULL"
+	}
+	return v.Text
+}
+
+// QueryResult holds the result set of an ad-hoc read query
+type QueryResult struct {
+	Columns   []string
+	Rows      [][]QueryValue
+	Truncated bool // More rows were available than the row limit
+}
+
+// RowWriter receives a result set one row at a time
+type RowWriter interface {
+	// WriteHeader is called once with the column names before any row
+	WriteHeader(columns []string) error
+
+	// WriteRow is called for each row
+	WriteRow(row []QueryValue) error
+}
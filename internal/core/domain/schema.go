@@ -2,80 +2,118 @@ package domain
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // ObjectType represents the type of database object
 type ObjectType string
 
 const (
-	ObjectTypeTable           ObjectType = "TABLE"
-	ObjectTypeView            ObjectType = "VIEW"
-	ObjectTypeProcedure       ObjectType = "PROCEDURE"
-	ObjectTypeFunction        ObjectType = "FUNCTION"
-	ObjectTypeTrigger         ObjectType = "TRIGGER"
-	ObjectTypeIndex           ObjectType = "INDEX"
-	ObjectTypeConstraint      ObjectType = "CONSTRAINT"
-	ObjectTypeSchema          ObjectType = "SCHEMA"
-	ObjectTypeType            ObjectType = "TYPE"
-	ObjectTypeSequence        ObjectType = "SEQUENCE"
-	ObjectTypeSynonym         ObjectType = "SYNONYM"
+	ObjectTypeTable      ObjectType = "TABLE"
+	ObjectTypeView       ObjectType = "VIEW"
+	ObjectTypeProcedure  ObjectType = "PROCEDURE"
+	ObjectTypeFunction   ObjectType = "FUNCTION"
+	ObjectTypeTrigger    ObjectType = "TRIGGER"
+	ObjectTypeIndex      ObjectType = "INDEX"
+	ObjectTypeConstraint ObjectType = "CONSTRAINT"
+	ObjectTypeSchema     ObjectType = "SCHEMA"
+	ObjectTypeType       ObjectType = "TYPE"
+	ObjectTypeSequence   ObjectType = "SEQUENCE"
+	ObjectTypeSynonym    ObjectType = "SYNONYM"
 )
 
 // Column represents a table column
 type Column struct {
-	Name             string
-	OrdinalPosition  int
-	DataType         string
-	MaxLength        int
-	Precision        int
-	Scale            int
-	IsNullable       bool
-	HasDefault       bool
-	DefaultValue     string
-	IsIdentity       bool
-	IdentitySeed     int64
-	IdentityIncrement int64
-	IsComputed       bool
+	Name               string
+	OrdinalPosition    int
+	DataType           string
+	MaxLength          int
+	Precision          int
+	Scale              int
+	IsNullable         bool
+	HasDefault         bool
+	DefaultName        string // Name of the default constraint
+	DefaultValue       string
+	IsIdentity         bool
+	IdentitySeed       int64
+	IdentityIncrement  int64
+	IsComputed         bool
 	ComputedDefinition string
-	Collation        string
+	Collation          string
+	GeneratedAlways    string // ROW START or ROW END for the period columns of a temporal table
+	IsHidden           bool   // Hidden period column, left out of SELECT *
+	IsSparse           bool   // Sparse column, storing NULLs without space
+	IsColumnSet        bool   // XML column set exposing all sparse columns
+	MaskingFunction    string // Dynamic Data Masking function, e.g. partial(1,"XXX",0)
+	IsRowGuidCol       bool   // ROWGUIDCOL uniqueidentifier, required by merge replication and FILESTREAM
+	IsFilestream       bool   // varbinary(max) stored as FILESTREAM data
+}
+
+// MaskSQL returns the MASKED WITH clause for the column's masking function
+func (c *Column) MaskSQL() string {
+	return fmt.Sprintf("MASKED WITH (FUNCTION = '%s')", strings.ReplaceAll(c.MaskingFunction, "'", "''"))
 }
 
 // GenerateSQL generates the column definition SQL
 func (c *Column) GenerateSQL() string {
+	return c.generateSQL(TSQL, false)
+}
+
+// GenerateSQLFor generates the column definition in the given dialect
+func (c *Column) GenerateSQLFor(d Dialect) string {
+	return c.generateSQL(d, false)
+}
+
+// generateSQL generates the column definition, naming the default constraint
+// when namedDefault is set and the name is known
+func (c *Column) generateSQL(d Dialect, namedDefault bool) string {
 	var sb strings.Builder
 
-	sb.WriteString(fmt.Sprintf("[%s] ", c.Name))
+	sb.WriteString(fmt.Sprintf("%s ", d.QuoteIdent(c.Name)))
+
+	dataType := d.MapType(c.DataType, c.MaxLength, c.Precision, c.Scale)
 
 	// Handle computed columns
 	if c.IsComputed {
-		sb.WriteString(fmt.Sprintf("AS %s", c.ComputedDefinition))
+		sb.WriteString(d.ComputedColumn(dataType, c.ComputedDefinition))
 		return sb.String()
 	}
 
-	sb.WriteString(c.DataType)
+	sb.WriteString(dataType)
 
-	// Add length/precision/scale based on data type
-	switch strings.ToUpper(c.DataType) {
-	case "VARCHAR", "NVARCHAR", "CHAR", "NCHAR", "VARBINARY", "BINARY":
-		if c.MaxLength == -1 {
-			sb.WriteString("(MAX)")
-		} else if strings.HasPrefix(strings.ToUpper(c.DataType), "N") {
-			sb.WriteString(fmt.Sprintf("(%d)", c.MaxLength/2))
-		} else {
-			sb.WriteString(fmt.Sprintf("(%d)", c.MaxLength))
-		}
-	case "DECIMAL", "NUMERIC":
-		sb.WriteString(fmt.Sprintf("(%d,%d)", c.Precision, c.Scale))
-	case "DATETIME2", "DATETIMEOFFSET", "TIME":
-		if c.Scale > 0 {
-			sb.WriteString(fmt.Sprintf("(%d)", c.Scale))
+	if c.IsFilestream && d.StorageOptions() {
+		sb.WriteString(" FILESTREAM")
+	}
+
+	// Column set of a table with sparse columns; it is always nullable
+	if c.IsColumnSet && d.StorageOptions() {
+		sb.WriteString(" COLUMN_SET FOR ALL_SPARSE_COLUMNS")
+		return sb.String()
+	}
+
+	// Period column of a system-versioned temporal table
+	if c.GeneratedAlways != "" && d.Supports(FeatureTemporalTables) {
+		sb.WriteString(" GENERATED ALWAYS AS " + c.GeneratedAlways)
+		if c.IsHidden {
+			sb.WriteString(" HIDDEN")
 		}
 	}
 
 	// Identity
 	if c.IsIdentity {
-		sb.WriteString(fmt.Sprintf(" IDENTITY(%d,%d)", c.IdentitySeed, c.IdentityIncrement))
+		sb.WriteString(d.Identity(c.IdentitySeed, c.IdentityIncrement))
+	}
+
+	if c.IsSparse && d.StorageOptions() {
+		sb.WriteString(" SPARSE")
+	}
+
+	// Dynamic Data Masking
+	if c.MaskingFunction != "" && d.Supports(FeatureDataMasking) {
+		sb.WriteString(" " + c.MaskSQL())
 	}
 
 	// Nullability
@@ -87,63 +125,279 @@ func (c *Column) GenerateSQL() string {
 
 	// Default value
 	if c.HasDefault && c.DefaultValue != "" {
-		sb.WriteString(fmt.Sprintf(" DEFAULT %s", c.DefaultValue))
+		if namedDefault && c.DefaultName != "" {
+			sb.WriteString(fmt.Sprintf(" CONSTRAINT %s", d.QuoteIdent(c.DefaultName)))
+		}
+		sb.WriteString(fmt.Sprintf(" DEFAULT %s", d.TranslateDefault(c.DefaultValue, c.DataType)))
+	}
+
+	if c.IsRowGuidCol && d.StorageOptions() {
+		sb.WriteString(" ROWGUIDCOL")
+	}
+
+	return sb.String()
+}
+
+// formatDataType renders a data type with its length, precision, or scale
+func formatDataType(dataType string, maxLength, precision, scale int) string {
+	var sb strings.Builder
+
+	sb.WriteString(dataType)
+
+	// Add length/precision/scale based on data type
+	switch strings.ToUpper(dataType) {
+	case "VARCHAR", "NVARCHAR", "CHAR", "NCHAR", "VARBINARY", "BINARY":
+		if maxLength == -1 {
+			sb.WriteString("(MAX)")
+		} else if strings.HasPrefix(strings.ToUpper(dataType), "N") {
+			sb.WriteString(fmt.Sprintf("(%d)", maxLength/2))
+		} else {
+			sb.WriteString(fmt.Sprintf("(%d)", maxLength))
+		}
+	case "DECIMAL", "NUMERIC":
+		sb.WriteString(fmt.Sprintf("(%d,%d)", precision, scale))
+	case "DATETIME2", "DATETIMEOFFSET", "TIME":
+		if scale > 0 {
+			sb.WriteString(fmt.Sprintf("(%d)", scale))
+		}
 	}
 
 	return sb.String()
 }
 
+// QuoteIdent wraps an identifier in brackets, doubling any embedded closing
+// bracket so names such as weird]name produce valid T-SQL
+func QuoteIdent(name string) string {
+	return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+}
+
+// storageClause renders the ON [scheme]([column]) or ON [filegroup] clause.
+// The default PRIMARY filegroup is omitted.
+func storageClause(scheme, column, fileGroup string) string {
+	if scheme != "" {
+		if column == "" {
+			return fmt.Sprintf(" ON %s", QuoteIdent(scheme))
+		}
+		return fmt.Sprintf(" ON %s(%s)", QuoteIdent(scheme), QuoteIdent(column))
+	}
+	if fileGroup != "" && !strings.EqualFold(fileGroup, "PRIMARY") {
+		return fmt.Sprintf(" ON %s", QuoteIdent(fileGroup))
+	}
+	return ""
+}
+
 // IndexColumn represents a column in an index
 type IndexColumn struct {
-	Name       string
-	Position   int
+	Name         string
+	Position     int
 	IsDescending bool
-	IsIncluded bool
+	IsIncluded   bool
 }
 
+// IndexType is the physical kind of an index, as reported by sys.indexes.type_desc
+type IndexType string
+
+const (
+	IndexTypeClustered               IndexType = "CLUSTERED"
+	IndexTypeNonclustered            IndexType = "NONCLUSTERED"
+	IndexTypeXML                     IndexType = "XML"
+	IndexTypeSpatial                 IndexType = "SPATIAL"
+	IndexTypeClusteredColumnstore    IndexType = "CLUSTERED COLUMNSTORE"
+	IndexTypeNonclusteredColumnstore IndexType = "NONCLUSTERED COLUMNSTORE"
+	IndexTypeNonclusteredHash        IndexType = "NONCLUSTERED HASH"
+)
+
 // Index represents a table index
 type Index struct {
-	Name           string
-	SchemaName     string
-	TableName      string
-	IsPrimaryKey   bool
-	IsUnique       bool
-	IsClustered    bool
-	IsDisabled     bool
-	FilterDefinition string
-	Columns        []IndexColumn
+	Name               string
+	SchemaName         string
+	TableName          string
+	Type               IndexType
+	IsPrimaryKey       bool
+	IsUnique           bool
+	IsClustered        bool
+	IsDisabled         bool
+	FilterDefinition   string
+	PartitionScheme    string // Partition scheme the index is built on
+	PartitionColumn    string // Partitioning column
+	FileGroup          string // Filegroup the index is stored on
+	XMLPrimaryIndex    string // For secondary XML indexes, the primary XML index it uses
+	XMLSecondaryType   string // For secondary XML indexes: PATH, VALUE or PROPERTY
+	TessellationScheme string // For spatial indexes, e.g. GEOMETRY_AUTO_GRID
+	BoundingBox        string // For geometry spatial indexes, e.g. (0, 0, 100, 100)
+	BucketCount        int64  // For hash indexes, the number of buckets
+	IsMemoryOptimized  bool   // On a memory-optimized table, so declared with the table
+	PadIndex           bool   // Fill factor also applies to intermediate pages
+	IgnoreDupKey       bool   // Duplicate inserts into a unique index are discarded with a warning
+	AllowRowLocks      bool   // On by default
+	AllowPageLocks     bool   // On by default
+	Columns            []IndexColumn
+}
+
+// withClause returns the WITH clause of the index options that differ from
+// the server defaults, or an empty string when all are defaults. A rebuild
+// adds DROP_EXISTING = ON.
+func (i *Index) withClause(rebuild bool) string {
+	var opts []string
+	if i.PadIndex {
+		opts = append(opts, "PAD_INDEX = ON")
+	}
+	if i.IgnoreDupKey {
+		opts = append(opts, "IGNORE_DUP_KEY = ON")
+	}
+	if !i.AllowRowLocks {
+		opts = append(opts, "ALLOW_ROW_LOCKS = OFF")
+	}
+	if !i.AllowPageLocks {
+		opts = append(opts, "ALLOW_PAGE_LOCKS = OFF")
+	}
+	if rebuild {
+		opts = append(opts, "DROP_EXISTING = ON")
+	}
+	if len(opts) == 0 {
+		return ""
+	}
+	return " WITH (" + strings.Join(opts, ", ") + ")"
+}
+
+// placementClause renders where the index is stored. A rebuild names the
+// PRIMARY filegroup too, so an index moves back to it.
+func (i *Index) placementClause(rebuild bool) string {
+	clause := storageClause(i.PartitionScheme, i.PartitionColumn, i.FileGroup)
+	if clause == "" && rebuild {
+		return " ON [PRIMARY]"
+	}
+	return clause
 }
 
 // GenerateSQL generates the CREATE INDEX statement
 func (i *Index) GenerateSQL() string {
-	var sb strings.Builder
+	return i.GenerateSQLFor(TSQL)
+}
 
+// RebuildSQL generates the CREATE INDEX statement that rebuilds the index
+// with DROP_EXISTING = ON on its partition scheme or filegroup, moving it
+// there. Primary keys, XML indexes and indexes of memory-optimized tables
+// cannot be moved this way and get an empty string.
+func (i *Index) RebuildSQL() string {
+	if i.IsPrimaryKey || i.IsMemoryOptimized || i.Type == IndexTypeXML {
+		return ""
+	}
+	return i.createSQL(TSQL, true, nil)
+}
+
+// GenerateDropSQL generates the DROP INDEX statement. Indexes of
+// memory-optimized tables are dropped with ALTER TABLE.
+func (i *Index) GenerateDropSQL() string {
+	if i.IsMemoryOptimized {
+		return fmt.Sprintf("ALTER TABLE %s.%s DROP INDEX %s", QuoteIdent(i.SchemaName), QuoteIdent(i.TableName), QuoteIdent(i.Name))
+	}
+	return fmt.Sprintf("DROP INDEX %s ON %s.%s", QuoteIdent(i.Name), QuoteIdent(i.SchemaName), QuoteIdent(i.TableName))
+}
+
+// memoryOptimized reports whether the index is generated in the syntax of
+// memory-optimized tables in the given dialect
+func (i *Index) memoryOptimized(d Dialect) bool {
+	return i.IsMemoryOptimized && d.StorageOptions() && d.Supports(FeatureMemoryOptimizedTables)
+}
+
+// inlineSQL generates the INDEX clause declaring an index of a
+// memory-optimized table in its CREATE or ALTER TABLE statement
+func (i *Index) inlineSQL() string {
+	if i.Type == IndexTypeClusteredColumnstore {
+		return fmt.Sprintf("INDEX %s CLUSTERED COLUMNSTORE", QuoteIdent(i.Name))
+	}
+
+	var cols []string
+	for _, col := range i.Columns {
+		if col.IsIncluded {
+			continue
+		}
+		colDef := QuoteIdent(col.Name)
+		if col.IsDescending {
+			colDef += " DESC"
+		}
+		cols = append(cols, colDef)
+	}
+	if i.Type == IndexTypeNonclusteredHash {
+		return fmt.Sprintf("INDEX %s NONCLUSTERED HASH (%s)%s", QuoteIdent(i.Name), strings.Join(cols, ", "), i.bucketCountClause())
+	}
+	return fmt.Sprintf("INDEX %s NONCLUSTERED (%s)", QuoteIdent(i.Name), strings.Join(cols, ", "))
+}
+
+// bucketCountClause returns the WITH (BUCKET_COUNT = n) clause of a hash
+// index, or an empty string when the bucket count is unknown
+func (i *Index) bucketCountClause() string {
+	if i.BucketCount <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" WITH (BUCKET_COUNT = %d)", i.BucketCount)
+}
+
+// GenerateSQLFor generates the CREATE INDEX statement in the given dialect.
+// XML, spatial and columnstore indexes only exist in T-SQL; other dialects
+// get an empty string for them. bitColumns names the bit columns of the
+// table, which the filter may compare with 0 or 1.
+func (i *Index) GenerateSQLFor(d Dialect, bitColumns ...string) string {
 	if i.IsPrimaryKey {
 		return "" // PKs are generated as constraints
 	}
+	return i.createSQL(d, false, bitColumns)
+}
+
+// createSQL generates the CREATE INDEX statement, rebuilding the existing
+// index in place when rebuild is set
+func (i *Index) createSQL(d Dialect, rebuild bool, bitColumns []string) string {
+	// Memory-optimized tables take no CREATE INDEX
+	if i.memoryOptimized(d) {
+		return fmt.Sprintf("ALTER TABLE %s.%s ADD %s", QuoteIdent(i.SchemaName), QuoteIdent(i.TableName), i.inlineSQL())
+	}
+
+	switch i.Type {
+	case IndexTypeXML, IndexTypeSpatial, IndexTypeClusteredColumnstore, IndexTypeNonclusteredColumnstore:
+		if !d.StorageOptions() {
+			return ""
+		}
+	}
+	if i.Type == IndexTypeClusteredColumnstore && !d.Supports(FeatureClusteredColumnstore) {
+		return ""
+	}
+
+	switch i.Type {
+	case IndexTypeXML:
+		return i.generateXMLIndexSQL()
+	case IndexTypeSpatial:
+		return i.generateSpatialIndexSQL(rebuild)
+	case IndexTypeClusteredColumnstore, IndexTypeNonclusteredColumnstore:
+		return i.generateColumnstoreIndexSQL(rebuild)
+	}
+
+	var sb strings.Builder
 
 	sb.WriteString("CREATE ")
 	if i.IsUnique {
 		sb.WriteString("UNIQUE ")
 	}
-	if i.IsClustered {
-		sb.WriteString("CLUSTERED ")
-	} else {
-		sb.WriteString("NONCLUSTERED ")
+	if d.StorageOptions() {
+		if i.IsClustered {
+			sb.WriteString("CLUSTERED ")
+		} else {
+			sb.WriteString("NONCLUSTERED ")
+		}
 	}
 
-	sb.WriteString(fmt.Sprintf("INDEX [%s] ON [%s].[%s] (\n", i.Name, i.SchemaName, i.TableName))
+	sb.WriteString(fmt.Sprintf("INDEX %s ON %s.%s (\n", d.QuoteIdent(i.Name), d.QuoteIdent(i.SchemaName), d.QuoteIdent(i.TableName)))
 
 	// Key columns
 	var keyCols []string
 	var includeCols []string
 	for _, col := range i.Columns {
-		colDef := fmt.Sprintf("    [%s]", col.Name)
+		colDef := fmt.Sprintf("    %s", d.QuoteIdent(col.Name))
 		if col.IsDescending {
 			colDef += " DESC"
 		}
 		if col.IsIncluded {
-			includeCols = append(includeCols, fmt.Sprintf("    [%s]", col.Name))
+			includeCols = append(includeCols, fmt.Sprintf("    %s", d.QuoteIdent(col.Name)))
 		} else {
 			keyCols = append(keyCols, colDef)
 		}
@@ -161,12 +415,84 @@ func (i *Index) GenerateSQL() string {
 
 	// Filter
 	if i.FilterDefinition != "" {
-		sb.WriteString(fmt.Sprintf(" WHERE %s", i.FilterDefinition))
+		sb.WriteString(fmt.Sprintf(" WHERE %s", d.TranslateExpression(i.FilterDefinition, bitColumns)))
+	}
+
+	// Index options, then partitioning or filegroup placement
+	if d.StorageOptions() {
+		sb.WriteString(i.withClause(rebuild))
+		sb.WriteString(i.placementClause(rebuild))
 	}
 
 	return sb.String()
 }
 
+// firstColumn returns the single column an XML or spatial index is built on
+func (i *Index) firstColumn() string {
+	if len(i.Columns) == 0 {
+		return ""
+	}
+	return i.Columns[0].Name
+}
+
+// generateXMLIndexSQL generates a primary or secondary XML index.
+// XML indexes always live with the base table, so no storage clause is emitted.
+func (i *Index) generateXMLIndexSQL() string {
+	if i.XMLPrimaryIndex == "" {
+		return fmt.Sprintf("CREATE PRIMARY XML INDEX %s ON %s.%s (%s)",
+			QuoteIdent(i.Name), QuoteIdent(i.SchemaName), QuoteIdent(i.TableName), QuoteIdent(i.firstColumn()))
+	}
+	return fmt.Sprintf("CREATE XML INDEX %s ON %s.%s (%s) USING XML INDEX %s FOR %s",
+		QuoteIdent(i.Name), QuoteIdent(i.SchemaName), QuoteIdent(i.TableName), QuoteIdent(i.firstColumn()), QuoteIdent(i.XMLPrimaryIndex), i.XMLSecondaryType)
+}
+
+// generateSpatialIndexSQL generates a spatial index with its tessellation scheme
+func (i *Index) generateSpatialIndexSQL(rebuild bool) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("CREATE SPATIAL INDEX %s ON %s.%s (%s)",
+		QuoteIdent(i.Name), QuoteIdent(i.SchemaName), QuoteIdent(i.TableName), QuoteIdent(i.firstColumn())))
+	if i.TessellationScheme != "" {
+		sb.WriteString(" USING " + i.TessellationScheme)
+	}
+	var opts []string
+	if i.BoundingBox != "" {
+		opts = append(opts, "BOUNDING_BOX = "+i.BoundingBox)
+	}
+	if rebuild {
+		opts = append(opts, "DROP_EXISTING = ON")
+	}
+	if len(opts) > 0 {
+		sb.WriteString(" WITH (" + strings.Join(opts, ", ") + ")")
+	}
+	sb.WriteString(i.placementClause(rebuild))
+	return sb.String()
+}
+
+// generateColumnstoreIndexSQL generates a clustered or nonclustered columnstore index.
+// Columnstore columns are reported as included columns; a clustered columnstore
+// index covers the whole table and takes no column list.
+func (i *Index) generateColumnstoreIndexSQL(rebuild bool) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("CREATE %s INDEX %s ON %s.%s", i.Type, QuoteIdent(i.Name), QuoteIdent(i.SchemaName), QuoteIdent(i.TableName)))
+
+	if i.Type == IndexTypeNonclusteredColumnstore {
+		var cols []string
+		for _, col := range i.Columns {
+			cols = append(cols, QuoteIdent(col.Name))
+		}
+		sb.WriteString(fmt.Sprintf(" (%s)", strings.Join(cols, ", ")))
+		if i.FilterDefinition != "" {
+			sb.WriteString(fmt.Sprintf(" WHERE %s", i.FilterDefinition))
+		}
+	}
+
+	if rebuild {
+		sb.WriteString(" WITH (DROP_EXISTING = ON)")
+	}
+	sb.WriteString(i.placementClause(rebuild))
+	return sb.String()
+}
+
 // ForeignKeyColumn represents a column mapping in a foreign key
 type ForeignKeyColumn struct {
 	ColumnName           string
@@ -175,32 +501,45 @@ type ForeignKeyColumn struct {
 
 // ForeignKey represents a foreign key constraint
 type ForeignKey struct {
-	Name                   string
-	SchemaName             string
-	TableName              string
-	ReferencedSchemaName   string
-	ReferencedTableName    string
-	DeleteAction           string
-	UpdateAction           string
-	Columns                []ForeignKeyColumn
+	Name                 string
+	SchemaName           string
+	TableName            string
+	ReferencedSchemaName string
+	ReferencedTableName  string
+	DeleteAction         string
+	UpdateAction         string
+	IsDisabled           bool // Created or altered WITH NOCHECK CONSTRAINT
+	IsNotTrusted         bool // Existing rows were not validated (WITH NOCHECK)
+	Columns              []ForeignKeyColumn
 }
 
 // GenerateSQL generates the foreign key constraint SQL
 func (fk *ForeignKey) GenerateSQL() string {
+	return fk.GenerateSQLFor(TSQL)
+}
+
+// GenerateDropSQL generates the statement that drops the foreign key constraint
+func (fk *ForeignKey) GenerateDropSQL() string {
+	return dropConstraintSQL(fk.SchemaName, fk.TableName, fk.Name)
+}
+
+// GenerateSQLFor generates the foreign key constraint SQL in the given dialect
+func (fk *ForeignKey) GenerateSQLFor(d Dialect) string {
 	var sb strings.Builder
 
-	sb.WriteString(fmt.Sprintf("ALTER TABLE [%s].[%s] ADD CONSTRAINT [%s] FOREIGN KEY (\n",
-		fk.SchemaName, fk.TableName, fk.Name))
+	noCheck := d.StorageOptions() && (fk.IsNotTrusted || fk.IsDisabled)
+	sb.WriteString(fmt.Sprintf("ALTER TABLE %s.%s%s ADD CONSTRAINT %s FOREIGN KEY (\n",
+		d.QuoteIdent(fk.SchemaName), d.QuoteIdent(fk.TableName), noCheckClause(noCheck), d.QuoteIdent(fk.Name)))
 
 	var cols []string
 	var refCols []string
 	for _, c := range fk.Columns {
-		cols = append(cols, fmt.Sprintf("    [%s]", c.ColumnName))
-		refCols = append(refCols, fmt.Sprintf("    [%s]", c.ReferencedColumnName))
+		cols = append(cols, fmt.Sprintf("    %s", d.QuoteIdent(c.ColumnName)))
+		refCols = append(refCols, fmt.Sprintf("    %s", d.QuoteIdent(c.ReferencedColumnName)))
 	}
 
 	sb.WriteString(strings.Join(cols, ",\n"))
-	sb.WriteString(fmt.Sprintf("\n) REFERENCES [%s].[%s] (\n", fk.ReferencedSchemaName, fk.ReferencedTableName))
+	sb.WriteString(fmt.Sprintf("\n) REFERENCES %s.%s (\n", d.QuoteIdent(fk.ReferencedSchemaName), d.QuoteIdent(fk.ReferencedTableName)))
 	sb.WriteString(strings.Join(refCols, ",\n"))
 	sb.WriteString("\n)")
 
@@ -211,22 +550,176 @@ func (fk *ForeignKey) GenerateSQL() string {
 		sb.WriteString(fmt.Sprintf(" ON UPDATE %s", strings.ReplaceAll(fk.UpdateAction, "_", " ")))
 	}
 
+	if fk.IsDisabled && d.StorageOptions() {
+		sb.WriteString(";\n" + ConstraintStateSQL(fk.SchemaName, fk.TableName, fk.Name, true, true))
+	}
+
 	return sb.String()
 }
 
 // CheckConstraint represents a check constraint
 type CheckConstraint struct {
-	Name       string
-	SchemaName string
-	TableName  string
-	Definition string
-	IsDisabled bool
+	Name         string
+	SchemaName   string
+	TableName    string
+	Definition   string
+	IsDisabled   bool
+	IsNotTrusted bool // Existing rows were not validated (WITH NOCHECK)
 }
 
 // GenerateSQL generates the check constraint SQL
 func (cc *CheckConstraint) GenerateSQL() string {
-	return fmt.Sprintf("ALTER TABLE [%s].[%s] ADD CONSTRAINT [%s] CHECK %s",
-		cc.SchemaName, cc.TableName, cc.Name, cc.Definition)
+	return cc.GenerateSQLFor(TSQL)
+}
+
+// GenerateDropSQL generates the statement that drops the check constraint
+func (cc *CheckConstraint) GenerateDropSQL() string {
+	return dropConstraintSQL(cc.SchemaName, cc.TableName, cc.Name)
+}
+
+// GenerateSQLFor generates the check constraint SQL in the given dialect.
+// bitColumns names the bit columns of the table.
+func (cc *CheckConstraint) GenerateSQLFor(d Dialect, bitColumns ...string) string {
+	noCheck := d.StorageOptions() && (cc.IsNotTrusted || cc.IsDisabled)
+	sql := fmt.Sprintf("ALTER TABLE %s.%s%s ADD CONSTRAINT %s CHECK %s",
+		d.QuoteIdent(cc.SchemaName), d.QuoteIdent(cc.TableName), noCheckClause(noCheck), d.QuoteIdent(cc.Name), d.TranslateExpression(cc.Definition, bitColumns))
+	if cc.IsDisabled && d.StorageOptions() {
+		sql += ";\n" + ConstraintStateSQL(cc.SchemaName, cc.TableName, cc.Name, true, true)
+	}
+	return sql
+}
+
+// dropConstraintSQL generates the ALTER TABLE ... DROP CONSTRAINT statement
+func dropConstraintSQL(schemaName, tableName, name string) string {
+	return fmt.Sprintf("ALTER TABLE %s.%s DROP CONSTRAINT %s", QuoteIdent(schemaName), QuoteIdent(tableName), QuoteIdent(name))
+}
+
+// noCheckClause returns the WITH NOCHECK option for constraints that skip validation of existing rows
+func noCheckClause(noCheck bool) string {
+	if noCheck {
+		return " WITH NOCHECK"
+	}
+	return ""
+}
+
+// ConstraintState describes whether a foreign key or check constraint is
+// enforced and trusted by the optimizer
+func ConstraintState(disabled, notTrusted bool) string {
+	switch {
+	case disabled:
+		return "DISABLED"
+	case notTrusted:
+		return "NOT TRUSTED"
+	default:
+		return "TRUSTED"
+	}
+}
+
+// ConstraintStateSQL returns the ALTER TABLE statement that puts an existing
+// constraint into the given state
+func ConstraintStateSQL(schemaName, tableName, name string, disabled, notTrusted bool) string {
+	table := fmt.Sprintf("%s.%s", QuoteIdent(schemaName), QuoteIdent(tableName))
+	switch {
+	case disabled:
+		return fmt.Sprintf("ALTER TABLE %s NOCHECK CONSTRAINT %s", table, QuoteIdent(name))
+	case notTrusted:
+		return fmt.Sprintf("ALTER TABLE %s CHECK CONSTRAINT %s", table, QuoteIdent(name))
+	default:
+		return fmt.Sprintf("ALTER TABLE %s WITH CHECK CHECK CONSTRAINT %s", table, QuoteIdent(name))
+	}
+}
+
+// UniqueConstraint represents a UNIQUE constraint (as opposed to a unique index)
+type UniqueConstraint struct {
+	Name        string
+	SchemaName  string
+	TableName   string
+	IsClustered bool
+	Columns     []IndexColumn
+}
+
+// GenerateSQL generates the ALTER TABLE ... ADD CONSTRAINT ... UNIQUE statement
+func (uc *UniqueConstraint) GenerateSQL() string {
+	return uc.GenerateSQLFor(TSQL)
+}
+
+// GenerateDropSQL generates the statement that drops the unique constraint
+func (uc *UniqueConstraint) GenerateDropSQL() string {
+	return dropConstraintSQL(uc.SchemaName, uc.TableName, uc.Name)
+}
+
+// GenerateSQLFor generates the unique constraint SQL in the given dialect
+func (uc *UniqueConstraint) GenerateSQLFor(d Dialect) string {
+	return fmt.Sprintf("ALTER TABLE %s.%s ADD %s", d.QuoteIdent(uc.SchemaName), d.QuoteIdent(uc.TableName), uc.definition(d))
+}
+
+// definition returns the CONSTRAINT ... UNIQUE clause shared by ALTER TABLE and CREATE TABLE
+func (uc *UniqueConstraint) definition(d Dialect) string {
+	var cols []string
+	for _, col := range uc.Columns {
+		colDef := d.QuoteIdent(col.Name)
+		if col.IsDescending && d.StorageOptions() {
+			colDef += " DESC"
+		}
+		cols = append(cols, colDef)
+	}
+	clustering := ""
+	if d.StorageOptions() {
+		clustering = " NONCLUSTERED"
+		if uc.IsClustered {
+			clustering = " CLUSTERED"
+		}
+	}
+	return fmt.Sprintf("CONSTRAINT %s UNIQUE%s (%s)", d.QuoteIdent(uc.Name), clustering, strings.Join(cols, ", "))
+}
+
+// Statistic represents a statistics object on table columns that does not
+// back an index, either created with CREATE STATISTICS or automatically by
+// the query optimizer
+type Statistic struct {
+	Name             string
+	SchemaName       string
+	TableName        string
+	Columns          []string
+	FilterDefinition string
+	NoRecompute      bool    // Not updated automatically when the data changes
+	IsAutoCreated    bool    // Created by the optimizer (AUTO_CREATE_STATISTICS)
+	SamplePercent    float64 // Persisted sample rate, 0 when statistics are sampled at the default rate
+}
+
+// GenerateSQL generates the CREATE STATISTICS statement
+func (st *Statistic) GenerateSQL() string {
+	return st.GenerateSQLFor(TSQL)
+}
+
+// GenerateSQLFor generates the CREATE STATISTICS statement for the given
+// T-SQL version. Before PERSIST_SAMPLE_PERCENT, the sample rate only applies
+// to the initial update.
+func (st *Statistic) GenerateSQLFor(d Dialect) string {
+	cols := make([]string, len(st.Columns))
+	for i, col := range st.Columns {
+		cols[i] = QuoteIdent(col)
+	}
+	sql := fmt.Sprintf("CREATE STATISTICS %s ON %s.%s (%s)",
+		QuoteIdent(st.Name), QuoteIdent(st.SchemaName), QuoteIdent(st.TableName), strings.Join(cols, ", "))
+	if st.FilterDefinition != "" {
+		sql += " WHERE " + st.FilterDefinition
+	}
+
+	var opts []string
+	if st.SamplePercent > 0 {
+		opts = append(opts, fmt.Sprintf("SAMPLE %s PERCENT", strconv.FormatFloat(st.SamplePercent, 'f', -1, 64)))
+		if d.Supports(FeaturePersistSamplePercent) {
+			opts = append(opts, "PERSIST_SAMPLE_PERCENT = ON")
+		}
+	}
+	if st.NoRecompute {
+		opts = append(opts, "NORECOMPUTE")
+	}
+	if len(opts) > 0 {
+		sql += " WITH " + strings.Join(opts, ", ")
+	}
+	return sql
 }
 
 // DefaultConstraint represents a default constraint
@@ -240,57 +733,196 @@ type DefaultConstraint struct {
 
 // Table represents a database table
 type Table struct {
-	SchemaName       string
-	Name             string
-	Columns          []Column
-	PrimaryKey       *Index
-	Indexes          []Index
-	ForeignKeys      []ForeignKey
-	CheckConstraints []CheckConstraint
+	SchemaName        string
+	Name              string
+	Columns           []Column
+	PrimaryKey        *Index
+	Indexes           []Index
+	ForeignKeys       []ForeignKey
+	CheckConstraints  []CheckConstraint
+	UniqueConstraints []UniqueConstraint
+	PartitionScheme   string // Partition scheme of the heap or clustered index
+	PartitionColumn   string // Partitioning column
+	FileGroup         string // Filegroup of the heap or clustered index
+	IsSystemVersioned bool   // System-versioned temporal table
+	IsMemoryOptimized bool   // In-Memory OLTP table
+	Durability        string // Of a memory-optimized table: SCHEMA_AND_DATA or SCHEMA_ONLY
+	IsHistoryTable    bool   // History table of a system-versioned table
+	HistorySchema     string // Schema of the history table when system-versioned
+	HistoryTable      string // History table when system-versioned
+}
+
+// BitColumns returns the names of the bit columns of the table
+func (t *Table) BitColumns() []string {
+	var names []string
+	for _, c := range t.Columns {
+		if strings.EqualFold(c.DataType, "bit") {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+// PeriodColumns returns the ROW START and ROW END columns of a temporal
+// table, or empty strings when it has no SYSTEM_TIME period
+func (t *Table) PeriodColumns() (start, end string) {
+	for _, c := range t.Columns {
+		switch c.GeneratedAlways {
+		case "ROW START":
+			start = c.Name
+		case "ROW END":
+			end = c.Name
+		}
+	}
+	return start, end
+}
+
+// SystemVersioningSQL returns the SYSTEM_VERSIONING = ON clause naming the history table
+func (t *Table) SystemVersioningSQL() string {
+	if t.HistoryTable == "" {
+		return "SYSTEM_VERSIONING = ON"
+	}
+	return fmt.Sprintf("SYSTEM_VERSIONING = ON (HISTORY_TABLE = %s.%s)", QuoteIdent(t.HistorySchema), QuoteIdent(t.HistoryTable))
+}
+
+// IndexesInline reports whether the CREATE TABLE statement in the given
+// dialect declares the table's indexes, as memory-optimized tables take no
+// CREATE INDEX
+func (t *Table) IndexesInline(d Dialect) bool {
+	return t.IsMemoryOptimized && d.StorageOptions() && d.Supports(FeatureMemoryOptimizedTables)
+}
+
+// tableOptions returns the options of the WITH clause of the CREATE TABLE
+// statement in the given dialect
+func (t *Table) tableOptions(d Dialect) []string {
+	var opts []string
+	if t.IndexesInline(d) {
+		opts = append(opts, "MEMORY_OPTIMIZED = ON")
+		if t.Durability != "" {
+			opts = append(opts, "DURABILITY = "+t.Durability)
+		}
+	}
+	if t.IsSystemVersioned && d.Supports(FeatureTemporalTables) {
+		opts = append(opts, t.SystemVersioningSQL())
+	}
+	return opts
 }
 
 // GenerateSQL generates the CREATE TABLE statement
 func (t *Table) GenerateSQL() string {
+	return t.generateSQL(TSQL, false)
+}
+
+// SkippedNoColumns is generated instead of the CREATE TABLE statement of a
+// table whose columns were not extracted
+const SkippedNoColumns = "-- skipped: no columns extracted"
+
+// GenerateDropSQL generates the DROP TABLE statement
+func (t *Table) GenerateDropSQL() string {
+	return fmt.Sprintf("DROP TABLE %s.%s", QuoteIdent(t.SchemaName), QuoteIdent(t.Name))
+}
+
+// GenerateSQLFor generates the CREATE TABLE statement in the given dialect.
+// With inlineConstraints, named default and unique constraints are declared
+// inside the table body.
+func (t *Table) GenerateSQLFor(d Dialect, inlineConstraints bool) string {
+	return t.generateSQL(d, inlineConstraints)
+}
+
+// generateSQL generates the CREATE TABLE statement, optionally with inline
+// constraints. A table without columns cannot be created, so only a comment
+// saying it was skipped is returned.
+func (t *Table) generateSQL(d Dialect, inlineConstraints bool) string {
+	if len(t.Columns) == 0 {
+		return SkippedNoColumns
+	}
+
 	var sb strings.Builder
 
-	sb.WriteString(fmt.Sprintf("CREATE TABLE [%s].[%s] (\n", t.SchemaName, t.Name))
+	sb.WriteString(fmt.Sprintf("CREATE TABLE %s.%s (\n", d.QuoteIdent(t.SchemaName), d.QuoteIdent(t.Name)))
+
+	// Columns, in ordinal order
+	columns := make([]Column, len(t.Columns))
+	copy(columns, t.Columns)
+	sort.SliceStable(columns, func(i, j int) bool {
+		return columns[i].OrdinalPosition < columns[j].OrdinalPosition
+	})
 
-	// Columns
 	var colDefs []string
-	for _, col := range t.Columns {
-		colDefs = append(colDefs, "    "+col.GenerateSQL())
+	for _, col := range columns {
+		colDefs = append(colDefs, "    "+col.generateSQL(d, inlineConstraints))
 	}
 
 	// Primary Key constraint inline
 	if t.PrimaryKey != nil && len(t.PrimaryKey.Columns) > 0 {
 		var pkCols []string
 		for _, col := range t.PrimaryKey.Columns {
-			colDef := fmt.Sprintf("[%s]", col.Name)
-			if col.IsDescending {
+			colDef := d.QuoteIdent(col.Name)
+			if col.IsDescending && d.StorageOptions() {
 				colDef += " DESC"
 			}
 			pkCols = append(pkCols, colDef)
 		}
-		clustered := "CLUSTERED"
-		if !t.PrimaryKey.IsClustered {
-			clustered = "NONCLUSTERED"
+		clustered, bucketCount := "", ""
+		if d.StorageOptions() {
+			clustered = " CLUSTERED"
+			if !t.PrimaryKey.IsClustered {
+				clustered = " NONCLUSTERED"
+			}
+			if t.PrimaryKey.Type == IndexTypeNonclusteredHash && t.IndexesInline(d) {
+				clustered = " NONCLUSTERED HASH"
+				bucketCount = t.PrimaryKey.bucketCountClause()
+			}
 		}
-		pkDef := fmt.Sprintf("    CONSTRAINT [%s] PRIMARY KEY %s (%s)",
-			t.PrimaryKey.Name, clustered, strings.Join(pkCols, ", "))
+		pkDef := fmt.Sprintf("    CONSTRAINT %s PRIMARY KEY%s (%s)%s",
+			d.QuoteIdent(t.PrimaryKey.Name), clustered, strings.Join(pkCols, ", "), bucketCount)
 		colDefs = append(colDefs, pkDef)
 	}
 
+	// SYSTEM_TIME period of a temporal table
+	if start, end := t.PeriodColumns(); start != "" && end != "" && d.Supports(FeatureTemporalTables) {
+		colDefs = append(colDefs, fmt.Sprintf("    PERIOD FOR SYSTEM_TIME (%s, %s)", d.QuoteIdent(start), d.QuoteIdent(end)))
+	}
+
+	// Indexes of a memory-optimized table
+	if t.IndexesInline(d) {
+		for _, idx := range t.Indexes {
+			colDefs = append(colDefs, "    "+idx.inlineSQL())
+		}
+	}
+
+	// Unique constraints inline
+	if inlineConstraints {
+		for _, uc := range t.UniqueConstraints {
+			colDefs = append(colDefs, "    "+uc.definition(d))
+		}
+	}
+
 	sb.WriteString(strings.Join(colDefs, ",\n"))
 	sb.WriteString("\n)")
 
+	// Partitioning or filegroup placement; memory-optimized tables live in
+	// the memory-optimized filegroup
+	if d.StorageOptions() {
+		if !t.IndexesInline(d) {
+			sb.WriteString(storageClause(t.PartitionScheme, t.PartitionColumn, t.FileGroup))
+		}
+		if opts := t.tableOptions(d); len(opts) > 0 {
+			sb.WriteString(fmt.Sprintf("\nWITH (%s)", strings.Join(opts, ", ")))
+		}
+	}
+
 	return sb.String()
 }
 
 // View represents a database view
 type View struct {
-	SchemaName string
-	Name       string
-	Definition string
+	SchemaName           string
+	Name                 string
+	Definition           string
+	DefinitionHash       string // SHA-256 of Definition, extracted instead of it for hash-only comparisons
+	UsesAnsiNulls        bool   // ANSI_NULLS setting at creation
+	UsesQuotedIdentifier bool   // QUOTED_IDENTIFIER setting at creation
 }
 
 // GenerateSQL returns the view definition
@@ -298,11 +930,39 @@ func (v *View) GenerateSQL() string {
 	return v.Definition
 }
 
+// GenerateDropSQL generates the DROP VIEW statement
+func (v *View) GenerateDropSQL() string {
+	return fmt.Sprintf("DROP VIEW %s.%s", QuoteIdent(v.SchemaName), QuoteIdent(v.Name))
+}
+
+// Parameter represents a parameter of a stored procedure or function
+type Parameter struct {
+	Name         string // Including the leading @
+	Position     int
+	DataType     string
+	MaxLength    int
+	Precision    int
+	Scale        int
+	IsOutput     bool
+	IsReadOnly   bool // Table-valued parameters are always READONLY
+	HasDefault   bool // Read from the definition for T-SQL modules
+	DefaultValue string
+}
+
+// TypeSQL returns the data type with its length, precision, or scale
+func (p *Parameter) TypeSQL() string {
+	return formatDataType(p.DataType, p.MaxLength, p.Precision, p.Scale)
+}
+
 // StoredProcedure represents a stored procedure
 type StoredProcedure struct {
-	SchemaName string
-	Name       string
-	Definition string
+	SchemaName           string
+	Name                 string
+	Definition           string
+	DefinitionHash       string // SHA-256 of Definition, extracted instead of it for hash-only comparisons
+	UsesAnsiNulls        bool   // ANSI_NULLS setting at creation
+	UsesQuotedIdentifier bool   // QUOTED_IDENTIFIER setting at creation
+	Parameters           []Parameter
 }
 
 // GenerateSQL returns the procedure definition
@@ -310,12 +970,21 @@ func (sp *StoredProcedure) GenerateSQL() string {
 	return sp.Definition
 }
 
+// GenerateDropSQL generates the DROP PROCEDURE statement
+func (sp *StoredProcedure) GenerateDropSQL() string {
+	return fmt.Sprintf("DROP PROCEDURE %s.%s", QuoteIdent(sp.SchemaName), QuoteIdent(sp.Name))
+}
+
 // Function represents a user-defined function
 type Function struct {
-	SchemaName string
-	Name       string
-	Definition string
-	FuncType   string // SCALAR, TABLE, INLINE
+	SchemaName           string
+	Name                 string
+	Definition           string
+	DefinitionHash       string // SHA-256 of Definition, extracted instead of it for hash-only comparisons
+	FuncType             string // SCALAR, TABLE, INLINE
+	UsesAnsiNulls        bool   // ANSI_NULLS setting at creation
+	UsesQuotedIdentifier bool   // QUOTED_IDENTIFIER setting at creation
+	Parameters           []Parameter
 }
 
 // GenerateSQL returns the function definition
@@ -323,13 +992,33 @@ func (f *Function) GenerateSQL() string {
 	return f.Definition
 }
 
-// Trigger represents a database trigger
+// GenerateDropSQL generates the DROP FUNCTION statement
+func (f *Function) GenerateDropSQL() string {
+	return fmt.Sprintf("DROP FUNCTION %s.%s", QuoteIdent(f.SchemaName), QuoteIdent(f.Name))
+}
+
+// TriggerScope is what a trigger is defined on
+type TriggerScope string
+
+const (
+	TriggerScopeTable    TriggerScope = "TABLE"    // DML trigger on a table
+	TriggerScopeDatabase TriggerScope = "DATABASE" // DDL trigger ON DATABASE
+)
+
+// Trigger represents a database trigger. Database-scoped DDL triggers have
+// no schema or table.
 type Trigger struct {
-	SchemaName  string
-	TableName   string
-	Name        string
-	Definition  string
-	IsDisabled  bool
+	SchemaName           string
+	TableName            string
+	Name                 string
+	Scope                TriggerScope // Empty in older snapshots, meaning TriggerScopeTable
+	Definition           string
+	DefinitionHash       string // SHA-256 of Definition, extracted instead of it for hash-only comparisons
+	IsDisabled           bool
+	IsInsteadOf          bool     // INSTEAD OF rather than AFTER
+	Events               []string // Firing events: INSERT, UPDATE, DELETE, or DDL events such as CREATE_TABLE
+	UsesAnsiNulls        bool     // ANSI_NULLS setting at creation
+	UsesQuotedIdentifier bool     // QUOTED_IDENTIFIER setting at creation
 }
 
 // GenerateSQL returns the trigger definition
@@ -337,44 +1026,331 @@ func (tr *Trigger) GenerateSQL() string {
 	return tr.Definition
 }
 
+// IsDatabaseScoped reports whether the trigger is a DDL trigger ON DATABASE
+func (tr *Trigger) IsDatabaseScoped() bool {
+	return tr.Scope == TriggerScopeDatabase
+}
+
+// GenerateDropSQL generates the DROP TRIGGER statement. DML triggers are
+// named by the schema of their table.
+func (tr *Trigger) GenerateDropSQL() string {
+	if tr.IsDatabaseScoped() {
+		return fmt.Sprintf("DROP TRIGGER %s ON DATABASE", QuoteIdent(tr.Name))
+	}
+	return fmt.Sprintf("DROP TRIGGER %s.%s", QuoteIdent(tr.SchemaName), QuoteIdent(tr.Name))
+}
+
+// Timing returns AFTER or INSTEAD OF
+func (tr *Trigger) Timing() string {
+	if tr.IsInsteadOf {
+		return "INSTEAD OF"
+	}
+	return "AFTER"
+}
+
+// StateSQL returns the ENABLE or DISABLE TRIGGER statement for the trigger's state
+func (tr *Trigger) StateSQL() string {
+	action := "ENABLE"
+	if tr.IsDisabled {
+		action = "DISABLE"
+	}
+	if tr.IsDatabaseScoped() {
+		return fmt.Sprintf("%s TRIGGER %s ON DATABASE", action, QuoteIdent(tr.Name))
+	}
+	return fmt.Sprintf("%s TRIGGER %s ON %s.%s", action, QuoteIdent(tr.Name), QuoteIdent(tr.SchemaName), QuoteIdent(tr.TableName))
+}
+
+// ModuleSetOptions returns the SET statements that restore the
+// QUOTED_IDENTIFIER and ANSI_NULLS settings a module was created with. Both
+// are parse-time settings stored with the module, so they must be in effect
+// in the batch before CREATE runs.
+func ModuleSetOptions(quotedIdentifier, ansiNulls bool) string {
+	onOff := func(on bool) string {
+		if on {
+			return "ON"
+		}
+		return "OFF"
+	}
+	return fmt.Sprintf("SET QUOTED_IDENTIFIER %s;\nSET ANSI_NULLS %s;", onOff(quotedIdentifier), onOff(ansiNulls))
+}
+
 // Schema represents a database schema
 type Schema struct {
 	Name  string
-	Owner string
+	Owner string // Empty when the owner principal no longer exists
 }
 
 // GenerateSQL generates the CREATE SCHEMA statement
 func (s *Schema) GenerateSQL() string {
+	return s.GenerateSQLFor(TSQL)
+}
+
+// GenerateSQLFor generates the CREATE SCHEMA statement in the given dialect
+func (s *Schema) GenerateSQLFor(d Dialect) string {
 	if s.Owner != "" {
-		return fmt.Sprintf("CREATE SCHEMA [%s] AUTHORIZATION [%s]", s.Name, s.Owner)
+		return fmt.Sprintf("CREATE SCHEMA %s AUTHORIZATION %s", d.QuoteIdent(s.Name), d.QuoteIdent(s.Owner))
 	}
-	return fmt.Sprintf("CREATE SCHEMA [%s]", s.Name)
+	return fmt.Sprintf("CREATE SCHEMA %s", d.QuoteIdent(s.Name))
+}
+
+// PartitionFunction represents a partition function
+type PartitionFunction struct {
+	Name       string
+	DataType   string // Parameter data type
+	MaxLength  int
+	Precision  int
+	Scale      int
+	RangeRight bool     // RANGE RIGHT when true, RANGE LEFT otherwise
+	Boundaries []string // Boundary values as SQL literals
+}
+
+// GenerateSQL generates the CREATE PARTITION FUNCTION statement
+func (pf *PartitionFunction) GenerateSQL() string {
+	rangeType := "LEFT"
+	if pf.RangeRight {
+		rangeType = "RIGHT"
+	}
+	return fmt.Sprintf("CREATE PARTITION FUNCTION %s (%s) AS RANGE %s FOR VALUES (%s)",
+		QuoteIdent(pf.Name), formatDataType(pf.DataType, pf.MaxLength, pf.Precision, pf.Scale),
+		rangeType, strings.Join(pf.Boundaries, ", "))
+}
+
+// PartitionScheme represents a partition scheme
+type PartitionScheme struct {
+	Name         string
+	FunctionName string
+	FileGroups   []string // Destination filegroups in partition order
+}
+
+// GenerateSQL generates the CREATE PARTITION SCHEME statement
+func (ps *PartitionScheme) GenerateSQL() string {
+	var fgs []string
+	for _, fg := range ps.FileGroups {
+		fgs = append(fgs, QuoteIdent(fg))
+	}
+	return fmt.Sprintf("CREATE PARTITION SCHEME %s AS PARTITION %s TO (%s)",
+		QuoteIdent(ps.Name), QuoteIdent(ps.FunctionName), strings.Join(fgs, ", "))
+}
+
+// Permission represents a GRANT or DENY on the database, a schema or an object
+type Permission struct {
+	State          string // GRANT, DENY, REVOKE or GRANT_WITH_GRANT_OPTION (sys.database_permissions.state_desc)
+	PermissionName string // e.g. SELECT, EXECUTE, CONNECT
+	ClassDesc      string // DATABASE, SCHEMA or OBJECT_OR_COLUMN
+	SchemaName     string // Schema of the securable (empty for database permissions)
+	ObjectName     string // Object of the securable (empty for schema and database permissions)
+	ColumnName     string // Column for column-level permissions
+	Grantee        string
+}
+
+// Securable returns the ON clause target of the permission, or an empty
+// string for database-level permissions
+func (p *Permission) Securable() string {
+	switch p.ClassDesc {
+	case "SCHEMA":
+		return fmt.Sprintf("SCHEMA::%s", QuoteIdent(p.SchemaName))
+	case "OBJECT_OR_COLUMN":
+		if p.ColumnName != "" {
+			return fmt.Sprintf("%s.%s (%s)", QuoteIdent(p.SchemaName), QuoteIdent(p.ObjectName), QuoteIdent(p.ColumnName))
+		}
+		return fmt.Sprintf("%s.%s", QuoteIdent(p.SchemaName), QuoteIdent(p.ObjectName))
+	default:
+		return ""
+	}
+}
+
+// onClause returns " ON <securable>" or an empty string for database permissions
+func (p *Permission) onClause() string {
+	if securable := p.Securable(); securable != "" {
+		return " ON " + securable
+	}
+	return ""
+}
+
+// GenerateSQL generates the GRANT, DENY or REVOKE statement
+func (p *Permission) GenerateSQL() string {
+	switch p.State {
+	case "GRANT_WITH_GRANT_OPTION":
+		return fmt.Sprintf("GRANT %s%s TO %s WITH GRANT OPTION", p.PermissionName, p.onClause(), QuoteIdent(p.Grantee))
+	case "REVOKE":
+		return p.RevokeSQL()
+	default:
+		return fmt.Sprintf("%s %s%s TO %s", p.State, p.PermissionName, p.onClause(), QuoteIdent(p.Grantee))
+	}
+}
+
+// RevokeSQL generates the REVOKE statement that removes the permission
+func (p *Permission) RevokeSQL() string {
+	sql := fmt.Sprintf("REVOKE %s%s FROM %s", p.PermissionName, p.onClause(), QuoteIdent(p.Grantee))
+	if p.State == "GRANT_WITH_GRANT_OPTION" {
+		sql += " CASCADE"
+	}
+	return sql
+}
+
+// RevokeGrantOptionSQL generates the REVOKE GRANT OPTION FOR statement that
+// keeps the permission but removes the right to grant it to others
+func (p *Permission) RevokeGrantOptionSQL() string {
+	return fmt.Sprintf("REVOKE GRANT OPTION FOR %s%s FROM %s CASCADE", p.PermissionName, p.onClause(), QuoteIdent(p.Grantee))
 }
 
 // DatabaseSchema represents the complete database schema
 type DatabaseSchema struct {
-	DatabaseName     string
-	Schemas          []Schema
-	Tables           []Table
-	Views            []View
-	StoredProcedures []StoredProcedure
-	Functions        []Function
-	Triggers         []Trigger
+	DatabaseName         string
+	ProductVersion       string // SERVERPROPERTY('ProductVersion') of the server, e.g. 16.0.1000.6
+	Collation            string // Database default collation
+	ServerCollation      string // Server default collation, which new databases get
+	ScopedConfigurations []ScopedConfiguration
+	Schemas              []Schema
+	PartitionFunctions   []PartitionFunction
+	PartitionSchemes     []PartitionScheme
+	Tables               []Table
+	Views                []View
+	StoredProcedures     []StoredProcedure
+	Functions            []Function
+	Triggers             []Trigger
+	Permissions          []Permission
+	Statistics           []Statistic
+	TableData            []TableData // Rows of tables dumped with --data-for
+}
+
+// UnreadableModules returns the views, procedures, functions and triggers
+// whose definitions came back empty, because they are encrypted or the login
+// lacks VIEW DEFINITION on them
+func (s *DatabaseSchema) UnreadableModules() []string {
+	var names []string
+	for _, v := range s.Views {
+		if v.Definition == "" {
+			names = append(names, fmt.Sprintf("view %s.%s", v.SchemaName, v.Name))
+		}
+	}
+	for _, p := range s.StoredProcedures {
+		if p.Definition == "" {
+			names = append(names, fmt.Sprintf("procedure %s.%s", p.SchemaName, p.Name))
+		}
+	}
+	for _, f := range s.Functions {
+		if f.Definition == "" {
+			names = append(names, fmt.Sprintf("function %s.%s", f.SchemaName, f.Name))
+		}
+	}
+	for _, tr := range s.Triggers {
+		if tr.Definition == "" && tr.IsDatabaseScoped() {
+			names = append(names, fmt.Sprintf("database trigger %s", tr.Name))
+		} else if tr.Definition == "" {
+			names = append(names, fmt.Sprintf("trigger %s.%s", tr.SchemaName, tr.Name))
+		}
+	}
+	return names
+}
+
+// numericScopedConfigurations are scoped configurations whose value is a number rather than ON/OFF
+var numericScopedConfigurations = map[string]bool{
+	"MAXDOP": true,
+	"PAUSED_RESUMABLE_INDEX_ABORT_DURATION_MINUTES": true,
+}
+
+// stringScopedConfigurations are scoped configurations whose value is a
+// string literal unless it is OFF
+var stringScopedConfigurations = map[string]bool{
+	"LEDGER_DIGEST_STORAGE_ENDPOINT": true,
+}
+
+// scopedConfigurationDefaults are the default values of the scoped
+// configurations of SQL Server 2016, whose catalog does not flag defaults
+var scopedConfigurationDefaults = map[string]string{
+	"MAXDOP":                        "0",
+	"LEGACY_CARDINALITY_ESTIMATION": "0",
+	"PARAMETER_SNIFFING":            "1",
+	"QUERY_OPTIMIZER_HOTFIXES":      "0",
+}
+
+// ScopedConfiguration represents a database scoped configuration such as MAXDOP
+type ScopedConfiguration struct {
+	Name              string
+	Value             string
+	ValueForSecondary string // Empty when secondaries use the primary value
+	ValueIsDefault    bool   // The catalog reports the value as the default
+}
+
+// IsDefault reports whether the configuration has its default value, on
+// the primary and on secondaries
+func (sc *ScopedConfiguration) IsDefault() bool {
+	if sc.ValueForSecondary != "" {
+		return false
+	}
+	if sc.ValueIsDefault {
+		return true
+	}
+	value, known := scopedConfigurationDefaults[strings.ToUpper(sc.Name)]
+	return known && sc.Value == value
+}
+
+// GenerateSQL generates the ALTER DATABASE SCOPED CONFIGURATION statements
+func (sc *ScopedConfiguration) GenerateSQL() string {
+	sql := fmt.Sprintf("ALTER DATABASE SCOPED CONFIGURATION SET %s = %s", sc.Name, sc.formatValue(sc.Value))
+	if sc.ValueForSecondary != "" {
+		sql += fmt.Sprintf(";\nALTER DATABASE SCOPED CONFIGURATION FOR SECONDARY SET %s = %s",
+			sc.Name, sc.formatValue(sc.ValueForSecondary))
+	}
+	return sql
+}
+
+// formatValue renders a catalog value as it is written in ALTER DATABASE SCOPED CONFIGURATION.
+// The catalog reports switches as 0/1, which the statement spells OFF/ON.
+func (sc *ScopedConfiguration) formatValue(value string) string {
+	if numericScopedConfigurations[strings.ToUpper(sc.Name)] {
+		return value
+	}
+	if stringScopedConfigurations[strings.ToUpper(sc.Name)] && !strings.EqualFold(value, "OFF") {
+		return quoteString(value)
+	}
+	switch value {
+	case "0":
+		return "OFF"
+	case "1":
+		return "ON"
+	}
+	return value
 }
 
 // DumpOptions defines options for DDL extraction
 type DumpOptions struct {
-	IncludeTables       bool
-	IncludeViews        bool
-	IncludeProcedures   bool
-	IncludeFunctions    bool
-	IncludeTriggers     bool
-	IncludeIndexes      bool
-	IncludeForeignKeys  bool
-	IncludeConstraints  bool
-	SchemaFilter        []string // Filter by schema names
-	TableFilter         []string // Filter by table names
-	OutputFormat        string   // "sql", "json"
+	IncludeTables         bool
+	IncludeViews          bool
+	IncludeProcedures     bool
+	IncludeFunctions      bool
+	IncludeTriggers       bool
+	IncludeIndexes        bool
+	IncludeForeignKeys    bool
+	IncludeConstraints    bool
+	IncludeSchemaDDL      bool      // Emit CREATE SCHEMA statements
+	IncludeFileGroups     bool      // Emit ON [filegroup] placement
+	IncludePermissions    bool      // Extract GRANT/DENY statements
+	IncludeStatistics     bool      // Extract user-created statistics
+	IncludeAutoStatistics bool      // Also extract statistics created automatically by the optimizer
+	IncludeSystemObjects  bool      // Also extract objects shipped with SQL Server and built-in schemas
+	InlineConstraints     bool      // Declare named default and unique constraints inside CREATE TABLE
+	OrderByDependency     bool      // Emit CREATE TABLE statements in foreign key dependency order
+	Dialect               string    // Target SQL dialect: "tsql" (default) or "postgres"
+	SchemaFilter          []string  // Filter by schema names
+	TableFilter           []string  // Filter by table names
+	SchemaExclude         []string  // Exclude schema names (takes precedence over SchemaFilter)
+	TableExclude          []string  // Exclude table names (takes precedence over TableFilter)
+	ObjectFilter          []string  // Restrict to named objects of any type, e.g. dbo.MyProc
+	IgnoreMissingObjects  bool      // Skip ObjectFilter names that do not exist instead of failing
+	ModifiedSince         time.Time // Only objects modified after this time (partial snapshot)
+	DataFor               []string  // Tables whose rows are dumped as INSERT statements
+	OutputFormat          string    // "sql", "json"
+	DefinitionHashesOnly  bool      // Fetch a server-computed hash of module definitions instead of their text; hashes ignore whitespace
+	FormatSQL             bool      // Uppercase keywords and normalize indentation of dumped module definitions
+	Guarded               bool      // Wrap object creation in IF NOT EXISTS checks
+}
+
+// IsPartial reports whether the options extract only some objects, so that
+// database settings and schemas are skipped
+func (o *DumpOptions) IsPartial() bool {
+	return len(o.ObjectFilter) > 0 || !o.ModifiedSince.IsZero()
 }
 
 // DefaultDumpOptions returns default options with all objects included
@@ -388,6 +1364,8 @@ func DefaultDumpOptions() *DumpOptions {
 		IncludeIndexes:     true,
 		IncludeForeignKeys: true,
 		IncludeConstraints: true,
+		IncludeSchemaDDL:   true,
+		IncludeFileGroups:  true,
 		OutputFormat:       "sql",
 	}
 }
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQuoteIdent(t *testing.T) {
+	tests := []struct {
+		name     string
+		tsql     string
+		postgres string
+	}{
+		{"Users", "[Users]", `"Users"`},
+		{"weird]name", "[weird]]name]", `"weird]name"`},
+		{"]]", "[]]]]]", `"]]"`},
+		{"[bracketed]", "[[bracketed]]]", `"[bracketed]"`},
+		{"O'Brien", "[O'Brien]", `"O'Brien"`},
+		{`say "hi"`, `[say "hi"]`, `"say ""hi"""`},
+		{"dbo.Users", "[dbo.Users]", `"dbo.Users"`},
+		{"with space", "[with space]", `"with space"`},
+		{"Überprüfung", "[Überprüfung]", `"Überprüfung"`},
+		{"顧客]表", "[顧客]]表]", `"顧客]表"`},
+		{"", "[]", `""`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := QuoteIdent(tt.name); got != tt.tsql {
+				t.Errorf("QuoteIdent(%q) = %s, want %s", tt.name, got, tt.tsql)
+			}
+			if got := Postgres.QuoteIdent(tt.name); got != tt.postgres {
+				t.Errorf("Postgres.QuoteIdent(%q) = %s, want %s", tt.name, got, tt.postgres)
+			}
+		})
+	}
+}
+
+func TestGeneratedSQLQuotesIdentifiers(t *testing.T) {
+	table := Table{
+		SchemaName: "sales.eu",
+		Name:       "O'Brien]Orders",
+		Columns:    []Column{{Name: "Total]", DataType: "int", OrdinalPosition: 1}},
+		PrimaryKey: &Index{Name: "PK]", IsPrimaryKey: true, IsClustered: true, Columns: []IndexColumn{{Name: "Total]"}}},
+	}
+	fk := ForeignKey{
+		Name: "FK.a]b", SchemaName: "sales.eu", TableName: "O'Brien]Orders",
+		ReferencedSchemaName: "ref]", ReferencedTableName: "Ünïcode",
+		Columns: []ForeignKeyColumn{{ColumnName: "Total]", ReferencedColumnName: "Id'"}},
+	}
+
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{"create table", table.GenerateSQL(), []string{
+			"CREATE TABLE [sales.eu].[O'Brien]]Orders] (",
+			"[Total]]] int NOT NULL",
+			"CONSTRAINT [PK]]] PRIMARY KEY CLUSTERED ([Total]]])",
+		}},
+		{"table exists", table.ExistsSQL(), []string{"OBJECT_ID(N'[sales.eu].[O''Brien]]Orders]')"}},
+		{"foreign key", fk.GenerateSQL(), []string{
+			"ALTER TABLE [sales.eu].[O'Brien]]Orders]",
+			"CONSTRAINT [FK.a]]b] FOREIGN KEY",
+			"REFERENCES [ref]]].[Ünïcode]",
+			"[Id']",
+		}},
+		{"foreign key exists", fk.ExistsSQL(), []string{"N'[sales.eu].[O''Brien]]Orders]'", "N'FK.a]b'"}},
+		{"postgres table", table.GenerateSQLFor(Postgres, false), []string{`CREATE TABLE "sales.eu"."O'Brien]Orders" (`, `"Total]" integer NOT NULL`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, want := range tt.want {
+				if !strings.Contains(tt.sql, want) {
+					t.Errorf("SQL does not contain %s:\n%s", want, tt.sql)
+				}
+			}
+		})
+	}
+}
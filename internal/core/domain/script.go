@@ -0,0 +1,235 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// goSeparator matches a batch separator line (GO, optionally with a repeat count)
+var goSeparator = regexp.MustCompile(`(?i)^\s*GO(?:\s+(\d+))?\s*;?\s*$`)
+
+// nonTransactionalStatement matches statements that SQL Server refuses to run
+// inside a user transaction
+var nonTransactionalStatement = regexp.MustCompile(`(?im)^\s*(ALTER\s+DATABASE|CREATE\s+DATABASE|DROP\s+DATABASE|BACKUP|RESTORE|RECONFIGURE|(CREATE|ALTER|DROP)\s+FULLTEXT\s+(CATALOG|INDEX))\b`)
+
+// NonTransactionalStatement returns the first statement in sql that cannot
+// run inside a user transaction, such as ALTER DATABASE, or "" if there is none
+func NonTransactionalStatement(sql string) string {
+	match := nonTransactionalStatement.FindStringSubmatch(sql)
+	if match == nil {
+		return ""
+	}
+	return strings.ToUpper(strings.Join(strings.Fields(match[1]), " "))
+}
+
+// Batch represents a single GO-separated batch of a T-SQL script
+type Batch struct {
+	SQL  string // Batch text without the GO separator
+	Line int    // 1-based line number where the batch starts in the script
+}
+
+// SplitBatches splits a T-SQL script into batches on GO separator lines.
+// Empty batches are skipped. A batch ended by GO n is repeated n times, as
+// sqlcmd and SSMS run it.
+func SplitBatches(script string) []Batch {
+	var batches []Batch
+	var current []string
+	start := 1
+
+	flush := func(next, repeat int) {
+		sql := strings.TrimSpace(strings.Join(current, "\n"))
+		if sql != "" {
+			// Skip leading blank lines so Line points at the first statement
+			offset := 0
+			for offset < len(current) && strings.TrimSpace(current[offset]) == "" {
+				offset++
+			}
+			for n := 0; n < repeat; n++ {
+				batches = append(batches, Batch{SQL: sql, Line: start + offset})
+			}
+		}
+		current = nil
+		start = next
+	}
+
+	lines := strings.Split(strings.ReplaceAll(script, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		if match := goSeparator.FindStringSubmatch(line); match != nil {
+			repeat := 1
+			if n, err := strconv.Atoi(match[1]); err == nil && n > 1 {
+				repeat = n
+			}
+			flush(i+2, repeat)
+			continue
+		}
+		current = append(current, line)
+	}
+	flush(len(lines)+1, 1)
+
+	return batches
+}
+
+// BatchError reports the failure of a batch while executing a script
+type BatchError struct {
+	Index int   // 1-based batch number
+	Line  int   // Script line where the error was raised
+	Err   error // Underlying error
+}
+
+// Error returns the error message including the batch position
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch %d (line %d): %v", e.Index, e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// statementRisks maps the keywords that make a statement more than a read
+// to the risk of running it. Keywords ending or starting a transaction, or
+// running code SQLPulse cannot inspect, are destructive: their effects can
+// escape the rollback of the enclosing transaction.
+var statementRisks = map[string]Risk{
+	"INSERT":         RiskModification,
+	"INTO":           RiskModification,
+	"CREATE":         RiskModification,
+	"ALTER":          RiskModification,
+	"GRANT":          RiskModification,
+	"REVOKE":         RiskModification,
+	"DENY":           RiskModification,
+	"BEGIN":          RiskModification,
+	"SAVE":           RiskModification,
+	"SET":            RiskModification,
+	"DECLARE":        RiskModification,
+	"USE":            RiskModification,
+	"BACKUP":         RiskModification,
+	"UPDATE":         RiskDestructive,
+	"DELETE":         RiskDestructive,
+	"MERGE":          RiskDestructive,
+	"DROP":           RiskDestructive,
+	"TRUNCATE":       RiskDestructive,
+	"COMMIT":         RiskDestructive,
+	"ROLLBACK":       RiskDestructive,
+	"EXEC":           RiskDestructive,
+	"EXECUTE":        RiskDestructive,
+	"KILL":           RiskDestructive,
+	"SHUTDOWN":       RiskDestructive,
+	"RESTORE":        RiskDestructive,
+	"DBCC":           RiskDestructive,
+	"OPENQUERY":      RiskDestructive,
+	"OPENROWSET":     RiskDestructive,
+	"OPENDATASOURCE": RiskDestructive,
+}
+
+// sqlWord matches a keyword or identifier; variables and temporary tables
+// start with @ or # and are skipped by the callers
+var sqlWord = regexp.MustCompile(`[A-Za-z_@#][\w@#$]*`)
+
+// maskedRun matches the characters blanked out of a literal or quoted identifier
+var maskedRun = regexp.MustCompile(`\x00+`)
+
+// fourPartName matches a server.database.schema.object name, which runs on a
+// linked server
+var fourPartName = regexp.MustCompile(`\w+\s*\.\s*\w+\s*\.\s*\w*\s*\.\s*\w+`)
+
+// sqlShape returns sql without comments and with each string literal or
+// quoted identifier replaced by the word x, so that keywords can be found
+// without matching text inside literals
+func sqlShape(sql string) string {
+	return maskedRun.ReplaceAllString(maskSQL(stripComments(sql), 0, true), " x ")
+}
+
+// sqlKeywords returns the upper-cased words of a shaped statement, without
+// variables and temporary tables
+func sqlKeywords(shape string) []string {
+	var words []string
+	for _, w := range sqlWord.FindAllString(shape, -1) {
+		if w[0] != '@' && w[0] != '#' {
+			words = append(words, strings.ToUpper(w))
+		}
+	}
+	return words
+}
+
+// IsReadOnlyQuery reports whether sql is a single SELECT or WITH statement
+// that neither changes data nor reaches outside the database, so it can run
+// without approval
+func IsReadOnlyQuery(sql string) bool {
+	shape := strings.TrimRight(strings.TrimSpace(sqlShape(sql)), "; \t\r\n")
+	if strings.Contains(shape, ";") {
+		return false
+	}
+	words := sqlKeywords(shape)
+	if len(words) == 0 || (words[0] != "SELECT" && words[0] != "WITH") {
+		return false
+	}
+	return ClassifySQL(sql) == RiskUnknown
+}
+
+// ClassifySQL returns the risk of running sql: RiskDestructive when it can
+// lose data or has effects a rollback cannot undo, RiskModification when it
+// otherwise changes the database, and RiskUnknown when it has no keyword
+// that changes anything
+func ClassifySQL(sql string) Risk {
+	shape := sqlShape(sql)
+	if fourPartName.MatchString(shape) {
+		return RiskDestructive
+	}
+
+	risk := RiskUnknown
+	words := sqlKeywords(shape)
+	for i, w := range words {
+		// NEXT VALUE FOR draws from a sequence, which a rollback does not undo
+		if w == "NEXT" && i+2 < len(words) && words[i+1] == "VALUE" && words[i+2] == "FOR" {
+			risk = RiskModification
+		}
+		switch statementRisks[w] {
+		case RiskDestructive:
+			return RiskDestructive
+		case RiskModification:
+			risk = RiskModification
+		}
+	}
+	return risk
+}
+
+// CheckWhereExpression returns an error when a WHERE expression given by the
+// user holds more than an expression: a ; or a GO separator outside string
+// literals and comments would start another statement or batch
+func CheckWhereExpression(where string) error {
+	shape := sqlShape(where)
+	if strings.Contains(shape, ";") {
+		return fmt.Errorf("WHERE expression must not contain ;")
+	}
+	for _, line := range strings.Split(shape, "\n") {
+		if goSeparator.MatchString(line) {
+			return fmt.Errorf("WHERE expression must not contain a GO batch separator")
+		}
+	}
+	return nil
+}
+
+// TransactionStatement returns the first COMMIT, ROLLBACK or BEGIN
+// TRANSACTION in sql, or "" if there is none. Such statements end or nest
+// the transaction a script is run in, so the script cannot be validated
+// inside one.
+func TransactionStatement(sql string) string {
+	words := sqlKeywords(sqlShape(sql))
+	for i, w := range words {
+		switch w {
+		case "COMMIT", "ROLLBACK":
+			return w
+		case "BEGIN":
+			if i+1 < len(words) {
+				switch words[i+1] {
+				case "TRAN", "TRANSACTION", "DISTRIBUTED":
+					return "BEGIN " + words[i+1]
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package domain
+
+import "testing"
+
+func TestClassifySQL(t *testing.T) {
+	tests := []struct {
+		sql      string
+		readOnly bool
+		risk     Risk
+	}{
+		{"SELECT name FROM sys.tables", true, RiskUnknown},
+		{"WITH t AS (SELECT 1 AS a) SELECT a FROM t;", true, RiskUnknown},
+		{"SELECT 'DELETE' AS [Update] -- DROP TABLE x", true, RiskUnknown},
+		{"SELECT user_updates FROM sys.dm_db_index_usage_stats", true, RiskUnknown},
+		{"SELECT 1; SELECT 2", false, RiskUnknown},
+		{"SELECT * INTO dbo.Copy FROM dbo.Users", false, RiskModification},
+		{"SELECT NEXT VALUE FOR dbo.OrderNumbers", false, RiskModification},
+		{"INSERT INTO dbo.Log VALUES (1)", false, RiskModification},
+		{"DELETE FROM dbo.Users; COMMIT", false, RiskDestructive},
+		{"SELECT 1 DELETE[dbo].[Users]", false, RiskDestructive},
+		{"DELETE/**/FROM dbo.Users", false, RiskDestructive},
+		{"EXEC xp_cmdshell 'dir'", false, RiskDestructive},
+		{"SELECT * FROM [remote].[Shop].[dbo].[Users]", false, RiskDestructive},
+		{"SELECT * FROM OPENQUERY(remote, 'SELECT 1')", false, RiskDestructive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			if got := IsReadOnlyQuery(tt.sql); got != tt.readOnly {
+				t.Errorf("IsReadOnlyQuery = %v, want %v", got, tt.readOnly)
+			}
+			if got := ClassifySQL(tt.sql); got != tt.risk {
+				t.Errorf("ClassifySQL = %v, want %v", got, tt.risk)
+			}
+		})
+	}
+}
+
+func TestCheckWhereExpression(t *testing.T) {
+	tests := []struct {
+		where   string
+		wantErr bool
+	}{
+		{"IsActive = 1", false},
+		{"Name = 'a;b' -- no; statement", false},
+		{"Name LIKE 'GO%'", false},
+		{"1=1; DELETE FROM dbo.T; COMMIT", true},
+		{"1=1\nGO\nDELETE FROM dbo.T", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.where, func(t *testing.T) {
+			if err := CheckWhereExpression(tt.where); (err != nil) != tt.wantErr {
+				t.Errorf("got %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSplitBatchesRepeatCount(t *testing.T) {
+	batches := SplitBatches("INSERT INTO dbo.T DEFAULT VALUES\nGO 3\nSELECT COUNT(*) FROM dbo.T\nGO\n")
+	if len(batches) != 4 {
+		t.Fatalf("got %d batches, want the first repeated 3 times plus the second: %+v", len(batches), batches)
+	}
+	for _, b := range batches[:3] {
+		if b.SQL != "INSERT INTO dbo.T DEFAULT VALUES" || b.Line != 1 {
+			t.Errorf("got %+v, want the INSERT batch at line 1", b)
+		}
+	}
+	if batches[3].Line != 3 {
+		t.Errorf("second batch at line %d, want 3", batches[3].Line)
+	}
+}
+
+func TestTransactionStatement(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"CREATE TABLE dbo.T (Id int)", ""},
+		{"BEGIN TRY SELECT 1 END TRY BEGIN CATCH SELECT 2 END CATCH", ""},
+		{"PRINT 'COMMIT' -- ROLLBACK", ""},
+		{"DELETE FROM dbo.T\nCOMMIT", "COMMIT"},
+		{"IF @@ERROR <> 0 ROLLBACK TRANSACTION", "ROLLBACK"},
+		{"begin tran\nUPDATE dbo.T SET x = 1", "BEGIN TRAN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			if got := TransactionStatement(tt.sql); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WriteJSON writes the schema as an indented JSON snapshot that
+// LoadSchemaFromJSON reads back
+func (s *DatabaseSchema) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// LoadSchemaFromJSON reads a schema snapshot written by WriteJSON, so a saved
+// snapshot can be compared like a live extraction
+func LoadSchemaFromJSON(r io.Reader) (*DatabaseSchema, error) {
+	var schema DatabaseSchema
+	if err := json.NewDecoder(r).Decode(&schema); err != nil {
+		return nil, fmt.Errorf("invalid schema JSON: %w", err)
+	}
+	return &schema, nil
+}
+
+// UnmarshalJSON decodes an index, keeping the lock options that default to
+// ON when the snapshot predates them
+func (i *Index) UnmarshalJSON(data []byte) error {
+	type plain Index
+	p := plain{AllowRowLocks: true, AllowPageLocks: true}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*i = Index(p)
+	return nil
+}
@@ -0,0 +1,65 @@
+package domain_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/enunezf/SQLPulse/internal/adapters/memory"
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+	"github.com/enunezf/SQLPulse/internal/core/services"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	schema := memory.NewSchema("Shop").
+		Collation("Latin1_General_CI_AS").
+		Schema("sales", "dbo").
+		Table(memory.NewTable("sales", "Orders").
+			Column(memory.NewColumn("Id", "int").Identity(1, 1)).
+			Column(memory.NewColumn("CustomerId", "int")).
+			Column(memory.NewColumn("Note", "nvarchar").Length(200).Nullable().Default("DF_Orders_Note", "(N'')")).
+			Column(memory.NewColumn("Total", "decimal").Precision(10, 2)).
+			PrimaryKey("PK_Orders", "Id").
+			Index("IX_Orders_CustomerId", false, "CustomerId").
+			Check("CK_Orders_Total", "([Total]>=(0))")).
+		View("sales", "BigOrders", "CREATE VIEW sales.BigOrders AS SELECT Id FROM sales.Orders WHERE Total > 100").
+		Procedure("sales", "GetOrder", "CREATE PROCEDURE sales.GetOrder @id int AS SELECT * FROM sales.Orders WHERE Id = @id").
+		Permission(domain.Permission{State: "GRANT", PermissionName: "EXECUTE", ClassDesc: "SCHEMA", SchemaName: "sales", Grantee: "app"}).
+		Build()
+	schema.Tables[0].Indexes[0].AllowPageLocks = false
+
+	var buf bytes.Buffer
+	if err := schema.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	loaded, err := domain.LoadSchemaFromJSON(&buf)
+	if err != nil {
+		t.Fatalf("LoadSchemaFromJSON: %v", err)
+	}
+
+	if !reflect.DeepEqual(schema, loaded) {
+		t.Errorf("loaded snapshot differs from the saved schema:\nsaved  %+v\nloaded %+v", schema, loaded)
+	}
+	if result := services.NewSchemaComparator(nil).Compare(schema, loaded); len(result.Differences) != 0 {
+		t.Errorf("comparing the saved and loaded schema found differences: %+v", result.Differences)
+	}
+}
+
+func TestSnapshotIndexLockDefaults(t *testing.T) {
+	const snapshot = `{"Tables": [{"SchemaName": "dbo", "Name": "T", "Indexes": [{"Name": "IX_T"}]}]}`
+	loaded, err := domain.LoadSchemaFromJSON(strings.NewReader(snapshot))
+	if err != nil {
+		t.Fatalf("LoadSchemaFromJSON: %v", err)
+	}
+	idx := loaded.Tables[0].Indexes[0]
+	if !idx.AllowRowLocks || !idx.AllowPageLocks {
+		t.Errorf("index from an older snapshot has row locks %v, page locks %v; want both on", idx.AllowRowLocks, idx.AllowPageLocks)
+	}
+}
+
+func TestLoadSchemaFromJSONInvalid(t *testing.T) {
+	if _, err := domain.LoadSchemaFromJSON(strings.NewReader("{")); err == nil {
+		t.Error("LoadSchemaFromJSON accepted truncated JSON")
+	}
+}
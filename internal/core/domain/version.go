@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SQL Server major versions that introduced syntax the generators emit
+const (
+	SQLServer2014 = 12
+	SQLServer2016 = 13
+)
+
+// Feature is generated syntax that older SQL Server versions do not support
+type Feature int
+
+const (
+	// FeatureClusteredColumnstore is CREATE CLUSTERED COLUMNSTORE INDEX
+	FeatureClusteredColumnstore Feature = iota
+	// FeatureTemporalTables is PERIOD FOR SYSTEM_TIME, GENERATED ALWAYS AS ROW
+	// START/END and SYSTEM_VERSIONING
+	FeatureTemporalTables
+	// FeatureDataMasking is MASKED WITH (FUNCTION = ...)
+	FeatureDataMasking
+	// FeatureScopedConfigurations is ALTER DATABASE SCOPED CONFIGURATION
+	FeatureScopedConfigurations
+	// FeaturePersistSamplePercent is PERSIST_SAMPLE_PERCENT on statistics
+	FeaturePersistSamplePercent
+	// FeatureMemoryOptimizedTables is MEMORY_OPTIMIZED = ON and the inline
+	// hash and range indexes of memory-optimized tables
+	FeatureMemoryOptimizedTables
+)
+
+// featureVersions is the major version that introduced each feature
+var featureVersions = map[Feature]int{
+	FeatureClusteredColumnstore:  SQLServer2014,
+	FeatureTemporalTables:        SQLServer2016,
+	FeatureDataMasking:           SQLServer2016,
+	FeatureScopedConfigurations:  SQLServer2016,
+	FeaturePersistSamplePercent:  SQLServer2016,
+	FeatureMemoryOptimizedTables: SQLServer2014,
+}
+
+// featureBuilds lists, for features added in a service pack or cumulative
+// update, the first build of each major version that supports them. Later
+// major versions support them from their first build.
+var featureBuilds = map[Feature][]string{
+	FeaturePersistSamplePercent: {"13.0.4446", "14.0.3006"}, // 2016 SP1 CU4, 2017 CU1
+}
+
+// supportsFeature reports whether the dotted product version supports f
+func supportsFeature(productVersion string, f Feature) bool {
+	major := MajorVersion(productVersion)
+	if major < featureVersions[f] {
+		return false
+	}
+	for _, build := range featureBuilds[f] {
+		if MajorVersion(build) == major {
+			return VersionAtLeast(productVersion, build)
+		}
+	}
+	return true
+}
+
+// MajorVersion returns the major version of a dotted product version such as
+// 11.0.7001.0, as reported by SERVERPROPERTY('ProductVersion'), or 0 when it
+// is unknown
+func MajorVersion(productVersion string) int {
+	major, _, _ := strings.Cut(productVersion, ".")
+	n, err := strconv.Atoi(strings.TrimSpace(major))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// VersionAtLeast reports whether the dotted product version is min or later
+func VersionAtLeast(version, min string) bool {
+	have := strings.Split(version, ".")
+	want := strings.Split(min, ".")
+	for i, w := range want {
+		wn, _ := strconv.Atoi(w)
+		hn := 0
+		if i < len(have) {
+			hn, _ = strconv.Atoi(have[i])
+		}
+		if hn != wn {
+			return hn > wn
+		}
+	}
+	return true
+}
+
+// TSQLVersion returns the T-SQL dialect of the SQL Server with the given
+// product version, such as 13.0.4001.0, which omits the syntax that build
+// does not support. An unknown version ("") supports everything, like TSQL.
+func TSQLVersion(productVersion string) Dialect {
+	return tsqlDialect{version: productVersion}
+}
+
+// IsTSQL reports whether d is T-SQL, of any SQL Server version
+func IsTSQL(d Dialect) bool {
+	_, ok := d.(tsqlDialect)
+	return ok
+}
@@ -0,0 +1,47 @@
+package domain
+
+import "testing"
+
+func TestTSQLVersionSupports(t *testing.T) {
+	tests := []struct {
+		version string
+		feature Feature
+		want    bool
+	}{
+		{"", FeaturePersistSamplePercent, true},
+		{"12.0.6024.0", FeaturePersistSamplePercent, false},
+		{"13.0.1601.5", FeaturePersistSamplePercent, false},   // 2016 RTM
+		{"13.0.4001.0", FeaturePersistSamplePercent, false},   // 2016 SP1
+		{"13.0.4446.0", FeaturePersistSamplePercent, true},    // 2016 SP1 CU4
+		{"13.0.5026.0", FeaturePersistSamplePercent, true},    // 2016 SP2
+		{"14.0.1000.169", FeaturePersistSamplePercent, false}, // 2017 RTM
+		{"14.0.3006.16", FeaturePersistSamplePercent, true},   // 2017 CU1
+		{"15.0.2000.5", FeaturePersistSamplePercent, true},
+		{"13.0.1601.5", FeatureTemporalTables, true},
+		{"12.0.2000.8", FeatureTemporalTables, false},
+		{"11.0.7001.0", FeatureClusteredColumnstore, false},
+	}
+	for _, tt := range tests {
+		if got := TSQLVersion(tt.version).Supports(tt.feature); got != tt.want {
+			t.Errorf("TSQLVersion(%q).Supports(%d) = %v, want %v", tt.version, tt.feature, got, tt.want)
+		}
+	}
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		version, min string
+		want         bool
+	}{
+		{"13.0.4001.0", "13.0.4001", true},
+		{"13.0.4000.0", "13.0.4001", false},
+		{"14.0.1000.169", "13.0.4001", true},
+		{"9.0.5000.0", "11.0", false},
+		{"", "11.0", false},
+	}
+	for _, tt := range tests {
+		if got := VersionAtLeast(tt.version, tt.min); got != tt.want {
+			t.Errorf("VersionAtLeast(%q, %q) = %v, want %v", tt.version, tt.min, got, tt.want)
+		}
+	}
+}
@@ -22,9 +22,32 @@ type DatabasePort interface {
 	// GetServerInfo retrieves information about the connected server
 	GetServerInfo(ctx context.Context) (*domain.ServerInfo, error)
 
+	// GetDatabaseInfo retrieves the size, file layout and settings of the
+	// connected database
+	GetDatabaseInfo(ctx context.Context) (*domain.DatabaseInfo, error)
+
+	// GetPermissions retrieves the effective database permissions of the
+	// connected principal, such as VIEW DEFINITION
+	GetPermissions(ctx context.Context) ([]string, error)
+
 	// ExecuteWithApproval executes SQL after getting user approval
 	ExecuteWithApproval(ctx context.Context, sql string, level security.ApprovalLevel, operation string) error
 
+	// ExecuteTransaction executes batches in a single transaction after one
+	// approval, rolling all of them back if any fails
+	ExecuteTransaction(ctx context.Context, batches []domain.Batch, level security.ApprovalLevel, operation string) error
+
+	// ExecuteReadQuery runs an ad-hoc query, at read-only approval level when
+	// it is a single SELECT, and returns at most maxRows rows (0 for no limit)
+	ExecuteReadQuery(ctx context.Context, sql string, maxRows int) (*domain.QueryResult, error)
+
+	// ExportTable streams the rows of a table to w, optionally limited to
+	// columns and filtered by a WHERE expression
+	ExportTable(ctx context.Context, schemaName, tableName string, columns []string, where string, w domain.RowWriter) (int, error)
+
+	// ValidateScript executes script batches in a transaction that is always rolled back
+	ValidateScript(ctx context.Context, batches []domain.Batch) error
+
 	// SetApprover sets the approver to use for operations
 	SetApprover(approver security.Approver)
 }
@@ -12,20 +12,42 @@ type SchemaPort interface {
 	ExtractSchema(ctx context.Context, opts *domain.DumpOptions) (*domain.DatabaseSchema, error)
 
 	// ExtractTables extracts table definitions
-	ExtractTables(ctx context.Context, schemaFilter, tableFilter []string) ([]domain.Table, error)
+	ExtractTables(ctx context.Context, opts *domain.DumpOptions) ([]domain.Table, error)
 
 	// ExtractViews extracts view definitions
-	ExtractViews(ctx context.Context, schemaFilter []string) ([]domain.View, error)
+	ExtractViews(ctx context.Context, opts *domain.DumpOptions) ([]domain.View, error)
 
 	// ExtractProcedures extracts stored procedure definitions
-	ExtractProcedures(ctx context.Context, schemaFilter []string) ([]domain.StoredProcedure, error)
+	ExtractProcedures(ctx context.Context, opts *domain.DumpOptions) ([]domain.StoredProcedure, error)
 
 	// ExtractFunctions extracts function definitions
-	ExtractFunctions(ctx context.Context, schemaFilter []string) ([]domain.Function, error)
+	ExtractFunctions(ctx context.Context, opts *domain.DumpOptions) ([]domain.Function, error)
 
 	// ExtractTriggers extracts trigger definitions
-	ExtractTriggers(ctx context.Context, schemaFilter []string) ([]domain.Trigger, error)
+	ExtractTriggers(ctx context.Context, opts *domain.DumpOptions) ([]domain.Trigger, error)
 
 	// ExtractSchemas extracts schema definitions
-	ExtractSchemas(ctx context.Context) ([]domain.Schema, error)
+	ExtractSchemas(ctx context.Context, opts *domain.DumpOptions) ([]domain.Schema, error)
+
+	// ExtractPartitionFunctions extracts partition function definitions
+	ExtractPartitionFunctions(ctx context.Context) ([]domain.PartitionFunction, error)
+
+	// ExtractPartitionSchemes extracts partition scheme definitions
+	ExtractPartitionSchemes(ctx context.Context) ([]domain.PartitionScheme, error)
+
+	// ExtractScopedConfigurations extracts database scoped configurations
+	ExtractScopedConfigurations(ctx context.Context) ([]domain.ScopedConfiguration, error)
+
+	// ExtractPermissions extracts database, schema and object permissions
+	ExtractPermissions(ctx context.Context, opts *domain.DumpOptions) ([]domain.Permission, error)
+
+	// ExtractStatistics extracts statistics on table columns that do not back an index
+	ExtractStatistics(ctx context.Context, opts *domain.DumpOptions) ([]domain.Statistic, error)
+
+	// CountObjects counts the objects of each category the options include,
+	// without extracting their definitions
+	CountObjects(ctx context.Context, opts *domain.DumpOptions) (domain.ObjectCounts, error)
+
+	// ExtractTableData extracts the rows of a table for INSERT generation
+	ExtractTableData(ctx context.Context, table domain.Table) (*domain.TableData, error)
 }
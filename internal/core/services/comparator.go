@@ -4,14 +4,19 @@ package services
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/enunezf/SQLPulse/internal/core/domain"
 )
 
+// whitespacePattern matches runs of whitespace
+var whitespacePattern = regexp.MustCompile(`\s+`)
+
 // SchemaComparator compares two database schemas
 type SchemaComparator struct {
 	options *domain.DiffOptions
+	dialect domain.Dialect // T-SQL of the target server version, for migration SQL
 }
 
 // NewSchemaComparator creates a new schema comparator
@@ -19,7 +24,7 @@ func NewSchemaComparator(options *domain.DiffOptions) *SchemaComparator {
 	if options == nil {
 		options = domain.DefaultDiffOptions()
 	}
-	return &SchemaComparator{options: options}
+	return &SchemaComparator{options: options, dialect: domain.TSQLVersion(options.TargetVersion)}
 }
 
 // Compare compares source and target schemas and returns the differences
@@ -30,146 +35,549 @@ func (c *SchemaComparator) Compare(source, target *domain.DatabaseSchema) *domai
 		Differences:    []domain.Difference{},
 	}
 
+	result.Omitted = c.CompareStream(source, target, func(d domain.Difference) {
+		result.Differences = append(result.Differences, d)
+	})
+	result.Truncated = result.Omitted > 0
+
+	ClassifyRisks(result)
+	result.CalculateSummary()
+	return result
+}
+
+// CompareStream compares source and target schemas and calls emit for each
+// difference as soon as it is found, so callers can process large schemas
+// without holding every difference in memory. With MaxDifferences, emit is
+// no longer called past the cap; the differences found after it are only
+// counted, and their number is returned.
+func (c *SchemaComparator) CompareStream(source, target *domain.DatabaseSchema, emit func(domain.Difference)) (omitted int) {
+	// Every difference knows when its migration runs relative to the others
+	ordered := emit
+	emit = func(d domain.Difference) {
+		d.Risk = ClassifyRisk(d)
+		d.SetMigrationOrder()
+		ordered(d)
+	}
+
+	if limit := c.options.MaxDifferences; limit > 0 {
+		next, kept := emit, 0
+		emit = func(d domain.Difference) {
+			if kept == limit {
+				omitted++
+				return
+			}
+			kept++
+			next(d)
+		}
+	}
+
+	// Objects that exist only in the target are still reported, but not dropped
+	if c.options.NoDrop {
+		next := emit
+		emit = func(d domain.Difference) {
+			if d.Type == domain.DiffAdded {
+				d.MigrationSQL = ""
+			}
+			next(d)
+		}
+	}
+
+	// Compare database-level settings
+	c.compareDatabaseSettings(source, target, emit)
+
+	// Compare schemas
+	if c.options.IncludeSchemas {
+		c.compareSchemas(source.Schemas, target.Schemas, emit)
+	}
+
 	// Compare tables
 	if c.options.IncludeTables {
-		c.compareTables(source.Tables, target.Tables, result)
+		c.compareTables(source.Tables, target.Tables, emit)
 	}
 
 	// Compare views
 	if c.options.IncludeViews {
-		c.compareViews(source.Views, target.Views, result)
+		c.compareViews(source.Views, target.Views, emit)
 	}
 
 	// Compare stored procedures
 	if c.options.IncludeProcedures {
-		c.compareProcedures(source.StoredProcedures, target.StoredProcedures, result)
+		c.compareProcedures(source.StoredProcedures, target.StoredProcedures, emit)
 	}
 
 	// Compare functions
 	if c.options.IncludeFunctions {
-		c.compareFunctions(source.Functions, target.Functions, result)
+		c.compareFunctions(source.Functions, target.Functions, emit)
 	}
 
 	// Compare triggers
 	if c.options.IncludeTriggers {
-		c.compareTriggers(source.Triggers, target.Triggers, result)
+		c.compareTriggers(source.Triggers, target.Triggers, emit)
 	}
 
-	result.CalculateSummary()
-	return result
+	// Compare permissions
+	if c.options.IncludePermissions {
+		c.comparePermissions(source.Permissions, target.Permissions, emit)
+	}
+	return omitted
+}
+
+// CompareDefinitions reports for each view, procedure, function and trigger
+// in either schema whether it changed, in report order. A module changed
+// when it exists on one side only or any difference was found in it.
+func (c *SchemaComparator) CompareDefinitions(source, target *domain.DatabaseSchema) []domain.ModuleChange {
+	changed := make(map[string]bool)
+	record := func(d domain.Difference) {
+		changed[string(d.Category)+" "+c.nameKey(d.ObjectName)] = true
+	}
+
+	names := make(map[domain.DiffCategory]map[string]string)
+	addName := func(category domain.DiffCategory, name string) {
+		if names[category] == nil {
+			names[category] = make(map[string]string)
+		}
+		names[category][c.nameKey(name)] = name
+	}
+
+	if c.options.IncludeViews {
+		c.compareViews(source.Views, target.Views, record)
+		for _, views := range [][]domain.View{source.Views, target.Views} {
+			for _, v := range views {
+				addName(domain.DiffCategoryView, c.qualifiedName(v.SchemaName, v.Name))
+			}
+		}
+	}
+	if c.options.IncludeProcedures {
+		c.compareProcedures(source.StoredProcedures, target.StoredProcedures, record)
+		for _, procs := range [][]domain.StoredProcedure{source.StoredProcedures, target.StoredProcedures} {
+			for _, p := range procs {
+				addName(domain.DiffCategoryProcedure, c.qualifiedName(p.SchemaName, p.Name))
+			}
+		}
+	}
+	if c.options.IncludeFunctions {
+		c.compareFunctions(source.Functions, target.Functions, record)
+		for _, funcs := range [][]domain.Function{source.Functions, target.Functions} {
+			for _, f := range funcs {
+				addName(domain.DiffCategoryFunction, c.qualifiedName(f.SchemaName, f.Name))
+			}
+		}
+	}
+	if c.options.IncludeTriggers {
+		c.compareTriggers(source.Triggers, target.Triggers, record)
+		for _, triggers := range [][]domain.Trigger{source.Triggers, target.Triggers} {
+			for _, t := range triggers {
+				addName(domain.DiffCategoryTrigger, c.formatTriggerName(t))
+			}
+		}
+	}
+
+	var modules []domain.ModuleChange
+	for _, category := range []domain.DiffCategory{domain.DiffCategoryView, domain.DiffCategoryProcedure, domain.DiffCategoryFunction, domain.DiffCategoryTrigger} {
+		for _, key := range sortedKeys(names[category]) {
+			modules = append(modules, domain.ModuleChange{
+				Category: category,
+				Name:     names[category][key],
+				Changed:  changed[string(category)+" "+key],
+			})
+		}
+	}
+	return modules
+}
+
+// compareDatabaseSettings compares the database collation and scoped configurations.
+// Configurations that exist on only one server (due to version differences) are skipped.
+func (c *SchemaComparator) compareDatabaseSettings(source, target *domain.DatabaseSchema, emit func(domain.Difference)) {
+	if !c.options.IgnoreCollation && source.Collation != "" && target.Collation != "" &&
+		!strings.EqualFold(source.Collation, target.Collation) {
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategoryDatabase,
+			ObjectName:   target.DatabaseName,
+			PropertyName: "Collation",
+			SourceValue:  source.Collation,
+			TargetValue:  target.Collation,
+			Description:  fmt.Sprintf("Database collation differs: %s vs %s", source.Collation, target.Collation),
+			MigrationSQL: fmt.Sprintf("ALTER DATABASE CURRENT COLLATE %s;", source.Collation),
+		})
+	}
+
+	targetConfigs := make(map[string]domain.ScopedConfiguration)
+	for _, sc := range target.ScopedConfigurations {
+		targetConfigs[strings.ToUpper(sc.Name)] = sc
+	}
+	for _, srcSC := range source.ScopedConfigurations {
+		tgtSC, exists := targetConfigs[strings.ToUpper(srcSC.Name)]
+		if !exists {
+			continue
+		}
+		if !strings.EqualFold(srcSC.Value, tgtSC.Value) || !strings.EqualFold(srcSC.ValueForSecondary, tgtSC.ValueForSecondary) {
+			migration := srcSC.GenerateSQL() + ";"
+			if srcSC.ValueForSecondary == "" && tgtSC.ValueForSecondary != "" {
+				migration += fmt.Sprintf("\nALTER DATABASE SCOPED CONFIGURATION FOR SECONDARY SET %s = PRIMARY;", srcSC.Name)
+			}
+			emit(domain.Difference{
+				Type:         domain.DiffModified,
+				Category:     domain.DiffCategoryDatabase,
+				ObjectName:   target.DatabaseName,
+				PropertyName: srcSC.Name,
+				SourceValue:  srcSC.Value,
+				TargetValue:  tgtSC.Value,
+				Description:  fmt.Sprintf("Scoped configuration %s differs: %s vs %s", srcSC.Name, srcSC.Value, tgtSC.Value),
+				MigrationSQL: migration,
+			})
+		}
+	}
+}
+
+// compareSchemas compares schema objects and, unless IgnoreOwners is set,
+// their owners
+func (c *SchemaComparator) compareSchemas(source, target []domain.Schema, emit func(domain.Difference)) {
+	sourceMap := c.schemasToMap(source)
+	targetMap := c.schemasToMap(target)
+
+	for _, key := range sortedKeys(sourceMap) {
+		srcSchema := sourceMap[key]
+		if _, exists := targetMap[key]; !exists {
+			name := domain.QuoteIdent(srcSchema.Name)
+			sql := fmt.Sprintf("CREATE SCHEMA %s;", name)
+			if !c.options.IgnoreOwners {
+				sql = srcSchema.GenerateSQL() + ";"
+			}
+			emit(domain.Difference{
+				Type:         domain.DiffRemoved,
+				Category:     domain.DiffCategorySchema,
+				ObjectName:   name,
+				Description:  fmt.Sprintf("Schema [%s] missing in target", srcSchema.Name),
+				MigrationSQL: sql,
+			})
+		}
+	}
+
+	for _, key := range sortedKeys(targetMap) {
+		tgtSchema := targetMap[key]
+		if _, exists := sourceMap[key]; !exists {
+			name := domain.QuoteIdent(tgtSchema.Name)
+			emit(domain.Difference{
+				Type:         domain.DiffAdded,
+				Category:     domain.DiffCategorySchema,
+				ObjectName:   name,
+				Description:  fmt.Sprintf("Schema [%s] exists only in target", tgtSchema.Name),
+				MigrationSQL: c.dropSQL(tgtSchema.ExistsSQL(), fmt.Sprintf("DROP SCHEMA %s", name)) + ";",
+			})
+		}
+	}
+
+	if c.options.IgnoreOwners {
+		return
+	}
+	for _, key := range sortedKeys(sourceMap) {
+		srcSchema := sourceMap[key]
+		tgtSchema, exists := targetMap[key]
+		if !exists || strings.EqualFold(srcSchema.Owner, tgtSchema.Owner) {
+			continue
+		}
+		name := domain.QuoteIdent(srcSchema.Name)
+		// A source schema whose owner was dropped has no owner to transfer to
+		migration := ""
+		if srcSchema.Owner != "" {
+			migration = fmt.Sprintf("ALTER AUTHORIZATION ON SCHEMA::%s TO %s;", name, domain.QuoteIdent(srcSchema.Owner))
+		}
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategorySchema,
+			ObjectName:   name,
+			PropertyName: "Owner",
+			SourceValue:  srcSchema.Owner,
+			TargetValue:  tgtSchema.Owner,
+			Description:  fmt.Sprintf("Schema owner differs: %s vs %s", displayValue(srcSchema.Owner), displayValue(tgtSchema.Owner)),
+			MigrationSQL: migration,
+		})
+	}
 }
 
 // compareTables compares table structures
-func (c *SchemaComparator) compareTables(source, target []domain.Table, result *domain.DiffResult) {
+func (c *SchemaComparator) compareTables(source, target []domain.Table, emit func(domain.Difference)) {
 	sourceMap := c.tablesToMap(source)
 	targetMap := c.tablesToMap(target)
 
-	// Find removed tables (in source but not in target)
-	for name := range sourceMap {
-		if _, exists := targetMap[name]; !exists {
-			result.Differences = append(result.Differences, domain.Difference{
+	// Find removed tables (in source but not in target), referenced tables first
+	created, _ := domain.SortTablesByDependency(source, c.nameKey)
+	for _, srcTable := range created {
+		if _, exists := targetMap[c.nameKey(c.formatTableName(srcTable))]; !exists {
+			name := c.formatTableName(srcTable)
+			d := domain.Difference{
 				Type:        domain.DiffRemoved,
 				Category:    domain.DiffCategoryTable,
 				ObjectName:  name,
 				Description: fmt.Sprintf("Table [%s] exists in source but not in target", name),
-				MigrationSQL: fmt.Sprintf("CREATE TABLE %s (\n    -- Copy structure from source\n)", name),
-			})
+			}
+			if len(srcTable.Columns) == 0 {
+				d.Description += " (skipped: no columns extracted)"
+			} else {
+				d.MigrationSQL = c.createTableSQL(srcTable)
+			}
+			emit(d)
 		}
 	}
 
-	// Find added tables (in target but not in source)
-	for name, tgtTable := range targetMap {
-		if _, exists := sourceMap[name]; !exists {
-			result.Differences = append(result.Differences, domain.Difference{
-				Type:        domain.DiffAdded,
-				Category:    domain.DiffCategoryTable,
-				ObjectName:  name,
-				Description: fmt.Sprintf("Table [%s] exists in target but not in source", name),
-				MigrationSQL: fmt.Sprintf("DROP TABLE %s;", c.formatTableName(tgtTable)),
+	// Find added tables (in target but not in source). They are dropped in
+	// reverse dependency order, referencing tables before the tables they reference.
+	dropped, _ := domain.SortTablesByDependency(target, c.nameKey)
+	for i := len(dropped) - 1; i >= 0; i-- {
+		tgtTable := dropped[i]
+		if _, exists := sourceMap[c.nameKey(c.formatTableName(tgtTable))]; !exists {
+			name := c.formatTableName(tgtTable)
+			emit(domain.Difference{
+				Type:         domain.DiffAdded,
+				Category:     domain.DiffCategoryTable,
+				ObjectName:   name,
+				Description:  fmt.Sprintf("Table [%s] exists in target but not in source", name),
+				MigrationSQL: c.dropSQL(tgtTable.ExistsSQL(), tgtTable.GenerateDropSQL()) + ";",
 			})
 		}
 	}
 
 	// Compare tables that exist in both
-	for name, srcTable := range sourceMap {
-		if tgtTable, exists := targetMap[name]; exists {
-			c.compareTableStructure(srcTable, tgtTable, result)
+	for _, key := range sortedKeys(sourceMap) {
+		srcTable := sourceMap[key]
+		if tgtTable, exists := targetMap[key]; exists {
+			c.compareTableStructure(srcTable, tgtTable, emit)
 		}
 	}
 }
 
+// createTableSQL generates the statements that create a table and its indexes
+func (c *SchemaComparator) createTableSQL(t domain.Table) string {
+	if !c.options.IncludeIndexes {
+		t.Indexes = nil
+	}
+	stmts := []string{t.GenerateSQLFor(c.dialect, false) + ";"}
+	// Indexes of memory-optimized tables are declared in CREATE TABLE
+	if !t.IndexesInline(c.dialect) {
+		for _, idx := range t.Indexes {
+			if sql := idx.GenerateSQLFor(c.dialect); sql != "" {
+				stmts = append(stmts, sql+";")
+			}
+		}
+	}
+	if c.options.IncludeConstraints {
+		for _, uc := range t.UniqueConstraints {
+			stmts = append(stmts, uc.GenerateSQL()+";")
+		}
+	}
+	return strings.Join(stmts, "\n")
+}
+
 // compareTableStructure compares two tables in detail
-func (c *SchemaComparator) compareTableStructure(source, target domain.Table, result *domain.DiffResult) {
+func (c *SchemaComparator) compareTableStructure(source, target domain.Table, emit func(domain.Difference)) {
 	tableName := c.formatTableName(source)
 
-	// Compare columns
-	c.compareColumns(tableName, source.Columns, target.Columns, result)
+	// Compare columns. Every table has at least one, so when either side has
+	// none they were not extracted, and comparing would add or drop them all.
+	if len(source.Columns) > 0 && len(target.Columns) > 0 {
+		c.compareColumns(tableName, source.Columns, target.Columns, emit)
+	}
 
 	// Compare indexes
 	if c.options.IncludeIndexes {
-		c.compareIndexes(tableName, source.Indexes, target.Indexes, result)
+		c.compareIndexes(tableName, source.Indexes, target.Indexes, emit)
 	}
 
 	// Compare foreign keys
 	if c.options.IncludeForeignKeys {
-		c.compareForeignKeys(tableName, source.ForeignKeys, target.ForeignKeys, result)
+		c.compareForeignKeys(tableName, source.ForeignKeys, target.ForeignKeys, emit)
 	}
 
 	// Compare check constraints
 	if c.options.IncludeConstraints {
-		c.compareCheckConstraints(tableName, source.CheckConstraints, target.CheckConstraints, result)
+		c.compareCheckConstraints(tableName, source.CheckConstraints, target.CheckConstraints, emit)
+		c.compareUniqueConstraints(tableName, source.UniqueConstraints, target.UniqueConstraints, emit)
 	}
 
 	// Compare primary keys
-	c.comparePrimaryKeys(tableName, source.PrimaryKey, target.PrimaryKey, result)
+	c.comparePrimaryKeys(tableName, source.PrimaryKey, target.PrimaryKey, emit)
+
+	// Compare system versioning
+	c.compareTemporal(tableName, source, target, emit)
+
+	// Compare In-Memory OLTP options
+	c.compareMemoryOptimized(tableName, source, target, emit)
+}
+
+// compareMemoryOptimized compares whether two tables are memory-optimized and
+// their durability. Neither can be changed in place, as the table has to be
+// recreated, so no migration is generated.
+func (c *SchemaComparator) compareMemoryOptimized(tableName string, source, target domain.Table, emit func(domain.Difference)) {
+	if source.IsMemoryOptimized != target.IsMemoryOptimized {
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategoryTable,
+			ObjectName:   tableName,
+			PropertyName: "MemoryOptimized",
+			SourceValue:  onOff(source.IsMemoryOptimized),
+			TargetValue:  onOff(target.IsMemoryOptimized),
+			Description:  fmt.Sprintf("Memory-optimized differs: %s vs %s (the table must be recreated)", onOff(source.IsMemoryOptimized), onOff(target.IsMemoryOptimized)),
+		})
+		return
+	}
+
+	if source.IsMemoryOptimized && !strings.EqualFold(source.Durability, target.Durability) {
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategoryTable,
+			ObjectName:   tableName,
+			PropertyName: "Durability",
+			SourceValue:  source.Durability,
+			TargetValue:  target.Durability,
+			Description:  fmt.Sprintf("Durability differs: %s vs %s (the table must be recreated)", displayValue(source.Durability), displayValue(target.Durability)),
+		})
+	}
+}
+
+// compareTemporal compares whether two tables are system-versioned and the
+// history table they use. Versioning can only be switched on by the migration
+// when the target already has the SYSTEM_TIME period.
+func (c *SchemaComparator) compareTemporal(tableName string, source, target domain.Table, emit func(domain.Difference)) {
+	if source.IsSystemVersioned != target.IsSystemVersioned {
+		d := domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategoryTable,
+			ObjectName:   tableName,
+			PropertyName: "SystemVersioning",
+			SourceValue:  onOff(source.IsSystemVersioned),
+			TargetValue:  onOff(target.IsSystemVersioned),
+			Description:  fmt.Sprintf("System versioning differs: %s vs %s", onOff(source.IsSystemVersioned), onOff(target.IsSystemVersioned)),
+		}
+		if !source.IsSystemVersioned {
+			d.MigrationSQL = fmt.Sprintf("ALTER TABLE %s SET (SYSTEM_VERSIONING = OFF);", tableName)
+		} else if start, end := target.PeriodColumns(); start != "" && end != "" {
+			d.MigrationSQL = fmt.Sprintf("ALTER TABLE %s SET (%s);", tableName, source.SystemVersioningSQL())
+		}
+		emit(d)
+		return
+	}
+
+	if !source.IsSystemVersioned {
+		return
+	}
+	srcHistory := c.qualifiedName(source.HistorySchema, source.HistoryTable)
+	tgtHistory := c.qualifiedName(target.HistorySchema, target.HistoryTable)
+	if !c.namesEqual(srcHistory, tgtHistory) {
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategoryTable,
+			ObjectName:   tableName,
+			PropertyName: "HistoryTable",
+			SourceValue:  srcHistory,
+			TargetValue:  tgtHistory,
+			Description:  fmt.Sprintf("History table differs: %s vs %s", srcHistory, tgtHistory),
+		})
+	}
+}
+
+// onOff renders a boolean setting as ON or OFF
+func onOff(b bool) string {
+	if b {
+		return "ON"
+	}
+	return "OFF"
 }
 
 // compareColumns compares column definitions
-func (c *SchemaComparator) compareColumns(tableName string, source, target []domain.Column, result *domain.DiffResult) {
+func (c *SchemaComparator) compareColumns(tableName string, source, target []domain.Column, emit func(domain.Difference)) {
 	sourceMap := c.columnsToMap(source)
 	targetMap := c.columnsToMap(target)
 
+	// Walk columns by ordinal rather than map order so output is stable
+	source = columnsByOrdinal(source)
+	target = columnsByOrdinal(target)
+
 	// Find removed columns
-	for name, srcCol := range sourceMap {
-		if _, exists := targetMap[name]; !exists {
-			result.Differences = append(result.Differences, domain.Difference{
-				Type:        domain.DiffRemoved,
-				Category:    domain.DiffCategoryColumn,
-				ObjectName:  fmt.Sprintf("%s.%s", tableName, name),
-				Description: fmt.Sprintf("Column [%s] missing in target", name),
-				MigrationSQL: fmt.Sprintf("ALTER TABLE %s ADD %s;", tableName, srcCol.GenerateSQL()),
+	for _, srcCol := range source {
+		if _, exists := targetMap[c.nameKey(srcCol.Name)]; !exists {
+			name := srcCol.Name
+			emit(domain.Difference{
+				Type:         domain.DiffRemoved,
+				Category:     domain.DiffCategoryColumn,
+				ObjectName:   fmt.Sprintf("%s.%s", tableName, name),
+				Description:  fmt.Sprintf("Column [%s] missing in target", name),
+				MigrationSQL: fmt.Sprintf("ALTER TABLE %s ADD %s;", tableName, srcCol.GenerateSQLFor(c.dialect)),
 			})
 		}
 	}
 
 	// Find added columns
-	for name := range targetMap {
-		if _, exists := sourceMap[name]; !exists {
-			result.Differences = append(result.Differences, domain.Difference{
+	for _, tgtCol := range target {
+		if _, exists := sourceMap[c.nameKey(tgtCol.Name)]; !exists {
+			name := tgtCol.Name
+			emit(domain.Difference{
 				Type:        domain.DiffAdded,
 				Category:    domain.DiffCategoryColumn,
 				ObjectName:  fmt.Sprintf("%s.%s", tableName, name),
 				Description: fmt.Sprintf("Column [%s] exists only in target", name),
-				MigrationSQL: fmt.Sprintf("ALTER TABLE %s DROP COLUMN [%s];", tableName, name),
+				MigrationSQL: c.dropSQL(domain.ColumnExistsSQL(tableName, name),
+					fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", tableName, domain.QuoteIdent(name))) + ";",
 			})
 		}
 	}
 
 	// Compare columns that exist in both
-	for name, srcCol := range sourceMap {
-		if tgtCol, exists := targetMap[name]; exists {
-			c.compareColumnDetails(tableName, srcCol, tgtCol, result)
+	for _, srcCol := range source {
+		if tgtCol, exists := targetMap[c.nameKey(srcCol.Name)]; exists {
+			c.compareColumnDetails(tableName, srcCol, tgtCol, emit)
+		}
+	}
+
+	c.compareColumnOrder(tableName, source, target, emit)
+}
+
+// compareColumnOrder reports columns whose position differs. Positions are
+// counted among the columns present on both sides, so an added or dropped
+// column does not make every later column look moved. SQL Server cannot
+// reorder columns in place, so no migration is generated.
+func (c *SchemaComparator) compareColumnOrder(tableName string, source, target []domain.Column, emit func(domain.Difference)) {
+	sourceMap := c.columnsToMap(source)
+	targetMap := c.columnsToMap(target)
+
+	var sourceOrder, targetOrder []string
+	for _, col := range source {
+		if _, exists := targetMap[c.nameKey(col.Name)]; exists {
+			sourceOrder = append(sourceOrder, col.Name)
+		}
+	}
+	targetPosition := make(map[string]int)
+	for _, col := range target {
+		if _, exists := sourceMap[c.nameKey(col.Name)]; exists {
+			targetOrder = append(targetOrder, col.Name)
+			targetPosition[c.nameKey(col.Name)] = len(targetOrder)
 		}
 	}
+
+	for i, name := range sourceOrder {
+		if c.namesEqual(name, targetOrder[i]) {
+			continue
+		}
+		srcPos, tgtPos := i+1, targetPosition[c.nameKey(name)]
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategoryColumn,
+			ObjectName:   fmt.Sprintf("%s.%s", tableName, name),
+			PropertyName: "OrdinalPosition",
+			SourceValue:  fmt.Sprintf("%d", srcPos),
+			TargetValue:  fmt.Sprintf("%d", tgtPos),
+			Description:  fmt.Sprintf("Column order differs: position %d vs %d (reordering requires a table rebuild)", srcPos, tgtPos),
+		})
+	}
 }
 
 // compareColumnDetails compares individual column properties
-func (c *SchemaComparator) compareColumnDetails(tableName string, source, target domain.Column, result *domain.DiffResult) {
+func (c *SchemaComparator) compareColumnDetails(tableName string, source, target domain.Column, emit func(domain.Difference)) {
 	colName := fmt.Sprintf("%s.%s", tableName, source.Name)
 
 	// Compare data type
 	if source.DataType != target.DataType {
-		result.Differences = append(result.Differences, domain.Difference{
+		emit(domain.Difference{
 			Type:         domain.DiffModified,
 			Category:     domain.DiffCategoryColumn,
 			ObjectName:   colName,
@@ -177,13 +585,13 @@ func (c *SchemaComparator) compareColumnDetails(tableName string, source, target
 			SourceValue:  source.DataType,
 			TargetValue:  target.DataType,
 			Description:  fmt.Sprintf("Data type differs: %s vs %s", source.DataType, target.DataType),
-			MigrationSQL: fmt.Sprintf("ALTER TABLE %s ALTER COLUMN [%s] %s;", tableName, source.Name, source.DataType),
+			MigrationSQL: fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s;", tableName, domain.QuoteIdent(source.Name), source.DataType),
 		})
 	}
 
 	// Compare max length (for string types)
 	if source.MaxLength != target.MaxLength {
-		result.Differences = append(result.Differences, domain.Difference{
+		emit(domain.Difference{
 			Type:         domain.DiffModified,
 			Category:     domain.DiffCategoryColumn,
 			ObjectName:   colName,
@@ -196,7 +604,7 @@ func (c *SchemaComparator) compareColumnDetails(tableName string, source, target
 
 	// Compare precision/scale (for numeric types)
 	if source.Precision != target.Precision || source.Scale != target.Scale {
-		result.Differences = append(result.Differences, domain.Difference{
+		emit(domain.Difference{
 			Type:         domain.DiffModified,
 			Category:     domain.DiffCategoryColumn,
 			ObjectName:   colName,
@@ -207,8 +615,8 @@ func (c *SchemaComparator) compareColumnDetails(tableName string, source, target
 		})
 	}
 
-	// Compare nullability
-	if source.IsNullable != target.IsNullable {
+	// Compare nullability (if not ignored)
+	if !c.options.IgnoreNullability && source.IsNullable != target.IsNullable {
 		srcNull := "NULL"
 		tgtNull := "NULL"
 		if !source.IsNullable {
@@ -217,7 +625,7 @@ func (c *SchemaComparator) compareColumnDetails(tableName string, source, target
 		if !target.IsNullable {
 			tgtNull = "NOT NULL"
 		}
-		result.Differences = append(result.Differences, domain.Difference{
+		emit(domain.Difference{
 			Type:         domain.DiffModified,
 			Category:     domain.DiffCategoryColumn,
 			ObjectName:   colName,
@@ -228,9 +636,9 @@ func (c *SchemaComparator) compareColumnDetails(tableName string, source, target
 		})
 	}
 
-	// Compare identity
-	if source.IsIdentity != target.IsIdentity {
-		result.Differences = append(result.Differences, domain.Difference{
+	// Compare identity (if not ignored)
+	if !c.options.IgnoreIdentity && source.IsIdentity != target.IsIdentity {
+		emit(domain.Difference{
 			Type:         domain.DiffModified,
 			Category:     domain.DiffCategoryColumn,
 			ObjectName:   colName,
@@ -241,9 +649,106 @@ func (c *SchemaComparator) compareColumnDetails(tableName string, source, target
 		})
 	}
 
+	// Compare computed expression (if not ignored)
+	srcComputed := c.columnComputed(source)
+	tgtComputed := c.columnComputed(target)
+	if !c.options.IgnoreComputed && srcComputed != tgtComputed {
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategoryColumn,
+			ObjectName:   colName,
+			PropertyName: "Computed",
+			SourceValue:  srcComputed,
+			TargetValue:  tgtComputed,
+			Description:  fmt.Sprintf("Computed expression differs: %s vs %s", displayValue(srcComputed), displayValue(tgtComputed)),
+		})
+	}
+
+	// Compare sparse storage
+	if source.IsSparse != target.IsSparse {
+		change := "DROP SPARSE"
+		if source.IsSparse {
+			change = "ADD SPARSE"
+		}
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategoryColumn,
+			ObjectName:   colName,
+			PropertyName: "Sparse",
+			SourceValue:  fmt.Sprintf("%v", source.IsSparse),
+			TargetValue:  fmt.Sprintf("%v", target.IsSparse),
+			Description:  "Sparse property differs",
+			MigrationSQL: fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s;", tableName, domain.QuoteIdent(source.Name), change),
+		})
+	}
+
+	// Compare masking function, so a missing mask in one environment is flagged
+	if source.MaskingFunction != target.MaskingFunction {
+		migration := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP MASKED;", tableName, domain.QuoteIdent(source.Name))
+		if !c.dialect.Supports(domain.FeatureDataMasking) {
+			migration = ""
+		} else if source.MaskingFunction != "" {
+			migration = fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s ADD %s;", tableName, domain.QuoteIdent(source.Name), source.MaskSQL())
+		}
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategoryColumn,
+			ObjectName:   colName,
+			PropertyName: "MaskingFunction",
+			SourceValue:  source.MaskingFunction,
+			TargetValue:  target.MaskingFunction,
+			Description:  fmt.Sprintf("Masking function differs: %s vs %s", displayValue(source.MaskingFunction), displayValue(target.MaskingFunction)),
+			MigrationSQL: migration,
+		})
+	}
+
+	// Compare ROWGUIDCOL, which merge replication and FILESTREAM rely on
+	if source.IsRowGuidCol != target.IsRowGuidCol {
+		change := "DROP ROWGUIDCOL"
+		if source.IsRowGuidCol {
+			change = "ADD ROWGUIDCOL"
+		}
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategoryColumn,
+			ObjectName:   colName,
+			PropertyName: "RowGuidCol",
+			SourceValue:  fmt.Sprintf("%v", source.IsRowGuidCol),
+			TargetValue:  fmt.Sprintf("%v", target.IsRowGuidCol),
+			Description:  "ROWGUIDCOL property differs",
+			MigrationSQL: fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s;", tableName, domain.QuoteIdent(source.Name), change),
+		})
+	}
+
+	// Compare FILESTREAM storage; it cannot be changed in place
+	if source.IsFilestream != target.IsFilestream {
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategoryColumn,
+			ObjectName:   colName,
+			PropertyName: "Filestream",
+			SourceValue:  fmt.Sprintf("%v", source.IsFilestream),
+			TargetValue:  fmt.Sprintf("%v", target.IsFilestream),
+			Description:  "FILESTREAM property differs",
+		})
+	}
+
+	// Compare column set
+	if source.IsColumnSet != target.IsColumnSet {
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategoryColumn,
+			ObjectName:   colName,
+			PropertyName: "ColumnSet",
+			SourceValue:  fmt.Sprintf("%v", source.IsColumnSet),
+			TargetValue:  fmt.Sprintf("%v", target.IsColumnSet),
+			Description:  "Column set property differs",
+		})
+	}
+
 	// Compare collation (if not ignored)
 	if !c.options.IgnoreCollation && source.Collation != target.Collation {
-		result.Differences = append(result.Differences, domain.Difference{
+		emit(domain.Difference{
 			Type:         domain.DiffModified,
 			Category:     domain.DiffCategoryColumn,
 			ObjectName:   colName,
@@ -253,51 +758,127 @@ func (c *SchemaComparator) compareColumnDetails(tableName string, source, target
 			Description:  fmt.Sprintf("Collation differs: %s vs %s", source.Collation, target.Collation),
 		})
 	}
+
+	// Compare default value (if not ignored). Defaults are compared here as a column
+	// property rather than as standalone constraints, so a changed default is reported once.
+	srcDefault := c.columnDefault(source)
+	tgtDefault := c.columnDefault(target)
+	if !c.options.IgnoreDefaults && srcDefault != tgtDefault {
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategoryColumn,
+			ObjectName:   colName,
+			PropertyName: "Default",
+			SourceValue:  srcDefault,
+			TargetValue:  tgtDefault,
+			Description:  fmt.Sprintf("Default value differs: %s vs %s", displayValue(srcDefault), displayValue(tgtDefault)),
+			MigrationSQL: c.defaultMigrationSQL(tableName, source, target),
+		})
+	}
+}
+
+// columnDefault returns the normalized default expression of a column
+func (c *SchemaComparator) columnDefault(col domain.Column) string {
+	if !col.HasDefault {
+		return ""
+	}
+	return normalizeParentheses(col.DefaultValue)
+}
+
+// columnComputed returns the normalized expression of a computed column
+func (c *SchemaComparator) columnComputed(col domain.Column) string {
+	if !col.IsComputed {
+		return ""
+	}
+	return normalizeParentheses(col.ComputedDefinition)
+}
+
+// defaultMigrationSQL drops the target default constraint and adds the source one
+func (c *SchemaComparator) defaultMigrationSQL(tableName string, source, target domain.Column) string {
+	var stmts []string
+
+	if target.HasDefault && target.DefaultName != "" {
+		stmts = append(stmts, c.dropSQL(domain.ConstraintExistsSQL(tableName, target.DefaultName),
+			fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", tableName, domain.QuoteIdent(target.DefaultName)))+";")
+	}
+
+	if source.HasDefault && source.DefaultValue != "" {
+		if source.DefaultName != "" {
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s DEFAULT %s FOR %s;",
+				tableName, domain.QuoteIdent(source.DefaultName), source.DefaultValue, domain.QuoteIdent(source.Name)))
+		} else {
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ADD DEFAULT %s FOR %s;",
+				tableName, source.DefaultValue, domain.QuoteIdent(source.Name)))
+		}
+	}
+
+	return strings.Join(stmts, "\n")
 }
 
 // compareIndexes compares index definitions
-func (c *SchemaComparator) compareIndexes(tableName string, source, target []domain.Index, result *domain.DiffResult) {
+func (c *SchemaComparator) compareIndexes(tableName string, source, target []domain.Index, emit func(domain.Difference)) {
 	sourceMap := c.indexesToMap(source)
 	targetMap := c.indexesToMap(target)
 
-	for name, srcIdx := range sourceMap {
-		if _, exists := targetMap[name]; !exists {
-			result.Differences = append(result.Differences, domain.Difference{
+	for _, key := range sortedKeys(sourceMap) {
+		srcIdx := sourceMap[key]
+		if _, exists := targetMap[key]; !exists {
+			name := srcIdx.Name
+			d := domain.Difference{
 				Type:        domain.DiffRemoved,
 				Category:    domain.DiffCategoryIndex,
 				ObjectName:  fmt.Sprintf("%s.%s", tableName, name),
 				Description: fmt.Sprintf("Index [%s] missing in target", name),
-				MigrationSQL: srcIdx.GenerateSQL() + ";",
-			})
+			}
+			// Index types the target version does not support have no SQL
+			if sql := srcIdx.GenerateSQLFor(c.dialect); sql != "" {
+				d.MigrationSQL = sql + ";"
+			}
+			emit(d)
 		}
 	}
 
-	for name := range targetMap {
-		if _, exists := sourceMap[name]; !exists {
-			result.Differences = append(result.Differences, domain.Difference{
-				Type:        domain.DiffAdded,
-				Category:    domain.DiffCategoryIndex,
-				ObjectName:  fmt.Sprintf("%s.%s", tableName, name),
-				Description: fmt.Sprintf("Index [%s] exists only in target", name),
-				MigrationSQL: fmt.Sprintf("DROP INDEX [%s] ON %s;", name, tableName),
+	for _, key := range sortedKeys(targetMap) {
+		tgtIdx := targetMap[key]
+		if _, exists := sourceMap[key]; !exists {
+			name := tgtIdx.Name
+			emit(domain.Difference{
+				Type:         domain.DiffAdded,
+				Category:     domain.DiffCategoryIndex,
+				ObjectName:   fmt.Sprintf("%s.%s", tableName, name),
+				Description:  fmt.Sprintf("Index [%s] exists only in target", name),
+				MigrationSQL: c.dropSQL(tgtIdx.ExistsSQL(), tgtIdx.GenerateDropSQL()) + ";",
 			})
 		}
 	}
 
 	// Compare index properties for matching indexes
-	for name, srcIdx := range sourceMap {
-		if tgtIdx, exists := targetMap[name]; exists {
-			c.compareIndexDetails(tableName, srcIdx, tgtIdx, result)
+	for _, key := range sortedKeys(sourceMap) {
+		srcIdx := sourceMap[key]
+		if tgtIdx, exists := targetMap[key]; exists {
+			c.compareIndexDetails(tableName, srcIdx, tgtIdx, emit)
 		}
 	}
 }
 
 // compareIndexDetails compares individual index properties
-func (c *SchemaComparator) compareIndexDetails(tableName string, source, target domain.Index, result *domain.DiffResult) {
+func (c *SchemaComparator) compareIndexDetails(tableName string, source, target domain.Index, emit func(domain.Difference)) {
 	idxName := fmt.Sprintf("%s.%s", tableName, source.Name)
 
+	if source.Type != target.Type {
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategoryIndex,
+			ObjectName:   idxName,
+			PropertyName: "Type",
+			SourceValue:  string(source.Type),
+			TargetValue:  string(target.Type),
+			Description:  fmt.Sprintf("Index type differs: %s vs %s", source.Type, target.Type),
+		})
+	}
+
 	if source.IsUnique != target.IsUnique {
-		result.Differences = append(result.Differences, domain.Difference{
+		emit(domain.Difference{
 			Type:         domain.DiffModified,
 			Category:     domain.DiffCategoryIndex,
 			ObjectName:   idxName,
@@ -309,7 +890,7 @@ func (c *SchemaComparator) compareIndexDetails(tableName string, source, target
 	}
 
 	if source.IsClustered != target.IsClustered {
-		result.Differences = append(result.Differences, domain.Difference{
+		emit(domain.Difference{
 			Type:         domain.DiffModified,
 			Category:     domain.DiffCategoryIndex,
 			ObjectName:   idxName,
@@ -320,11 +901,38 @@ func (c *SchemaComparator) compareIndexDetails(tableName string, source, target
 		})
 	}
 
+	srcFG := c.fileGroupName(source.FileGroup)
+	tgtFG := c.fileGroupName(target.FileGroup)
+	if srcFG != tgtFG {
+		d := domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategoryIndex,
+			ObjectName:   idxName,
+			PropertyName: "FileGroup",
+			SourceValue:  srcFG,
+			TargetValue:  tgtFG,
+			Description:  fmt.Sprintf("Filegroup differs: %s vs %s", srcFG, tgtFG),
+		}
+		// The index moves by a rebuild with DROP_EXISTING = ON. A filegroup
+		// other than PRIMARY is created first, but only the DBA can place its
+		// files, so the rebuild fails until the filegroup has one.
+		var statements []string
+		if !strings.EqualFold(srcFG, "PRIMARY") {
+			statements = append(statements, domain.AddFileGroupSQL(srcFG))
+			d.Description += fmt.Sprintf("; [%s] needs a file before the index can be rebuilt on it", srcFG)
+		}
+		if rebuild := source.RebuildSQL(); rebuild != "" {
+			statements = append(statements, rebuild+";")
+		}
+		d.MigrationSQL = strings.Join(statements, "\n")
+		emit(d)
+	}
+
 	// Compare columns
 	srcCols := c.indexColumnsToString(source.Columns)
 	tgtCols := c.indexColumnsToString(target.Columns)
-	if srcCols != tgtCols {
-		result.Differences = append(result.Differences, domain.Difference{
+	if !c.namesEqual(srcCols, tgtCols) {
+		emit(domain.Difference{
 			Type:         domain.DiffModified,
 			Category:     domain.DiffCategoryIndex,
 			ObjectName:   idxName,
@@ -334,76 +942,314 @@ func (c *SchemaComparator) compareIndexDetails(tableName string, source, target
 			Description:  fmt.Sprintf("Index columns differ: [%s] vs [%s]", srcCols, tgtCols),
 		})
 	}
+
+	// Compare the WHERE filter of filtered indexes; the server stores it with
+	// its own parenthesization, so compare it normalized
+	srcFilter := normalizeParentheses(source.FilterDefinition)
+	tgtFilter := normalizeParentheses(target.FilterDefinition)
+	if srcFilter != tgtFilter {
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategoryIndex,
+			ObjectName:   idxName,
+			PropertyName: "Filter",
+			SourceValue:  source.FilterDefinition,
+			TargetValue:  target.FilterDefinition,
+			Description:  fmt.Sprintf("Index filter differs: %s vs %s", displayValue(source.FilterDefinition), displayValue(target.FilterDefinition)),
+			MigrationSQL: c.dropSQL(target.ExistsSQL(), target.GenerateDropSQL()) + ";\n" + source.GenerateSQL() + ";",
+		})
+	}
+
+	// Compare the bucket count of hash indexes, which a rebuild changes
+	if source.Type == domain.IndexTypeNonclusteredHash && source.BucketCount != target.BucketCount {
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategoryIndex,
+			ObjectName:   idxName,
+			PropertyName: "BucketCount",
+			SourceValue:  fmt.Sprintf("%d", source.BucketCount),
+			TargetValue:  fmt.Sprintf("%d", target.BucketCount),
+			Description:  fmt.Sprintf("Hash index bucket count differs: %d vs %d", source.BucketCount, target.BucketCount),
+			MigrationSQL: fmt.Sprintf("ALTER TABLE %s.%s ALTER INDEX %s REBUILD WITH (BUCKET_COUNT = %d);",
+				domain.QuoteIdent(source.SchemaName), domain.QuoteIdent(source.TableName), domain.QuoteIdent(source.Name), source.BucketCount),
+		})
+	}
+
+	// Compare the index options; PAD_INDEX only changes with a rebuild, the
+	// others can be set in place
+	onTable := fmt.Sprintf("%s ON %s.%s", domain.QuoteIdent(source.Name), domain.QuoteIdent(source.SchemaName), domain.QuoteIdent(source.TableName))
+	options := []struct {
+		property, option string
+		source, target   bool
+		rebuild          bool
+	}{
+		{"PadIndex", "PAD_INDEX", source.PadIndex, target.PadIndex, true},
+		{"IgnoreDupKey", "IGNORE_DUP_KEY", source.IgnoreDupKey, target.IgnoreDupKey, false},
+		{"AllowRowLocks", "ALLOW_ROW_LOCKS", source.AllowRowLocks, target.AllowRowLocks, false},
+		{"AllowPageLocks", "ALLOW_PAGE_LOCKS", source.AllowPageLocks, target.AllowPageLocks, false},
+	}
+	for _, o := range options {
+		if o.source == o.target {
+			continue
+		}
+		migration := fmt.Sprintf("ALTER INDEX %s SET (%s = %s);", onTable, o.option, onOff(o.source))
+		if o.rebuild {
+			migration = fmt.Sprintf("ALTER INDEX %s REBUILD WITH (%s = %s);", onTable, o.option, onOff(o.source))
+		}
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategoryIndex,
+			ObjectName:   idxName,
+			PropertyName: o.property,
+			SourceValue:  onOff(o.source),
+			TargetValue:  onOff(o.target),
+			Description:  fmt.Sprintf("Index option %s differs: %s vs %s", o.option, onOff(o.source), onOff(o.target)),
+			MigrationSQL: migration,
+		})
+	}
 }
 
 // compareForeignKeys compares foreign key definitions
-func (c *SchemaComparator) compareForeignKeys(tableName string, source, target []domain.ForeignKey, result *domain.DiffResult) {
+func (c *SchemaComparator) compareForeignKeys(tableName string, source, target []domain.ForeignKey, emit func(domain.Difference)) {
 	sourceMap := c.foreignKeysToMap(source)
 	targetMap := c.foreignKeysToMap(target)
 
-	for name, srcFK := range sourceMap {
-		if _, exists := targetMap[name]; !exists {
-			result.Differences = append(result.Differences, domain.Difference{
-				Type:        domain.DiffRemoved,
-				Category:    domain.DiffCategoryForeignKey,
-				ObjectName:  fmt.Sprintf("%s.%s", tableName, name),
-				Description: fmt.Sprintf("Foreign key [%s] missing in target", name),
+	for _, key := range sortedKeys(sourceMap) {
+		srcFK := sourceMap[key]
+		if _, exists := targetMap[key]; !exists {
+			name := srcFK.Name
+			emit(domain.Difference{
+				Type:         domain.DiffRemoved,
+				Category:     domain.DiffCategoryForeignKey,
+				ObjectName:   fmt.Sprintf("%s.%s", tableName, name),
+				Description:  fmt.Sprintf("Foreign key [%s] missing in target", name),
 				MigrationSQL: srcFK.GenerateSQL() + ";",
 			})
 		}
 	}
 
-	for name := range targetMap {
-		if _, exists := sourceMap[name]; !exists {
-			result.Differences = append(result.Differences, domain.Difference{
-				Type:        domain.DiffAdded,
-				Category:    domain.DiffCategoryForeignKey,
-				ObjectName:  fmt.Sprintf("%s.%s", tableName, name),
-				Description: fmt.Sprintf("Foreign key [%s] exists only in target", name),
-				MigrationSQL: fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT [%s];", tableName, name),
+	for _, key := range sortedKeys(targetMap) {
+		tgtFK := targetMap[key]
+		if _, exists := sourceMap[key]; !exists {
+			name := tgtFK.Name
+			emit(domain.Difference{
+				Type:         domain.DiffAdded,
+				Category:     domain.DiffCategoryForeignKey,
+				ObjectName:   fmt.Sprintf("%s.%s", tableName, name),
+				Description:  fmt.Sprintf("Foreign key [%s] exists only in target", name),
+				MigrationSQL: c.dropSQL(tgtFK.ExistsSQL(), tgtFK.GenerateDropSQL()) + ";",
 			})
 		}
 	}
+
+	// Compare foreign key properties for matching foreign keys
+	for _, key := range sortedKeys(sourceMap) {
+		srcFK := sourceMap[key]
+		if tgtFK, exists := targetMap[key]; exists {
+			c.compareForeignKeyDetails(tableName, srcFK, tgtFK, emit)
+		}
+	}
+}
+
+// compareForeignKeyDetails compares the column mapping, referenced table and
+// referential actions of a foreign key present in both databases
+func (c *SchemaComparator) compareForeignKeyDetails(tableName string, source, target domain.ForeignKey, emit func(domain.Difference)) {
+	fkName := fmt.Sprintf("%s.%s", tableName, source.Name)
+	var diffs []domain.Difference
+
+	srcCols := c.foreignKeyColumnsToString(source.Columns)
+	tgtCols := c.foreignKeyColumnsToString(target.Columns)
+	if !c.namesEqual(srcCols, tgtCols) {
+		diffs = append(diffs, domain.Difference{
+			PropertyName: "Columns",
+			SourceValue:  srcCols,
+			TargetValue:  tgtCols,
+			Description:  fmt.Sprintf("Foreign key columns differ: [%s] vs [%s]", srcCols, tgtCols),
+		})
+	}
+
+	srcRef := c.qualifiedName(source.ReferencedSchemaName, source.ReferencedTableName)
+	tgtRef := c.qualifiedName(target.ReferencedSchemaName, target.ReferencedTableName)
+	if !c.namesEqual(srcRef, tgtRef) {
+		diffs = append(diffs, domain.Difference{
+			PropertyName: "ReferencedTable",
+			SourceValue:  srcRef,
+			TargetValue:  tgtRef,
+			Description:  fmt.Sprintf("Referenced table differs: %s vs %s", srcRef, tgtRef),
+		})
+	}
+
+	srcDelete := c.referentialAction(source.DeleteAction)
+	tgtDelete := c.referentialAction(target.DeleteAction)
+	if srcDelete != tgtDelete {
+		diffs = append(diffs, domain.Difference{
+			PropertyName: "DeleteAction",
+			SourceValue:  srcDelete,
+			TargetValue:  tgtDelete,
+			Description:  fmt.Sprintf("ON DELETE action differs: %s vs %s", srcDelete, tgtDelete),
+		})
+	}
+
+	srcUpdate := c.referentialAction(source.UpdateAction)
+	tgtUpdate := c.referentialAction(target.UpdateAction)
+	if srcUpdate != tgtUpdate {
+		diffs = append(diffs, domain.Difference{
+			PropertyName: "UpdateAction",
+			SourceValue:  srcUpdate,
+			TargetValue:  tgtUpdate,
+			Description:  fmt.Sprintf("ON UPDATE action differs: %s vs %s", srcUpdate, tgtUpdate),
+		})
+	}
+
+	// The state can be changed in place, but a recreate already applies it
+	srcState := domain.ConstraintState(source.IsDisabled, source.IsNotTrusted)
+	tgtState := domain.ConstraintState(target.IsDisabled, target.IsNotTrusted)
+	if srcState != tgtState {
+		state := domain.Difference{
+			PropertyName: "State",
+			SourceValue:  srcState,
+			TargetValue:  tgtState,
+			Description:  fmt.Sprintf("Foreign key state differs: %s vs %s", srcState, tgtState),
+		}
+		if len(diffs) == 0 {
+			state.MigrationSQL = domain.ConstraintStateSQL(target.SchemaName, target.TableName, target.Name,
+				source.IsDisabled, source.IsNotTrusted) + ";"
+		}
+		diffs = append(diffs, state)
+	}
+
+	// A foreign key cannot be altered in place, so the first difference
+	// carries the drop and recreate for all of them
+	for i := range diffs {
+		diffs[i].Type = domain.DiffModified
+		diffs[i].Category = domain.DiffCategoryForeignKey
+		diffs[i].ObjectName = fkName
+		if i == 0 && diffs[i].MigrationSQL == "" {
+			diffs[i].MigrationSQL = c.dropSQL(target.ExistsSQL(), target.GenerateDropSQL()) + ";\n" + source.GenerateSQL() + ";"
+		}
+	}
+	for _, d := range diffs {
+		emit(d)
+	}
 }
 
 // compareCheckConstraints compares check constraint definitions
-func (c *SchemaComparator) compareCheckConstraints(tableName string, source, target []domain.CheckConstraint, result *domain.DiffResult) {
+func (c *SchemaComparator) compareCheckConstraints(tableName string, source, target []domain.CheckConstraint, emit func(domain.Difference)) {
 	sourceMap := c.checkConstraintsToMap(source)
 	targetMap := c.checkConstraintsToMap(target)
 
-	for name, srcCC := range sourceMap {
-		if _, exists := targetMap[name]; !exists {
-			result.Differences = append(result.Differences, domain.Difference{
-				Type:        domain.DiffRemoved,
-				Category:    domain.DiffCategoryConstraint,
-				ObjectName:  fmt.Sprintf("%s.%s", tableName, name),
-				Description: fmt.Sprintf("Check constraint [%s] missing in target", name),
+	for _, key := range sortedKeys(sourceMap) {
+		srcCC := sourceMap[key]
+		if _, exists := targetMap[key]; !exists {
+			name := srcCC.Name
+			emit(domain.Difference{
+				Type:         domain.DiffRemoved,
+				Category:     domain.DiffCategoryConstraint,
+				ObjectName:   fmt.Sprintf("%s.%s", tableName, name),
+				Description:  fmt.Sprintf("Check constraint [%s] missing in target", name),
 				MigrationSQL: srcCC.GenerateSQL() + ";",
 			})
 		}
 	}
 
-	for name := range targetMap {
-		if _, exists := sourceMap[name]; !exists {
-			result.Differences = append(result.Differences, domain.Difference{
-				Type:        domain.DiffAdded,
-				Category:    domain.DiffCategoryConstraint,
-				ObjectName:  fmt.Sprintf("%s.%s", tableName, name),
-				Description: fmt.Sprintf("Check constraint [%s] exists only in target", name),
-				MigrationSQL: fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT [%s];", tableName, name),
+	for _, key := range sortedKeys(targetMap) {
+		tgtCC := targetMap[key]
+		if _, exists := sourceMap[key]; !exists {
+			name := tgtCC.Name
+			emit(domain.Difference{
+				Type:         domain.DiffAdded,
+				Category:     domain.DiffCategoryConstraint,
+				ObjectName:   fmt.Sprintf("%s.%s", tableName, name),
+				Description:  fmt.Sprintf("Check constraint [%s] exists only in target", name),
+				MigrationSQL: c.dropSQL(tgtCC.ExistsSQL(), tgtCC.GenerateDropSQL()) + ";",
+			})
+		}
+	}
+
+	// Compare enabled/trusted state for matching check constraints
+	for _, key := range sortedKeys(sourceMap) {
+		srcCC := sourceMap[key]
+		tgtCC, exists := targetMap[key]
+		if !exists {
+			continue
+		}
+		srcState := domain.ConstraintState(srcCC.IsDisabled, srcCC.IsNotTrusted)
+		tgtState := domain.ConstraintState(tgtCC.IsDisabled, tgtCC.IsNotTrusted)
+		if srcState != tgtState {
+			emit(domain.Difference{
+				Type:         domain.DiffModified,
+				Category:     domain.DiffCategoryConstraint,
+				ObjectName:   fmt.Sprintf("%s.%s", tableName, srcCC.Name),
+				PropertyName: "State",
+				SourceValue:  srcState,
+				TargetValue:  tgtState,
+				Description:  fmt.Sprintf("Check constraint state differs: %s vs %s", srcState, tgtState),
+				MigrationSQL: domain.ConstraintStateSQL(tgtCC.SchemaName, tgtCC.TableName, tgtCC.Name,
+					srcCC.IsDisabled, srcCC.IsNotTrusted) + ";",
+			})
+		}
+	}
+}
+
+// compareUniqueConstraints compares UNIQUE constraints by name and key columns
+func (c *SchemaComparator) compareUniqueConstraints(tableName string, source, target []domain.UniqueConstraint, emit func(domain.Difference)) {
+	sourceMap := c.uniqueConstraintsToMap(source)
+	targetMap := c.uniqueConstraintsToMap(target)
+
+	for _, key := range sortedKeys(sourceMap) {
+		srcUC := sourceMap[key]
+		name := srcUC.Name
+		tgtUC, exists := targetMap[key]
+		if !exists {
+			emit(domain.Difference{
+				Type:         domain.DiffRemoved,
+				Category:     domain.DiffCategoryConstraint,
+				ObjectName:   fmt.Sprintf("%s.%s", tableName, name),
+				Description:  fmt.Sprintf("Unique constraint [%s] missing in target", name),
+				MigrationSQL: srcUC.GenerateSQL() + ";",
+			})
+			continue
+		}
+
+		srcCols := c.indexColumnsToString(srcUC.Columns)
+		tgtCols := c.indexColumnsToString(tgtUC.Columns)
+		if !c.namesEqual(srcCols, tgtCols) || srcUC.IsClustered != tgtUC.IsClustered {
+			emit(domain.Difference{
+				Type:         domain.DiffModified,
+				Category:     domain.DiffCategoryConstraint,
+				ObjectName:   fmt.Sprintf("%s.%s", tableName, name),
+				PropertyName: "Columns",
+				SourceValue:  srcCols,
+				TargetValue:  tgtCols,
+				Description:  fmt.Sprintf("Unique constraint [%s] differs", name),
+				MigrationSQL: c.dropSQL(tgtUC.ExistsSQL(), tgtUC.GenerateDropSQL()) + ";\n" + srcUC.GenerateSQL() + ";",
+			})
+		}
+	}
+
+	for _, key := range sortedKeys(targetMap) {
+		tgtUC := targetMap[key]
+		if _, exists := sourceMap[key]; !exists {
+			name := tgtUC.Name
+			emit(domain.Difference{
+				Type:         domain.DiffAdded,
+				Category:     domain.DiffCategoryConstraint,
+				ObjectName:   fmt.Sprintf("%s.%s", tableName, name),
+				Description:  fmt.Sprintf("Unique constraint [%s] exists only in target", name),
+				MigrationSQL: c.dropSQL(tgtUC.ExistsSQL(), tgtUC.GenerateDropSQL()) + ";",
 			})
 		}
 	}
 }
 
 // comparePrimaryKeys compares primary key definitions
-func (c *SchemaComparator) comparePrimaryKeys(tableName string, source, target *domain.Index, result *domain.DiffResult) {
+func (c *SchemaComparator) comparePrimaryKeys(tableName string, source, target *domain.Index, emit func(domain.Difference)) {
 	if source == nil && target == nil {
 		return
 	}
 
 	if source == nil && target != nil {
-		result.Differences = append(result.Differences, domain.Difference{
+		emit(domain.Difference{
 			Type:        domain.DiffAdded,
 			Category:    domain.DiffCategoryConstraint,
 			ObjectName:  fmt.Sprintf("%s.PK", tableName),
@@ -413,7 +1259,7 @@ func (c *SchemaComparator) comparePrimaryKeys(tableName string, source, target *
 	}
 
 	if source != nil && target == nil {
-		result.Differences = append(result.Differences, domain.Difference{
+		emit(domain.Difference{
 			Type:        domain.DiffRemoved,
 			Category:    domain.DiffCategoryConstraint,
 			ObjectName:  fmt.Sprintf("%s.PK", tableName),
@@ -425,8 +1271,8 @@ func (c *SchemaComparator) comparePrimaryKeys(tableName string, source, target *
 	// Compare PK columns
 	srcCols := c.indexColumnsToString(source.Columns)
 	tgtCols := c.indexColumnsToString(target.Columns)
-	if srcCols != tgtCols {
-		result.Differences = append(result.Differences, domain.Difference{
+	if !c.namesEqual(srcCols, tgtCols) {
+		emit(domain.Difference{
 			Type:         domain.DiffModified,
 			Category:     domain.DiffCategoryConstraint,
 			ObjectName:   fmt.Sprintf("%s.%s", tableName, source.Name),
@@ -439,13 +1285,15 @@ func (c *SchemaComparator) comparePrimaryKeys(tableName string, source, target *
 }
 
 // compareViews compares view definitions
-func (c *SchemaComparator) compareViews(source, target []domain.View, result *domain.DiffResult) {
+func (c *SchemaComparator) compareViews(source, target []domain.View, emit func(domain.Difference)) {
 	sourceMap := c.viewsToMap(source)
 	targetMap := c.viewsToMap(target)
 
-	for name := range sourceMap {
-		if _, exists := targetMap[name]; !exists {
-			result.Differences = append(result.Differences, domain.Difference{
+	for _, key := range sortedKeys(sourceMap) {
+		srcView := sourceMap[key]
+		if _, exists := targetMap[key]; !exists {
+			name := c.qualifiedName(srcView.SchemaName, srcView.Name)
+			emit(domain.Difference{
 				Type:        domain.DiffRemoved,
 				Category:    domain.DiffCategoryView,
 				ObjectName:  name,
@@ -454,9 +1302,11 @@ func (c *SchemaComparator) compareViews(source, target []domain.View, result *do
 		}
 	}
 
-	for name := range targetMap {
-		if _, exists := sourceMap[name]; !exists {
-			result.Differences = append(result.Differences, domain.Difference{
+	for _, key := range sortedKeys(targetMap) {
+		tgtView := targetMap[key]
+		if _, exists := sourceMap[key]; !exists {
+			name := c.qualifiedName(tgtView.SchemaName, tgtView.Name)
+			emit(domain.Difference{
 				Type:        domain.DiffAdded,
 				Category:    domain.DiffCategoryView,
 				ObjectName:  name,
@@ -466,28 +1316,26 @@ func (c *SchemaComparator) compareViews(source, target []domain.View, result *do
 	}
 
 	// Compare definitions
-	for name, srcView := range sourceMap {
-		if tgtView, exists := targetMap[name]; exists {
-			if !c.definitionsEqual(srcView.Definition, tgtView.Definition) {
-				result.Differences = append(result.Differences, domain.Difference{
-					Type:        domain.DiffModified,
-					Category:    domain.DiffCategoryView,
-					ObjectName:  name,
-					Description: "View definition differs",
-				})
-			}
+	for _, key := range sortedKeys(sourceMap) {
+		srcView := sourceMap[key]
+		if tgtView, exists := targetMap[key]; exists {
+			name := c.qualifiedName(srcView.SchemaName, srcView.Name)
+			c.compareModuleDefinitions(domain.DiffCategoryView, "View", name,
+				srcView.Definition, tgtView.Definition, srcView.DefinitionHash, tgtView.DefinitionHash, emit)
 		}
 	}
 }
 
 // compareProcedures compares stored procedure definitions
-func (c *SchemaComparator) compareProcedures(source, target []domain.StoredProcedure, result *domain.DiffResult) {
+func (c *SchemaComparator) compareProcedures(source, target []domain.StoredProcedure, emit func(domain.Difference)) {
 	sourceMap := c.proceduresToMap(source)
 	targetMap := c.proceduresToMap(target)
 
-	for name := range sourceMap {
-		if _, exists := targetMap[name]; !exists {
-			result.Differences = append(result.Differences, domain.Difference{
+	for _, key := range sortedKeys(sourceMap) {
+		srcProc := sourceMap[key]
+		if _, exists := targetMap[key]; !exists {
+			name := c.qualifiedName(srcProc.SchemaName, srcProc.Name)
+			emit(domain.Difference{
 				Type:        domain.DiffRemoved,
 				Category:    domain.DiffCategoryProcedure,
 				ObjectName:  name,
@@ -496,9 +1344,11 @@ func (c *SchemaComparator) compareProcedures(source, target []domain.StoredProce
 		}
 	}
 
-	for name := range targetMap {
-		if _, exists := sourceMap[name]; !exists {
-			result.Differences = append(result.Differences, domain.Difference{
+	for _, key := range sortedKeys(targetMap) {
+		tgtProc := targetMap[key]
+		if _, exists := sourceMap[key]; !exists {
+			name := c.qualifiedName(tgtProc.SchemaName, tgtProc.Name)
+			emit(domain.Difference{
 				Type:        domain.DiffAdded,
 				Category:    domain.DiffCategoryProcedure,
 				ObjectName:  name,
@@ -507,28 +1357,27 @@ func (c *SchemaComparator) compareProcedures(source, target []domain.StoredProce
 		}
 	}
 
-	for name, srcProc := range sourceMap {
-		if tgtProc, exists := targetMap[name]; exists {
-			if !c.definitionsEqual(srcProc.Definition, tgtProc.Definition) {
-				result.Differences = append(result.Differences, domain.Difference{
-					Type:        domain.DiffModified,
-					Category:    domain.DiffCategoryProcedure,
-					ObjectName:  name,
-					Description: "Procedure definition differs",
-				})
-			}
+	for _, key := range sortedKeys(sourceMap) {
+		srcProc := sourceMap[key]
+		if tgtProc, exists := targetMap[key]; exists {
+			name := c.qualifiedName(srcProc.SchemaName, srcProc.Name)
+			c.compareParameters(domain.DiffCategoryProcedure, name, srcProc.Parameters, tgtProc.Parameters, emit)
+			c.compareModuleDefinitions(domain.DiffCategoryProcedure, "Procedure", name,
+				srcProc.Definition, tgtProc.Definition, srcProc.DefinitionHash, tgtProc.DefinitionHash, emit)
 		}
 	}
 }
 
 // compareFunctions compares function definitions
-func (c *SchemaComparator) compareFunctions(source, target []domain.Function, result *domain.DiffResult) {
+func (c *SchemaComparator) compareFunctions(source, target []domain.Function, emit func(domain.Difference)) {
 	sourceMap := c.functionsToMap(source)
 	targetMap := c.functionsToMap(target)
 
-	for name := range sourceMap {
-		if _, exists := targetMap[name]; !exists {
-			result.Differences = append(result.Differences, domain.Difference{
+	for _, key := range sortedKeys(sourceMap) {
+		srcFunc := sourceMap[key]
+		if _, exists := targetMap[key]; !exists {
+			name := c.qualifiedName(srcFunc.SchemaName, srcFunc.Name)
+			emit(domain.Difference{
 				Type:        domain.DiffRemoved,
 				Category:    domain.DiffCategoryFunction,
 				ObjectName:  name,
@@ -537,9 +1386,11 @@ func (c *SchemaComparator) compareFunctions(source, target []domain.Function, re
 		}
 	}
 
-	for name := range targetMap {
-		if _, exists := sourceMap[name]; !exists {
-			result.Differences = append(result.Differences, domain.Difference{
+	for _, key := range sortedKeys(targetMap) {
+		tgtFunc := targetMap[key]
+		if _, exists := sourceMap[key]; !exists {
+			name := c.qualifiedName(tgtFunc.SchemaName, tgtFunc.Name)
+			emit(domain.Difference{
 				Type:        domain.DiffAdded,
 				Category:    domain.DiffCategoryFunction,
 				ObjectName:  name,
@@ -548,28 +1399,27 @@ func (c *SchemaComparator) compareFunctions(source, target []domain.Function, re
 		}
 	}
 
-	for name, srcFunc := range sourceMap {
-		if tgtFunc, exists := targetMap[name]; exists {
-			if !c.definitionsEqual(srcFunc.Definition, tgtFunc.Definition) {
-				result.Differences = append(result.Differences, domain.Difference{
-					Type:        domain.DiffModified,
-					Category:    domain.DiffCategoryFunction,
-					ObjectName:  name,
-					Description: "Function definition differs",
-				})
-			}
+	for _, key := range sortedKeys(sourceMap) {
+		srcFunc := sourceMap[key]
+		if tgtFunc, exists := targetMap[key]; exists {
+			name := c.qualifiedName(srcFunc.SchemaName, srcFunc.Name)
+			c.compareParameters(domain.DiffCategoryFunction, name, srcFunc.Parameters, tgtFunc.Parameters, emit)
+			c.compareModuleDefinitions(domain.DiffCategoryFunction, "Function", name,
+				srcFunc.Definition, tgtFunc.Definition, srcFunc.DefinitionHash, tgtFunc.DefinitionHash, emit)
 		}
 	}
 }
 
 // compareTriggers compares trigger definitions
-func (c *SchemaComparator) compareTriggers(source, target []domain.Trigger, result *domain.DiffResult) {
+func (c *SchemaComparator) compareTriggers(source, target []domain.Trigger, emit func(domain.Difference)) {
 	sourceMap := c.triggersToMap(source)
 	targetMap := c.triggersToMap(target)
 
-	for name := range sourceMap {
-		if _, exists := targetMap[name]; !exists {
-			result.Differences = append(result.Differences, domain.Difference{
+	for _, key := range sortedKeys(sourceMap) {
+		srcTrig := sourceMap[key]
+		if _, exists := targetMap[key]; !exists {
+			name := c.formatTriggerName(srcTrig)
+			emit(domain.Difference{
 				Type:        domain.DiffRemoved,
 				Category:    domain.DiffCategoryTrigger,
 				ObjectName:  name,
@@ -578,9 +1428,11 @@ func (c *SchemaComparator) compareTriggers(source, target []domain.Trigger, resu
 		}
 	}
 
-	for name := range targetMap {
-		if _, exists := sourceMap[name]; !exists {
-			result.Differences = append(result.Differences, domain.Difference{
+	for _, key := range sortedKeys(targetMap) {
+		tgtTrig := targetMap[key]
+		if _, exists := sourceMap[key]; !exists {
+			name := c.formatTriggerName(tgtTrig)
+			emit(domain.Difference{
 				Type:        domain.DiffAdded,
 				Category:    domain.DiffCategoryTrigger,
 				ObjectName:  name,
@@ -589,38 +1441,441 @@ func (c *SchemaComparator) compareTriggers(source, target []domain.Trigger, resu
 		}
 	}
 
-	for name, srcTrig := range sourceMap {
-		if tgtTrig, exists := targetMap[name]; exists {
-			if !c.definitionsEqual(srcTrig.Definition, tgtTrig.Definition) {
-				result.Differences = append(result.Differences, domain.Difference{
-					Type:        domain.DiffModified,
-					Category:    domain.DiffCategoryTrigger,
-					ObjectName:  name,
-					Description: "Trigger definition differs",
-				})
-			}
+	for _, key := range sortedKeys(sourceMap) {
+		srcTrig := sourceMap[key]
+		if tgtTrig, exists := targetMap[key]; exists {
+			name := c.formatTriggerName(srcTrig)
+			c.compareModuleDefinitions(domain.DiffCategoryTrigger, "Trigger", name,
+				srcTrig.Definition, tgtTrig.Definition, srcTrig.DefinitionHash, tgtTrig.DefinitionHash, emit)
+			c.compareTriggerDetails(name, srcTrig, tgtTrig, emit)
 		}
 	}
 }
 
+// compareTriggerDetails compares the enabled state, timing and firing events.
+// Timing and events change with the definition, so only the state carries a migration.
+func (c *SchemaComparator) compareTriggerDetails(name string, source, target domain.Trigger, emit func(domain.Difference)) {
+	if source.IsDisabled != target.IsDisabled {
+		srcState := triggerState(source.IsDisabled)
+		tgtState := triggerState(target.IsDisabled)
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategoryTrigger,
+			ObjectName:   name,
+			PropertyName: "State",
+			SourceValue:  srcState,
+			TargetValue:  tgtState,
+			Description:  fmt.Sprintf("Trigger state differs: %s vs %s", srcState, tgtState),
+			MigrationSQL: source.StateSQL() + ";",
+		})
+	}
+
+	if source.IsInsteadOf != target.IsInsteadOf {
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategoryTrigger,
+			ObjectName:   name,
+			PropertyName: "Timing",
+			SourceValue:  source.Timing(),
+			TargetValue:  target.Timing(),
+			Description:  fmt.Sprintf("Trigger timing differs: %s vs %s", source.Timing(), target.Timing()),
+		})
+	}
+
+	srcEvents := strings.Join(source.Events, ", ")
+	tgtEvents := strings.Join(target.Events, ", ")
+	if !strings.EqualFold(srcEvents, tgtEvents) {
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategoryTrigger,
+			ObjectName:   name,
+			PropertyName: "Events",
+			SourceValue:  srcEvents,
+			TargetValue:  tgtEvents,
+			Description:  fmt.Sprintf("Trigger events differ: %s vs %s", displayValue(srcEvents), displayValue(tgtEvents)),
+		})
+	}
+}
+
+// triggerState describes whether a trigger fires
+func triggerState(disabled bool) string {
+	if disabled {
+		return "DISABLED"
+	}
+	return "ENABLED"
+}
+
+// comparePermissions compares GRANT/DENY permissions. Permissions are matched
+// by principal, permission and securable, so one whose state changed (e.g.
+// GRANT to DENY) is reported as modified, with the statement that sets the
+// source state.
+func (c *SchemaComparator) comparePermissions(source, target []domain.Permission, emit func(domain.Difference)) {
+	sourceMap := c.permissionsToMap(source)
+	targetMap := c.permissionsToMap(target)
+
+	for _, key := range sortedKeys(sourceMap) {
+		srcPerm := sourceMap[key]
+		if _, exists := targetMap[key]; !exists {
+			name := c.formatPermissionName(srcPerm)
+			emit(domain.Difference{
+				Type:         domain.DiffRemoved,
+				Category:     domain.DiffCategoryPermission,
+				ObjectName:   name,
+				Description:  fmt.Sprintf("Permission [%s] missing in target", name),
+				MigrationSQL: srcPerm.GenerateSQL() + ";",
+			})
+		}
+	}
+
+	for _, key := range sortedKeys(targetMap) {
+		tgtPerm := targetMap[key]
+		if _, exists := sourceMap[key]; !exists {
+			name := c.formatPermissionName(tgtPerm)
+			emit(domain.Difference{
+				Type:         domain.DiffAdded,
+				Category:     domain.DiffCategoryPermission,
+				ObjectName:   name,
+				Description:  fmt.Sprintf("Permission [%s] exists only in target", name),
+				MigrationSQL: tgtPerm.RevokeSQL() + ";",
+			})
+		}
+	}
+
+	for _, key := range sortedKeys(sourceMap) {
+		srcPerm := sourceMap[key]
+		tgtPerm, exists := targetMap[key]
+		if !exists || srcPerm.State == tgtPerm.State {
+			continue
+		}
+		// GRANT and DENY replace each other, but a plain GRANT leaves the
+		// grant option in place, so it has to be revoked
+		migration := srcPerm.GenerateSQL() + ";"
+		if srcPerm.State == "GRANT" && tgtPerm.State == "GRANT_WITH_GRANT_OPTION" {
+			migration = tgtPerm.RevokeGrantOptionSQL() + ";"
+		}
+		name := c.permissionKey(srcPerm)
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     domain.DiffCategoryPermission,
+			ObjectName:   name,
+			PropertyName: "State",
+			SourceValue:  srcPerm.State,
+			TargetValue:  tgtPerm.State,
+			Description:  fmt.Sprintf("Permission [%s] state differs: %s vs %s", name, srcPerm.State, tgtPerm.State),
+			MigrationSQL: migration,
+		})
+	}
+}
+
+// compareModuleDefinitions compares the definitions of a view, procedure,
+// function or trigger, or only their hashes when those were extracted instead.
+// Empty definitions (encrypted or not visible to the login) cannot be
+// compared and are reported instead of treated as equal.
+func (c *SchemaComparator) compareModuleDefinitions(category domain.DiffCategory, kind, name, source, target, sourceHash, targetHash string, emit func(domain.Difference)) {
+	if sourceHash != "" || targetHash != "" {
+		// A server too old to hash its definitions sent the text instead
+		if sourceHash == "" {
+			sourceHash = domain.DefinitionHash(source)
+		}
+		if targetHash == "" {
+			targetHash = domain.DefinitionHash(target)
+		}
+		if sourceHash != targetHash {
+			emit(domain.Difference{
+				Type:         domain.DiffModified,
+				Category:     category,
+				ObjectName:   name,
+				PropertyName: "DefinitionHash",
+				SourceValue:  sourceHash,
+				TargetValue:  targetHash,
+				Description:  fmt.Sprintf("%s definition differs", kind),
+			})
+		}
+		return
+	}
+
+	if source == "" || target == "" {
+		where := "source and target"
+		if source != "" {
+			where = "target"
+		} else if target != "" {
+			where = "source"
+		}
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     category,
+			ObjectName:   name,
+			PropertyName: "Availability",
+			SourceValue:  c.availability(source),
+			TargetValue:  c.availability(target),
+			Description:  fmt.Sprintf("%s definition unavailable in %s (possibly encrypted); cannot compare", kind, where),
+		})
+		return
+	}
+
+	if !c.definitionsEqual(source, target) {
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     category,
+			ObjectName:   name,
+			PropertyName: "Definition",
+			SourceValue:  source,
+			TargetValue:  target,
+			Description:  fmt.Sprintf("%s definition differs", kind),
+			Detail:       c.definitionDiff(source, target),
+		})
+	}
+
+	srcBound := domain.HasSchemaBinding(source)
+	tgtBound := domain.HasSchemaBinding(target)
+	if srcBound != tgtBound {
+		emit(domain.Difference{
+			Type:         domain.DiffModified,
+			Category:     category,
+			ObjectName:   name,
+			PropertyName: "SchemaBinding",
+			SourceValue:  fmt.Sprintf("%v", srcBound),
+			TargetValue:  fmt.Sprintf("%v", tgtBound),
+			Description:  "SCHEMABINDING differs",
+		})
+	}
+}
+
+// compareParameters compares the parameters of a procedure or function by
+// name, so a changed signature is reported parameter by parameter alongside
+// the definition difference
+func (c *SchemaComparator) compareParameters(category domain.DiffCategory, name string, source, target []domain.Parameter, emit func(domain.Difference)) {
+	sourceMap := c.parametersToMap(source)
+	targetMap := c.parametersToMap(target)
+
+	// Positions are compared among the parameters both sides declare, so
+	// adding or dropping one does not move every parameter after it
+	sourceOrder := c.sharedParameterOrder(source, targetMap)
+	targetOrder := c.sharedParameterOrder(target, sourceMap)
+
+	for _, srcParam := range source {
+		if _, exists := targetMap[c.nameKey(srcParam.Name)]; !exists {
+			emit(domain.Difference{
+				Type:         domain.DiffModified,
+				Category:     category,
+				ObjectName:   name,
+				PropertyName: "Parameter " + srcParam.Name,
+				SourceValue:  parameterSignature(srcParam),
+				TargetValue:  "(none)",
+				Description:  fmt.Sprintf("Parameter %s missing in target", srcParam.Name),
+			})
+		}
+	}
+
+	for _, tgtParam := range target {
+		if _, exists := sourceMap[c.nameKey(tgtParam.Name)]; !exists {
+			emit(domain.Difference{
+				Type:         domain.DiffModified,
+				Category:     category,
+				ObjectName:   name,
+				PropertyName: "Parameter " + tgtParam.Name,
+				SourceValue:  "(none)",
+				TargetValue:  parameterSignature(tgtParam),
+				Description:  fmt.Sprintf("Parameter %s exists only in target", tgtParam.Name),
+			})
+		}
+	}
+
+	for _, srcParam := range source {
+		tgtParam, exists := targetMap[c.nameKey(srcParam.Name)]
+		if !exists {
+			continue
+		}
+		property := "Parameter " + srcParam.Name
+
+		srcType, tgtType := srcParam.TypeSQL(), tgtParam.TypeSQL()
+		if !strings.EqualFold(srcType, tgtType) {
+			emit(domain.Difference{
+				Type:         domain.DiffModified,
+				Category:     category,
+				ObjectName:   name,
+				PropertyName: property,
+				SourceValue:  srcType,
+				TargetValue:  tgtType,
+				Description:  fmt.Sprintf("Parameter %s type changed %s→%s", srcParam.Name, tgtType, srcType),
+			})
+		}
+
+		if srcParam.IsOutput != tgtParam.IsOutput {
+			emit(domain.Difference{
+				Type:         domain.DiffModified,
+				Category:     category,
+				ObjectName:   name,
+				PropertyName: property,
+				SourceValue:  parameterDirection(srcParam),
+				TargetValue:  parameterDirection(tgtParam),
+				Description:  fmt.Sprintf("Parameter %s direction changed %s→%s", srcParam.Name, parameterDirection(tgtParam), parameterDirection(srcParam)),
+			})
+		}
+
+		srcDefault, tgtDefault := parameterDefault(srcParam), parameterDefault(tgtParam)
+		if srcDefault != tgtDefault {
+			emit(domain.Difference{
+				Type:         domain.DiffModified,
+				Category:     category,
+				ObjectName:   name,
+				PropertyName: property,
+				SourceValue:  srcDefault,
+				TargetValue:  tgtDefault,
+				Description:  fmt.Sprintf("Parameter %s default changed %s→%s", srcParam.Name, tgtDefault, srcDefault),
+			})
+		}
+
+		if key := c.nameKey(srcParam.Name); sourceOrder[key] != targetOrder[key] {
+			emit(domain.Difference{
+				Type:         domain.DiffModified,
+				Category:     category,
+				ObjectName:   name,
+				PropertyName: property,
+				SourceValue:  fmt.Sprintf("%d", srcParam.Position),
+				TargetValue:  fmt.Sprintf("%d", tgtParam.Position),
+				Description:  fmt.Sprintf("Parameter %s position changed %d→%d", srcParam.Name, tgtParam.Position, srcParam.Position),
+			})
+		}
+	}
+}
+
+// sharedParameterOrder returns the order of the parameters that also appear
+// in other, keyed by name
+func (c *SchemaComparator) sharedParameterOrder(params []domain.Parameter, other map[string]domain.Parameter) map[string]int {
+	sorted := make([]domain.Parameter, len(params))
+	copy(sorted, params)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Position < sorted[j].Position })
+
+	order := make(map[string]int)
+	for _, p := range sorted {
+		key := c.nameKey(p.Name)
+		if _, ok := other[key]; ok {
+			order[key] = len(order)
+		}
+	}
+	return order
+}
+
+// parameterSignature renders a parameter as declared, e.g. @id int OUTPUT
+func parameterSignature(p domain.Parameter) string {
+	s := p.Name + " " + p.TypeSQL()
+	if p.HasDefault {
+		s += " = " + p.DefaultValue
+	}
+	if p.IsOutput {
+		s += " OUTPUT"
+	}
+	if p.IsReadOnly {
+		s += " READONLY"
+	}
+	return s
+}
+
+// parameterDirection describes whether a parameter is input or OUTPUT
+func parameterDirection(p domain.Parameter) string {
+	if p.IsOutput {
+		return "OUTPUT"
+	}
+	return "input"
+}
+
+// parameterDefault describes the recorded default of a parameter
+func parameterDefault(p domain.Parameter) string {
+	if !p.HasDefault {
+		return "(none)"
+	}
+	return p.DefaultValue
+}
+
+// availability describes whether a module definition could be read
+func (c *SchemaComparator) availability(definition string) string {
+	if definition == "" {
+		return "unavailable"
+	}
+	return "available"
+}
+
 // Helper methods for creating maps
 
+// nameKey returns the map key used to match an object name between source and
+// target. Names are folded to lower case when comparing case-insensitively.
+func (c *SchemaComparator) nameKey(name string) string {
+	if c.options.CaseInsensitiveNames {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// namesEqual reports whether two object names refer to the same object
+func (c *SchemaComparator) namesEqual(a, b string) bool {
+	return c.nameKey(a) == c.nameKey(b)
+}
+
+func (c *SchemaComparator) schemasToMap(schemas []domain.Schema) map[string]domain.Schema {
+	m := make(map[string]domain.Schema)
+	for _, s := range schemas {
+		m[c.nameKey(s.Name)] = s
+	}
+	return m
+}
+
 func (c *SchemaComparator) tablesToMap(tables []domain.Table) map[string]domain.Table {
 	m := make(map[string]domain.Table)
 	for _, t := range tables {
-		m[c.formatTableName(t)] = t
+		m[c.nameKey(c.formatTableName(t))] = t
 	}
 	return m
 }
 
+// dropSQL wraps a drop statement in an existence check when GuardedDrops is set
+func (c *SchemaComparator) dropSQL(exists, drop string) string {
+	if c.options.GuardedDrops {
+		return domain.GuardDrop(exists, drop)
+	}
+	return drop
+}
+
 func (c *SchemaComparator) formatTableName(t domain.Table) string {
-	return fmt.Sprintf("[%s].[%s]", t.SchemaName, t.Name)
+	return c.qualifiedName(t.SchemaName, t.Name)
+}
+
+func (c *SchemaComparator) qualifiedName(schema, name string) string {
+	return fmt.Sprintf("%s.%s", domain.QuoteIdent(schema), domain.QuoteIdent(name))
+}
+
+func (c *SchemaComparator) formatTriggerName(t domain.Trigger) string {
+	if t.IsDatabaseScoped() {
+		return fmt.Sprintf("%s ON DATABASE", domain.QuoteIdent(t.Name))
+	}
+	return fmt.Sprintf("%s.%s.%s", domain.QuoteIdent(t.SchemaName), domain.QuoteIdent(t.TableName), domain.QuoteIdent(t.Name))
+}
+
+// sortedKeys returns the keys of m in sorted order, so that differences are
+// reported in the same order on every run
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// columnsByOrdinal returns a copy of columns sorted by ordinal position
+func columnsByOrdinal(columns []domain.Column) []domain.Column {
+	sorted := make([]domain.Column, len(columns))
+	copy(sorted, columns)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].OrdinalPosition < sorted[j].OrdinalPosition
+	})
+	return sorted
 }
 
 func (c *SchemaComparator) columnsToMap(columns []domain.Column) map[string]domain.Column {
 	m := make(map[string]domain.Column)
 	for _, col := range columns {
-		m[col.Name] = col
+		m[c.nameKey(col.Name)] = col
 	}
 	return m
 }
@@ -628,7 +1883,7 @@ func (c *SchemaComparator) columnsToMap(columns []domain.Column) map[string]doma
 func (c *SchemaComparator) indexesToMap(indexes []domain.Index) map[string]domain.Index {
 	m := make(map[string]domain.Index)
 	for _, idx := range indexes {
-		m[idx.Name] = idx
+		m[c.nameKey(idx.Name)] = idx
 	}
 	return m
 }
@@ -636,7 +1891,7 @@ func (c *SchemaComparator) indexesToMap(indexes []domain.Index) map[string]domai
 func (c *SchemaComparator) foreignKeysToMap(fks []domain.ForeignKey) map[string]domain.ForeignKey {
 	m := make(map[string]domain.ForeignKey)
 	for _, fk := range fks {
-		m[fk.Name] = fk
+		m[c.nameKey(fk.Name)] = fk
 	}
 	return m
 }
@@ -644,7 +1899,15 @@ func (c *SchemaComparator) foreignKeysToMap(fks []domain.ForeignKey) map[string]
 func (c *SchemaComparator) checkConstraintsToMap(ccs []domain.CheckConstraint) map[string]domain.CheckConstraint {
 	m := make(map[string]domain.CheckConstraint)
 	for _, cc := range ccs {
-		m[cc.Name] = cc
+		m[c.nameKey(cc.Name)] = cc
+	}
+	return m
+}
+
+func (c *SchemaComparator) uniqueConstraintsToMap(ucs []domain.UniqueConstraint) map[string]domain.UniqueConstraint {
+	m := make(map[string]domain.UniqueConstraint)
+	for _, uc := range ucs {
+		m[c.nameKey(uc.Name)] = uc
 	}
 	return m
 }
@@ -652,7 +1915,7 @@ func (c *SchemaComparator) checkConstraintsToMap(ccs []domain.CheckConstraint) m
 func (c *SchemaComparator) viewsToMap(views []domain.View) map[string]domain.View {
 	m := make(map[string]domain.View)
 	for _, v := range views {
-		m[fmt.Sprintf("[%s].[%s]", v.SchemaName, v.Name)] = v
+		m[c.nameKey(c.qualifiedName(v.SchemaName, v.Name))] = v
 	}
 	return m
 }
@@ -660,7 +1923,15 @@ func (c *SchemaComparator) viewsToMap(views []domain.View) map[string]domain.Vie
 func (c *SchemaComparator) proceduresToMap(procs []domain.StoredProcedure) map[string]domain.StoredProcedure {
 	m := make(map[string]domain.StoredProcedure)
 	for _, p := range procs {
-		m[fmt.Sprintf("[%s].[%s]", p.SchemaName, p.Name)] = p
+		m[c.nameKey(c.qualifiedName(p.SchemaName, p.Name))] = p
+	}
+	return m
+}
+
+func (c *SchemaComparator) parametersToMap(params []domain.Parameter) map[string]domain.Parameter {
+	m := make(map[string]domain.Parameter)
+	for _, p := range params {
+		m[c.nameKey(p.Name)] = p
 	}
 	return m
 }
@@ -668,7 +1939,7 @@ func (c *SchemaComparator) proceduresToMap(procs []domain.StoredProcedure) map[s
 func (c *SchemaComparator) functionsToMap(funcs []domain.Function) map[string]domain.Function {
 	m := make(map[string]domain.Function)
 	for _, f := range funcs {
-		m[fmt.Sprintf("[%s].[%s]", f.SchemaName, f.Name)] = f
+		m[c.nameKey(c.qualifiedName(f.SchemaName, f.Name))] = f
 	}
 	return m
 }
@@ -676,11 +1947,39 @@ func (c *SchemaComparator) functionsToMap(funcs []domain.Function) map[string]do
 func (c *SchemaComparator) triggersToMap(triggers []domain.Trigger) map[string]domain.Trigger {
 	m := make(map[string]domain.Trigger)
 	for _, t := range triggers {
-		m[fmt.Sprintf("[%s].[%s].[%s]", t.SchemaName, t.TableName, t.Name)] = t
+		m[c.nameKey(c.formatTriggerName(t))] = t
 	}
 	return m
 }
 
+func (c *SchemaComparator) permissionsToMap(perms []domain.Permission) map[string]domain.Permission {
+	m := make(map[string]domain.Permission)
+	for _, p := range perms {
+		m[c.nameKey(c.permissionKey(p))] = p
+	}
+	return m
+}
+
+// permissionKey identifies a permission regardless of its state, e.g.
+// "SELECT ON [dbo].[Users] TO [app]"; a principal has at most one state for
+// each permission on a securable
+func (c *SchemaComparator) permissionKey(p domain.Permission) string {
+	name := p.PermissionName
+	if securable := p.Securable(); securable != "" {
+		name += " ON " + securable
+	}
+	return fmt.Sprintf("%s TO %s", name, domain.QuoteIdent(p.Grantee))
+}
+
+// formatPermissionName identifies a permission, e.g. "GRANT SELECT ON [dbo].[Users] TO [app]"
+func (c *SchemaComparator) formatPermissionName(p domain.Permission) string {
+	name := fmt.Sprintf("%s %s", p.State, p.PermissionName)
+	if securable := p.Securable(); securable != "" {
+		name += " ON " + securable
+	}
+	return fmt.Sprintf("%s TO %s", name, domain.QuoteIdent(p.Grantee))
+}
+
 func (c *SchemaComparator) indexColumnsToString(cols []domain.IndexColumn) string {
 	var parts []string
 	for _, col := range cols {
@@ -696,6 +1995,31 @@ func (c *SchemaComparator) indexColumnsToString(cols []domain.IndexColumn) strin
 	return strings.Join(parts, ", ")
 }
 
+// foreignKeyColumnsToString renders a foreign key column mapping, e.g. "CustomerId -> Id"
+func (c *SchemaComparator) foreignKeyColumnsToString(cols []domain.ForeignKeyColumn) string {
+	var parts []string
+	for _, col := range cols {
+		parts = append(parts, fmt.Sprintf("%s -> %s", col.ColumnName, col.ReferencedColumnName))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// referentialAction normalizes a referential action, treating an empty action as NO_ACTION
+func (c *SchemaComparator) referentialAction(action string) string {
+	if action == "" {
+		return "NO_ACTION"
+	}
+	return strings.ToUpper(action)
+}
+
+// fileGroupName normalizes a filegroup name, treating an empty name as PRIMARY
+func (c *SchemaComparator) fileGroupName(fg string) string {
+	if fg == "" {
+		return "PRIMARY"
+	}
+	return fg
+}
+
 // definitionsEqual compares two SQL definitions
 func (c *SchemaComparator) definitionsEqual(source, target string) bool {
 	if c.options.IgnoreWhitespace {
@@ -705,10 +2029,76 @@ func (c *SchemaComparator) definitionsEqual(source, target string) bool {
 	return source == target
 }
 
+// Bounds of the unified diff shown for modified definitions
+const (
+	detailContextLines = 3
+	detailMaxLines     = 60
+)
+
+// definitionDiff returns a bounded unified diff of two definitions
+func (c *SchemaComparator) definitionDiff(source, target string) string {
+	srcLines := domain.SplitLines(source)
+	tgtLines := domain.SplitLines(target)
+	if c.options.IgnoreWhitespace {
+		srcLines = c.normalizeLines(srcLines)
+		tgtLines = c.normalizeLines(tgtLines)
+	}
+	return domain.UnifiedDiff(srcLines, tgtLines, detailContextLines, detailMaxLines)
+}
+
+// normalizeLines normalizes whitespace within each line and drops blank lines
+func (c *SchemaComparator) normalizeLines(lines []string) []string {
+	var normalized []string
+	for _, line := range lines {
+		if line = c.normalizeWhitespace(line); line != "" {
+			normalized = append(normalized, line)
+		}
+	}
+	return normalized
+}
+
 // normalizeWhitespace removes extra whitespace for comparison
 func (c *SchemaComparator) normalizeWhitespace(s string) string {
 	// Replace multiple whitespace with single space
-	re := regexp.MustCompile(`\s+`)
-	s = re.ReplaceAllString(s, " ")
+	s = whitespacePattern.ReplaceAllString(s, " ")
 	return strings.TrimSpace(s)
 }
+
+// normalizeParentheses strips redundant enclosing parentheses, so that
+// SQL Server's stored form "((0))" compares equal to "(0)" or "0"
+func normalizeParentheses(s string) string {
+	s = strings.TrimSpace(s)
+	for len(s) >= 2 && s[0] == '(' && s[len(s)-1] == ')' && enclosedByParens(s) {
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	return s
+}
+
+// enclosedByParens reports whether the first parenthesis of s closes at its last character
+func enclosedByParens(s string) bool {
+	depth := 0
+	inString := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\'':
+			inString = !inString
+		case inString:
+		case s[i] == '(':
+			depth++
+		case s[i] == ')':
+			depth--
+			if depth == 0 && i < len(s)-1 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
+// displayValue returns a printable placeholder for empty values
+func displayValue(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
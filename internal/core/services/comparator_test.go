@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/enunezf/SQLPulse/internal/adapters/memory"
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+)
+
+// compareSchemas compares source with target using the default options
+// changed by configure, when given
+func compareSchemas(source, target *domain.DatabaseSchema, configure func(*domain.DiffOptions)) *domain.DiffResult {
+	opts := domain.DefaultDiffOptions()
+	if configure != nil {
+		configure(opts)
+	}
+	return NewSchemaComparator(opts).Compare(source, target)
+}
+
+func TestComparePermissionStateChange(t *testing.T) {
+	perm := func(state string) domain.Permission {
+		return domain.Permission{
+			State:          state,
+			PermissionName: "SELECT",
+			ClassDesc:      "OBJECT_OR_COLUMN",
+			SchemaName:     "dbo",
+			ObjectName:     "Users",
+			Grantee:        "app",
+		}
+	}
+
+	tests := []struct {
+		name          string
+		source        string
+		target        string
+		wantMigration string
+	}{
+		{"grant to deny", "DENY", "GRANT", "DENY SELECT ON [dbo].[Users] TO [app];"},
+		{"deny to grant", "GRANT", "DENY", "GRANT SELECT ON [dbo].[Users] TO [app];"},
+		{"add grant option", "GRANT_WITH_GRANT_OPTION", "GRANT", "GRANT SELECT ON [dbo].[Users] TO [app] WITH GRANT OPTION;"},
+		{"remove grant option", "GRANT", "GRANT_WITH_GRANT_OPTION", "REVOKE GRANT OPTION FOR SELECT ON [dbo].[Users] FROM [app] CASCADE;"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &domain.DatabaseSchema{Permissions: []domain.Permission{perm(tt.source)}}
+			target := &domain.DatabaseSchema{Permissions: []domain.Permission{perm(tt.target)}}
+			result := compareSchemas(source, target, func(o *domain.DiffOptions) { o.IncludePermissions = true })
+
+			if len(result.Differences) != 1 {
+				t.Fatalf("got %d differences, want 1: %+v", len(result.Differences), result.Differences)
+			}
+			d := result.Differences[0]
+			if d.Type != domain.DiffModified || d.PropertyName != "State" {
+				t.Errorf("got %s %s difference, want MODIFIED State", d.Type, d.PropertyName)
+			}
+			if d.SourceValue != tt.source || d.TargetValue != tt.target {
+				t.Errorf("got states %s vs %s, want %s vs %s", d.SourceValue, d.TargetValue, tt.source, tt.target)
+			}
+			if d.MigrationSQL != tt.wantMigration {
+				t.Errorf("migration = %q, want %q", d.MigrationSQL, tt.wantMigration)
+			}
+		})
+	}
+}
+
+func TestComparePermissionSameState(t *testing.T) {
+	p := domain.Permission{State: "GRANT", PermissionName: "EXECUTE", ClassDesc: "SCHEMA", SchemaName: "api", Grantee: "app"}
+	source := &domain.DatabaseSchema{Permissions: []domain.Permission{p}}
+	target := &domain.DatabaseSchema{Permissions: []domain.Permission{p}}
+	result := compareSchemas(source, target, func(o *domain.DiffOptions) { o.IncludePermissions = true })
+	if len(result.Differences) != 0 {
+		t.Errorf("got differences for identical permissions: %+v", result.Differences)
+	}
+}
+
+// parameterDifferences returns the parameter differences of a procedure
+// compared with the given parameter lists
+func parameterDifferences(source, target []domain.Parameter) []domain.Difference {
+	proc := func(params []domain.Parameter) *domain.DatabaseSchema {
+		return &domain.DatabaseSchema{StoredProcedures: []domain.StoredProcedure{{
+			SchemaName: "dbo", Name: "Save", Definition: "CREATE PROCEDURE dbo.Save AS SELECT 1", Parameters: params,
+		}}}
+	}
+	var diffs []domain.Difference
+	for _, d := range compareSchemas(proc(source), proc(target), nil).Differences {
+		if strings.HasPrefix(d.PropertyName, "Parameter ") {
+			diffs = append(diffs, d)
+		}
+	}
+	return diffs
+}
+
+func TestCompareParametersInsertedParameter(t *testing.T) {
+	target := []domain.Parameter{
+		{Name: "@id", Position: 1, DataType: "int"},
+		{Name: "@name", Position: 2, DataType: "varchar", MaxLength: 50},
+		{Name: "@flag", Position: 3, DataType: "bit"},
+	}
+	source := []domain.Parameter{
+		{Name: "@id", Position: 1, DataType: "int"},
+		{Name: "@tenant", Position: 2, DataType: "int"},
+		{Name: "@name", Position: 3, DataType: "varchar", MaxLength: 50},
+		{Name: "@flag", Position: 4, DataType: "bit"},
+	}
+
+	diffs := parameterDifferences(source, target)
+	if len(diffs) != 1 || diffs[0].PropertyName != "Parameter @tenant" {
+		t.Fatalf("got %+v, want only the inserted @tenant", diffs)
+	}
+}
+
+func TestCompareParametersReordered(t *testing.T) {
+	target := []domain.Parameter{
+		{Name: "@a", Position: 1, DataType: "int"},
+		{Name: "@b", Position: 2, DataType: "int"},
+	}
+	source := []domain.Parameter{
+		{Name: "@b", Position: 1, DataType: "int"},
+		{Name: "@a", Position: 2, DataType: "int"},
+	}
+
+	if diffs := parameterDifferences(source, target); len(diffs) != 2 {
+		t.Errorf("got %d differences, want a position change for @a and @b: %+v", len(diffs), diffs)
+	}
+}
+
+func TestCompareParametersDefaultChanged(t *testing.T) {
+	target := []domain.Parameter{{Name: "@top", Position: 1, DataType: "int", HasDefault: true, DefaultValue: "10"}}
+	source := []domain.Parameter{{Name: "@top", Position: 1, DataType: "int", HasDefault: true, DefaultValue: "100"}}
+
+	diffs := parameterDifferences(source, target)
+	if len(diffs) != 1 || diffs[0].SourceValue != "100" || diffs[0].TargetValue != "10" {
+		t.Errorf("got %+v, want the default change 10→100", diffs)
+	}
+}
+
+func TestCompareDefinitionHashes(t *testing.T) {
+	const definition = "CREATE VIEW dbo.v AS SELECT 1 AS a"
+	view := func(definition, hash string) *domain.DatabaseSchema {
+		return &domain.DatabaseSchema{Views: []domain.View{{SchemaName: "dbo", Name: "v", Definition: definition, DefinitionHash: hash}}}
+	}
+
+	tests := []struct {
+		name           string
+		source, target *domain.DatabaseSchema
+		wantChanged    bool
+	}{
+		{"whitespace only", view("", domain.DefinitionHash(definition)), view("", domain.DefinitionHash("CREATE VIEW dbo.v\r\nAS\n\tSELECT 1 AS a")), false},
+		{"changed", view("", domain.DefinitionHash(definition)), view("", domain.DefinitionHash("CREATE VIEW dbo.v AS SELECT 2 AS a")), true},
+		{"text from an older server", view("", domain.DefinitionHash(definition)), view("CREATE VIEW dbo.v AS  SELECT 1 AS a", ""), false},
+		{"changed text from an older server", view("CREATE VIEW dbo.v AS SELECT 2 AS a", ""), view("", domain.DefinitionHash(definition)), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := NewSchemaComparator(nil).CompareDefinitions(tt.source, tt.target)
+			if len(changes) != 1 || changes[0].Changed != tt.wantChanged {
+				t.Errorf("got %+v, want changed=%v", changes, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestCompareSchemaWithDroppedOwner(t *testing.T) {
+	// The extractor reports the owner of a schema whose principal was dropped as empty
+	source, err := memory.NewSchema("Source").Schema("legacy", "").Store().ExtractSchema(context.Background(), domain.DefaultDumpOptions())
+	if err != nil {
+		t.Fatalf("ExtractSchema: %v", err)
+	}
+	if len(source.Schemas) != 1 || source.Schemas[0].Name != "legacy" {
+		t.Fatalf("extracted schemas %+v, want legacy kept", source.Schemas)
+	}
+
+	t.Run("missing in target", func(t *testing.T) {
+		result := compareSchemas(source, &domain.DatabaseSchema{}, nil)
+		if len(result.Differences) != 1 {
+			t.Fatalf("got %d differences, want 1: %+v", len(result.Differences), result.Differences)
+		}
+		if got := result.Differences[0].MigrationSQL; got != "CREATE SCHEMA [legacy];" {
+			t.Errorf("migration = %q, want CREATE SCHEMA without AUTHORIZATION", got)
+		}
+	})
+
+	t.Run("owned in target", func(t *testing.T) {
+		target := memory.NewSchema("Target").Schema("legacy", "bob").Build()
+		result := compareSchemas(source, target, nil)
+		if len(result.Differences) != 1 {
+			t.Fatalf("got %d differences, want the owner difference: %+v", len(result.Differences), result.Differences)
+		}
+		if d := result.Differences[0]; d.PropertyName != "Owner" || d.MigrationSQL != "" {
+			t.Errorf("got %s difference with migration %q, want Owner with none", d.PropertyName, d.MigrationSQL)
+		}
+	})
+}
+
+func TestCompareForeignKeysSameNameInOtherSchema(t *testing.T) {
+	orders := func(schema, refColumn string) *memory.TableBuilder {
+		return memory.NewTable(schema, "Orders").
+			Column(memory.NewColumn("CustomerId", "int")).
+			ForeignKey("FK_Orders_Customers", schema, "Customers", []string{"CustomerId"}, []string{refColumn})
+	}
+	source := memory.NewSchema("Source").Table(orders("sales", "Id")).Table(orders("archive", "Id")).Build()
+	target := memory.NewSchema("Target").Table(orders("sales", "Id")).Table(orders("archive", "LegacyId")).Build()
+
+	var fkDiffs []domain.Difference
+	for _, d := range compareSchemas(source, target, nil).Differences {
+		if d.Category == domain.DiffCategoryForeignKey {
+			fkDiffs = append(fkDiffs, d)
+		}
+	}
+	if len(fkDiffs) != 1 || !strings.HasPrefix(fkDiffs[0].ObjectName, "[archive].") {
+		t.Errorf("got %+v, want one difference for the archive foreign key", fkDiffs)
+	}
+}
+
+func TestCompareStreamCountsPastLimit(t *testing.T) {
+	source := memory.NewSchema("Source")
+	for _, name := range []string{"A", "B", "C", "D", "E"} {
+		source.Table(memory.NewTable("dbo", name).Column(memory.NewColumn("Id", "int")))
+	}
+
+	opts := domain.DefaultDiffOptions()
+	opts.MaxDifferences = 2
+	var emitted []domain.Difference
+	omitted := NewSchemaComparator(opts).CompareStream(source.Build(), &domain.DatabaseSchema{}, func(d domain.Difference) {
+		emitted = append(emitted, d)
+	})
+
+	// The tables come from one category, so the cap must apply within it
+	if len(emitted) != 2 {
+		t.Errorf("emitted %d differences, want 2", len(emitted))
+	}
+	if omitted != 3 {
+		t.Errorf("omitted = %d, want the 3 tables past the cap", omitted)
+	}
+}
+
+func TestCompareIndexFileGroup(t *testing.T) {
+	table := func(fileGroup string) *domain.DatabaseSchema {
+		return &domain.DatabaseSchema{Tables: []domain.Table{{
+			SchemaName: "dbo", Name: "Orders",
+			Columns: []domain.Column{{Name: "Id", DataType: "int"}},
+			Indexes: []domain.Index{{
+				SchemaName: "dbo", TableName: "Orders", Name: "IX_Orders_Id", FileGroup: fileGroup,
+				Columns: []domain.IndexColumn{{Name: "Id"}}, AllowRowLocks: true, AllowPageLocks: true,
+			}},
+		}}}
+	}
+
+	tests := []struct {
+		name           string
+		source, target string
+		wantMigration  string
+	}{
+		{"moved to a new filegroup", "ARCHIVE", "", "IF NOT EXISTS (SELECT 1 FROM sys.filegroups WHERE name = N'ARCHIVE')\nBEGIN\nALTER DATABASE CURRENT ADD FILEGROUP [ARCHIVE];\nEND\n" +
+			"CREATE NONCLUSTERED INDEX [IX_Orders_Id] ON [dbo].[Orders] (\n    [Id]\n) WITH (DROP_EXISTING = ON) ON [ARCHIVE];"},
+		{"moved back to PRIMARY", "", "ARCHIVE", "CREATE NONCLUSTERED INDEX [IX_Orders_Id] ON [dbo].[Orders] (\n    [Id]\n) WITH (DROP_EXISTING = ON) ON [PRIMARY];"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diffs []domain.Difference
+			for _, d := range compareSchemas(table(tt.source), table(tt.target), nil).Differences {
+				if d.PropertyName == "FileGroup" {
+					diffs = append(diffs, d)
+				}
+			}
+			if len(diffs) != 1 {
+				t.Fatalf("got %d filegroup differences, want 1: %+v", len(diffs), diffs)
+			}
+			if diffs[0].MigrationSQL != tt.wantMigration {
+				t.Errorf("migration = %q, want %q", diffs[0].MigrationSQL, tt.wantMigration)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+	"github.com/enunezf/SQLPulse/internal/core/ports"
+)
+
+// DiffDatabases extracts the source and target schemas and compares them.
+// It has no dependency on the CLI, so it can be embedded in other programs:
+//
+//	result, err := services.DiffDatabases(ctx,
+//		sqlserver.NewSchemaExtractor(sourceDB),
+//		sqlserver.NewSchemaExtractor(targetDB),
+//		domain.DefaultDiffOptions())
+//
+// Objects excluded by opts are not extracted. A nil opts uses DefaultDiffOptions.
+func DiffDatabases(ctx context.Context, source, target ports.SchemaPort, opts *domain.DiffOptions) (*domain.DiffResult, error) {
+	if opts == nil {
+		opts = domain.DefaultDiffOptions()
+	}
+	dumpOpts := opts.DumpOptions()
+
+	sourceSchema, err := source.ExtractSchema(ctx, dumpOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract source schema: %w", err)
+	}
+
+	targetSchema, err := target.ExtractSchema(ctx, dumpOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract target schema: %w", err)
+	}
+
+	return NewSchemaComparator(opts).Compare(sourceSchema, targetSchema), nil
+}
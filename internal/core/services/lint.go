@@ -0,0 +1,149 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+)
+
+// LintSchema inspects an extracted schema for common smells: heap tables,
+// foreign keys without a supporting index, and nullable primary key columns.
+// Findings are sorted by severity and object name.
+func LintSchema(schema *domain.DatabaseSchema) []domain.LintFinding {
+	var findings []domain.LintFinding
+
+	for _, t := range schema.Tables {
+		tableName := fmt.Sprintf("%s.%s", domain.QuoteIdent(t.SchemaName), domain.QuoteIdent(t.Name))
+
+		if !hasClusteredIndex(t) {
+			findings = append(findings, domain.LintFinding{
+				Severity:   domain.LintWarning,
+				Rule:       domain.LintRuleHeapTable,
+				ObjectName: tableName,
+				Message:    "Table is a heap (no clustered index or primary key)",
+			})
+		}
+
+		if t.PrimaryKey != nil {
+			nullable := nullableColumns(t)
+			for _, col := range t.PrimaryKey.Columns {
+				if nullable[strings.ToLower(col.Name)] {
+					findings = append(findings, domain.LintFinding{
+						Severity:   domain.LintError,
+						Rule:       domain.LintRuleNullablePKColumn,
+						ObjectName: fmt.Sprintf("%s.%s", tableName, domain.QuoteIdent(col.Name)),
+						Message:    fmt.Sprintf("Column is part of primary key [%s] but is nullable", t.PrimaryKey.Name),
+					})
+				}
+			}
+		}
+
+		for _, fk := range t.ForeignKeys {
+			if !hasSupportingIndex(t, fk) {
+				findings = append(findings, domain.LintFinding{
+					Severity:   domain.LintWarning,
+					Rule:       domain.LintRuleUnindexedFK,
+					ObjectName: fmt.Sprintf("%s.%s", tableName, domain.QuoteIdent(fk.Name)),
+					Message:    fmt.Sprintf("Foreign key columns (%s) have no supporting index", foreignKeyColumnNames(fk)),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Severity != findings[j].Severity {
+			return findings[i].Severity == domain.LintError
+		}
+		return findings[i].ObjectName < findings[j].ObjectName
+	})
+	return findings
+}
+
+// hasClusteredIndex reports whether a table has a clustered primary key or index
+func hasClusteredIndex(t domain.Table) bool {
+	if t.PrimaryKey != nil && t.PrimaryKey.IsClustered {
+		return true
+	}
+	for _, idx := range t.Indexes {
+		if idx.IsClustered || idx.Type == domain.IndexTypeClusteredColumnstore {
+			return true
+		}
+	}
+	for _, uc := range t.UniqueConstraints {
+		if uc.IsClustered {
+			return true
+		}
+	}
+	return false
+}
+
+// nullableColumns returns the lowercased names of a table's nullable columns
+func nullableColumns(t domain.Table) map[string]bool {
+	m := make(map[string]bool)
+	for _, col := range t.Columns {
+		if col.IsNullable {
+			m[strings.ToLower(col.Name)] = true
+		}
+	}
+	return m
+}
+
+// hasSupportingIndex reports whether the foreign key columns are the leading
+// key columns (in any order) of the primary key, an index or a unique constraint
+func hasSupportingIndex(t domain.Table, fk domain.ForeignKey) bool {
+	var candidates [][]domain.IndexColumn
+	if t.PrimaryKey != nil {
+		candidates = append(candidates, t.PrimaryKey.Columns)
+	}
+	for _, idx := range t.Indexes {
+		if !idx.IsDisabled {
+			candidates = append(candidates, idx.Columns)
+		}
+	}
+	for _, uc := range t.UniqueConstraints {
+		candidates = append(candidates, uc.Columns)
+	}
+
+	for _, cols := range candidates {
+		if leadingColumnsCover(cols, fk.Columns) {
+			return true
+		}
+	}
+	return false
+}
+
+// leadingColumnsCover reports whether the first len(fkCols) key columns of an
+// index are exactly the foreign key columns
+func leadingColumnsCover(cols []domain.IndexColumn, fkCols []domain.ForeignKeyColumn) bool {
+	var keys []string
+	for _, col := range cols {
+		if !col.IsIncluded {
+			keys = append(keys, strings.ToLower(col.Name))
+		}
+	}
+	if len(fkCols) == 0 || len(keys) < len(fkCols) {
+		return false
+	}
+
+	leading := make(map[string]bool)
+	for _, k := range keys[:len(fkCols)] {
+		leading[k] = true
+	}
+	for _, c := range fkCols {
+		if !leading[strings.ToLower(c.ColumnName)] {
+			return false
+		}
+	}
+	return true
+}
+
+// foreignKeyColumnNames returns the comma-separated referencing columns of a foreign key
+func foreignKeyColumnNames(fk domain.ForeignKey) string {
+	var names []string
+	for _, c := range fk.Columns {
+		names = append(names, c.ColumnName)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+	"github.com/enunezf/SQLPulse/internal/security"
+)
+
+// ClassifyRisk returns the risk of applying a difference's migration SQL to
+// the target database
+func ClassifyRisk(d domain.Difference) domain.Risk {
+	switch d.Type {
+	case domain.DiffAdded:
+		// Objects that exist only in the target are dropped
+		return domain.RiskDestructive
+	case domain.DiffRemoved:
+		// Objects that exist only in the source are created
+		return domain.RiskModification
+	case domain.DiffModified:
+		if d.Category == domain.DiffCategoryColumn {
+			return columnChangeRisk(d)
+		}
+		return domain.RiskModification
+	default:
+		return domain.RiskDestructive
+	}
+}
+
+// ApprovalLevel returns the approval level required to apply a change of the
+// given risk; unclassified changes need destructive approval
+func ApprovalLevel(r domain.Risk) security.ApprovalLevel {
+	if r.IsDestructive() {
+		return security.Destructive
+	}
+	return security.Modification
+}
+
+// ClassifyRisks tags each difference in the result with its risk
+func ClassifyRisks(result *domain.DiffResult) {
+	for i := range result.Differences {
+		result.Differences[i].Risk = ClassifyRisk(result.Differences[i])
+	}
+}
+
+// columnChangeRisk classifies a column property change. Changes that can
+// truncate or reject existing values are destructive; widening a column or
+// changing its default or position is not.
+func columnChangeRisk(d domain.Difference) domain.Risk {
+	switch d.PropertyName {
+	case "Default", "OrdinalPosition", "Sparse", "MaskingFunction", "RowGuidCol":
+		return domain.RiskModification
+	case "MaxLength":
+		// The target column takes the source length; -1 is MAX
+		src, srcErr := strconv.Atoi(d.SourceValue)
+		tgt, tgtErr := strconv.Atoi(d.TargetValue)
+		if srcErr == nil && tgtErr == nil && (src == -1 || (tgt != -1 && src > tgt)) {
+			return domain.RiskModification
+		}
+	case "Precision/Scale":
+		var srcPrecision, srcScale, tgtPrecision, tgtScale int
+		_, srcErr := fmt.Sscanf(d.SourceValue, "(%d,%d)", &srcPrecision, &srcScale)
+		_, tgtErr := fmt.Sscanf(d.TargetValue, "(%d,%d)", &tgtPrecision, &tgtScale)
+		if srcErr == nil && tgtErr == nil && srcPrecision >= tgtPrecision && srcScale >= tgtScale &&
+			srcPrecision-srcScale >= tgtPrecision-tgtScale {
+			return domain.RiskModification
+		}
+	case "Nullability":
+		// Making a column nullable cannot fail
+		if d.SourceValue == "NULL" {
+			return domain.RiskModification
+		}
+	}
+	return domain.RiskDestructive
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/enunezf/SQLPulse/internal/core/domain"
+	"github.com/enunezf/SQLPulse/internal/security"
+)
+
+func TestApprovalLevelUnclassifiedIsDestructive(t *testing.T) {
+	var d domain.Difference
+	if got := ApprovalLevel(d.Risk); got != security.Destructive {
+		t.Errorf("unclassified difference needs %s approval, want Destructive", got)
+	}
+	if got := ApprovalLevel(domain.RiskModification); got != security.Modification {
+		t.Errorf("modification needs %s approval, want Modification", got)
+	}
+}
+
+func TestClassifyRisk(t *testing.T) {
+	tests := []struct {
+		name string
+		d    domain.Difference
+		want domain.Risk
+	}{
+		{"create", domain.Difference{Type: domain.DiffRemoved, Category: domain.DiffCategoryTable}, domain.RiskModification},
+		{"drop", domain.Difference{Type: domain.DiffAdded, Category: domain.DiffCategoryTable}, domain.RiskDestructive},
+		{"widen column", domain.Difference{Type: domain.DiffModified, Category: domain.DiffCategoryColumn, PropertyName: "MaxLength", SourceValue: "100", TargetValue: "50"}, domain.RiskModification},
+		{"narrow column", domain.Difference{Type: domain.DiffModified, Category: domain.DiffCategoryColumn, PropertyName: "MaxLength", SourceValue: "50", TargetValue: "100"}, domain.RiskDestructive},
+		{"make nullable", domain.Difference{Type: domain.DiffModified, Category: domain.DiffCategoryColumn, PropertyName: "Nullability", SourceValue: "NULL", TargetValue: "NOT NULL"}, domain.RiskModification},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyRisk(tt.d); got != tt.want {
+				t.Errorf("ClassifyRisk() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareStreamClassifiesRisk(t *testing.T) {
+	source := &domain.DatabaseSchema{Tables: []domain.Table{{SchemaName: "dbo", Name: "Orders", Columns: []domain.Column{{Name: "Id", DataType: "int"}}}}}
+	target := &domain.DatabaseSchema{}
+
+	streamed := 0
+	NewSchemaComparator(nil).CompareStream(source, target, func(d domain.Difference) {
+		streamed++
+		if d.Risk != domain.RiskModification {
+			t.Errorf("streamed difference %s has risk %s, want Modification", d.ObjectName, d.Risk)
+		}
+	})
+	if streamed == 0 {
+		t.Error("no differences streamed")
+	}
+}
@@ -5,9 +5,17 @@ package security
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"runtime"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/enunezf/SQLPulse/internal/color"
 )
 
 // ApprovalLevel defines the risk level of an operation
@@ -36,102 +44,409 @@ func (a ApprovalLevel) String() string {
 	}
 }
 
+// ErrCancelled is returned when an operation is not approved
+var ErrCancelled = errors.New("operation cancelled by user")
+
+// ErrNotInteractive is returned when approval must be asked but stdin is not
+// a terminal, so nobody can answer the prompt
+var ErrNotInteractive = errors.New("approval required but stdin is not a terminal: run the command from a terminal to answer the prompt, or use --dry-run to preview the changes")
+
+// errNoResponse is returned by a prompt left unanswered for the approval timeout
+var errNoResponse = errors.New("no response")
+
+// DefaultApprovalTimeout is how long an interactive prompt waits for an answer
+const DefaultApprovalTimeout = 5 * time.Minute
+
+// DefaultConfirmationPhrase is the word typed to confirm destructive operations
+// when the request does not specify one
+const DefaultConfirmationPhrase = "CONFIRM"
+
+// editChoice is typed at a destructive confirmation to edit the SQL first
+const editChoice = "edit"
+
 // ApprovalRequest represents a request for user approval
 type ApprovalRequest struct {
-	Operation     string        // Description of the operation
-	SQL           string        // SQL script to execute
-	Level         ApprovalLevel // Risk level
-	ImpactSummary string        // Summary of the impact
+	Operation          string        // Description of the operation
+	SQL                string        // SQL script to execute
+	Level              ApprovalLevel // Risk level
+	ImpactSummary      string        // Summary of the impact
+	ConfirmationPhrase string        // Text to type for destructive operations (default CONFIRM)
+	Editable           bool          // The user may edit SQL before approving it
+}
+
+// Decision is the outcome of an approval request
+type Decision struct {
+	Approved  bool
+	EditedSQL string // SQL to execute instead of the request's, empty when not edited
 }
 
 // Approver defines the interface for approval handling
 type Approver interface {
-	RequestApproval(req ApprovalRequest) (bool, error)
+	RequestApproval(ctx context.Context, req ApprovalRequest) (Decision, error)
+}
+
+// BatchApprover is an Approver that can also approve a whole plan of
+// requests at once. The decision is remembered for the requests that follow.
+type BatchApprover interface {
+	Approver
+	// RequestBatchApproval returns false when the whole plan is rejected
+	RequestBatchApproval(ctx context.Context, reqs []ApprovalRequest) (bool, error)
+}
+
+// SessionApprover is an Approver that keeps a tally of the requests it
+// receives and can summarize them when the session ends
+type SessionApprover interface {
+	Approver
+	// Summary describes the requests received so far
+	Summary() string
 }
 
+// coloredLevel returns the risk level colored by severity: modifications in
+// yellow, destructive operations in red
+func coloredLevel(level ApprovalLevel) string {
+	switch level {
+	case Modification:
+		return color.Yellow(level.String())
+	case Destructive:
+		return color.Red(level.String())
+	}
+	return level.String()
+}
+
+// batchMode is the plan decision remembered by an InteractiveApprover
+type batchMode int
+
+const (
+	batchStep       batchMode = iota // Confirm each request separately
+	batchApproveAll                  // Approve every request without asking
+	batchRejectAll                   // Reject every request without asking
+)
+
 // InteractiveApprover implements approval via terminal interaction
 type InteractiveApprover struct {
-	reader *bufio.Reader
+	reader   *bufio.Reader
+	terminal bool          // stdin is a terminal
+	timeout  time.Duration // 0 waits for an answer forever
+	pending  *pendingRead
+	prompts  int // Prompts that read a line so far
+	batch    batchMode
+}
+
+// lineResult is a line read from stdin
+type lineResult struct {
+	line string
+	err  error
+}
+
+// pendingRead is a read from stdin started for a prompt
+type pendingRead struct {
+	prompt int
+	result chan lineResult
 }
 
 // NewInteractiveApprover creates a new interactive approver
 func NewInteractiveApprover() *InteractiveApprover {
 	return &InteractiveApprover{
-		reader: bufio.NewReader(os.Stdin),
+		reader:   bufio.NewReader(os.Stdin),
+		terminal: isTerminal(os.Stdin),
+		timeout:  DefaultApprovalTimeout,
 	}
 }
 
-// RequestApproval prompts the user for confirmation based on the operation level
-func (a *InteractiveApprover) RequestApproval(req ApprovalRequest) (bool, error) {
-	switch req.Level {
-	case ReadOnly:
+// SetTimeout sets how long a prompt waits for an answer before the operation
+// is not approved (0 = no limit)
+func (a *InteractiveApprover) SetTimeout(timeout time.Duration) {
+	a.timeout = timeout
+}
+
+// RequestApproval prompts the user for confirmation based on the operation
+// level. An unanswered prompt is not approved once the timeout elapses.
+func (a *InteractiveApprover) RequestApproval(ctx context.Context, req ApprovalRequest) (Decision, error) {
+	if req.Level == ReadOnly {
 		// No confirmation needed for read-only operations
-		return true, nil
+		return Decision{Approved: true}, nil
+	}
 
-	case Modification:
-		return a.requestSimpleConfirmation(req)
+	switch a.batch {
+	case batchApproveAll:
+		return Decision{Approved: true}, nil
+	case batchRejectAll:
+		return Decision{}, nil
+	}
+
+	if !a.terminal {
+		return Decision{}, ErrNotInteractive
+	}
 
+	var decision Decision
+	var err error
+	switch req.Level {
+	case Modification:
+		decision.Approved, err = a.requestSimpleConfirmation(ctx, req)
 	case Destructive:
-		return a.requestStrictConfirmation(req)
+		decision, err = a.requestStrictConfirmation(ctx, req)
+	default:
+		return Decision{}, fmt.Errorf("unknown approval level: %d", req.Level)
+	}
+
+	if errors.Is(err, errNoResponse) {
+		fmt.Println("\n" + color.Red(fmt.Sprintf("Operation cancelled. No response within %s.", a.timeout)))
+		return Decision{}, nil
+	}
+	return decision, err
+}
+
+// RequestBatchApproval shows every request of a plan and asks once whether
+// to approve all of them, reject all of them or confirm each one in turn.
+// Approving a plan with destructive requests requires typing the
+// confirmation word, as for a single destructive request.
+func (a *InteractiveApprover) RequestBatchApproval(ctx context.Context, reqs []ApprovalRequest) (bool, error) {
+	level := ReadOnly
+	confirmWord := ""
+	for _, req := range reqs {
+		level = max(level, req.Level)
+		if req.Level == Destructive && confirmWord == "" {
+			confirmWord = req.ConfirmationPhrase
+		}
+	}
+	if level == ReadOnly {
+		return true, nil
+	}
+	if !a.terminal {
+		return false, ErrNotInteractive
+	}
+	if confirmWord == "" {
+		confirmWord = DefaultConfirmationPhrase
+	}
+
+	fmt.Println("\n" + strings.Repeat("─", 60))
+	fmt.Printf("%s %d operation(s)\n", color.Bold("Plan:"), len(reqs))
+	fmt.Printf("%s %s\n", color.Bold("Risk Level:"), level)
+	for i, req := range reqs {
+		fmt.Printf("\n%3d. [%s] %s\n", i+1, req.Level, req.Operation)
+		if req.SQL != "" {
+			fmt.Println(color.Cyan(req.SQL))
+		}
+	}
+	fmt.Println(strings.Repeat("─", 60))
+
+	fmt.Print("\nApprove all, reject all or step through each operation? [a/r/S]: ")
+	response, err := a.readLine(ctx)
+	if errors.Is(err, errNoResponse) {
+		fmt.Println("\n" + color.Red(fmt.Sprintf("Plan rejected. No response within %s.", a.timeout)))
+		a.batch = batchRejectAll
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	switch strings.TrimSpace(strings.ToLower(response)) {
+	case "a", "all":
+		if level == Destructive {
+			fmt.Print("\n" + color.Red("⛔ WARNING: The plan includes DESTRUCTIVE operations!") + "\n")
+			fmt.Printf("Type '%s' to approve all of them: ", confirmWord)
+			response, err := a.readLine(ctx)
+			if err != nil && !errors.Is(err, errNoResponse) {
+				return false, err
+			}
+			if err != nil || strings.TrimSpace(response) != confirmWord {
+				fmt.Println("\n" + color.Red("Plan rejected. Confirmation word did not match."))
+				a.batch = batchRejectAll
+				return false, nil
+			}
+		}
+		a.batch = batchApproveAll
+		return true, nil
+
+	case "r", "reject":
+		a.batch = batchRejectAll
+		return false, nil
 
 	default:
-		return false, fmt.Errorf("unknown approval level: %d", req.Level)
+		a.batch = batchStep
+		return true, nil
 	}
 }
 
 // requestSimpleConfirmation asks for y/n confirmation
-func (a *InteractiveApprover) requestSimpleConfirmation(req ApprovalRequest) (bool, error) {
+func (a *InteractiveApprover) requestSimpleConfirmation(ctx context.Context, req ApprovalRequest) (bool, error) {
 	a.displayOperationDetails(req)
 
-	fmt.Print("\n\033[33m⚠ This operation will modify data.\033[0m\n")
+	fmt.Print("\n" + color.Yellow("⚠ This operation will modify data.") + "\n")
 	fmt.Print("Do you want to proceed? [y/N]: ")
 
-	response, err := a.reader.ReadString('\n')
+	response, err := a.readLine(ctx)
 	if err != nil {
-		return false, fmt.Errorf("failed to read response: %w", err)
+		return false, err
 	}
 
 	response = strings.TrimSpace(strings.ToLower(response))
 	return response == "y" || response == "yes", nil
 }
 
-// requestStrictConfirmation asks for confirmation + typing a specific word
-func (a *InteractiveApprover) requestStrictConfirmation(req ApprovalRequest) (bool, error) {
-	a.displayOperationDetails(req)
+// requestStrictConfirmation asks for confirmation + typing a specific word.
+// For editable requests the user may instead edit the SQL, which is then
+// shown and confirmed again.
+func (a *InteractiveApprover) requestStrictConfirmation(ctx context.Context, req ApprovalRequest) (Decision, error) {
+	confirmWord := req.ConfirmationPhrase
+	if confirmWord == "" {
+		confirmWord = DefaultConfirmationPhrase
+	}
+
+	var decision Decision
+	for {
+		a.displayOperationDetails(req)
+
+		fmt.Print("\n" + color.Red("⛔ WARNING: This is a DESTRUCTIVE operation!") + "\n")
+		fmt.Print(color.Red("This action cannot be undone.") + "\n\n")
+
+		if req.Editable {
+			fmt.Printf("Type '%s' to proceed or '%s' to edit the SQL: ", confirmWord, editChoice)
+		} else {
+			fmt.Printf("Type '%s' to proceed: ", confirmWord)
+		}
+
+		response, err := a.readLine(ctx)
+		if err != nil {
+			return Decision{}, err
+		}
+
+		response = strings.TrimSpace(response)
+		if response == confirmWord {
+			decision.Approved = true
+			return decision, nil
+		}
+		if !req.Editable || response != editChoice {
+			fmt.Println("\n" + color.Red("Operation cancelled. Confirmation word did not match."))
+			return Decision{}, nil
+		}
+
+		edited, err := editSQL(req.SQL)
+		if err != nil {
+			return Decision{}, err
+		}
+		if edited == "" {
+			fmt.Println("\n" + color.Red("Operation cancelled. The edited SQL is empty."))
+			return Decision{}, nil
+		}
+		req.SQL = edited
+		decision.EditedSQL = edited
+	}
+}
+
+// readLine reads a line from stdin, giving up when ctx is done or with
+// errNoResponse once the timeout elapses. A read given up on cannot be
+// interrupted, so it is kept, but the line it returns answered a prompt that
+// was already cancelled: it is discarded rather than taken as the answer to
+// a later prompt, which may be for a different operation.
+func (a *InteractiveApprover) readLine(ctx context.Context) (string, error) {
+	a.prompts++
+	prompt := a.prompts
+
+	var timeout <-chan time.Time
+	if a.timeout > 0 {
+		timer := time.NewTimer(a.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
 
-	fmt.Print("\n\033[31m⛔ WARNING: This is a DESTRUCTIVE operation!\033[0m\n")
-	fmt.Print("\033[31mThis action cannot be undone.\033[0m\n\n")
+	for {
+		if a.pending == nil {
+			a.pending = a.startRead(prompt)
+		}
+
+		select {
+		case result := <-a.pending.result:
+			stale := a.pending.prompt != prompt
+			a.pending = nil
+			if stale {
+				continue
+			}
+			if result.err != nil {
+				return "", fmt.Errorf("failed to read response: %w", result.err)
+			}
+			return result.line, nil
+		case <-timeout:
+			return "", errNoResponse
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
 
-	confirmWord := "CONFIRM"
-	fmt.Printf("Type '%s' to proceed: ", confirmWord)
+// startRead starts reading a line from stdin for the given prompt
+func (a *InteractiveApprover) startRead(prompt int) *pendingRead {
+	p := &pendingRead{prompt: prompt, result: make(chan lineResult, 1)}
+	go func() {
+		line, err := a.reader.ReadString('\n')
+		p.result <- lineResult{line, err}
+	}()
+	return p
+}
 
-	response, err := a.reader.ReadString('\n')
+// isTerminal reports whether f is a character device
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
 	if err != nil {
-		return false, fmt.Errorf("failed to read response: %w", err)
+		return false
 	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
 
-	response = strings.TrimSpace(response)
-	if response != confirmWord {
-		fmt.Println("\n\033[31mOperation cancelled. Confirmation word did not match.\033[0m")
-		return false, nil
+// editSQL opens sql in the user's $EDITOR and returns the saved text
+func editSQL(sql string) (string, error) {
+	f, err := os.CreateTemp("", "sqlpulse-*.sql")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
 	}
+	path := f.Name()
+	defer os.Remove(path)
 
-	return true, nil
+	_, err = f.WriteString(sql + "\n")
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	// EDITOR may carry arguments, e.g. "code --wait"
+	args := strings.Fields(os.Getenv("EDITOR"))
+	if len(args) == 0 {
+		args = []string{"vi"}
+		if runtime.GOOS == "windows" {
+			args = []string{"notepad"}
+		}
+	}
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %s failed: %w", args[0], err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited SQL: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
 }
 
 // displayOperationDetails shows the operation information to the user
 func (a *InteractiveApprover) displayOperationDetails(req ApprovalRequest) {
 	fmt.Println("\n" + strings.Repeat("─", 60))
-	fmt.Printf("\033[1mOperation:\033[0m %s\n", req.Operation)
-	fmt.Printf("\033[1mRisk Level:\033[0m %s\n", req.Level)
+	fmt.Printf("%s %s\n", color.Bold("Operation:"), req.Operation)
+	fmt.Printf("%s %s\n", color.Bold("Risk Level:"), req.Level)
 
 	if req.ImpactSummary != "" {
-		fmt.Printf("\033[1mImpact:\033[0m %s\n", req.ImpactSummary)
+		fmt.Printf("%s %s\n", color.Bold("Impact:"), req.ImpactSummary)
 	}
 
 	if req.SQL != "" {
-		fmt.Println("\n\033[1mSQL to execute:\033[0m")
-		fmt.Println("\033[36m" + req.SQL + "\033[0m")
+		fmt.Println("\n" + color.Bold("SQL to execute:"))
+		fmt.Println(color.Cyan(req.SQL))
 	}
 
 	fmt.Println(strings.Repeat("─", 60))
@@ -148,36 +463,85 @@ func NewAutoApprover(approve bool) *AutoApprover {
 }
 
 // RequestApproval returns the configured approval decision
-func (a *AutoApprover) RequestApproval(req ApprovalRequest) (bool, error) {
-	return a.approve, nil
+func (a *AutoApprover) RequestApproval(ctx context.Context, req ApprovalRequest) (Decision, error) {
+	return Decision{Approved: a.approve}, nil
 }
 
-// DryRunApprover displays what would happen but never approves
-type DryRunApprover struct{}
+// DryRunApprover displays what would happen but never approves. It counts
+// the operations of the session by risk level.
+type DryRunApprover struct {
+	mu     sync.Mutex
+	counts map[ApprovalLevel]int
+}
 
 // NewDryRunApprover creates a new dry-run approver
 func NewDryRunApprover() *DryRunApprover {
-	return &DryRunApprover{}
+	return &DryRunApprover{counts: make(map[ApprovalLevel]int)}
 }
 
-// RequestApproval displays the operation but always returns false
-func (a *DryRunApprover) RequestApproval(req ApprovalRequest) (bool, error) {
-	fmt.Println("\n\033[34m[DRY-RUN MODE]\033[0m The following operation would be executed:")
+// RequestApproval displays the operation and the running tally but never
+// approves changes. Read-only operations make no changes and are always
+// approved.
+func (a *DryRunApprover) RequestApproval(ctx context.Context, req ApprovalRequest) (Decision, error) {
+	if req.Level == ReadOnly {
+		return Decision{Approved: true}, nil
+	}
+
+	a.mu.Lock()
+	a.counts[req.Level]++
+	tally := a.tally()
+	a.mu.Unlock()
+
+	fmt.Println("\n" + color.Blue("[DRY-RUN MODE]") + " The following operation would be executed:")
 	fmt.Println(strings.Repeat("─", 60))
-	fmt.Printf("\033[1mOperation:\033[0m %s\n", req.Operation)
-	fmt.Printf("\033[1mRisk Level:\033[0m %s\n", req.Level)
+	fmt.Printf("%s %s\n", color.Bold("Operation:"), req.Operation)
+	fmt.Printf("%s %s\n", color.Bold("Risk Level:"), coloredLevel(req.Level))
 
 	if req.ImpactSummary != "" {
-		fmt.Printf("\033[1mImpact:\033[0m %s\n", req.ImpactSummary)
+		fmt.Printf("%s %s\n", color.Bold("Impact:"), req.ImpactSummary)
 	}
 
 	if req.SQL != "" {
-		fmt.Println("\n\033[1mSQL that would execute:\033[0m")
-		fmt.Println("\033[36m" + req.SQL + "\033[0m")
+		fmt.Println("\n" + color.Bold("SQL that would execute:"))
+		fmt.Println(color.Cyan(req.SQL))
 	}
 
 	fmt.Println(strings.Repeat("─", 60))
-	fmt.Println("\033[34mNo changes were made (dry-run mode).\033[0m")
+	fmt.Println(color.Blue("No changes were made (dry-run mode).") + " So far: " + tally)
+
+	return Decision{}, nil
+}
+
+// Summary returns the totals of the session, e.g. "Dry run: 12
+// modifications, 3 destructive operations would execute."
+func (a *DryRunApprover) Summary() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return "Dry run: " + a.tally() + " would execute."
+}
+
+// tally counts the operations received by risk level, colored by severity.
+// The caller holds a.mu.
+func (a *DryRunApprover) tally() string {
+	modifications, destructive := a.counts[Modification], a.counts[Destructive]
+	if modifications == 0 && destructive == 0 {
+		return "no operations"
+	}
+
+	var parts []string
+	if modifications > 0 {
+		parts = append(parts, color.Yellow(plural(modifications, "modification", "modifications")))
+	}
+	if destructive > 0 {
+		parts = append(parts, color.Red(plural(destructive, "destructive operation", "destructive operations")))
+	}
+	return strings.Join(parts, ", ")
+}
 
-	return false, nil
+// plural returns n followed by the noun in the singular (one) or plural (many)
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, one)
+	}
+	return fmt.Sprintf("%d %s", n, many)
 }
@@ -0,0 +1,88 @@
+package security
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// newPipeApprover returns an interactive approver reading its answers from
+// the returned pipe writer
+func newPipeApprover(timeout time.Duration) (*InteractiveApprover, *io.PipeWriter) {
+	r, w := io.Pipe()
+	return &InteractiveApprover{
+		reader:   bufio.NewReader(r),
+		terminal: true,
+		timeout:  timeout,
+	}, w
+}
+
+func TestReadLineDiscardsLateAnswer(t *testing.T) {
+	a, w := newPipeApprover(50 * time.Millisecond)
+	defer w.Close()
+	ctx := context.Background()
+
+	if _, err := a.readLine(ctx); !errors.Is(err, errNoResponse) {
+		t.Fatalf("first prompt: got %v, want errNoResponse", err)
+	}
+
+	// The answer to the timed-out prompt arrives while the next one waits
+	go w.Write([]byte("y\n"))
+
+	line, err := a.readLine(ctx)
+	if !errors.Is(err, errNoResponse) {
+		t.Fatalf("second prompt: got %q, %v; want the late answer discarded", line, err)
+	}
+}
+
+func TestReadLineAnswersNextPromptAfterLateAnswer(t *testing.T) {
+	a, w := newPipeApprover(50 * time.Millisecond)
+	defer w.Close()
+	ctx := context.Background()
+
+	if _, err := a.readLine(ctx); !errors.Is(err, errNoResponse) {
+		t.Fatalf("first prompt: got %v, want errNoResponse", err)
+	}
+
+	a.SetTimeout(time.Second)
+	go func() {
+		w.Write([]byte("y\n"))
+		w.Write([]byte("n\n"))
+	}()
+
+	line, err := a.readLine(ctx)
+	if err != nil {
+		t.Fatalf("second prompt: %v", err)
+	}
+	if line != "n\n" {
+		t.Errorf("second prompt got %q, want %q", line, "n\n")
+	}
+}
+
+func TestReadLineCancelledPromptDiscardsAnswer(t *testing.T) {
+	a, w := newPipeApprover(0)
+	defer w.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := a.readLine(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled prompt: got %v, want context.Canceled", err)
+	}
+
+	a.SetTimeout(time.Second)
+	go func() {
+		w.Write([]byte("delete\n"))
+		w.Write([]byte("no\n"))
+	}()
+
+	line, err := a.readLine(context.Background())
+	if err != nil {
+		t.Fatalf("next prompt: %v", err)
+	}
+	if line != "no\n" {
+		t.Errorf("next prompt got %q, want %q", line, "no\n")
+	}
+}
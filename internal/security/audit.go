@@ -0,0 +1,175 @@
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"sync"
+	"time"
+)
+
+// Audit decisions recorded in the log
+const (
+	AuditRequested = "requested"
+	AuditApproved  = "approved"
+	AuditDenied    = "denied"
+	AuditError     = "error"
+)
+
+// AuditEntry is a single record of the audit log
+type AuditEntry struct {
+	Timestamp     time.Time `json:"timestamp"`
+	Server        string    `json:"server"`
+	Database      string    `json:"database"`
+	OSUser        string    `json:"os_user"`
+	Operation     string    `json:"operation"`
+	Level         string    `json:"level"`
+	SQL           string    `json:"sql,omitempty"`
+	EditedSQL     string    `json:"edited_sql,omitempty"`
+	ImpactSummary string    `json:"impact_summary,omitempty"`
+	Decision      string    `json:"decision"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// AuditingApprover wraps another approver and appends every request and its
+// decision to a JSONL file
+type AuditingApprover struct {
+	next     Approver
+	path     string
+	server   string
+	database string
+	osUser   string
+	mu       sync.Mutex
+}
+
+// NewAuditingApprover creates an approver that audits the decisions of next
+func NewAuditingApprover(next Approver, path, server, database string) *AuditingApprover {
+	return &AuditingApprover{
+		next:     next,
+		path:     path,
+		server:   server,
+		database: database,
+		osUser:   currentOSUser(),
+	}
+}
+
+// RequestApproval records the request, delegates to the wrapped approver and
+// records the outcome, so the log shows a request even when SQLPulse dies
+// while it waits for an answer. If the audit record cannot be written the
+// operation is not approved.
+func (a *AuditingApprover) RequestApproval(ctx context.Context, req ApprovalRequest) (Decision, error) {
+	entry := a.entry(req.Operation, req.Level)
+	entry.SQL = req.SQL
+	entry.ImpactSummary = req.ImpactSummary
+	if err := a.write(entry); err != nil {
+		return Decision{}, fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	decision, err := a.next.RequestApproval(ctx, req)
+
+	entry.Timestamp = time.Now().UTC()
+	entry.EditedSQL = decision.EditedSQL
+	entry.Decision, entry.Error = outcome(decision.Approved, err)
+	if logErr := a.write(entry); logErr != nil {
+		return Decision{}, fmt.Errorf("failed to write audit log: %w", logErr)
+	}
+
+	return decision, err
+}
+
+// RequestBatchApproval delegates a plan approval to the wrapped approver and
+// records its outcome as one entry; each request is still recorded when it
+// runs. An approver without plan approval steps through the requests.
+func (a *AuditingApprover) RequestBatchApproval(ctx context.Context, reqs []ApprovalRequest) (bool, error) {
+	batch, ok := a.next.(BatchApprover)
+	if !ok {
+		return true, nil
+	}
+
+	level := ReadOnly
+	for _, req := range reqs {
+		level = max(level, req.Level)
+	}
+	entry := a.entry(fmt.Sprintf("Plan of %d operation(s)", len(reqs)), level)
+	if err := a.write(entry); err != nil {
+		return false, fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	approved, err := batch.RequestBatchApproval(ctx, reqs)
+
+	entry.Timestamp = time.Now().UTC()
+	entry.Decision, entry.Error = outcome(approved, err)
+	if logErr := a.write(entry); logErr != nil {
+		return false, fmt.Errorf("failed to write audit log: %w", logErr)
+	}
+
+	return approved, err
+}
+
+// entry returns the record of a request that has not been answered yet
+func (a *AuditingApprover) entry(operation string, level ApprovalLevel) AuditEntry {
+	return AuditEntry{
+		Timestamp: time.Now().UTC(),
+		Server:    a.server,
+		Database:  a.database,
+		OSUser:    a.osUser,
+		Operation: operation,
+		Level:     level.String(),
+		Decision:  AuditRequested,
+	}
+}
+
+// outcome returns the decision and error recorded for an answered request
+func outcome(approved bool, err error) (decision, errText string) {
+	switch {
+	case err != nil:
+		return AuditError, err.Error()
+	case approved:
+		return AuditApproved, ""
+	}
+	return AuditDenied, ""
+}
+
+// Summary returns the summary of the wrapped approver's session, or "" when
+// it keeps no tally
+func (a *AuditingApprover) Summary() string {
+	if session, ok := a.next.(SessionApprover); ok {
+		return session.Summary()
+	}
+	return ""
+}
+
+// write appends an entry to the audit log file
+func (a *AuditingApprover) write(entry AuditEntry) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// currentOSUser returns the name of the operating system user running SQLPulse
+func currentOSUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
@@ -0,0 +1,97 @@
+package security
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// approverFunc adapts a function to the Approver interface
+type approverFunc func(ctx context.Context, req ApprovalRequest) (Decision, error)
+
+func (f approverFunc) RequestApproval(ctx context.Context, req ApprovalRequest) (Decision, error) {
+	return f(ctx, req)
+}
+
+// readAuditLog returns the entries of the audit log at path
+func readAuditLog(t *testing.T, path string) []AuditEntry {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open audit log: %v", err)
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("parse audit entry %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestAuditingApproverRecordsRequestBeforeDelegating(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	req := ApprovalRequest{Operation: "Drop table", SQL: "DROP TABLE [dbo].[Old];", Level: Destructive}
+
+	var whileAsking []AuditEntry
+	next := approverFunc(func(ctx context.Context, req ApprovalRequest) (Decision, error) {
+		whileAsking = readAuditLog(t, path)
+		return Decision{Approved: true}, nil
+	})
+	a := NewAuditingApprover(next, path, "srv", "db")
+
+	if _, err := a.RequestApproval(context.Background(), req); err != nil {
+		t.Fatalf("RequestApproval: %v", err)
+	}
+
+	if len(whileAsking) != 1 || whileAsking[0].Decision != AuditRequested || whileAsking[0].SQL != req.SQL {
+		t.Errorf("log while asking = %+v, want the request entry", whileAsking)
+	}
+	entries := readAuditLog(t, path)
+	if len(entries) != 2 || entries[1].Decision != AuditApproved || entries[1].SQL != req.SQL {
+		t.Errorf("log after answering = %+v, want the request then the approval", entries)
+	}
+}
+
+func TestAuditingApproverRecordsError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	next := approverFunc(func(ctx context.Context, req ApprovalRequest) (Decision, error) {
+		return Decision{}, context.Canceled
+	})
+	a := NewAuditingApprover(next, path, "srv", "db")
+
+	if _, err := a.RequestApproval(context.Background(), ApprovalRequest{Operation: "Alter", Level: Modification}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	entries := readAuditLog(t, path)
+	if len(entries) != 2 || entries[1].Decision != AuditError || entries[1].Error == "" {
+		t.Errorf("log = %+v, want the request then the error", entries)
+	}
+}
+
+func TestAuditingApproverUnwritableLogDoesNotAsk(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "audit.jsonl")
+	asked := false
+	next := approverFunc(func(ctx context.Context, req ApprovalRequest) (Decision, error) {
+		asked = true
+		return Decision{Approved: true}, nil
+	})
+	a := NewAuditingApprover(next, path, "srv", "db")
+
+	decision, err := a.RequestApproval(context.Background(), ApprovalRequest{Operation: "Alter", Level: Modification})
+	if err == nil || decision.Approved {
+		t.Errorf("got %+v, %v; want an error and no approval", decision, err)
+	}
+	if asked {
+		t.Error("the wrapped approver was asked although the request could not be recorded")
+	}
+}